@@ -31,7 +31,58 @@ type SecurityConfig struct {
 }
 
 type PayGateConfig struct {
-	ServerKey string
+	Provider    string
+	ServerKey   string
+	Environment string
+	FakeURL     string
+}
+
+type CancelPolicyConfig struct {
+	FullRefundHours      int
+	PartialRefundHours   int
+	PartialRefundPercent int
+}
+
+type DepositConfig struct {
+	Percent int
+}
+
+type ScheduleConfig struct {
+	OpenTime    string
+	CloseTime   string
+	SlotMinutes int
+}
+
+type ExpiryConfig struct {
+	HoldTime      time.Duration
+	SweepInterval time.Duration
+}
+
+type ReconcileConfig struct {
+	StaleAfter time.Duration
+	Interval   time.Duration
+}
+
+type NoShowConfig struct {
+	GracePeriod   time.Duration
+	SweepInterval time.Duration
+}
+
+// NoShowPenaltyConfig restricts customers who keep missing their bookings. A
+// customer with Count no-shows within WindowDays is restricted for Days; a
+// zero Count switches the rule off.
+type NoShowPenaltyConfig struct {
+	BlockCount       int
+	BlockWindowDays  int
+	BlockDays        int
+	PrepayCount      int
+	PrepayWindowDays int
+	PrepayDays       int
+}
+
+type WaitlistConfig struct {
+	OfferTime     time.Duration
+	SweepInterval time.Duration
 }
 
 type Config struct {
@@ -39,6 +90,30 @@ type Config struct {
 	AppConfig
 	SecurityConfig
 	PayGateConfig
+	CancelPolicyConfig
+	DepositConfig
+	ScheduleConfig
+	ExpiryConfig
+	ReconcileConfig
+	NoShowConfig
+	NoShowPenaltyConfig
+	WaitlistConfig
+}
+
+func getEnv(key string, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
 }
 
 func (c *Config) readConfig() error {
@@ -60,7 +135,55 @@ func (c *Config) readConfig() error {
 	}
 
 	c.PayGateConfig = PayGateConfig{
-		ServerKey: os.Getenv("MIDTRANS_SB_SERVER_KEY"),
+		Provider:    getEnv("PAYMENT_PROVIDER", "midtrans"),
+		ServerKey:   getEnv("MIDTRANS_SERVER_KEY", os.Getenv("MIDTRANS_SB_SERVER_KEY")),
+		Environment: getEnv("MIDTRANS_ENVIRONMENT", "sandbox"),
+		FakeURL:     getEnv("FAKE_GATEWAY_URL", "http://localhost"+c.AppPort+"/api/v1/fake-gateway"),
+	}
+
+	c.CancelPolicyConfig = CancelPolicyConfig{
+		FullRefundHours:      getEnvInt("CANCEL_FULL_REFUND_HOURS", 24),
+		PartialRefundHours:   getEnvInt("CANCEL_PARTIAL_REFUND_HOURS", 6),
+		PartialRefundPercent: getEnvInt("CANCEL_PARTIAL_REFUND_PERCENT", 50),
+	}
+
+	c.DepositConfig = DepositConfig{
+		Percent: getEnvInt("DOWN_PAYMENT_PERCENT", 50),
+	}
+
+	c.ScheduleConfig = ScheduleConfig{
+		OpenTime:    getEnv("COURT_OPEN_TIME", "08:00:00"),
+		CloseTime:   getEnv("COURT_CLOSE_TIME", "23:00:00"),
+		SlotMinutes: getEnvInt("COURT_SLOT_MINUTES", 60),
+	}
+
+	c.ExpiryConfig = ExpiryConfig{
+		HoldTime:      time.Minute * time.Duration(getEnvInt("BOOKING_HOLD_MINUTES", 30)),
+		SweepInterval: time.Second * time.Duration(getEnvInt("BOOKING_SWEEP_INTERVAL_SECONDS", 60)),
+	}
+
+	c.ReconcileConfig = ReconcileConfig{
+		StaleAfter: time.Minute * time.Duration(getEnvInt("PAYMENT_RECONCILE_AFTER_MINUTES", 10)),
+		Interval:   time.Second * time.Duration(getEnvInt("PAYMENT_RECONCILE_INTERVAL_SECONDS", 300)),
+	}
+
+	c.NoShowConfig = NoShowConfig{
+		GracePeriod:   time.Minute * time.Duration(getEnvInt("NO_SHOW_GRACE_MINUTES", 15)),
+		SweepInterval: time.Second * time.Duration(getEnvInt("NO_SHOW_SWEEP_INTERVAL_SECONDS", 300)),
+	}
+
+	c.NoShowPenaltyConfig = NoShowPenaltyConfig{
+		BlockCount:       getEnvInt("NO_SHOW_BLOCK_COUNT", 3),
+		BlockWindowDays:  getEnvInt("NO_SHOW_BLOCK_WINDOW_DAYS", 60),
+		BlockDays:        getEnvInt("NO_SHOW_BLOCK_DAYS", 14),
+		PrepayCount:      getEnvInt("NO_SHOW_PREPAY_COUNT", 2),
+		PrepayWindowDays: getEnvInt("NO_SHOW_PREPAY_WINDOW_DAYS", 60),
+		PrepayDays:       getEnvInt("NO_SHOW_PREPAY_DAYS", 30),
+	}
+
+	c.WaitlistConfig = WaitlistConfig{
+		OfferTime:     time.Minute * time.Duration(getEnvInt("WAITLIST_OFFER_MINUTES", 30)),
+		SweepInterval: time.Second * time.Duration(getEnvInt("WAITLIST_SWEEP_INTERVAL_SECONDS", 60)),
 	}
 
 	c.DbConfig = DbConfig{
@@ -72,9 +195,13 @@ func (c *Config) readConfig() error {
 		Driver:   os.Getenv("DB_DRIVER"),
 	}
 
-	if c.DbConfig.Host == "" || c.DbConfig.Port == "" || c.DbConfig.Name == "" || c.DbConfig.User == "" || c.DbConfig.Password == "" || c.DbConfig.Driver == "" || c.SecurityConfig.Key == "" || c.SecurityConfig.Duration < 0 || c.SecurityConfig.Issuer == "" || c.PayGateConfig.ServerKey == "" {
+	if c.DbConfig.Host == "" || c.DbConfig.Port == "" || c.DbConfig.Name == "" || c.DbConfig.User == "" || c.DbConfig.Password == "" || c.DbConfig.Driver == "" || c.SecurityConfig.Key == "" || c.SecurityConfig.Duration < 0 || c.SecurityConfig.Issuer == "" || (c.PayGateConfig.Provider == "midtrans" && c.PayGateConfig.ServerKey == "") {
 		return errors.New("missing environment config")
 	}
+
+	if c.DepositConfig.Percent < 1 || c.DepositConfig.Percent > 100 {
+		return fmt.Errorf("invalid environment config, DOWN_PAYMENT_PERCENT must be between 1 and 100, got %d", c.DepositConfig.Percent)
+	}
 	return nil
 }
 
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"team2/shuttleslot/middleware"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/service"
+	"team2/shuttleslot/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditService service.AuditService
+	auth         middleware.AuthMiddleware
+	rg           *gin.RouterGroup
+}
+
+func (c *AuditController) FindAuditLogsHandler(ctx *gin.Context) {
+	page, err1 := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	size, err2 := strconv.Atoi(ctx.DefaultQuery("size", "10"))
+	if err1 != nil || err2 != nil {
+		util.SendErrorResponse(ctx, "invalid page or size", http.StatusBadRequest)
+		return
+	}
+
+	startDate := ctx.Query("startDate")
+	endDate := ctx.Query("endDate")
+	if (startDate != "" && !util.IsValidDate(startDate)) || (endDate != "" && !util.IsValidDate(endDate)) {
+		util.SendErrorResponse(ctx, "invalid date format, use 'dd-mm-yyyy' for startDate and endDate", http.StatusBadRequest)
+		return
+	}
+
+	filter := dto.AuditLogFilter{
+		Actor:      ctx.Query("actor"),
+		Action:     ctx.Query("action"),
+		EntityType: ctx.Query("entityType"),
+		EntityId:   ctx.Query("entityId"),
+	}
+	if startDate != "" {
+		filter.StartDate = util.StringToDate(startDate)
+	}
+	if endDate != "" {
+		filter.EndDate = util.StringToDate(endDate)
+	}
+
+	entries, paginate, err := c.auditService.FindAll(filter, page, size)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	var responseTemplate util.AuditLogResponse
+	for _, val := range entries {
+		listData = append(listData, responseTemplate.FromModel(val))
+	}
+
+	util.SendPaginateResponse(ctx, "success get data", listData, paginate, http.StatusOK)
+}
+
+func (c *AuditController) Route() {
+	router := c.rg.Group("audit-logs", c.auth.CheckToken("admin"))
+	{
+		router.GET("/", c.FindAuditLogsHandler)
+	}
+}
+
+func NewAuditController(auditService service.AuditService, authMiddleware middleware.AuthMiddleware, rg *gin.RouterGroup) *AuditController {
+	return &AuditController{
+		auditService: auditService,
+		auth:         authMiddleware,
+		rg:           rg,
+	}
+}
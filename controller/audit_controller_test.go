@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	mock "team2/shuttleslot/mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditControllerTestSuite struct {
+	suite.Suite
+	auditServiceMock *servicemock.AuditServiceMock
+	middlewareMock   *mock.AuthMiddlewareMock
+	controller       *AuditController
+}
+
+func (suite *AuditControllerTestSuite) SetupTest() {
+	suite.auditServiceMock = new(servicemock.AuditServiceMock)
+	suite.middlewareMock = new(mock.AuthMiddlewareMock)
+	suite.controller = NewAuditController(suite.auditServiceMock, suite.middlewareMock, gin.Default().Group("/api/v1"))
+	suite.controller.Route()
+}
+
+func TestAuditControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditControllerTestSuite))
+}
+
+func (suite *AuditControllerTestSuite) TestFindAuditLogsHandler_Success() {
+	filter := dto.AuditLogFilter{
+		EntityType: "booking",
+		EntityId:   "1",
+		StartDate:  time.Date(2030, 10, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2030, 10, 7, 0, 0, 0, 0, time.UTC),
+	}
+	entries := []model.AuditLog{{Id: "1", Actor: "employee_1", Action: "booking.cancel", EntityType: "booking", EntityId: "1", Before: `{"status":"booked"}`, After: `{"status":"cancel"}`}}
+	suite.auditServiceMock.On("FindAll", filter, 1, 10).Return(entries, dto.Paginate{Page: 1, Size: 10, TotalRows: 1, TotalPages: 1}, nil)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit-logs?entityType=booking&entityId=1&startDate=01-10-2030&endDate=07-10-2030", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.FindAuditLogsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"before":{"status":"booked"}`)
+}
+
+func (suite *AuditControllerTestSuite) TestFindAuditLogsHandler_InvalidDate() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit-logs?startDate=2030-10-01", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.FindAuditLogsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *AuditControllerTestSuite) TestFindAuditLogsHandler_InvalidPage() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit-logs?page=one", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.FindAuditLogsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *AuditControllerTestSuite) TestFindAuditLogsHandler_InvalidRange() {
+	suite.auditServiceMock.On("FindAll", dto.AuditLogFilter{StartDate: time.Date(2030, 10, 7, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2030, 10, 1, 0, 0, 0, 0, time.UTC)}, 1, 10).
+		Return([]model.AuditLog{}, dto.Paginate{}, errors.New("invalid date range, startDate cannot be after endDate"))
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit-logs?startDate=07-10-2030&endDate=01-10-2030", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.FindAuditLogsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *AuditControllerTestSuite) TestFindAuditLogsHandler_Failed() {
+	suite.auditServiceMock.On("FindAll", dto.AuditLogFilter{}, 1, 10).Return([]model.AuditLog{}, dto.Paginate{}, errors.New("error"))
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/audit-logs", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.FindAuditLogsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusInternalServerError, record.Code)
+}
@@ -1,12 +1,16 @@
 package controller
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"team2/shuttleslot/middleware"
+	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
 	"team2/shuttleslot/service"
 	"team2/shuttleslot/util"
 	"time"
@@ -15,9 +19,10 @@ import (
 )
 
 type BookingController struct {
-	service service.BookingService
-	auth    middleware.AuthMiddleware
-	rg      *gin.RouterGroup
+	service             service.BookingService
+	notificationService service.PaymentNotificationService
+	auth                middleware.AuthMiddleware
+	rg                  *gin.RouterGroup
 }
 
 func (c *BookingController) Route() {
@@ -25,6 +30,19 @@ func (c *BookingController) Route() {
 	{
 		router.POST("/", c.auth.CheckToken("admin", "employee", "customer"), c.CreateBookingHandler)
 		router.GET("/check", c.auth.CheckToken("admin", "employee", "customer"), c.CheckBookingHandler)
+		router.GET("/availability", c.auth.CheckToken("admin", "employee", "customer"), c.AvailabilityHandler)
+		router.POST("/:id/cancel", c.auth.CheckToken("admin", "employee", "customer"), c.CancelBookingHandler)
+		router.PUT("/:id/reschedule", c.auth.CheckToken("admin", "employee", "customer"), c.RescheduleBookingHandler)
+		router.POST("/series", c.auth.CheckToken("admin", "employee", "customer"), c.CreateBookingSeriesHandler)
+		router.POST("/series/:id/cancel", c.auth.CheckToken("admin", "employee", "customer"), c.CancelBookingSeriesHandler)
+	}
+
+	guestGroup := router.Group("/guest")
+	{
+		guestGroup.POST("/", c.CreateGuestBookingHandler)
+		guestGroup.GET("/:token", c.GuestBookingHandler)
+		guestGroup.POST("/:token/cancel", c.CancelGuestBookingHandler)
+		guestGroup.GET("/:token/check-in-code", c.GuestCheckInCodeHandler)
 	}
 
 	midtransGroup := router.Group("/")
@@ -38,13 +56,28 @@ func (c *BookingController) Route() {
 	adminGroup := router.Group("/", c.auth.CheckToken("admin"))
 	{
 		adminGroup.GET("/report", c.PaymentReportHandler)
+		adminGroup.GET("/payment/notifications", c.FindNotificationsHandler)
+		adminGroup.POST("/payment/notifications/:id/replay", c.ReplayNotificationHandler)
 		adminGroup.GET("/", c.GetAllBookingsHandler)
 	}
 
 	employeeGroup := router.Group("/", c.auth.CheckToken("admin", "employee"))
 	{
+		employeeGroup.POST("/walk-in", c.CreateWalkInBookingHandler)
 		employeeGroup.POST("/repayment", c.CreateRepayHandler)
+		employeeGroup.POST("/refunds", c.CreateRefundHandler)
 		employeeGroup.GET("/today", c.CheckBookingTodayHandler)
+		employeeGroup.GET("/:id/history", c.StatusHistoryHandler)
+		employeeGroup.POST("/check-in", c.CheckInHandler)
+		employeeGroup.GET("/customers/:id/no-shows", c.NoShowCountHandler)
+	}
+
+	customerGroup := router.Group("/me", c.auth.CheckToken("customer"))
+	{
+		customerGroup.GET("/upcoming", c.UpcomingBookingsHandler)
+		customerGroup.GET("/past", c.PastBookingsHandler)
+		customerGroup.GET("/:id", c.CustomerBookingHandler)
+		customerGroup.GET("/:id/check-in-code", c.CheckInCodeHandler)
 	}
 }
 
@@ -56,30 +89,65 @@ func (c *BookingController) CreateBookingHandler(ctx *gin.Context) {
 		return
 	}
 
-	if !util.IsValidDate(payload.BookingDate) || !util.IsValidTime(payload.StartTime) {
-		util.SendErrorResponse(ctx, "invalid date or time format, use 'dd-mm-yyyy for bookingDate and 'hh-mm-ss' for startTime", http.StatusBadRequest)
+	if !isValidSchedule(ctx, payload.BookingDate, payload.StartTime) {
 		return
 	}
 
 	payload.CustomerId = ctx.GetString("userId")
 
-	dateNow := util.StringToDate(time.Now().Format("02-01-2006"))
-	timeNow := util.StringToTime(time.Now().Format("15:04:05"))
-	bookingDate := util.StringToDate(payload.BookingDate)
-	startTime := util.StringToTime(payload.StartTime)
+	data, err := c.service.Create(payload)
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingConflict) {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusConflict)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot book") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	if bookingDate.Before(dateNow) {
-		util.SendErrorResponse(ctx, "booking date cant in the past", http.StatusBadRequest)
+	response := util.CreateBookingResponse{}
+
+	util.SendSingleResponse(ctx, "booking created successfully", response.FromModel(data), http.StatusCreated)
+}
+
+func (c *BookingController) CreateWalkInBookingHandler(ctx *gin.Context) {
+	var payload dto.CreateWalkInBookingRequest
+
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if bookingDate.Equal(dateNow) && startTime.Before(timeNow) {
-		util.SendErrorResponse(ctx, "start time cant in the past", http.StatusBadRequest)
+	if !isValidSchedule(ctx, payload.BookingDate, payload.StartTime) {
 		return
 	}
 
-	data, err := c.service.Create(payload)
+	if payload.CustomerId == "" && (payload.Name == "" || payload.PhoneNumber == "") {
+		util.SendErrorResponse(ctx, "invalid customer, provide customerId or name and phoneNumber of the guest", http.StatusBadRequest)
+		return
+	}
+
+	if !util.IsValidPaymentMethod(payload.PaymentMethod) {
+		util.SendErrorResponse(ctx, "invalid payment method, use 'mid' for midtrans or 'cash'", http.StatusBadRequest)
+		return
+	}
+
+	payload.EmployeeId = ctx.GetString("userId")
+
+	data, err := c.service.CreateWalkIn(payload)
 	if err != nil {
+		if errors.Is(err, repository.ErrBookingConflict) {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusConflict)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
 		if strings.Contains(err.Error(), "cannot book") {
 			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
 			return
@@ -93,17 +161,93 @@ func (c *BookingController) CreateBookingHandler(ctx *gin.Context) {
 	util.SendSingleResponse(ctx, "booking created successfully", response.FromModel(data), http.StatusCreated)
 }
 
+func (c *BookingController) CreateGuestBookingHandler(ctx *gin.Context) {
+	var payload dto.CreateGuestBookingRequest
+
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !isValidSchedule(ctx, payload.BookingDate, payload.StartTime) {
+		return
+	}
+
+	if payload.Name == "" || payload.PhoneNumber == "" || !strings.Contains(payload.Email, "@") {
+		util.SendErrorResponse(ctx, "invalid guest, name, phoneNumber and a valid email are required", http.StatusBadRequest)
+		return
+	}
+
+	data, token, err := c.service.CreateGuestBooking(payload)
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingConflict) {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusConflict)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot book") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.GuestBookingResponse{}
+
+	util.SendSingleResponse(ctx, "booking created successfully", response.FromModel(data, token), http.StatusCreated)
+}
+
+func (c *BookingController) GuestBookingHandler(ctx *gin.Context) {
+	data, err := c.service.FindGuestBooking(ctx.Param("token"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "forbidden") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusForbidden)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var responseTemplate util.CustomerBookingResponse
+	util.SendSingleResponse(ctx, "success get data", responseTemplate.FromModel(data), http.StatusOK)
+}
+
+func (c *BookingController) CancelGuestBookingHandler(ctx *gin.Context) {
+	data, err := c.service.CancelGuestBooking(ctx.Param("token"))
+	if err != nil {
+		sendCancelError(ctx, err)
+		return
+	}
+
+	response := util.CancelBookingResponse{}
+	util.SendSingleResponse(ctx, "booking cancelled successfully", response.FromModel(data), http.StatusOK)
+}
+
 func (c *BookingController) NotificationHandler(ctx *gin.Context) {
 	var payload dto.PaymentNotificationInput
 
-	if err := ctx.ShouldBindJSON(&payload); err != nil {
+	raw, err := ctx.GetRawData()
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.Unmarshal(raw, &payload); err != nil {
 		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err := c.service.UpdatePayment(payload)
+	err = c.notificationService.Receive(payload, raw)
 	if err != nil {
 		fmt.Println("=============== ERROR >>>>", err.Error())
+		if strings.Contains(err.Error(), "forbidden") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusForbidden)
+			return
+		}
 		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -111,6 +255,46 @@ func (c *BookingController) NotificationHandler(ctx *gin.Context) {
 	util.SendSingleResponse(ctx, "booking created successfully", payload, http.StatusOK)
 }
 
+func (c *BookingController) FindNotificationsHandler(ctx *gin.Context) {
+	page, err1 := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	size, err2 := strconv.Atoi(ctx.DefaultQuery("size", "10"))
+	if err1 != nil || err2 != nil {
+		util.SendErrorResponse(ctx, "invalid page or size", http.StatusBadRequest)
+		return
+	}
+
+	notifications, paginate, err := c.notificationService.FindNotifications(ctx.Query("status"), page, size)
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	for _, val := range notifications {
+		listData = append(listData, val)
+	}
+
+	util.SendPaginateResponse(ctx, "success get data", listData, paginate, http.StatusOK)
+}
+
+func (c *BookingController) ReplayNotificationHandler(ctx *gin.Context) {
+	notification, err := c.notificationService.Replay(ctx.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "payment notification replayed", notification, http.StatusOK)
+}
+
 func (c *BookingController) CreateRepayHandler(ctx *gin.Context) {
 	var payload dto.CreateRepayRequest
 
@@ -128,6 +312,11 @@ func (c *BookingController) CreateRepayHandler(ctx *gin.Context) {
 
 	data, err := c.service.CreateRepay(payload)
 	if err != nil {
+		var transitionErr *model.BookingTransitionError
+		if errors.As(err, &transitionErr) {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
 		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -136,6 +325,206 @@ func (c *BookingController) CreateRepayHandler(ctx *gin.Context) {
 	util.SendSingleResponse(ctx, "repayment created successfully", response.FromModel(data), http.StatusCreated)
 }
 
+func (c *BookingController) CreateRefundHandler(ctx *gin.Context) {
+	var payload dto.CreateRefundRequest
+
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if payload.PaymentMethod != "" && !util.IsValidPaymentMethod(payload.PaymentMethod) {
+		util.SendErrorResponse(ctx, "invalid payment method, use 'mid' for midtrans or 'cash'", http.StatusBadRequest)
+		return
+	}
+
+	payload.EmployeeId = ctx.GetString("userId")
+
+	data, err := c.service.Refund(payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.RefundResponse{}
+	util.SendSingleResponse(ctx, "refund created successfully", response.FromModel(data), http.StatusCreated)
+}
+
+func (c *BookingController) CancelBookingHandler(ctx *gin.Context) {
+	payload := dto.CancelBookingRequest{
+		BookingId: ctx.Param("id"),
+		UserId:    ctx.GetString("userId"),
+		Role:      ctx.GetString("role"),
+	}
+
+	data, err := c.service.Cancel(payload)
+	if err != nil {
+		sendCancelError(ctx, err)
+		return
+	}
+
+	response := util.CancelBookingResponse{}
+	util.SendSingleResponse(ctx, "booking cancelled successfully", response.FromModel(data), http.StatusOK)
+}
+
+func sendCancelError(ctx *gin.Context, err error) {
+	var transitionErr *model.BookingTransitionError
+	if errors.As(err, &transitionErr) {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusConflict)
+		return
+	}
+	if strings.Contains(err.Error(), "not found") {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+		return
+	}
+	if strings.Contains(err.Error(), "forbidden") {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusForbidden)
+		return
+	}
+	if strings.Contains(err.Error(), "cannot cancel") {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+	util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+}
+
+func (c *BookingController) RescheduleBookingHandler(ctx *gin.Context) {
+	var payload dto.RescheduleBookingRequest
+
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !isValidSchedule(ctx, payload.BookingDate, payload.StartTime) {
+		return
+	}
+
+	payload.BookingId = ctx.Param("id")
+	payload.UserId = ctx.GetString("userId")
+	payload.Role = ctx.GetString("role")
+
+	data, err := c.service.Reschedule(payload)
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingConflict) {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusConflict)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "forbidden") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusForbidden)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.RescheduleBookingResponse{}
+	util.SendSingleResponse(ctx, "booking rescheduled successfully", response.FromModel(data), http.StatusOK)
+}
+
+func (c *BookingController) CreateBookingSeriesHandler(ctx *gin.Context) {
+	var payload dto.CreateBookingSeriesRequest
+
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !isValidSchedule(ctx, payload.StartDate, payload.StartTime) {
+		return
+	}
+
+	if payload.EndDate == "" && payload.Occurrences < 1 {
+		util.SendErrorResponse(ctx, "endDate or occurrences is required", http.StatusBadRequest)
+		return
+	}
+
+	if payload.EndDate != "" && !util.IsValidDate(payload.EndDate) {
+		util.SendErrorResponse(ctx, "invalid date format, use 'dd-mm-yyyy for endDate", http.StatusBadRequest)
+		return
+	}
+
+	if !(model.BookingSeries{Frequency: payload.Frequency}).IsValidFrequency() {
+		util.SendErrorResponse(ctx, "invalid frequency, use 'weekly' or 'biweekly'", http.StatusBadRequest)
+		return
+	}
+
+	payload.CustomerId = ctx.GetString("userId")
+
+	data, err := c.service.CreateSeries(payload)
+	if err != nil {
+		var collision *service.SeriesCollisionError
+		if errors.As(err, &collision) {
+			util.SendErrorDataResponse(ctx, err.Error(), collision.Collisions, http.StatusConflict)
+			return
+		}
+		if errors.Is(err, repository.ErrBookingConflict) {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusConflict)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot book") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.CreateBookingSeriesResponse{}
+	util.SendSingleResponse(ctx, "booking series created successfully", response.FromModel(data), http.StatusCreated)
+}
+
+func (c *BookingController) CancelBookingSeriesHandler(ctx *gin.Context) {
+	payload := dto.CancelBookingSeriesRequest{
+		SeriesId: ctx.Param("id"),
+		UserId:   ctx.GetString("userId"),
+		Role:     ctx.GetString("role"),
+	}
+
+	data, err := c.service.CancelSeries(payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "forbidden") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusForbidden)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot cancel") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	var responseTemplate util.CancelBookingResponse
+
+	for _, val := range data {
+		listData = append(listData, responseTemplate.FromModel(val))
+	}
+
+	util.SendSingleResponse(ctx, "booking series cancelled successfully", listData, http.StatusOK)
+}
+
 func (c *BookingController) GetAllBookingsHandler(ctx *gin.Context) {
 	page, err1 := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	size, err2 := strconv.Atoi(ctx.DefaultQuery("size", "10"))
@@ -159,6 +548,142 @@ func (c *BookingController) GetAllBookingsHandler(ctx *gin.Context) {
 	util.SendPaginateResponse(ctx, "success get data", listData, paginate, http.StatusOK)
 }
 
+func (c *BookingController) UpcomingBookingsHandler(ctx *gin.Context) {
+	c.customerBookings(ctx, true)
+}
+
+func (c *BookingController) PastBookingsHandler(ctx *gin.Context) {
+	c.customerBookings(ctx, false)
+}
+
+func (c *BookingController) customerBookings(ctx *gin.Context, upcoming bool) {
+	page, err1 := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	size, err2 := strconv.Atoi(ctx.DefaultQuery("size", "10"))
+	if err1 != nil || err2 != nil {
+		util.SendErrorResponse(ctx, "invalid page or size", http.StatusBadRequest)
+		return
+	}
+
+	rows, paginate, err := c.service.FindCustomerBookings(ctx.GetString("userId"), upcoming, page, size)
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	var responseTemplate util.CustomerBookingResponse
+	for _, v := range rows {
+		listData = append(listData, responseTemplate.FromModel(v))
+	}
+
+	util.SendPaginateResponse(ctx, "success get data", listData, paginate, http.StatusOK)
+}
+
+func (c *BookingController) CustomerBookingHandler(ctx *gin.Context) {
+	data, err := c.service.FindCustomerBooking(ctx.GetString("userId"), ctx.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "forbidden") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusForbidden)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var responseTemplate util.CustomerBookingResponse
+	util.SendSingleResponse(ctx, "success get data", responseTemplate.FromModel(data), http.StatusOK)
+}
+
+func (c *BookingController) CheckInCodeHandler(ctx *gin.Context) {
+	png, err := c.service.CheckInQRCode(ctx.GetString("userId"), ctx.Param("id"))
+	if err != nil {
+		sendCheckInCodeError(ctx, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "image/png", png)
+}
+
+func (c *BookingController) GuestCheckInCodeHandler(ctx *gin.Context) {
+	png, err := c.service.GuestCheckInQRCode(ctx.Param("token"))
+	if err != nil {
+		sendCheckInCodeError(ctx, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "image/png", png)
+}
+
+func sendCheckInCodeError(ctx *gin.Context, err error) {
+	if strings.Contains(err.Error(), "not found") {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+		return
+	}
+	if strings.Contains(err.Error(), "forbidden") {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusForbidden)
+		return
+	}
+	if strings.Contains(err.Error(), "cannot issue") {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+	util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+}
+
+func (c *BookingController) CheckInHandler(ctx *gin.Context) {
+	var payload dto.CheckInRequest
+
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if payload.Code == "" {
+		util.SendErrorResponse(ctx, "invalid check-in code", http.StatusBadRequest)
+		return
+	}
+
+	payload.EmployeeId = ctx.GetString("userId")
+
+	data, err := c.service.CheckIn(payload)
+	if err != nil {
+		var transitionErr *model.BookingTransitionError
+		if errors.As(err, &transitionErr) {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusConflict)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot check in") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var responseTemplate util.CustomerBookingResponse
+	util.SendSingleResponse(ctx, "booking checked in successfully", responseTemplate.FromModel(data), http.StatusOK)
+}
+
+func (c *BookingController) NoShowCountHandler(ctx *gin.Context) {
+	customerId := ctx.Param("id")
+
+	count, err := c.service.CountNoShows(customerId)
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "success get data", util.NoShowCountResponse{CustomerId: customerId, NoShowCount: count}, http.StatusOK)
+}
+
 func (c *BookingController) CheckBookingHandler(ctx *gin.Context) {
 	page, err1 := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	size, err2 := strconv.Atoi(ctx.DefaultQuery("size", "10"))
@@ -197,6 +722,69 @@ func (c *BookingController) CheckBookingHandler(ctx *gin.Context) {
 	util.SendPaginateResponse(ctx, "success get data", listData, paginate, http.StatusOK)
 }
 
+func (c *BookingController) AvailabilityHandler(ctx *gin.Context) {
+	page, err1 := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	size, err2 := strconv.Atoi(ctx.DefaultQuery("size", "10"))
+	if err1 != nil || err2 != nil {
+		util.SendErrorResponse(ctx, "invalid page or size", http.StatusBadRequest)
+		return
+	}
+
+	payload := dto.AvailabilityRequest{
+		StartDate: ctx.DefaultQuery("date", time.Now().Format("02-01-2006")),
+		EndDate:   ctx.Query("endDate"),
+		CourtId:   ctx.Query("courtId"),
+	}
+
+	if !util.IsValidDate(payload.StartDate) || (payload.EndDate != "" && !util.IsValidDate(payload.EndDate)) {
+		util.SendErrorResponse(ctx, "invalid date format, use 'dd-mm-yyyy' for date and endDate", http.StatusBadRequest)
+		return
+	}
+
+	rows, paginate, err := c.service.FindAvailability(payload, page, size)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	var responseTemplate util.CourtAvailabilityResponse
+
+	for _, val := range rows {
+		listData = append(listData, responseTemplate.FromModel(val))
+	}
+
+	util.SendPaginateResponse(ctx, "success get data", listData, paginate, http.StatusOK)
+}
+
+func (c *BookingController) StatusHistoryHandler(ctx *gin.Context) {
+	data, err := c.service.FindStatusHistory(ctx.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []util.StatusHistoryResponse
+	var responseTemplate util.StatusHistoryResponse
+	for _, v := range data {
+		listData = append(listData, *responseTemplate.FromModel(v))
+	}
+
+	util.SendSingleResponse(ctx, "success get data", listData, http.StatusOK)
+}
+
 func (c *BookingController) CheckBookingTodayHandler(ctx *gin.Context) {
 	page, err1 := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	size, err2 := strconv.Atoi(ctx.DefaultQuery("size", "10"))
@@ -294,10 +882,37 @@ func (c *BookingController) PaymentErrorHandler(ctx *gin.Context) {
 	util.SendPaymentResponse(ctx, data, code)
 }
 
-func NewBookingController(bookingService service.BookingService, authMiddleware middleware.AuthMiddleware, rg *gin.RouterGroup) *BookingController {
+// isValidSchedule checks the requested booking date and start time, writing
+// the error response itself when they are malformed or already passed.
+func isValidSchedule(ctx *gin.Context, date string, start string) bool {
+	if !util.IsValidDate(date) || !util.IsValidTime(start) {
+		util.SendErrorResponse(ctx, "invalid date or time format, use 'dd-mm-yyyy for bookingDate and 'hh-mm-ss' for startTime", http.StatusBadRequest)
+		return false
+	}
+
+	dateNow := util.StringToDate(time.Now().Format("02-01-2006"))
+	timeNow := util.StringToTime(time.Now().Format("15:04:05"))
+	bookingDate := util.StringToDate(date)
+	startTime := util.StringToTime(start)
+
+	if bookingDate.Before(dateNow) {
+		util.SendErrorResponse(ctx, "booking date cant in the past", http.StatusBadRequest)
+		return false
+	}
+
+	if bookingDate.Equal(dateNow) && startTime.Before(timeNow) {
+		util.SendErrorResponse(ctx, "start time cant in the past", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func NewBookingController(bookingService service.BookingService, notificationService service.PaymentNotificationService, authMiddleware middleware.AuthMiddleware, rg *gin.RouterGroup) *BookingController {
 	return &BookingController{
-		service: bookingService,
-		auth:    authMiddleware,
-		rg:      rg,
+		service:             bookingService,
+		notificationService: notificationService,
+		auth:                authMiddleware,
+		rg:                  rg,
 	}
 }
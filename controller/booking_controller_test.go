@@ -13,6 +13,8 @@ import (
 	servicemock "team2/shuttleslot/mock/service_mock"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"team2/shuttleslot/service"
 	"testing"
 	"time"
 
@@ -55,6 +57,7 @@ var (
 type BookingControllerTestSuite struct {
 	suite.Suite
 	bookingServiceMock *servicemock.BookingServiceMock
+	notificationMock   *servicemock.PaymentNotificationServiceMock
 	rg                 *gin.RouterGroup
 	controller         *BookingController
 	middlewareMock     *mock.AuthMiddlewareMock
@@ -62,8 +65,9 @@ type BookingControllerTestSuite struct {
 
 func (suite *BookingControllerTestSuite) SetupTest() {
 	suite.bookingServiceMock = new(servicemock.BookingServiceMock)
+	suite.notificationMock = new(servicemock.PaymentNotificationServiceMock)
 	suite.rg = gin.Default().Group("/api/v1")
-	suite.controller = NewBookingController(suite.bookingServiceMock, suite.middlewareMock, suite.rg.Group("/bookings"))
+	suite.controller = NewBookingController(suite.bookingServiceMock, suite.notificationMock, suite.middlewareMock, suite.rg.Group("/bookings"))
 	suite.controller.Route()
 }
 
@@ -110,6 +114,88 @@ func (suite *BookingControllerTestSuite) TestCreateBookingHandler_Success() {
 	assert.Equal(suite.T(), http.StatusOK, rec.Code)
 }
 
+func (suite *BookingControllerTestSuite) TestCreateWalkInBookingHandler_Success() {
+	payload := dto.CreateWalkInBookingRequest{
+		CourtId:       "1",
+		BookingDate:   time.Now().AddDate(0, 0, 1).Format("02-01-2006"),
+		StartTime:     "10:00:00",
+		Hour:          1,
+		Name:          "walk in",
+		PhoneNumber:   "0812",
+		PaymentMethod: "cash",
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/walk-in", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/walk-in", func(c *gin.Context) {
+		c.Set("userId", "employee_1")
+	}, suite.controller.CreateWalkInBookingHandler)
+	ctx.Request = req
+
+	expected := payload
+	expected.EmployeeId = "employee_1"
+	booking := mockBooking
+	booking.Status = model.BookingDone
+	suite.bookingServiceMock.On("CreateWalkIn", expected).Return(booking, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"status":"done"`)
+}
+
+func (suite *BookingControllerTestSuite) TestCreateWalkInBookingHandler_MissingCustomer() {
+	payload := dto.CreateWalkInBookingRequest{
+		CourtId:       "1",
+		BookingDate:   time.Now().AddDate(0, 0, 1).Format("02-01-2006"),
+		StartTime:     "10:00:00",
+		Hour:          1,
+		Name:          "walk in",
+		PaymentMethod: "cash",
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/walk-in", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/walk-in", suite.controller.CreateWalkInBookingHandler)
+	ctx.Request = req
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+	suite.bookingServiceMock.AssertNotCalled(suite.T(), "CreateWalkIn")
+}
+
+func (suite *BookingControllerTestSuite) TestCreateWalkInBookingHandler_CustomerNotFound() {
+	payload := dto.CreateWalkInBookingRequest{
+		CourtId:       "1",
+		BookingDate:   time.Now().AddDate(0, 0, 1).Format("02-01-2006"),
+		StartTime:     "10:00:00",
+		Hour:          1,
+		CustomerId:    "missing",
+		PaymentMethod: "mid",
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/walk-in", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/walk-in", suite.controller.CreateWalkInBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("CreateWalkIn", payload).Return(model.Booking{}, errors.New("customer not found"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
 func (suite *BookingControllerTestSuite) TestCreateBookingHandler_InvalidJSON() {
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings", strings.NewReader("{invalid json}"))
 	req.Header.Set("Content-Type", "application/json")
@@ -279,7 +365,7 @@ func (suite *BookingControllerTestSuite) TestNotificationHandler_Success() {
 	ctx, _ := gin.CreateTestContext(record)
 	ctx.Request = req
 
-	suite.bookingServiceMock.On("UpdatePayment", mockNotification).Return(nil)
+	suite.notificationMock.On("Receive", mockNotification, mockPayloadJSON).Return(nil)
 
 	suite.controller.NotificationHandler(ctx)
 	assert.Equal(suite.T(), http.StatusOK, record.Code)
@@ -311,12 +397,80 @@ func (suite *BookingControllerTestSuite) TestNotificationHandler_ServiceError()
 	ctx, _ := gin.CreateTestContext(record)
 	ctx.Request = req
 
-	suite.bookingServiceMock.On("UpdatePayment", mockNotification).Return(errors.New("update error"))
+	suite.notificationMock.On("Receive", mockNotification, mockPayloadJSON).Return(errors.New("update error"))
 
 	suite.controller.NotificationHandler(ctx)
 	assert.Equal(suite.T(), http.StatusInternalServerError, record.Code)
 }
 
+func (suite *BookingControllerTestSuite) TestNotificationHandler_InvalidSignature() {
+	mockNotification := dto.PaymentNotificationInput{
+		OrderId:      "order123",
+		StatusCode:   "200",
+		GrossAmount:  "60000.00",
+		SignatureKey: "forged",
+	}
+
+	mockPayloadJSON, err := json.Marshal(mockNotification)
+	assert.NoError(suite.T(), err)
+
+	record := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/bookings/payment/notif", bytes.NewBuffer(mockPayloadJSON))
+	assert.NoError(suite.T(), err)
+
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.notificationMock.On("Receive", mockNotification, mockPayloadJSON).Return(errors.New("forbidden, invalid notification signature"))
+
+	suite.controller.NotificationHandler(ctx)
+	assert.Equal(suite.T(), http.StatusForbidden, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestFindNotificationsHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/payment/notifications?status=failed", nil)
+
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	notifications := []model.PaymentNotification{{Id: "1", OrderId: "Booking-1", TransactionStatus: "settlement", Status: "failed"}}
+	suite.notificationMock.On("FindNotifications", "failed", 1, 10).Return(notifications, dto.Paginate{Page: 1, Size: 10, TotalRows: 1, TotalPages: 1}, nil)
+
+	suite.controller.FindNotificationsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"orderId":"Booking-1"`)
+}
+
+func (suite *BookingControllerTestSuite) TestReplayNotificationHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/payment/notifications/1/replay", nil)
+
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	suite.notificationMock.On("Replay", "1").Return(model.PaymentNotification{Id: "1", Status: "processed"}, nil)
+
+	suite.controller.ReplayNotificationHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"status":"processed"`)
+}
+
+func (suite *BookingControllerTestSuite) TestReplayNotificationHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/payment/notifications/9/replay", nil)
+
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: "9"}}
+
+	suite.notificationMock.On("Replay", "9").Return(model.PaymentNotification{}, errors.New("payment notification not found"))
+
+	suite.controller.ReplayNotificationHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
 // -
 func (suite *BookingControllerTestSuite) TestCreateRepayHandler_Success() {
 	payload := dto.CreateRepayRequest{
@@ -367,6 +521,95 @@ func (suite *BookingControllerTestSuite) TestCreateRepayHandler_Success() {
 
 }
 
+func (suite *BookingControllerTestSuite) TestCreateRefundHandler_Success() {
+	payload := dto.CreateRefundRequest{OrderId: "Booking00001-1", Amount: 20000, Reason: "court light broken"}
+
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/refunds", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	ctx, router := gin.CreateTestContext(rec)
+	router.POST("/api/v1/bookings/refunds", func(c *gin.Context) {
+		c.Set("userId", "employee_id")
+		suite.controller.CreateRefundHandler(c)
+	})
+	ctx.Request = req
+
+	payload.EmployeeId = "employee_id"
+	refund := model.Payment{BookingId: "1", OrderId: "Refund1-1", Description: payload.Reason, PaymentMethod: "gopay", Price: 20000, Status: "refund", RefundOf: payload.OrderId}
+	suite.bookingServiceMock.On("Refund", payload).Return(refund, nil)
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(suite.T(), "Booking00001-1", data["refundOf"])
+	assert.Equal(suite.T(), float64(20000), data["price"])
+}
+
+func (suite *BookingControllerTestSuite) TestCreateRefundHandler_InvalidPaymentMethod() {
+	body, _ := json.Marshal(dto.CreateRefundRequest{OrderId: "Booking00001-1", PaymentMethod: "transfer"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/refunds", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = req
+
+	suite.controller.CreateRefundHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCreateRefundHandler_NotFound() {
+	body, _ := json.Marshal(dto.CreateRefundRequest{OrderId: "Booking00001-1"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/refunds", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("Refund", dto.CreateRefundRequest{OrderId: "Booking00001-1"}).Return(model.Payment{}, errors.New("payment not found"))
+
+	suite.controller.CreateRefundHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, rec.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCreateRefundHandler_CannotRefund() {
+	body, _ := json.Marshal(dto.CreateRefundRequest{OrderId: "Booking00001-1"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/refunds", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("Refund", dto.CreateRefundRequest{OrderId: "Booking00001-1"}).Return(model.Payment{}, errors.New("cannot refund payment with status unpaid"))
+
+	suite.controller.CreateRefundHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCreateRefundHandler_ServiceError() {
+	body, _ := json.Marshal(dto.CreateRefundRequest{OrderId: "Booking00001-1"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/refunds", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("Refund", dto.CreateRefundRequest{OrderId: "Booking00001-1"}).Return(model.Payment{}, errors.New("gateway error"))
+
+	suite.controller.CreateRefundHandler(ctx)
+	assert.Equal(suite.T(), http.StatusInternalServerError, rec.Code)
+}
+
 func (suite *BookingControllerTestSuite) TestCreateRepayHandler_BindingError() {
 	record := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/repayment", strings.NewReader("invalid JSON"))
@@ -432,6 +675,27 @@ func (suite *BookingControllerTestSuite) TestCreateRepayHandler_ServiceError() {
 	assert.Equal(suite.T(), nil, nil)
 }
 
+func (suite *BookingControllerTestSuite) TestCreateRepayHandler_IllegalTransition() {
+	payload := dto.CreateRepayRequest{
+		BookingId:     "2",
+		PaymentMethod: "cash",
+	}
+
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/repayment", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	_, router := gin.CreateTestContext(rec)
+	router.POST("/api/v1/bookings/repayment", suite.controller.CreateRepayHandler)
+
+	suite.bookingServiceMock.On("CreateRepay", payload).Return(model.Payment{}, &model.BookingTransitionError{From: model.BookingCancel, To: model.BookingDone})
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+}
+
 // --
 func (suite *BookingControllerTestSuite) TestGetAllBookingsHandler_Success() {
 	record := httptest.NewRecorder()
@@ -652,3 +916,686 @@ func (suite *BookingControllerTestSuite) TestPaymentReportHandler_InternalServer
 func (suite *BookingControllerTestSuite) TestRoute() {
 	assert.NotNil(suite.T(), suite.rg)
 }
+
+func (suite *BookingControllerTestSuite) TestCancelBookingHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/1/cancel", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/:id/cancel", func(c *gin.Context) {
+		c.Set("userId", "1")
+		c.Set("role", "customer")
+	}, suite.controller.CancelBookingHandler)
+	ctx.Request = req
+
+	cancelled := model.Booking{Id: "1", Status: "cancel", PaymentDetails: []model.Payment{{OrderId: "Refund1-1", Price: 15000}}}
+	suite.bookingServiceMock.On("Cancel", dto.CancelBookingRequest{BookingId: "1", UserId: "1", Role: "customer"}).Return(cancelled, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(record.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), float64(15000), response["data"].(map[string]interface{})["refundAmount"])
+}
+
+func (suite *BookingControllerTestSuite) TestCancelBookingHandler_Forbidden() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/1/cancel", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/:id/cancel", suite.controller.CancelBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("Cancel", dto.CancelBookingRequest{BookingId: "1"}).Return(model.Booking{}, errors.New("forbidden, this booking belongs to another customer"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusForbidden, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCancelBookingHandler_InvalidStatus() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/1/cancel", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/:id/cancel", suite.controller.CancelBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("Cancel", dto.CancelBookingRequest{BookingId: "1"}).Return(model.Booking{}, errors.New("cannot cancel booking with status done"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCancelBookingHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/1/cancel", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/:id/cancel", suite.controller.CancelBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("Cancel", dto.CancelBookingRequest{BookingId: "1"}).Return(model.Booking{}, errors.New("booking not found"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCancelBookingHandler_Conflict() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/1/cancel", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/:id/cancel", suite.controller.CancelBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("Cancel", dto.CancelBookingRequest{BookingId: "1"}).Return(model.Booking{}, &model.BookingTransitionError{From: model.BookingDone, To: model.BookingCancel})
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusConflict, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestStatusHistoryHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/1/history", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/:id/history", suite.controller.StatusHistoryHandler)
+	ctx.Request = req
+
+	history := []model.BookingStatusHistory{{Id: "1", BookingId: "1", FromStatus: model.BookingPending, ToStatus: model.BookingBooked, ChangedBy: "payment-gateway"}}
+	suite.bookingServiceMock.On("FindStatusHistory", "1").Return(history, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), "payment-gateway")
+}
+
+func (suite *BookingControllerTestSuite) TestStatusHistoryHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/1/history", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/:id/history", suite.controller.StatusHistoryHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("FindStatusHistory", "1").Return([]model.BookingStatusHistory{}, errors.New("booking not found"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestUpcomingBookingsHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/me/upcoming?page=1&size=10", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/me/upcoming", func(c *gin.Context) {
+		c.Set("userId", "1")
+	}, suite.controller.UpcomingBookingsHandler)
+	ctx.Request = req
+
+	booking := mockBooking
+	booking.Status = model.BookingBooked
+	booking.Total_Payment = 100000
+	paginate := dto.Paginate{Page: 1, Size: 10, TotalRows: 1, TotalPages: 1}
+	suite.bookingServiceMock.On("FindCustomerBookings", "1", true, 1, 10).Return([]model.Booking{booking}, paginate, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"remainingPayment":40000`)
+}
+
+func (suite *BookingControllerTestSuite) TestPastBookingsHandler_InvalidPage() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/me/past?page=abc", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/me/past", suite.controller.PastBookingsHandler)
+	ctx.Request = req
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+	suite.bookingServiceMock.AssertNotCalled(suite.T(), "FindCustomerBookings")
+}
+
+func (suite *BookingControllerTestSuite) TestCustomerBookingHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/me/1", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/me/:id", func(c *gin.Context) {
+		c.Set("userId", "1")
+	}, suite.controller.CustomerBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("FindCustomerBooking", "1", "1").Return(mockBooking, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"paidAmount":60000`)
+}
+
+func (suite *BookingControllerTestSuite) TestCustomerBookingHandler_Forbidden() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/me/1", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/me/:id", func(c *gin.Context) {
+		c.Set("userId", "2")
+	}, suite.controller.CustomerBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("FindCustomerBooking", "2", "1").Return(model.Booking{}, errors.New("forbidden, this booking belongs to another customer"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusForbidden, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCustomerBookingHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/me/1", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/me/:id", suite.controller.CustomerBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("FindCustomerBooking", "", "1").Return(model.Booking{}, errors.New("booking not found"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCreateGuestBookingHandler_Success() {
+	payload := dto.CreateGuestBookingRequest{
+		CourtId:     "1",
+		BookingDate: time.Now().AddDate(0, 0, 1).Format("02-01-2006"),
+		StartTime:   "10:00:00",
+		Hour:        1,
+		Name:        "guest",
+		PhoneNumber: "0812",
+		Email:       "guest@mail.com",
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/guest/", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/guest/", suite.controller.CreateGuestBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("CreateGuestBooking", payload).Return(mockBooking, "lookup-token", nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"lookupToken":"lookup-token"`)
+}
+
+func (suite *BookingControllerTestSuite) TestCreateGuestBookingHandler_MissingEmail() {
+	payload := dto.CreateGuestBookingRequest{
+		CourtId:     "1",
+		BookingDate: time.Now().AddDate(0, 0, 1).Format("02-01-2006"),
+		StartTime:   "10:00:00",
+		Hour:        1,
+		Name:        "guest",
+		PhoneNumber: "0812",
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/guest/", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/guest/", suite.controller.CreateGuestBookingHandler)
+	ctx.Request = req
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+	suite.bookingServiceMock.AssertNotCalled(suite.T(), "CreateGuestBooking")
+}
+
+func (suite *BookingControllerTestSuite) TestCreateGuestBookingHandler_RegisteredContact() {
+	payload := dto.CreateGuestBookingRequest{
+		CourtId:     "1",
+		BookingDate: time.Now().AddDate(0, 0, 1).Format("02-01-2006"),
+		StartTime:   "10:00:00",
+		Hour:        1,
+		Name:        "guest",
+		PhoneNumber: "0812",
+		Email:       "guest@mail.com",
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/guest/", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/guest/", suite.controller.CreateGuestBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("CreateGuestBooking", payload).Return(model.Booking{}, "", errors.New("cannot book as guest, an account with this phone number or email already exists, please log in"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestGuestBookingHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/guest/lookup-token", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/guest/:token", suite.controller.GuestBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("FindGuestBooking", "lookup-token").Return(mockBooking, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestGuestBookingHandler_InvalidToken() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/guest/bad-token", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/guest/:token", suite.controller.GuestBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("FindGuestBooking", "bad-token").Return(model.Booking{}, errors.New("forbidden, invalid or expired lookup token"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusForbidden, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCancelGuestBookingHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/guest/lookup-token/cancel", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/guest/:token/cancel", suite.controller.CancelGuestBookingHandler)
+	ctx.Request = req
+
+	cancelled := model.Booking{Id: "1", Status: model.BookingCancel}
+	suite.bookingServiceMock.On("CancelGuestBooking", "lookup-token").Return(cancelled, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCancelGuestBookingHandler_InvalidStatus() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/guest/lookup-token/cancel", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/guest/:token/cancel", suite.controller.CancelGuestBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("CancelGuestBooking", "lookup-token").Return(model.Booking{}, &model.BookingTransitionError{From: model.BookingDone, To: model.BookingCancel})
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusConflict, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestRescheduleBookingHandler_Success() {
+	payload := dto.RescheduleBookingRequest{
+		CourtId:     "2",
+		BookingDate: time.Now().AddDate(0, 0, 2).Format("02-01-2006"),
+		StartTime:   "10:00:00",
+		Hour:        2,
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/bookings/1/reschedule", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.PUT("/api/v1/bookings/:id/reschedule", func(c *gin.Context) {
+		c.Set("userId", "1")
+		c.Set("role", "customer")
+	}, suite.controller.RescheduleBookingHandler)
+	ctx.Request = req
+
+	expected := payload
+	expected.BookingId = "1"
+	expected.UserId = "1"
+	expected.Role = "customer"
+	rescheduled := model.Booking{Id: "1", Total_Payment: 80000, PaymentDetails: []model.Payment{{Price: 30000, Status: "paid"}, {Price: 10000, Status: "unpaid"}}}
+	suite.bookingServiceMock.On("Reschedule", expected).Return(rescheduled, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(record.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), float64(50000), response["data"].(map[string]interface{})["remainingPayment"])
+}
+
+func (suite *BookingControllerTestSuite) TestRescheduleBookingHandler_PastDate() {
+	payload := dto.RescheduleBookingRequest{
+		BookingDate: time.Now().AddDate(0, 0, -2).Format("02-01-2006"),
+		StartTime:   "10:00:00",
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/bookings/1/reschedule", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.PUT("/api/v1/bookings/:id/reschedule", suite.controller.RescheduleBookingHandler)
+	ctx.Request = req
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestRescheduleBookingHandler_Collision() {
+	payload := dto.RescheduleBookingRequest{
+		BookingDate: time.Now().AddDate(0, 0, 2).Format("02-01-2006"),
+		StartTime:   "10:00:00",
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/bookings/1/reschedule", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.PUT("/api/v1/bookings/:id/reschedule", suite.controller.RescheduleBookingHandler)
+	ctx.Request = req
+
+	expected := payload
+	expected.BookingId = "1"
+	suite.bookingServiceMock.On("Reschedule", expected).Return(model.Booking{}, errors.New("cannot book court in that time"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCreateBookingSeriesHandler_Success() {
+	payload := dto.CreateBookingSeriesRequest{
+		CourtId:     "1",
+		Frequency:   "weekly",
+		StartDate:   time.Now().AddDate(0, 0, 2).Format("02-01-2006"),
+		Occurrences: 2,
+		StartTime:   "19:00:00",
+		Hour:        2,
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/series", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/series", func(c *gin.Context) {
+		c.Set("userId", "1")
+	}, suite.controller.CreateBookingSeriesHandler)
+	ctx.Request = req
+
+	expected := payload
+	expected.CustomerId = "1"
+	series := model.BookingSeries{Id: "s1", Bookings: []model.Booking{mockBooking, mockBooking}}
+	suite.bookingServiceMock.On("CreateSeries", expected).Return(series, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCreateBookingSeriesHandler_Collision() {
+	payload := dto.CreateBookingSeriesRequest{
+		Frequency:   "weekly",
+		StartDate:   time.Now().AddDate(0, 0, 2).Format("02-01-2006"),
+		Occurrences: 2,
+		StartTime:   "19:00:00",
+		Hour:        2,
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/series", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/series", suite.controller.CreateBookingSeriesHandler)
+	ctx.Request = req
+
+	collisions := []dto.SeriesCollision{{BookingDate: "01-01-2030", Reason: "cannot book court in that time"}}
+	suite.bookingServiceMock.On("CreateSeries", payload).Return(model.BookingSeries{}, &service.SeriesCollisionError{Collisions: collisions})
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusConflict, record.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(record.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(response["data"].([]interface{})))
+}
+
+func (suite *BookingControllerTestSuite) TestCreateBookingSeriesHandler_InvalidFrequency() {
+	payload := dto.CreateBookingSeriesRequest{
+		Frequency:   "daily",
+		StartDate:   time.Now().AddDate(0, 0, 2).Format("02-01-2006"),
+		Occurrences: 2,
+		StartTime:   "19:00:00",
+	}
+	body, _ := json.Marshal(payload)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/series", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/series", suite.controller.CreateBookingSeriesHandler)
+	ctx.Request = req
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCancelBookingSeriesHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/series/s1/cancel", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/series/:id/cancel", suite.controller.CancelBookingSeriesHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("CancelSeries", dto.CancelBookingSeriesRequest{SeriesId: "s1"}).Return([]model.Booking{{Id: "2", Status: "cancel"}}, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestAvailabilityHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/availability?date=01-10-2030&courtId=1", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/availability", suite.controller.AvailabilityHandler)
+	ctx.Request = req
+
+	availability := []model.CourtAvailability{{
+		Court: model.Court{Id: "1", Name: "Court A"},
+		Days: []model.DayAvailability{{
+			Date:  time.Date(2030, 10, 1, 0, 0, 0, 0, time.UTC),
+			Slots: []model.Slot{{Status: "free"}},
+		}},
+	}}
+	suite.bookingServiceMock.On("FindAvailability", dto.AvailabilityRequest{StartDate: "01-10-2030", CourtId: "1"}, 1, 10).
+		Return(availability, dto.Paginate{Page: 1, Size: 1, TotalRows: 1, TotalPages: 1}, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"courtName":"Court A"`)
+}
+
+func (suite *BookingControllerTestSuite) TestAvailabilityHandler_InvalidDate() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/availability?date=2030-10-01", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/availability", suite.controller.AvailabilityHandler)
+	ctx.Request = req
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestAvailabilityHandler_RangeTooLong() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/availability?date=01-10-2030&endDate=01-12-2030", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/availability", suite.controller.AvailabilityHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("FindAvailability", dto.AvailabilityRequest{StartDate: "01-10-2030", EndDate: "01-12-2030"}, 1, 10).
+		Return([]model.CourtAvailability{}, dto.Paginate{}, errors.New("cannot check availability for more than 31 days"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCreateBookingHandler_Conflict() {
+	payload := dto.CreateBookingRequest{
+		CourtId:     "1",
+		BookingDate: time.Now().AddDate(0, 0, 1).Format("02-01-2006"),
+		StartTime:   "14:00:00",
+		Hour:        1,
+	}
+
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	ctx, router := gin.CreateTestContext(rec)
+	router.POST("/api/v1/bookings", suite.controller.CreateBookingHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("Create", payload).Return(model.Booking{}, repository.ErrBookingConflict)
+
+	router.ServeHTTP(rec, req)
+	assert.Equal(suite.T(), http.StatusConflict, rec.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCheckInCodeHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/me/1/check-in-code", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/me/:id/check-in-code", func(c *gin.Context) {
+		c.Set("userId", "customer_1")
+	}, suite.controller.CheckInCodeHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("CheckInQRCode", "customer_1", "1").Return([]byte("\x89PNG"), nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Equal(suite.T(), "image/png", record.Header().Get("Content-Type"))
+}
+
+func (suite *BookingControllerTestSuite) TestCheckInCodeHandler_NotBooked() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/me/1/check-in-code", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/me/:id/check-in-code", suite.controller.CheckInCodeHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("CheckInQRCode", "", "1").Return([]byte(nil), errors.New("cannot issue a check-in code for a booking with status pending"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestGuestCheckInCodeHandler_InvalidToken() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/guest/bad-token/check-in-code", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/guest/:token/check-in-code", suite.controller.GuestCheckInCodeHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("GuestCheckInQRCode", "bad-token").Return([]byte(nil), errors.New("forbidden, invalid or expired lookup token"))
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusForbidden, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestCheckInHandler_Success() {
+	body, _ := json.Marshal(dto.CheckInRequest{Code: "check-in-code"})
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/check-in", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/check-in", func(c *gin.Context) {
+		c.Set("userId", "employee_1")
+	}, suite.controller.CheckInHandler)
+	ctx.Request = req
+
+	checkedIn := mockBooking
+	checkedIn.Status = model.BookingCheckedIn
+	suite.bookingServiceMock.On("CheckIn", dto.CheckInRequest{Code: "check-in-code", EmployeeId: "employee_1"}).Return(checkedIn, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"status":"checked_in"`)
+}
+
+func (suite *BookingControllerTestSuite) TestCheckInHandler_MissingCode() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/check-in", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/check-in", suite.controller.CheckInHandler)
+	ctx.Request = req
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+	suite.bookingServiceMock.AssertNotCalled(suite.T(), "CheckIn")
+}
+
+func (suite *BookingControllerTestSuite) TestCheckInHandler_AlreadyCheckedIn() {
+	body, _ := json.Marshal(dto.CheckInRequest{Code: "check-in-code"})
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/bookings/check-in", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, router := gin.CreateTestContext(record)
+	router.POST("/api/v1/bookings/check-in", suite.controller.CheckInHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("CheckIn", dto.CheckInRequest{Code: "check-in-code"}).Return(model.Booking{}, &model.BookingTransitionError{From: model.BookingCheckedIn, To: model.BookingCheckedIn})
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusConflict, record.Code)
+}
+
+func (suite *BookingControllerTestSuite) TestNoShowCountHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/bookings/customers/customer_1/no-shows", nil)
+
+	ctx, router := gin.CreateTestContext(record)
+	router.GET("/api/v1/bookings/customers/:id/no-shows", suite.controller.NoShowCountHandler)
+	ctx.Request = req
+
+	suite.bookingServiceMock.On("CountNoShows", "customer_1").Return(2, nil)
+
+	router.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"noShowCount":2`)
+}
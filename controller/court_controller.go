@@ -3,8 +3,10 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"team2/shuttleslot/middleware"
 	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
 	"team2/shuttleslot/service"
 	"team2/shuttleslot/util"
 
@@ -24,8 +26,12 @@ func (c *CourtController) CreateCourtHandler(ctx *gin.Context) {
 		return
 	}
 
-	data, err := c.courtService.CreateCourt(payload)
+	data, err := c.courtService.CreateCourt(payload, ctx.GetString("userId"))
 	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
 		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -75,8 +81,12 @@ func (c *CourtController) UpdateCourtHandler(ctx *gin.Context) {
 		return
 	}
 
-	courtUpdate, err := c.courtService.UpdateCourt(id, payload)
+	courtUpdate, err := c.courtService.UpdateCourt(id, payload, ctx.GetString("userId"))
 	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
 		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -86,7 +96,7 @@ func (c *CourtController) UpdateCourtHandler(ctx *gin.Context) {
 
 func (c *CourtController) DeleteCourtHandler(ctx *gin.Context) {
 	id := ctx.Param("id")
-	err := c.courtService.DeleteCourt(id)
+	err := c.courtService.DeleteCourt(id, ctx.GetString("userId"))
 	if err != nil {
 		util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
 		return
@@ -94,6 +104,83 @@ func (c *CourtController) DeleteCourtHandler(ctx *gin.Context) {
 	util.SendSingleResponse(ctx, "court deleted successfully", nil, http.StatusOK)
 }
 
+func (c *CourtController) CreateClosureHandler(ctx *gin.Context) {
+	var payload dto.CreateCourtClosureRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload.CourtId = ctx.Param("id")
+
+	if !util.IsValidDate(payload.StartDate) || (payload.EndDate != "" && !util.IsValidDate(payload.EndDate)) {
+		util.SendErrorResponse(ctx, "invalid date format, use 'dd-mm-yyyy' for startDate and endDate", http.StatusBadRequest)
+		return
+	}
+
+	if (payload.StartTime != "" && !util.IsValidTime(payload.StartTime)) || (payload.EndTime != "" && !util.IsValidTime(payload.EndTime)) {
+		util.SendErrorResponse(ctx, "invalid time format, use 'hh:mm:ss' for startTime and endTime", http.StatusBadRequest)
+		return
+	}
+
+	if payload.Reason == "" {
+		util.SendErrorResponse(ctx, "reason is required", http.StatusBadRequest)
+		return
+	}
+
+	closure, conflicts, err := c.courtService.CreateClosure(payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.CreateCourtClosureResponse{}
+	util.SendSingleResponse(ctx, "court closure created successfully", response.FromModel(closure, conflicts), http.StatusCreated)
+}
+
+func (c *CourtController) FindClosuresHandler(ctx *gin.Context) {
+	closures, err := c.courtService.FindClosures(ctx.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	var responseTemplate util.CourtClosureResponse
+
+	for _, val := range closures {
+		listData = append(listData, responseTemplate.FromModel(val))
+	}
+
+	util.SendSingleResponse(ctx, "success get data", listData, http.StatusOK)
+}
+
+func (c *CourtController) DeleteClosureHandler(ctx *gin.Context) {
+	err := c.courtService.DeleteClosure(ctx.Param("id"), ctx.Param("closureId"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "court closure deleted successfully", nil, http.StatusOK)
+}
+
 func (c *CourtController) Route() {
 	router := c.rg.Group("courts", c.auth.CheckToken("admin", "employee", "customer"))
 	{
@@ -106,6 +193,9 @@ func (c *CourtController) Route() {
 		adminGroup.POST("/", c.CreateCourtHandler)
 		adminGroup.PUT("/:id", c.UpdateCourtHandler)
 		adminGroup.DELETE("/:id", c.DeleteCourtHandler)
+		adminGroup.GET("/:id/closures", c.FindClosuresHandler)
+		adminGroup.POST("/:id/closures", c.CreateClosureHandler)
+		adminGroup.DELETE("/:id/closures/:closureId", c.DeleteClosureHandler)
 	}
 }
 
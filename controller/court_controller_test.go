@@ -55,7 +55,7 @@ func (suite *CourtControllerTestSuite) TestCreateCourt_Succes() {
 	ctx, _ := gin.CreateTestContext(record)
 	ctx.Request = req
 
-	suite.courtServiceMock.On("CreateCourt", payloadCourt).Return(payloadCourt, nil)
+	suite.courtServiceMock.On("CreateCourt", payloadCourt, "").Return(payloadCourt, nil)
 	suite.courtController.CreateCourtHandler(ctx)
 	assert.Equal(suite.T(), http.StatusOK, record.Code)
 }
@@ -82,7 +82,7 @@ func (suite *CourtControllerTestSuite) TestCreateCourt_Failed() {
 	ctx, _ := gin.CreateTestContext(record)
 	ctx.Request = req
 
-	suite.courtServiceMock.On("CreateCourt", payloadCourt).Return(model.Court{}, errors.New("error"))
+	suite.courtServiceMock.On("CreateCourt", payloadCourt, "").Return(model.Court{}, errors.New("error"))
 	suite.courtController.CreateCourtHandler(ctx)
 	assert.Equal(suite.T(), http.StatusInternalServerError, record.Code)
 }
@@ -188,7 +188,7 @@ func (suite *CourtControllerTestSuite) TestFindCourtByIdHandler_Failed() {
 }
 
 func (suite *CourtControllerTestSuite) TestDeleteCourtHandler_Success() {
-	suite.courtServiceMock.On("DeleteCourt", "1").Return(nil)
+	suite.courtServiceMock.On("DeleteCourt", "1", "").Return(nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/court/1", nil)
@@ -204,7 +204,7 @@ func (suite *CourtControllerTestSuite) TestDeleteCourtHandler_Success() {
 }
 
 func (suite *CourtControllerTestSuite) TestDeleteCourtHandler_Failed() {
-	suite.courtServiceMock.On("DeleteCourt", "1").Return(errors.New("user not found"))
+	suite.courtServiceMock.On("DeleteCourt", "1", "").Return(errors.New("user not found"))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/court/1", nil)
@@ -220,7 +220,7 @@ func (suite *CourtControllerTestSuite) TestDeleteCourtHandler_Failed() {
 
 func (suite *CourtControllerTestSuite) TestUpdateCourtHandler_Success() {
 
-	suite.courtServiceMock.On("UpdateCourt", "1", payloadCourt).Return(payloadCourt, nil)
+	suite.courtServiceMock.On("UpdateCourt", "1", payloadCourt, "").Return(payloadCourt, nil)
 
 	w := httptest.NewRecorder()
 	body, _ := json.Marshal(payloadCourt)
@@ -250,7 +250,7 @@ func (suite *CourtControllerTestSuite) TestUpdateCourtHandler_FailedBindJSON() {
 }
 
 func (suite *CourtControllerTestSuite) TestUpdateCourtHandler_FailedUpdateError() {
-	suite.courtServiceMock.On("UpdateCourt", "1", model.Court{}).Return(model.Court{}, errors.New("update error"))
+	suite.courtServiceMock.On("UpdateCourt", "1", model.Court{}, "").Return(model.Court{}, errors.New("update error"))
 
 	w := httptest.NewRecorder()
 	body, _ := json.Marshal(payload)
@@ -264,3 +264,63 @@ func (suite *CourtControllerTestSuite) TestUpdateCourtHandler_FailedUpdateError(
 	suite.courtController.UpdateCourtHandler(ctx)
 	assert.Equal(suite.T(), http.StatusInternalServerError, w.Code)
 }
+
+func (suite *CourtControllerTestSuite) TestCreateCourt_InvalidSchedule() {
+	invalid := model.Court{Name: "Field 2", Price: 50000, MinBookingHours: 3, MaxBookingHours: 1}
+	mockPayloadjson, _ := json.Marshal(invalid)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/courts", bytes.NewBuffer(mockPayloadjson))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.courtServiceMock.On("CreateCourt", invalid, "").Return(model.Court{}, errors.New("invalid booking length, minBookingHours cannot exceed maxBookingHours"))
+	suite.courtController.CreateCourtHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *CourtControllerTestSuite) TestCreateClosureHandler_Success() {
+	request := dto.CreateCourtClosureRequest{StartDate: "02-01-2030", StartTime: "09:00:00", EndTime: "12:00:00", Reason: "resurfacing"}
+	body, _ := json.Marshal(request)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/courts/1/closures", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+	ctx.Request = req
+
+	expected := request
+	expected.CourtId = "1"
+	conflicts := []model.Booking{{Id: "booking_1", Status: "booked"}}
+	suite.courtServiceMock.On("CreateClosure", expected).Return(model.CourtClosure{Id: "closure_1", CourtId: "1"}, conflicts, nil)
+
+	suite.courtController.CreateClosureHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"bookingId":"booking_1"`)
+}
+
+func (suite *CourtControllerTestSuite) TestCreateClosureHandler_MissingReason() {
+	body, _ := json.Marshal(dto.CreateCourtClosureRequest{StartDate: "02-01-2030"})
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/courts/1/closures", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+	ctx.Request = req
+
+	suite.courtController.CreateClosureHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *CourtControllerTestSuite) TestDeleteClosureHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/courts/1/closures/closure_1", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "closureId", Value: "closure_1"}}
+	ctx.Request = req
+
+	suite.courtServiceMock.On("DeleteClosure", "1", "closure_1").Return(errors.New("court closure not found"))
+
+	suite.courtController.DeleteClosureHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"team2/shuttleslot/service"
+	"team2/shuttleslot/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FakeGatewayController exposes the fake payment provider so payments can be
+// completed locally. It is only routed when PAYMENT_PROVIDER is "fake".
+type FakeGatewayController struct {
+	provider *service.FakeProvider
+	rg       *gin.RouterGroup
+}
+
+func (c *FakeGatewayController) FindTransactionHandler(ctx *gin.Context) {
+	transaction, err := c.provider.FindTransaction(ctx.Param("orderId"))
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "success get data", transaction, http.StatusOK)
+}
+
+// EmitNotificationHandler moves a transaction to a new status and notifies the
+// app. With ?silent=true the notification is dropped, which leaves the
+// payment for the reconciliation job to pick up.
+func (c *FakeGatewayController) EmitNotificationHandler(ctx *gin.Context) {
+	if ctx.Query("silent") == "true" {
+		transaction, err := c.provider.UpdateStatus(ctx.Param("orderId"), ctx.Param("status"))
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+				return
+			}
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		util.SendSingleResponse(ctx, "status updated without notification", transaction, http.StatusOK)
+		return
+	}
+
+	payload, err := c.provider.Emit(ctx.Param("orderId"), ctx.Param("status"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "notification sent", payload, http.StatusOK)
+}
+
+func (c *FakeGatewayController) Route() {
+	router := c.rg.Group("fake-gateway")
+	{
+		router.GET("/:orderId", c.FindTransactionHandler)
+		router.POST("/:orderId/:status", c.EmitNotificationHandler)
+	}
+}
+
+func NewFakeGatewayController(provider *service.FakeProvider, rg *gin.RouterGroup) *FakeGatewayController {
+	return &FakeGatewayController{
+		provider: provider,
+		rg:       rg,
+	}
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FakeGatewayControllerTestSuite struct {
+	suite.Suite
+	provider   *service.FakeProvider
+	received   []dto.PaymentNotificationInput
+	engine     *gin.Engine
+	controller *FakeGatewayController
+}
+
+func (suite *FakeGatewayControllerTestSuite) SetupTest() {
+	suite.received = nil
+	suite.provider = service.NewFakeProvider("http://localhost/api/v1/fake-gateway", "server-key")
+	suite.provider.SetNotifier(func(payload dto.PaymentNotificationInput, raw []byte) error {
+		suite.received = append(suite.received, payload)
+		return nil
+	})
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	suite.engine = gin.Default()
+	suite.controller = NewFakeGatewayController(suite.provider, suite.engine.Group("/api/v1"))
+	suite.controller.Route()
+}
+
+func TestFakeGatewayControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(FakeGatewayControllerTestSuite))
+}
+
+func (suite *FakeGatewayControllerTestSuite) TestFindTransactionHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/fake-gateway/Booking-1", nil)
+
+	suite.engine.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"status":"pending"`)
+}
+
+func (suite *FakeGatewayControllerTestSuite) TestFindTransactionHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/fake-gateway/Booking-2", nil)
+
+	suite.engine.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
+func (suite *FakeGatewayControllerTestSuite) TestEmitNotificationHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/fake-gateway/Booking-1/settlement", nil)
+
+	suite.engine.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Len(suite.T(), suite.received, 1)
+	assert.Equal(suite.T(), "settlement", suite.received[0].TransactionStatus)
+}
+
+func (suite *FakeGatewayControllerTestSuite) TestEmitNotificationHandler_InvalidStatus() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/fake-gateway/Booking-1/refund", nil)
+
+	suite.engine.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+	assert.Empty(suite.T(), suite.received)
+}
+
+func (suite *FakeGatewayControllerTestSuite) TestEmitNotificationHandler_Silent() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/fake-gateway/Booking-1/settlement?silent=true", nil)
+
+	suite.engine.ServeHTTP(record, req)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Empty(suite.T(), suite.received)
+
+	transaction, _ := suite.provider.FindTransaction("Booking-1")
+	assert.Equal(suite.T(), "settlement", transaction.Status)
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"team2/shuttleslot/middleware"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/service"
+	"team2/shuttleslot/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoyaltyController struct {
+	loyaltyService service.LoyaltyService
+	auth           middleware.AuthMiddleware
+	rg             *gin.RouterGroup
+}
+
+func (c *LoyaltyController) FindSettingHandler(ctx *gin.Context) {
+	setting, err := c.loyaltyService.FindSetting()
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.LoyaltySettingResponse{}
+	util.SendSingleResponse(ctx, "success get data", response.FromModel(setting), http.StatusOK)
+}
+
+func (c *LoyaltyController) UpdateSettingHandler(ctx *gin.Context) {
+	var payload dto.UpdateLoyaltySettingRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setting, err := c.loyaltyService.UpdateSetting(payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.LoyaltySettingResponse{}
+	util.SendSingleResponse(ctx, "loyalty setting updated successfully", response.FromModel(setting), http.StatusOK)
+}
+
+func (c *LoyaltyController) AdjustPointsHandler(ctx *gin.Context) {
+	var payload dto.AdjustPointsRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entry, err := c.loyaltyService.AdjustPoints(payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.PointEntryResponse{}
+	util.SendSingleResponse(ctx, "points adjusted successfully", response.FromModel(entry), http.StatusCreated)
+}
+
+func (c *LoyaltyController) MyPointsHandler(ctx *gin.Context) {
+	c.sendPoints(ctx, ctx.GetString("userId"))
+}
+
+func (c *LoyaltyController) CustomerPointsHandler(ctx *gin.Context) {
+	c.sendPoints(ctx, ctx.Param("id"))
+}
+
+func (c *LoyaltyController) sendPoints(ctx *gin.Context, customerId string) {
+	page, err1 := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	size, err2 := strconv.Atoi(ctx.DefaultQuery("size", "10"))
+	if err1 != nil || err2 != nil {
+		util.SendErrorResponse(ctx, "invalid page or size", http.StatusBadRequest)
+		return
+	}
+
+	balance, paginate, err := c.loyaltyService.FindPoints(customerId, page, size)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.PointBalanceResponse{}
+	util.SendSingleResponse(ctx, "success get data", response.FromModel(balance, paginate), http.StatusOK)
+}
+
+func (c *LoyaltyController) Route() {
+	router := c.rg.Group("loyalty")
+	{
+		router.GET("/points", c.auth.CheckToken("customer"), c.MyPointsHandler)
+	}
+
+	adminGroup := router.Group("/", c.auth.CheckToken("admin"))
+	{
+		adminGroup.GET("/settings", c.FindSettingHandler)
+		adminGroup.PUT("/settings", c.UpdateSettingHandler)
+		adminGroup.POST("/adjustments", c.AdjustPointsHandler)
+		adminGroup.GET("/customers/:id/points", c.CustomerPointsHandler)
+	}
+}
+
+func NewLoyaltyController(loyaltyService service.LoyaltyService, authMiddleware middleware.AuthMiddleware, rg *gin.RouterGroup) *LoyaltyController {
+	return &LoyaltyController{
+		loyaltyService: loyaltyService,
+		auth:           authMiddleware,
+		rg:             rg,
+	}
+}
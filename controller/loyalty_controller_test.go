@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	mock "team2/shuttleslot/mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoyaltyControllerTestSuite struct {
+	suite.Suite
+	loyaltyServiceMock *servicemock.LoyaltyServiceMock
+	middlewareMock     *mock.AuthMiddlewareMock
+	rg                 *gin.RouterGroup
+	controller         *LoyaltyController
+}
+
+func (suite *LoyaltyControllerTestSuite) SetupTest() {
+	suite.loyaltyServiceMock = new(servicemock.LoyaltyServiceMock)
+	rg := gin.Default()
+	suite.rg = rg.Group("/api/v1")
+	suite.middlewareMock = new(mock.AuthMiddlewareMock)
+	suite.controller = NewLoyaltyController(suite.loyaltyServiceMock, suite.middlewareMock, suite.rg)
+	suite.controller.Route()
+}
+
+func TestLoyaltyControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(LoyaltyControllerTestSuite))
+}
+
+func (suite *LoyaltyControllerTestSuite) TestMyPointsHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/loyalty/points?page=1&size=10", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Set("userId", "customer_id")
+
+	balance := model.PointBalance{
+		CustomerId: "customer_id",
+		Balance:    110,
+		Entries:    []model.PointEntry{{Id: "1", BookingId: "1", Type: "earn", Points: 10, Description: "Earned from booking 1"}},
+	}
+	suite.loyaltyServiceMock.On("FindPoints", "customer_id", 1, 10).Return(balance, dto.Paginate{Page: 1, Size: 10, TotalRows: 1, TotalPages: 1}, nil)
+
+	suite.controller.MyPointsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"balance":110`)
+	assert.Contains(suite.T(), record.Body.String(), `"type":"earn"`)
+}
+
+func (suite *LoyaltyControllerTestSuite) TestMyPointsHandler_InvalidPage() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/loyalty/points?page=abc", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.MyPointsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *LoyaltyControllerTestSuite) TestCustomerPointsHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/loyalty/customers/unknown/points", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: "unknown"}}
+
+	suite.loyaltyServiceMock.On("FindPoints", "unknown", 1, 10).Return(model.PointBalance{}, dto.Paginate{}, errors.New("customer not found"))
+
+	suite.controller.CustomerPointsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
+func (suite *LoyaltyControllerTestSuite) TestFindSettingHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/loyalty/settings", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.loyaltyServiceMock.On("FindSetting").Return(model.DefaultLoyaltySetting, nil)
+
+	suite.controller.FindSettingHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"redeemPoints":100`)
+}
+
+func (suite *LoyaltyControllerTestSuite) TestUpdateSettingHandler_Success() {
+	request := dto.UpdateLoyaltySettingRequest{EarnPoints: 1, EarnAmount: 10000, RedeemPoints: 50, RedeemValue: 5000}
+	body, _ := json.Marshal(request)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/loyalty/settings", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.loyaltyServiceMock.On("UpdateSetting", request).Return(model.LoyaltySetting{EarnPoints: 1, EarnAmount: 10000, RedeemPoints: 50, RedeemValue: 5000}, nil)
+
+	suite.controller.UpdateSettingHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+}
+
+func (suite *LoyaltyControllerTestSuite) TestUpdateSettingHandler_Invalid() {
+	request := dto.UpdateLoyaltySettingRequest{EarnPoints: -1, RedeemPoints: 50, RedeemValue: 5000}
+	body, _ := json.Marshal(request)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/loyalty/settings", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.loyaltyServiceMock.On("UpdateSetting", request).Return(model.LoyaltySetting{}, errors.New("invalid loyalty setting, earnPoints, earnAmount and expiryDays cannot be negative"))
+
+	suite.controller.UpdateSettingHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *LoyaltyControllerTestSuite) TestAdjustPointsHandler_Success() {
+	request := dto.AdjustPointsRequest{CustomerId: "customer_id", Points: 50, Description: "Tournament prize"}
+	body, _ := json.Marshal(request)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/loyalty/adjustments", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.loyaltyServiceMock.On("AdjustPoints", request).Return(model.PointEntry{Id: "1", Type: "adjust", Points: 50}, nil)
+
+	suite.controller.AdjustPointsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"points":50`)
+}
+
+func (suite *LoyaltyControllerTestSuite) TestAdjustPointsHandler_CustomerNotFound() {
+	request := dto.AdjustPointsRequest{CustomerId: "unknown", Points: 50, Description: "Tournament prize"}
+	body, _ := json.Marshal(request)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/loyalty/adjustments", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.loyaltyServiceMock.On("AdjustPoints", request).Return(model.PointEntry{}, errors.New("customer not found"))
+
+	suite.controller.AdjustPointsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
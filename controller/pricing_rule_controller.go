@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"team2/shuttleslot/middleware"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/service"
+	"team2/shuttleslot/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingRuleController struct {
+	pricingService service.PricingService
+	auth           middleware.AuthMiddleware
+	rg             *gin.RouterGroup
+}
+
+func (c *PricingRuleController) CreateRuleHandler(ctx *gin.Context) {
+	var payload dto.CreatePricingRuleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule, err := c.pricingService.CreateRule(payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.PricingRuleResponse{}
+	util.SendSingleResponse(ctx, "pricing rule created successfully", response.FromModel(rule), http.StatusCreated)
+}
+
+func (c *PricingRuleController) FindAllRulesHandler(ctx *gin.Context) {
+	rules, err := c.pricingService.FindAllRules()
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	var responseTemplate util.PricingRuleResponse
+
+	for _, val := range rules {
+		listData = append(listData, responseTemplate.FromModel(val))
+	}
+
+	util.SendSingleResponse(ctx, "success get data", listData, http.StatusOK)
+}
+
+func (c *PricingRuleController) DeleteRuleHandler(ctx *gin.Context) {
+	err := c.pricingService.DeleteRule(ctx.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "pricing rule deleted successfully", nil, http.StatusOK)
+}
+
+func (c *PricingRuleController) Route() {
+	router := c.rg.Group("pricing-rules", c.auth.CheckToken("admin"))
+	{
+		router.GET("/", c.FindAllRulesHandler)
+		router.POST("/", c.CreateRuleHandler)
+		router.DELETE("/:id", c.DeleteRuleHandler)
+	}
+}
+
+func NewPricingRuleController(pricingService service.PricingService, authMiddleware middleware.AuthMiddleware, rg *gin.RouterGroup) *PricingRuleController {
+	return &PricingRuleController{
+		pricingService: pricingService,
+		auth:           authMiddleware,
+		rg:             rg,
+	}
+}
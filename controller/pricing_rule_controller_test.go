@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	mock "team2/shuttleslot/mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var pricingRuleRequest = dto.CreatePricingRuleRequest{
+	Name:      "Evening",
+	Weekdays:  []int{1, 2, 3, 4, 5},
+	StartTime: "18:00:00",
+	EndTime:   "22:00:00",
+	Price:     70000,
+}
+
+type PricingRuleControllerTestSuite struct {
+	suite.Suite
+	pricingServiceMock *servicemock.PricingServiceMock
+	middlewareMock     *mock.AuthMiddlewareMock
+	rg                 *gin.RouterGroup
+	controller         *PricingRuleController
+}
+
+func (suite *PricingRuleControllerTestSuite) SetupTest() {
+	suite.pricingServiceMock = new(servicemock.PricingServiceMock)
+	rg := gin.Default()
+	suite.rg = rg.Group("/api/v1")
+	suite.middlewareMock = new(mock.AuthMiddlewareMock)
+	suite.controller = NewPricingRuleController(suite.pricingServiceMock, suite.middlewareMock, suite.rg)
+	suite.controller.Route()
+}
+
+func TestPricingRuleControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(PricingRuleControllerTestSuite))
+}
+
+func (suite *PricingRuleControllerTestSuite) TestCreateRuleHandler_Success() {
+	body, _ := json.Marshal(pricingRuleRequest)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/pricing-rules", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	rule := model.PricingRule{
+		Id:        "rule_1",
+		Name:      "Evening",
+		StartTime: time.Date(0, 1, 1, 18, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(0, 1, 1, 22, 0, 0, 0, time.UTC),
+		Price:     70000,
+	}
+	suite.pricingServiceMock.On("CreateRule", pricingRuleRequest).Return(rule, nil)
+
+	suite.controller.CreateRuleHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"startTime":"18:00:00"`)
+	assert.Contains(suite.T(), record.Body.String(), `"holidayDate":""`)
+}
+
+func (suite *PricingRuleControllerTestSuite) TestCreateRuleHandler_Invalid() {
+	body, _ := json.Marshal(pricingRuleRequest)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/pricing-rules", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.pricingServiceMock.On("CreateRule", pricingRuleRequest).Return(model.PricingRule{}, errors.New("invalid pricing rule, startTime must be before endTime"))
+
+	suite.controller.CreateRuleHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *PricingRuleControllerTestSuite) TestCreateRuleHandler_FailedBinding() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/pricing-rules", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.CreateRuleHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *PricingRuleControllerTestSuite) TestFindAllRulesHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/pricing-rules", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.pricingServiceMock.On("FindAllRules").Return([]model.PricingRule{{Id: "rule_1"}, {Id: "rule_2"}}, nil)
+
+	suite.controller.FindAllRulesHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"id":"rule_2"`)
+}
+
+func (suite *PricingRuleControllerTestSuite) TestFindAllRulesHandler_Failed() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/pricing-rules", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.pricingServiceMock.On("FindAllRules").Return([]model.PricingRule{}, errors.New("error"))
+
+	suite.controller.FindAllRulesHandler(ctx)
+	assert.Equal(suite.T(), http.StatusInternalServerError, record.Code)
+}
+
+func (suite *PricingRuleControllerTestSuite) TestDeleteRuleHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/pricing-rules/rule_1", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Params = gin.Params{{Key: "id", Value: "rule_1"}}
+	ctx.Request = req
+
+	suite.pricingServiceMock.On("DeleteRule", "rule_1").Return(errors.New("pricing rule not found"))
+
+	suite.controller.DeleteRuleHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
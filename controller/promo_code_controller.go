@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"team2/shuttleslot/middleware"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/service"
+	"team2/shuttleslot/util"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoCodeController struct {
+	promoService service.PromoService
+	auth         middleware.AuthMiddleware
+	rg           *gin.RouterGroup
+}
+
+func (c *PromoCodeController) CreatePromoHandler(ctx *gin.Context) {
+	var payload dto.CreatePromoCodeRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	promo, err := c.promoService.CreatePromo(payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.PromoCodeResponse{}
+	util.SendSingleResponse(ctx, "promo code created successfully", response.FromModel(promo), http.StatusCreated)
+}
+
+func (c *PromoCodeController) FindAllPromosHandler(ctx *gin.Context) {
+	promos, err := c.promoService.FindAllPromos()
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	var responseTemplate util.PromoCodeResponse
+
+	for _, val := range promos {
+		listData = append(listData, responseTemplate.FromModel(val))
+	}
+
+	util.SendSingleResponse(ctx, "success get data", listData, http.StatusOK)
+}
+
+func (c *PromoCodeController) DeletePromoHandler(ctx *gin.Context) {
+	err := c.promoService.DeletePromo(ctx.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "promo code deleted successfully", nil, http.StatusOK)
+}
+
+func (c *PromoCodeController) PromoReportHandler(ctx *gin.Context) {
+	now := time.Now()
+	startDate := ctx.DefaultQuery("startDate", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("02-01-2006"))
+	endDate := ctx.DefaultQuery("endDate", now.Format("02-01-2006"))
+
+	if !util.IsValidDate(startDate) || !util.IsValidDate(endDate) {
+		util.SendErrorResponse(ctx, "invalid date format, use 'dd-mm-yyyy' for startDate and endDate", http.StatusBadRequest)
+		return
+	}
+
+	reports, err := c.promoService.FindPromoReport(util.StringToDate(startDate), util.StringToDate(endDate))
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	for _, val := range reports {
+		listData = append(listData, val)
+	}
+
+	util.SendSingleResponse(ctx, "success get data", listData, http.StatusOK)
+}
+
+func (c *PromoCodeController) Route() {
+	router := c.rg.Group("promo-codes", c.auth.CheckToken("admin"))
+	{
+		router.GET("/", c.FindAllPromosHandler)
+		router.POST("/", c.CreatePromoHandler)
+		router.GET("/report", c.PromoReportHandler)
+		router.DELETE("/:id", c.DeletePromoHandler)
+	}
+}
+
+func NewPromoCodeController(promoService service.PromoService, authMiddleware middleware.AuthMiddleware, rg *gin.RouterGroup) *PromoCodeController {
+	return &PromoCodeController{
+		promoService: promoService,
+		auth:         authMiddleware,
+		rg:           rg,
+	}
+}
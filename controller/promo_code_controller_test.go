@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	mock "team2/shuttleslot/mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var promoCodeRequest = dto.CreatePromoCodeRequest{
+	Code:          "HEMAT",
+	DiscountType:  "fixed",
+	DiscountValue: 10000,
+	ValidFrom:     "01-01-2030",
+	ValidUntil:    "31-01-2030",
+}
+
+type PromoCodeControllerTestSuite struct {
+	suite.Suite
+	promoServiceMock *servicemock.PromoServiceMock
+	middlewareMock   *mock.AuthMiddlewareMock
+	rg               *gin.RouterGroup
+	controller       *PromoCodeController
+}
+
+func (suite *PromoCodeControllerTestSuite) SetupTest() {
+	suite.promoServiceMock = new(servicemock.PromoServiceMock)
+	rg := gin.Default()
+	suite.rg = rg.Group("/api/v1")
+	suite.middlewareMock = new(mock.AuthMiddlewareMock)
+	suite.controller = NewPromoCodeController(suite.promoServiceMock, suite.middlewareMock, suite.rg)
+	suite.controller.Route()
+}
+
+func TestPromoCodeControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(PromoCodeControllerTestSuite))
+}
+
+func (suite *PromoCodeControllerTestSuite) TestCreatePromoHandler_Success() {
+	body, _ := json.Marshal(promoCodeRequest)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/promo-codes", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	promo := model.PromoCode{
+		Id:         "promo_1",
+		Code:       "HEMAT",
+		ValidFrom:  time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		ValidUntil: time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	suite.promoServiceMock.On("CreatePromo", promoCodeRequest).Return(promo, nil)
+
+	suite.controller.CreatePromoHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"validUntil":"31-01-2030"`)
+}
+
+func (suite *PromoCodeControllerTestSuite) TestCreatePromoHandler_Invalid() {
+	body, _ := json.Marshal(promoCodeRequest)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/promo-codes", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.promoServiceMock.On("CreatePromo", promoCodeRequest).Return(model.PromoCode{}, errors.New("invalid promo code, discountType must be percentage or fixed"))
+
+	suite.controller.CreatePromoHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *PromoCodeControllerTestSuite) TestFindAllPromosHandler_Failed() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/promo-codes", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.promoServiceMock.On("FindAllPromos").Return([]model.PromoCode{}, errors.New("error"))
+
+	suite.controller.FindAllPromosHandler(ctx)
+	assert.Equal(suite.T(), http.StatusInternalServerError, record.Code)
+}
+
+func (suite *PromoCodeControllerTestSuite) TestDeletePromoHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/promo-codes/promo_1", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Params = gin.Params{{Key: "id", Value: "promo_1"}}
+	ctx.Request = req
+
+	suite.promoServiceMock.On("DeletePromo", "promo_1").Return(errors.New("promo code not found"))
+
+	suite.controller.DeletePromoHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
+func (suite *PromoCodeControllerTestSuite) TestPromoReportHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/promo-codes/report?startDate=01-01-2030&endDate=31-01-2030", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	suite.promoServiceMock.On("FindPromoReport", start, end).Return([]model.PromoReport{{Code: "HEMAT", Redemptions: 2, TotalDiscount: 20000}}, nil)
+
+	suite.controller.PromoReportHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"totalDiscount":20000`)
+}
+
+func (suite *PromoCodeControllerTestSuite) TestPromoReportHandler_InvalidDate() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/promo-codes/report?startDate=2030-01-01", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.PromoReportHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+	"team2/shuttleslot/middleware"
+	"team2/shuttleslot/service"
+	"team2/shuttleslot/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReconciliationController struct {
+	reconciler *service.PaymentReconciler
+	auth       middleware.AuthMiddleware
+	rg         *gin.RouterGroup
+}
+
+func (c *ReconciliationController) LastReportHandler(ctx *gin.Context) {
+	util.SendSingleResponse(ctx, "success get data", c.reconciler.LastReport(), http.StatusOK)
+}
+
+func (c *ReconciliationController) ReconcileHandler(ctx *gin.Context) {
+	report, err := c.reconciler.Reconcile()
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "payments reconciled", report, http.StatusOK)
+}
+
+func (c *ReconciliationController) Route() {
+	router := c.rg.Group("payments/reconciliation", c.auth.CheckToken("admin"))
+	{
+		router.GET("/", c.LastReportHandler)
+		router.POST("/", c.ReconcileHandler)
+	}
+}
+
+func NewReconciliationController(reconciler *service.PaymentReconciler, authMiddleware middleware.AuthMiddleware, rg *gin.RouterGroup) *ReconciliationController {
+	return &ReconciliationController{
+		reconciler: reconciler,
+		auth:       authMiddleware,
+		rg:         rg,
+	}
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"team2/shuttleslot/config"
+	mock "team2/shuttleslot/mock"
+	repomock "team2/shuttleslot/mock/repo_mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/service"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReconciliationControllerTestSuite struct {
+	suite.Suite
+	repoMock         *repomock.BookingRepositoryMock
+	notificationMock *servicemock.PaymentNotificationServiceMock
+	provider         *service.FakeProvider
+	middlewareMock   *mock.AuthMiddlewareMock
+	controller       *ReconciliationController
+}
+
+func (suite *ReconciliationControllerTestSuite) SetupTest() {
+	suite.repoMock = new(repomock.BookingRepositoryMock)
+	suite.notificationMock = new(servicemock.PaymentNotificationServiceMock)
+	suite.provider = service.NewFakeProvider("http://localhost/api/v1/fake-gateway", "server-key")
+	reconciler := service.NewPaymentReconciler(suite.repoMock, suite.provider, suite.notificationMock, config.ReconcileConfig{StaleAfter: 10 * time.Minute}, time.Now)
+	suite.middlewareMock = new(mock.AuthMiddlewareMock)
+	suite.controller = NewReconciliationController(reconciler, suite.middlewareMock, gin.Default().Group("/api/v1"))
+	suite.controller.Route()
+}
+
+func TestReconciliationControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(ReconciliationControllerTestSuite))
+}
+
+func (suite *ReconciliationControllerTestSuite) TestReconcileHandler_Success() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	suite.provider.UpdateStatus("Booking-1", "settlement")
+	suite.repoMock.On("FindStalePayments", testifymock.Anything).Return([]model.Payment{{OrderId: "Booking-1", BookingId: "1", Status: "unpaid"}}, nil)
+	suite.notificationMock.On("Receive", testifymock.Anything, testifymock.Anything).Return(nil)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/payments/reconciliation", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.ReconcileHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"gatewayStatus":"settlement"`)
+
+	record = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(record)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/payments/reconciliation", nil)
+
+	suite.controller.LastReportHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"orderId":"Booking-1"`)
+}
+
+func (suite *ReconciliationControllerTestSuite) TestReconcileHandler_Failed() {
+	suite.repoMock.On("FindStalePayments", testifymock.Anything).Return([]model.Payment{}, errors.New("error"))
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/payments/reconciliation", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.controller.ReconcileHandler(ctx)
+	assert.Equal(suite.T(), http.StatusInternalServerError, record.Code)
+}
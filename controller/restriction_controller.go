@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"team2/shuttleslot/middleware"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/service"
+	"team2/shuttleslot/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RestrictionController struct {
+	restrictionService service.RestrictionService
+	auth               middleware.AuthMiddleware
+	rg                 *gin.RouterGroup
+}
+
+func (c *RestrictionController) ImposeRestrictionHandler(ctx *gin.Context) {
+	var payload dto.ImposeRestrictionRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload.AdminId = ctx.GetString("userId")
+
+	restriction, err := c.restrictionService.ImposeRestriction(payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.RestrictionResponse{}
+	util.SendSingleResponse(ctx, "restriction imposed successfully", response.FromModel(restriction), http.StatusCreated)
+}
+
+func (c *RestrictionController) FindRestrictionsHandler(ctx *gin.Context) {
+	restrictions, err := c.restrictionService.FindRestrictions(ctx.Param("customerId"))
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	var responseTemplate util.RestrictionResponse
+
+	for _, val := range restrictions {
+		listData = append(listData, responseTemplate.FromModel(val))
+	}
+
+	util.SendSingleResponse(ctx, "success get data", listData, http.StatusOK)
+}
+
+func (c *RestrictionController) LiftRestrictionHandler(ctx *gin.Context) {
+	restriction, err := c.restrictionService.LiftRestriction(ctx.Param("id"), ctx.GetString("userId"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.RestrictionResponse{}
+	util.SendSingleResponse(ctx, "restriction lifted successfully", response.FromModel(restriction), http.StatusOK)
+}
+
+func (c *RestrictionController) Route() {
+	router := c.rg.Group("restrictions", c.auth.CheckToken("admin"))
+	{
+		router.POST("/", c.ImposeRestrictionHandler)
+		router.GET("/customers/:customerId", c.FindRestrictionsHandler)
+		router.POST("/:id/lift", c.LiftRestrictionHandler)
+	}
+}
+
+func NewRestrictionController(restrictionService service.RestrictionService, authMiddleware middleware.AuthMiddleware, rg *gin.RouterGroup) *RestrictionController {
+	return &RestrictionController{
+		restrictionService: restrictionService,
+		auth:               authMiddleware,
+		rg:                 rg,
+	}
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	mock "team2/shuttleslot/mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var restrictionRequest = dto.ImposeRestrictionRequest{
+	CustomerId: "customer_1",
+	Type:       "block_online",
+	Days:       14,
+	Reason:     "repeated no-shows",
+}
+
+type RestrictionControllerTestSuite struct {
+	suite.Suite
+	restrictionServiceMock *servicemock.RestrictionServiceMock
+	middlewareMock         *mock.AuthMiddlewareMock
+	rg                     *gin.RouterGroup
+	controller             *RestrictionController
+}
+
+func (suite *RestrictionControllerTestSuite) SetupTest() {
+	suite.restrictionServiceMock = new(servicemock.RestrictionServiceMock)
+	rg := gin.Default()
+	suite.rg = rg.Group("/api/v1")
+	suite.middlewareMock = new(mock.AuthMiddlewareMock)
+	suite.controller = NewRestrictionController(suite.restrictionServiceMock, suite.middlewareMock, suite.rg)
+	suite.controller.Route()
+}
+
+func TestRestrictionControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(RestrictionControllerTestSuite))
+}
+
+func (suite *RestrictionControllerTestSuite) TestImposeRestrictionHandler_Success() {
+	body, _ := json.Marshal(restrictionRequest)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/restrictions", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Set("userId", "admin_1")
+
+	expected := restrictionRequest
+	expected.AdminId = "admin_1"
+	restriction := model.Restriction{
+		Id:         "restriction_1",
+		CustomerId: "customer_1",
+		Type:       model.RestrictionBlockOnline,
+		StartsAt:   time.Now().Add(-time.Hour),
+		EndsAt:     time.Now().AddDate(0, 0, 14),
+	}
+	suite.restrictionServiceMock.On("ImposeRestriction", expected).Return(restriction, nil)
+
+	suite.controller.ImposeRestrictionHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"active":true`)
+}
+
+func (suite *RestrictionControllerTestSuite) TestImposeRestrictionHandler_Invalid() {
+	body, _ := json.Marshal(restrictionRequest)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/restrictions", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.restrictionServiceMock.On("ImposeRestriction", restrictionRequest).Return(model.Restriction{}, errors.New("invalid restriction, days must be greater than 0"))
+
+	suite.controller.ImposeRestrictionHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *RestrictionControllerTestSuite) TestImposeRestrictionHandler_CustomerNotFound() {
+	body, _ := json.Marshal(restrictionRequest)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/restrictions", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+
+	suite.restrictionServiceMock.On("ImposeRestriction", restrictionRequest).Return(model.Restriction{}, errors.New("customer not found"))
+
+	suite.controller.ImposeRestrictionHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
+func (suite *RestrictionControllerTestSuite) TestFindRestrictionsHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/restrictions/customers/customer_1", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Params = gin.Params{{Key: "customerId", Value: "customer_1"}}
+	ctx.Request = req
+
+	lifted := model.Restriction{Id: "restriction_1", Type: model.RestrictionFullPrepayment, EndsAt: time.Now().AddDate(0, 0, 1), LiftedBy: "admin_1", LiftedAt: time.Now()}
+	suite.restrictionServiceMock.On("FindRestrictions", "customer_1").Return([]model.Restriction{lifted}, nil)
+
+	suite.controller.FindRestrictionsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"active":false`)
+	assert.Contains(suite.T(), record.Body.String(), `"liftedBy":"admin_1"`)
+}
+
+func (suite *RestrictionControllerTestSuite) TestLiftRestrictionHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/restrictions/restriction_1/lift", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Params = gin.Params{{Key: "id", Value: "restriction_1"}}
+	ctx.Request = req
+	ctx.Set("userId", "admin_1")
+
+	suite.restrictionServiceMock.On("LiftRestriction", "restriction_1", "admin_1").Return(model.Restriction{Id: "restriction_1", LiftedBy: "admin_1", LiftedAt: time.Now()}, nil)
+
+	suite.controller.LiftRestrictionHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+}
+
+func (suite *RestrictionControllerTestSuite) TestLiftRestrictionHandler_NotFound() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/restrictions/restriction_1/lift", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Params = gin.Params{{Key: "id", Value: "restriction_1"}}
+	ctx.Request = req
+
+	suite.restrictionServiceMock.On("LiftRestriction", "restriction_1", "").Return(model.Restriction{}, errors.New("restriction not found or already lifted"))
+
+	suite.controller.LiftRestrictionHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"team2/shuttleslot/middleware"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
@@ -41,7 +42,7 @@ func (c *UserController) CreateAdminHandler(ctx *gin.Context) {
 		return
 	}
 
-	data, err := c.userService.CreateAdmin(payload)
+	data, err := c.userService.CreateAdmin(payload, ctx.GetString("userId"))
 	if err != nil {
 		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
 		return
@@ -66,6 +67,30 @@ func (c *UserController) CreateCustomerHandler(ctx *gin.Context) {
 	util.SendSingleResponse(ctx, "register successfully", data, http.StatusOK)
 }
 
+func (c *UserController) ClaimGuestBookingsHandler(ctx *gin.Context) {
+	var payload dto.ClaimGuestRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	claimed, err := c.userService.ClaimGuestBookings(ctx.GetString("userId"), payload.LookupToken)
+	if err != nil {
+		if strings.Contains(err.Error(), "forbidden") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusForbidden)
+			return
+		}
+		if strings.Contains(err.Error(), "cannot claim") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "guest bookings claimed successfully", util.ClaimGuestResponse{ClaimedBookings: claimed}, http.StatusOK)
+}
+
 func (c *UserController) CreateEmployeeHandler(ctx *gin.Context) {
 	payload := model.User{}
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
@@ -73,7 +98,7 @@ func (c *UserController) CreateEmployeeHandler(ctx *gin.Context) {
 		return
 	}
 
-	data, err := c.userService.CreateEmployee(payload)
+	data, err := c.userService.CreateEmployee(payload, ctx.GetString("userId"))
 	if err != nil {
 		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
 		return
@@ -148,7 +173,7 @@ func (c *UserController) UpdateUserHandler(ctx *gin.Context) {
 		return
 	}
 
-	data, err := c.userService.UpdatedUser(id, payload)
+	data, err := c.userService.UpdatedUser(id, payload, ctx.GetString("userId"))
 	if err != nil {
 		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
 		return
@@ -159,7 +184,7 @@ func (c *UserController) UpdateUserHandler(ctx *gin.Context) {
 
 func (c *UserController) DeleteUserHandler(ctx *gin.Context) {
 	id := ctx.Param("id")
-	err := c.userService.DeletedUser(id)
+	err := c.userService.DeletedUser(id, ctx.GetString("userId"))
 	if err != nil {
 		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
 		return
@@ -174,6 +199,7 @@ func (c *UserController) Route() {
 		router.POST("/login", c.LoginHandler)
 		router.POST("/register", c.CreateCustomerHandler)
 		router.PUT("/:id", c.auth.CheckToken("admin", "employee", "customer"), c.UpdateUserHandler)
+		router.POST("/claim", c.auth.CheckToken("customer"), c.ClaimGuestBookingsHandler)
 	}
 
 	adminGroup := router.Group("/", c.auth.CheckToken("admin"))
@@ -114,7 +114,7 @@ func (suite *UserControllerTestSuite) TestCreateAdminHandler_Success() {
 	ctx, _ := gin.CreateTestContext(record)
 	ctx.Request = req
 
-	suite.userServiceMock.On("CreateAdmin", payloadUser).Return(payloadUser, nil)
+	suite.userServiceMock.On("CreateAdmin", payloadUser, "").Return(payloadUser, nil)
 	suite.userController.CreateAdminHandler(ctx)
 	assert.Equal(suite.T(), http.StatusOK, record.Code)
 }
@@ -141,7 +141,7 @@ func (suite *UserControllerTestSuite) TestCreateAdminHandler_Failed() {
 	ctx, _ := gin.CreateTestContext(record)
 	ctx.Request = req
 
-	suite.userServiceMock.On("CreateAdmin", payloadUser).Return(model.User{}, errors.New("error"))
+	suite.userServiceMock.On("CreateAdmin", payloadUser, "").Return(model.User{}, errors.New("error"))
 	suite.userController.CreateAdminHandler(ctx)
 	assert.Equal(suite.T(), http.StatusInternalServerError, record.Code)
 }
@@ -191,6 +191,55 @@ func (suite *UserControllerTestSuite) TestCreateCustomerHandler_Failed() {
 	assert.Equal(suite.T(), http.StatusInternalServerError, record.Code)
 }
 
+func (suite *UserControllerTestSuite) TestClaimGuestBookingsHandler_Success() {
+	mockPayloadjson, err := json.Marshal(dto.ClaimGuestRequest{LookupToken: "lookup-token"})
+	assert.NoError(suite.T(), err)
+
+	record := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/users/claim", bytes.NewBuffer(mockPayloadjson))
+	assert.NoError(suite.T(), err)
+
+	req.Header.Set("Content-Type", "application/json")
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Set("userId", "customer_1")
+
+	suite.userServiceMock.On("ClaimGuestBookings", "customer_1", "lookup-token").Return(2, nil)
+	suite.userController.ClaimGuestBookingsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"claimedBookings":2`)
+}
+
+func (suite *UserControllerTestSuite) TestClaimGuestBookingsHandler_MissingToken() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/claim", bytes.NewBufferString(`{}`))
+
+	req.Header.Set("Content-Type", "application/json")
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	suite.userController.ClaimGuestBookingsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+	suite.userServiceMock.AssertNotCalled(suite.T(), "ClaimGuestBookings", "", "")
+}
+
+func (suite *UserControllerTestSuite) TestClaimGuestBookingsHandler_InvalidToken() {
+	mockPayloadjson, err := json.Marshal(dto.ClaimGuestRequest{LookupToken: "forged"})
+	assert.NoError(suite.T(), err)
+
+	record := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/users/claim", bytes.NewBuffer(mockPayloadjson))
+	assert.NoError(suite.T(), err)
+
+	req.Header.Set("Content-Type", "application/json")
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Set("userId", "customer_1")
+
+	suite.userServiceMock.On("ClaimGuestBookings", "customer_1", "forged").Return(0, errors.New("forbidden, invalid or expired lookup token"))
+	suite.userController.ClaimGuestBookingsHandler(ctx)
+	assert.Equal(suite.T(), http.StatusForbidden, record.Code)
+}
+
 func (suite *UserControllerTestSuite) TestCreateEmployeeHandler_Success() {
 	mockPayloadjson, err := json.Marshal(payloadUser)
 	assert.NoError(suite.T(), err)
@@ -203,7 +252,7 @@ func (suite *UserControllerTestSuite) TestCreateEmployeeHandler_Success() {
 	ctx, _ := gin.CreateTestContext(record)
 	ctx.Request = req
 
-	suite.userServiceMock.On("CreateEmployee", payloadUser).Return(payloadUser, nil)
+	suite.userServiceMock.On("CreateEmployee", payloadUser, "").Return(payloadUser, nil)
 	suite.userController.CreateEmployeeHandler(ctx)
 	assert.Equal(suite.T(), http.StatusOK, record.Code)
 }
@@ -230,7 +279,7 @@ func (suite *UserControllerTestSuite) TestCreateEmployeeHandler_Failed() {
 	ctx, _ := gin.CreateTestContext(record)
 	ctx.Request = req
 
-	suite.userServiceMock.On("CreateEmployee", payloadUser).Return(model.User{}, errors.New("error"))
+	suite.userServiceMock.On("CreateEmployee", payloadUser, "").Return(model.User{}, errors.New("error"))
 	suite.userController.CreateEmployeeHandler(ctx)
 	assert.Equal(suite.T(), http.StatusInternalServerError, record.Code)
 }
@@ -365,7 +414,7 @@ func (suite *UserControllerTestSuite) TestFindUserByIdHandler_Failed() {
 }
 
 func (suite *UserControllerTestSuite) TestDeleteUserHandler_Success() {
-	suite.userServiceMock.On("DeletedUser", "1").Return(nil)
+	suite.userServiceMock.On("DeletedUser", "1", "").Return(nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/users/1", nil)
@@ -381,7 +430,7 @@ func (suite *UserControllerTestSuite) TestDeleteUserHandler_Success() {
 }
 
 func (suite *UserControllerTestSuite) TestDeleteUserHandler_Fail() {
-	suite.userServiceMock.On("DeletedUser", "1").Return(errors.New("user not found"))
+	suite.userServiceMock.On("DeletedUser", "1", "").Return(errors.New("user not found"))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/users/1", nil)
@@ -399,7 +448,7 @@ func (suite *UserControllerTestSuite) TestDeleteUserHandler_Fail() {
 //
 
 func (suite *UserControllerTestSuite) TestUpdateUserHandler_Success() {
-	suite.userServiceMock.On("UpdatedUser", "1", payloadUser).Return(payloadUser, nil)
+	suite.userServiceMock.On("UpdatedUser", "1", payloadUser, "1").Return(payloadUser, nil)
 
 	w := httptest.NewRecorder()
 	body, _ := json.Marshal(payloadUser)
@@ -432,7 +481,7 @@ func (suite *UserControllerTestSuite) TestUpdateUserHandler_FailedBind() {
 }
 
 func (suite *UserControllerTestSuite) TestUpdateUserHandler_Failed() {
-	suite.userServiceMock.On("UpdatedUser", "1", payloadUser).Return(model.User{}, errors.New("update error"))
+	suite.userServiceMock.On("UpdatedUser", "1", payloadUser, "1").Return(model.User{}, errors.New("update error"))
 
 	w := httptest.NewRecorder()
 	body, _ := json.Marshal(payloadUser)
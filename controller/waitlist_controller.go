@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"team2/shuttleslot/middleware"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"team2/shuttleslot/service"
+	"team2/shuttleslot/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WaitlistController struct {
+	waitlistService service.WaitlistService
+	bookingService  service.BookingService
+	auth            middleware.AuthMiddleware
+	rg              *gin.RouterGroup
+}
+
+func (c *WaitlistController) JoinWaitlistHandler(ctx *gin.Context) {
+	var payload dto.JoinWaitlistRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !isValidSchedule(ctx, payload.BookingDate, payload.StartTime) {
+		return
+	}
+
+	if !util.IsValidTime(payload.EndTime) {
+		util.SendErrorResponse(ctx, "invalid time format, use 'hh-mm-ss' for endTime", http.StatusBadRequest)
+		return
+	}
+
+	payload.CustomerId = ctx.GetString("userId")
+
+	entry, err := c.waitlistService.JoinWaitlist(payload)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+			return
+		}
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := util.WaitlistEntryResponse{}
+	util.SendSingleResponse(ctx, "joined the waitlist successfully", response.FromModel(entry), http.StatusCreated)
+}
+
+func (c *WaitlistController) FindWaitlistHandler(ctx *gin.Context) {
+	entries, err := c.waitlistService.FindWaitlist(ctx.GetString("userId"))
+	if err != nil {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var listData []any
+	var responseTemplate util.WaitlistEntryResponse
+
+	for _, val := range entries {
+		listData = append(listData, responseTemplate.FromModel(val))
+	}
+
+	util.SendSingleResponse(ctx, "success get data", listData, http.StatusOK)
+}
+
+func (c *WaitlistController) LeaveWaitlistHandler(ctx *gin.Context) {
+	err := c.waitlistService.LeaveWaitlist(ctx.GetString("userId"), ctx.Param("id"))
+	if err != nil {
+		sendWaitlistError(ctx, err)
+		return
+	}
+
+	util.SendSingleResponse(ctx, "left the waitlist successfully", nil, http.StatusOK)
+}
+
+func (c *WaitlistController) AcceptOfferHandler(ctx *gin.Context) {
+	booking, err := c.bookingService.AcceptWaitlistOffer(ctx.GetString("userId"), ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingConflict) {
+			util.SendErrorResponse(ctx, err.Error(), http.StatusConflict)
+			return
+		}
+		sendWaitlistError(ctx, err)
+		return
+	}
+
+	response := util.CreateBookingResponse{}
+	util.SendSingleResponse(ctx, "booking created successfully", response.FromModel(booking), http.StatusCreated)
+}
+
+func sendWaitlistError(ctx *gin.Context, err error) {
+	if strings.Contains(err.Error(), "not found") {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusNotFound)
+		return
+	}
+	if strings.Contains(err.Error(), "forbidden") {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusForbidden)
+		return
+	}
+	if strings.Contains(err.Error(), "cannot") {
+		util.SendErrorResponse(ctx, err.Error(), http.StatusBadRequest)
+		return
+	}
+	util.SendErrorResponse(ctx, err.Error(), http.StatusInternalServerError)
+}
+
+func (c *WaitlistController) Route() {
+	router := c.rg.Group("waitlist", c.auth.CheckToken("customer"))
+	{
+		router.POST("/", c.JoinWaitlistHandler)
+		router.GET("/", c.FindWaitlistHandler)
+		router.DELETE("/:id", c.LeaveWaitlistHandler)
+		router.POST("/:id/accept", c.AcceptOfferHandler)
+	}
+}
+
+func NewWaitlistController(waitlistService service.WaitlistService, bookingService service.BookingService, authMiddleware middleware.AuthMiddleware, rg *gin.RouterGroup) *WaitlistController {
+	return &WaitlistController{
+		waitlistService: waitlistService,
+		bookingService:  bookingService,
+		auth:            authMiddleware,
+		rg:              rg,
+	}
+}
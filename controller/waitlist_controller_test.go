@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	mock "team2/shuttleslot/mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"team2/shuttleslot/util"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var joinWaitlistRequest = dto.JoinWaitlistRequest{
+	CourtId:     "court_1",
+	BookingDate: "01-10-2030",
+	StartTime:   "10:00:00",
+	EndTime:     "14:00:00",
+	Hour:        2,
+}
+
+type WaitlistControllerTestSuite struct {
+	suite.Suite
+	waitlistServiceMock *servicemock.WaitlistServiceMock
+	bookingServiceMock  *servicemock.BookingServiceMock
+	middlewareMock      *mock.AuthMiddlewareMock
+	rg                  *gin.RouterGroup
+	controller          *WaitlistController
+}
+
+func (suite *WaitlistControllerTestSuite) SetupTest() {
+	suite.waitlistServiceMock = new(servicemock.WaitlistServiceMock)
+	suite.bookingServiceMock = new(servicemock.BookingServiceMock)
+	rg := gin.Default()
+	suite.rg = rg.Group("/api/v1")
+	suite.middlewareMock = new(mock.AuthMiddlewareMock)
+	suite.controller = NewWaitlistController(suite.waitlistServiceMock, suite.bookingServiceMock, suite.middlewareMock, suite.rg)
+	suite.controller.Route()
+}
+
+func TestWaitlistControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(WaitlistControllerTestSuite))
+}
+
+func (suite *WaitlistControllerTestSuite) joinContext(request dto.JoinWaitlistRequest) (*gin.Context, *httptest.ResponseRecorder) {
+	body, _ := json.Marshal(request)
+
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/waitlist", bytes.NewBuffer(body))
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Set("userId", "customer_1")
+
+	return ctx, record
+}
+
+func (suite *WaitlistControllerTestSuite) TestJoinWaitlistHandler_Success() {
+	ctx, record := suite.joinContext(joinWaitlistRequest)
+
+	expected := joinWaitlistRequest
+	expected.CustomerId = "customer_1"
+	suite.waitlistServiceMock.On("JoinWaitlist", expected).Return(model.WaitlistEntry{
+		Id:          "entry_1",
+		CourtId:     "court_1",
+		BookingDate: util.StringToDate("01-10-2030"),
+		StartTime:   util.StringToTime("10:00:00"),
+		EndTime:     util.StringToTime("14:00:00"),
+		Hour:        2,
+		Status:      model.WaitlistWaiting,
+	}, nil)
+
+	suite.controller.JoinWaitlistHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"status":"waiting"`)
+	assert.NotContains(suite.T(), record.Body.String(), "offerExpiresAt")
+}
+
+func (suite *WaitlistControllerTestSuite) TestJoinWaitlistHandler_InvalidEndTime() {
+	request := joinWaitlistRequest
+	request.EndTime = "2pm"
+	ctx, record := suite.joinContext(request)
+
+	suite.controller.JoinWaitlistHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+	suite.waitlistServiceMock.AssertNotCalled(suite.T(), "JoinWaitlist")
+}
+
+func (suite *WaitlistControllerTestSuite) TestJoinWaitlistHandler_PastDate() {
+	request := joinWaitlistRequest
+	request.BookingDate = "01-10-2020"
+	ctx, record := suite.joinContext(request)
+
+	suite.controller.JoinWaitlistHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *WaitlistControllerTestSuite) TestJoinWaitlistHandler_CourtNotFound() {
+	ctx, record := suite.joinContext(joinWaitlistRequest)
+
+	expected := joinWaitlistRequest
+	expected.CustomerId = "customer_1"
+	suite.waitlistServiceMock.On("JoinWaitlist", expected).Return(model.WaitlistEntry{}, errors.New("court not found"))
+
+	suite.controller.JoinWaitlistHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
+
+func (suite *WaitlistControllerTestSuite) TestFindWaitlistHandler_Success() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/waitlist", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Set("userId", "customer_1")
+
+	suite.waitlistServiceMock.On("FindWaitlist", "customer_1").Return([]model.WaitlistEntry{{
+		Id:               "entry_1",
+		Status:           model.WaitlistOffered,
+		OfferedCourtId:   "court_1",
+		OfferedStartTime: util.StringToTime("11:00:00"),
+		OfferExpiresAt:   time.Now().Add(30 * time.Minute),
+	}}, nil)
+
+	suite.controller.FindWaitlistHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), `"offeredStartTime":"11:00:00"`)
+}
+
+func (suite *WaitlistControllerTestSuite) TestLeaveWaitlistHandler_Forbidden() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/waitlist/entry_1", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: "entry_1"}}
+	ctx.Set("userId", "customer_1")
+
+	suite.waitlistServiceMock.On("LeaveWaitlist", "customer_1", "entry_1").Return(errors.New("forbidden, this waitlist entry belongs to another customer"))
+
+	suite.controller.LeaveWaitlistHandler(ctx)
+	assert.Equal(suite.T(), http.StatusForbidden, record.Code)
+}
+
+func (suite *WaitlistControllerTestSuite) TestLeaveWaitlistHandler_AlreadyClosed() {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/waitlist/entry_1", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: "entry_1"}}
+	ctx.Set("userId", "customer_1")
+
+	suite.waitlistServiceMock.On("LeaveWaitlist", "customer_1", "entry_1").Return(errors.New("cannot leave the waitlist, entry is already booked"))
+
+	suite.controller.LeaveWaitlistHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *WaitlistControllerTestSuite) acceptContext() (*gin.Context, *httptest.ResponseRecorder) {
+	record := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/waitlist/entry_1/accept", nil)
+	ctx, _ := gin.CreateTestContext(record)
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: "entry_1"}}
+	ctx.Set("userId", "customer_1")
+
+	return ctx, record
+}
+
+func (suite *WaitlistControllerTestSuite) TestAcceptOfferHandler_Success() {
+	ctx, record := suite.acceptContext()
+
+	suite.bookingServiceMock.On("AcceptWaitlistOffer", "customer_1", "entry_1").Return(model.Booking{
+		Id:             "booking_1",
+		PaymentDetails: []model.Payment{{PaymentURL: "http://test-payment-url.com"}},
+	}, nil)
+
+	suite.controller.AcceptOfferHandler(ctx)
+	assert.Equal(suite.T(), http.StatusOK, record.Code)
+	assert.Contains(suite.T(), record.Body.String(), "booking created successfully")
+}
+
+func (suite *WaitlistControllerTestSuite) TestAcceptOfferHandler_Expired() {
+	ctx, record := suite.acceptContext()
+
+	suite.bookingServiceMock.On("AcceptWaitlistOffer", "customer_1", "entry_1").Return(model.Booking{}, errors.New("cannot accept, the offer has expired"))
+
+	suite.controller.AcceptOfferHandler(ctx)
+	assert.Equal(suite.T(), http.StatusBadRequest, record.Code)
+}
+
+func (suite *WaitlistControllerTestSuite) TestAcceptOfferHandler_Conflict() {
+	ctx, record := suite.acceptContext()
+
+	suite.bookingServiceMock.On("AcceptWaitlistOffer", "customer_1", "entry_1").Return(model.Booking{}, repository.ErrBookingConflict)
+
+	suite.controller.AcceptOfferHandler(ctx)
+	assert.Equal(suite.T(), http.StatusConflict, record.Code)
+}
+
+func (suite *WaitlistControllerTestSuite) TestAcceptOfferHandler_NotFound() {
+	ctx, record := suite.acceptContext()
+
+	suite.bookingServiceMock.On("AcceptWaitlistOffer", "customer_1", "entry_1").Return(model.Booking{}, errors.New("waitlist entry not found"))
+
+	suite.controller.AcceptOfferHandler(ctx)
+	assert.Equal(suite.T(), http.StatusNotFound, record.Code)
+}
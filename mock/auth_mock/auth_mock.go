@@ -21,3 +21,13 @@ func (a *AuthServiceMock) VerifyToken(tokenString string) (jwt.MapClaims, error)
 	args := a.Called(tokenString)
 	return args.Get(0).(jwt.MapClaims), args.Error(1)
 }
+
+func (a *AuthServiceMock) GenerateLookupToken(booking model.Booking) (string, error) {
+	args := a.Called(booking)
+	return args.String(0), args.Error(1)
+}
+
+func (a *AuthServiceMock) VerifyLookupToken(tokenString string) (string, error) {
+	args := a.Called(tokenString)
+	return args.String(0), args.Error(1)
+}
@@ -0,0 +1,22 @@
+package repomock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditRepositoryMock struct {
+	mock.Mock
+}
+
+func (a *AuditRepositoryMock) Create(payload model.AuditLog) (model.AuditLog, error) {
+	args := a.Called(payload)
+	return args.Get(0).(model.AuditLog), args.Error(1)
+}
+
+func (a *AuditRepositoryMock) FindAll(filter dto.AuditLogFilter, page int, size int) ([]model.AuditLog, dto.Paginate, error) {
+	args := a.Called(filter, page, size)
+	return args.Get(0).([]model.AuditLog), args.Get(1).(dto.Paginate), args.Error(2)
+}
@@ -36,14 +36,30 @@ func (b *BookingRepositoryMock) FindPaymentByOrderId(order_id string) (model.Pay
 	args := b.Called(order_id)
 	return args.Get(0).(model.Payment), args.Error(1)
 }
+func (b *BookingRepositoryMock) FindOrderTotal(orderId string) (int, error) {
+	args := b.Called(orderId)
+	return args.Int(0), args.Error(1)
+}
+func (b *BookingRepositoryMock) FindPaymentsByBookingId(bookingId string) ([]model.Payment, error) {
+	args := b.Called(bookingId)
+	return args.Get(0).([]model.Payment), args.Error(1)
+}
 func (b *BookingRepositoryMock) UpdateStatus(payload model.Payment) error {
 	args := b.Called(payload)
 	return args.Error(0)
 }
+func (b *BookingRepositoryMock) UpdatePaymentStatus(payload model.Payment) error {
+	args := b.Called(payload)
+	return args.Error(0)
+}
 func (b *BookingRepositoryMock) CreateRepay(payload model.Payment) (model.Payment, error) {
 	args := b.Called(payload)
 	return args.Get(0).(model.Payment), args.Error(1)
 }
+func (b *BookingRepositoryMock) CreateRefund(payload model.Payment) (model.Payment, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.Payment), args.Error(1)
+}
 func (b *BookingRepositoryMock) UpdateRepaymentStatus(payload model.Payment) error {
 	args := b.Called(payload)
 	return args.Error(0)
@@ -62,3 +78,73 @@ func (b *BookingRepositoryMock) FindPaymentReport(day, month, year, page, size i
 	args := b.Called(day, month, year, page, size, filterType)
 	return args.Get(0).([]model.Payment), args.Get(1).(dto.Paginate), args.Get(2).(int64), args.Error(3)
 }
+
+func (b *BookingRepositoryMock) Cancel(bookingId string, refunds []model.Payment, changedBy string) error {
+	args := b.Called(bookingId, refunds, changedBy)
+	return args.Error(0)
+}
+
+func (b *BookingRepositoryMock) Reschedule(payload model.Booking) (model.Booking, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
+
+func (b *BookingRepositoryMock) CreateSeries(payload model.BookingSeries) (model.BookingSeries, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.BookingSeries), args.Error(1)
+}
+
+func (b *BookingRepositoryMock) FindSeriesById(seriesId string) (model.BookingSeries, error) {
+	args := b.Called(seriesId)
+	return args.Get(0).(model.BookingSeries), args.Error(1)
+}
+
+func (b *BookingRepositoryMock) UpdateSeriesStatus(payload model.Payment) error {
+	args := b.Called(payload)
+	return args.Error(0)
+}
+
+func (b *BookingRepositoryMock) FindExpiredPending(cutoff time.Time) ([]model.Payment, error) {
+	args := b.Called(cutoff)
+	return args.Get(0).([]model.Payment), args.Error(1)
+}
+
+func (b *BookingRepositoryMock) FindStalePayments(cutoff time.Time) ([]model.Payment, error) {
+	args := b.Called(cutoff)
+	return args.Get(0).([]model.Payment), args.Error(1)
+}
+
+func (b *BookingRepositoryMock) ChangeStatus(bookingId string, status model.BookingStatus, changedBy string, reason string) error {
+	args := b.Called(bookingId, status, changedBy, reason)
+	return args.Error(0)
+}
+
+func (b *BookingRepositoryMock) FindStatusHistory(bookingId string) ([]model.BookingStatusHistory, error) {
+	args := b.Called(bookingId)
+	return args.Get(0).([]model.BookingStatusHistory), args.Error(1)
+}
+
+func (b *BookingRepositoryMock) FindByCustomer(customerId string, upcoming bool, page int, size int) ([]model.Booking, dto.Paginate, error) {
+	args := b.Called(customerId, upcoming, page, size)
+	return args.Get(0).([]model.Booking), args.Get(1).(dto.Paginate), args.Error(2)
+}
+
+func (b *BookingRepositoryMock) CheckInCode(bookingId string, code string) (string, error) {
+	args := b.Called(bookingId, code)
+	return args.String(0), args.Error(1)
+}
+
+func (b *BookingRepositoryMock) FindByCheckInCode(code string) (model.Booking, error) {
+	args := b.Called(code)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
+
+func (b *BookingRepositoryMock) FindMissedCheckIns(cutoff time.Time) ([]model.Booking, error) {
+	args := b.Called(cutoff)
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
+
+func (b *BookingRepositoryMock) CountNoShows(customerId string) (int, error) {
+	args := b.Called(customerId)
+	return args.Int(0), args.Error(1)
+}
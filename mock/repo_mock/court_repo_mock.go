@@ -3,6 +3,7 @@ package repomock
 import (
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -31,3 +32,28 @@ func (c *CourtRepositoryMock) Deleted(id string) error {
 	args := c.Called(id)
 	return args.Error(0)
 }
+
+func (c *CourtRepositoryMock) CreateClosure(payload model.CourtClosure) (model.CourtClosure, error) {
+	args := c.Called(payload)
+	return args.Get(0).(model.CourtClosure), args.Error(1)
+}
+
+func (c *CourtRepositoryMock) FindClosures(courtId string) ([]model.CourtClosure, error) {
+	args := c.Called(courtId)
+	return args.Get(0).([]model.CourtClosure), args.Error(1)
+}
+
+func (c *CourtRepositoryMock) FindClosuresBetween(startDate, endDate time.Time) ([]model.CourtClosure, error) {
+	args := c.Called(startDate, endDate)
+	return args.Get(0).([]model.CourtClosure), args.Error(1)
+}
+
+func (c *CourtRepositoryMock) DeleteClosure(courtId string, closureId string) error {
+	args := c.Called(courtId, closureId)
+	return args.Error(0)
+}
+
+func (c *CourtRepositoryMock) FindConflictingBookings(closure model.CourtClosure) ([]model.Booking, error) {
+	args := c.Called(closure)
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
@@ -0,0 +1,48 @@
+package repomock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type LoyaltyRepositoryMock struct {
+	mock.Mock
+}
+
+func (l *LoyaltyRepositoryMock) FindSetting() (model.LoyaltySetting, error) {
+	args := l.Called()
+	return args.Get(0).(model.LoyaltySetting), args.Error(1)
+}
+
+func (l *LoyaltyRepositoryMock) SaveSetting(payload model.LoyaltySetting) (model.LoyaltySetting, error) {
+	args := l.Called(payload)
+	return args.Get(0).(model.LoyaltySetting), args.Error(1)
+}
+
+func (l *LoyaltyRepositoryMock) CreateEntry(payload model.PointEntry) (model.PointEntry, error) {
+	args := l.Called(payload)
+	return args.Get(0).(model.PointEntry), args.Error(1)
+}
+
+func (l *LoyaltyRepositoryMock) FindEntries(customerId string, page int, size int) ([]model.PointEntry, dto.Paginate, error) {
+	args := l.Called(customerId, page, size)
+	return args.Get(0).([]model.PointEntry), args.Get(1).(dto.Paginate), args.Error(2)
+}
+
+func (l *LoyaltyRepositoryMock) FindBalance(customerId string) (int, error) {
+	args := l.Called(customerId)
+	return args.Int(0), args.Error(1)
+}
+
+func (l *LoyaltyRepositoryMock) ExpirePoints(customerId string, now time.Time) (int, error) {
+	args := l.Called(customerId, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (l *LoyaltyRepositoryMock) FindRedemptionByBookingId(bookingId string) (model.PointEntry, error) {
+	args := l.Called(bookingId)
+	return args.Get(0).(model.PointEntry), args.Error(1)
+}
@@ -0,0 +1,42 @@
+package repomock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentNotificationRepositoryMock struct {
+	mock.Mock
+}
+
+func (p *PaymentNotificationRepositoryMock) Save(payload model.PaymentNotification) (model.PaymentNotification, error) {
+	args := p.Called(payload)
+	return args.Get(0).(model.PaymentNotification), args.Error(1)
+}
+
+func (p *PaymentNotificationRepositoryMock) Claim(id string) (bool, error) {
+	args := p.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (p *PaymentNotificationRepositoryMock) Reclaim(id string) (bool, error) {
+	args := p.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (p *PaymentNotificationRepositoryMock) Finish(id string, status string, message string) error {
+	args := p.Called(id, status, message)
+	return args.Error(0)
+}
+
+func (p *PaymentNotificationRepositoryMock) FindById(id string) (model.PaymentNotification, error) {
+	args := p.Called(id)
+	return args.Get(0).(model.PaymentNotification), args.Error(1)
+}
+
+func (p *PaymentNotificationRepositoryMock) FindAll(status string, page int, size int) ([]model.PaymentNotification, dto.Paginate, error) {
+	args := p.Called(status, page, size)
+	return args.Get(0).([]model.PaymentNotification), args.Get(1).(dto.Paginate), args.Error(2)
+}
@@ -0,0 +1,26 @@
+package repomock
+
+import (
+	"team2/shuttleslot/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PricingRuleRepositoryMock struct {
+	mock.Mock
+}
+
+func (p *PricingRuleRepositoryMock) Create(payload model.PricingRule) (model.PricingRule, error) {
+	args := p.Called(payload)
+	return args.Get(0).(model.PricingRule), args.Error(1)
+}
+
+func (p *PricingRuleRepositoryMock) FindAll() ([]model.PricingRule, error) {
+	args := p.Called()
+	return args.Get(0).([]model.PricingRule), args.Error(1)
+}
+
+func (p *PricingRuleRepositoryMock) Delete(id string) error {
+	args := p.Called(id)
+	return args.Error(0)
+}
@@ -0,0 +1,47 @@
+package repomock
+
+import (
+	"team2/shuttleslot/model"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PromoCodeRepositoryMock struct {
+	mock.Mock
+}
+
+func (p *PromoCodeRepositoryMock) Create(payload model.PromoCode) (model.PromoCode, error) {
+	args := p.Called(payload)
+	return args.Get(0).(model.PromoCode), args.Error(1)
+}
+
+func (p *PromoCodeRepositoryMock) FindAll() ([]model.PromoCode, error) {
+	args := p.Called()
+	return args.Get(0).([]model.PromoCode), args.Error(1)
+}
+
+func (p *PromoCodeRepositoryMock) FindByCode(code string) (model.PromoCode, error) {
+	args := p.Called(code)
+	return args.Get(0).(model.PromoCode), args.Error(1)
+}
+
+func (p *PromoCodeRepositoryMock) Delete(id string) error {
+	args := p.Called(id)
+	return args.Error(0)
+}
+
+func (p *PromoCodeRepositoryMock) CountRedemptions(promoCodeId, customerId string) (int, int, error) {
+	args := p.Called(promoCodeId, customerId)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (p *PromoCodeRepositoryMock) FindRedemptionByBookingId(bookingId string) (model.PromoRedemption, error) {
+	args := p.Called(bookingId)
+	return args.Get(0).(model.PromoRedemption), args.Error(1)
+}
+
+func (p *PromoCodeRepositoryMock) FindReport(startDate, endDate time.Time) ([]model.PromoReport, error) {
+	args := p.Called(startDate, endDate)
+	return args.Get(0).([]model.PromoReport), args.Error(1)
+}
@@ -0,0 +1,37 @@
+package repomock
+
+import (
+	"team2/shuttleslot/model"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RestrictionRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *RestrictionRepositoryMock) Create(payload model.Restriction) (model.Restriction, error) {
+	args := r.Called(payload)
+	return args.Get(0).(model.Restriction), args.Error(1)
+}
+
+func (r *RestrictionRepositoryMock) FindByCustomer(customerId string) ([]model.Restriction, error) {
+	args := r.Called(customerId)
+	return args.Get(0).([]model.Restriction), args.Error(1)
+}
+
+func (r *RestrictionRepositoryMock) FindActive(customerId string, at time.Time) ([]model.Restriction, error) {
+	args := r.Called(customerId, at)
+	return args.Get(0).([]model.Restriction), args.Error(1)
+}
+
+func (r *RestrictionRepositoryMock) Lift(id string, liftedBy string, at time.Time) (model.Restriction, error) {
+	args := r.Called(id, liftedBy, at)
+	return args.Get(0).(model.Restriction), args.Error(1)
+}
+
+func (r *RestrictionRepositoryMock) CountNoShowsSince(customerId string, since time.Time) (int, error) {
+	args := r.Called(customerId, since)
+	return args.Int(0), args.Error(1)
+}
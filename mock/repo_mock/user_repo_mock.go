@@ -31,6 +31,10 @@ func (u *UserRepositoryMock) FindUserById(id string) (model.User, error) {
 	args := u.Called(id)
 	return args.Get(0).(model.User), args.Error(1)
 }
+func (u *UserRepositoryMock) FindUserByContact(phoneNumber string, email string) (model.User, error) {
+	args := u.Called(phoneNumber, email)
+	return args.Get(0).(model.User), args.Error(1)
+}
 func (u *UserRepositoryMock) FindUserByRole(role string, page int, size int) ([]model.User, dto.Paginate, error) {
 	args := u.Called(role, page, size)
 	return args.Get(0).([]model.User), args.Get(1).(dto.Paginate), args.Error(2)
@@ -43,3 +47,8 @@ func (u *UserRepositoryMock) DeleteUser(id string) error {
 	args := u.Called(id)
 	return args.Error(0)
 }
+
+func (u *UserRepositoryMock) ClaimGuest(bookingId string, customerId string) (int, error) {
+	args := u.Called(bookingId, customerId)
+	return args.Int(0), args.Error(1)
+}
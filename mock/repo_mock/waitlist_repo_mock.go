@@ -0,0 +1,57 @@
+package repomock
+
+import (
+	"team2/shuttleslot/model"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WaitlistRepositoryMock struct {
+	mock.Mock
+}
+
+func (w *WaitlistRepositoryMock) Create(payload model.WaitlistEntry) (model.WaitlistEntry, error) {
+	args := w.Called(payload)
+	return args.Get(0).(model.WaitlistEntry), args.Error(1)
+}
+
+func (w *WaitlistRepositoryMock) FindById(id string) (model.WaitlistEntry, error) {
+	args := w.Called(id)
+	return args.Get(0).(model.WaitlistEntry), args.Error(1)
+}
+
+func (w *WaitlistRepositoryMock) FindByCustomer(customerId string) ([]model.WaitlistEntry, error) {
+	args := w.Called(customerId)
+	return args.Get(0).([]model.WaitlistEntry), args.Error(1)
+}
+
+func (w *WaitlistRepositoryMock) FindWaiting(bookingDate time.Time, courtId string) ([]model.WaitlistEntry, error) {
+	args := w.Called(bookingDate, courtId)
+	return args.Get(0).([]model.WaitlistEntry), args.Error(1)
+}
+
+func (w *WaitlistRepositoryMock) FindExpiredOffers(now time.Time) ([]model.WaitlistEntry, error) {
+	args := w.Called(now)
+	return args.Get(0).([]model.WaitlistEntry), args.Error(1)
+}
+
+func (w *WaitlistRepositoryMock) Offer(payload model.WaitlistEntry) error {
+	args := w.Called(payload)
+	return args.Error(0)
+}
+
+func (w *WaitlistRepositoryMock) Fulfil(id string, bookingId string) error {
+	args := w.Called(id, bookingId)
+	return args.Error(0)
+}
+
+func (w *WaitlistRepositoryMock) Expire(id string) error {
+	args := w.Called(id)
+	return args.Error(0)
+}
+
+func (w *WaitlistRepositoryMock) Leave(id string) error {
+	args := w.Called(id)
+	return args.Error(0)
+}
@@ -0,0 +1,21 @@
+package servicemock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditServiceMock struct {
+	mock.Mock
+}
+
+func (a *AuditServiceMock) Record(entry model.AuditLog, before any, after any) {
+	a.Called(entry, before, after)
+}
+
+func (a *AuditServiceMock) FindAll(filter dto.AuditLogFilter, page int, size int) ([]model.AuditLog, dto.Paginate, error) {
+	args := a.Called(filter, page, size)
+	return args.Get(0).([]model.AuditLog), args.Get(1).(dto.Paginate), args.Error(2)
+}
@@ -24,6 +24,26 @@ func (b *BookingServiceMock) CreateRepay(payload dto.CreateRepayRequest) (model.
 	args := b.Called(payload)
 	return args.Get(0).(model.Payment), args.Error(1)
 }
+func (b *BookingServiceMock) Refund(payload dto.CreateRefundRequest) (model.Payment, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.Payment), args.Error(1)
+}
+func (b *BookingServiceMock) Cancel(payload dto.CancelBookingRequest) (model.Booking, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
+func (b *BookingServiceMock) Reschedule(payload dto.RescheduleBookingRequest) (model.Booking, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
+func (b *BookingServiceMock) CreateSeries(payload dto.CreateBookingSeriesRequest) (model.BookingSeries, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.BookingSeries), args.Error(1)
+}
+func (b *BookingServiceMock) CancelSeries(payload dto.CancelBookingSeriesRequest) ([]model.Booking, error) {
+	args := b.Called(payload)
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
 func (b *BookingServiceMock) FindAllBookings(page int, size int) ([]model.Booking, dto.Paginate, error) {
 	args := b.Called(page, size)
 	return args.Get(0).([]model.Booking), args.Get(1).(dto.Paginate), args.Error(2)
@@ -40,3 +60,78 @@ func (b *BookingServiceMock) FindPaymentReport(day, month, year, page, size int,
 	args := b.Called(day, month, year, page, size, filterType)
 	return args.Get(0).([]model.Payment), args.Get(1).(dto.Paginate), args.Get(2).(int64), args.Error(3)
 }
+
+func (b *BookingServiceMock) FindAvailability(payload dto.AvailabilityRequest, page int, size int) ([]model.CourtAvailability, dto.Paginate, error) {
+	args := b.Called(payload, page, size)
+	return args.Get(0).([]model.CourtAvailability), args.Get(1).(dto.Paginate), args.Error(2)
+}
+
+func (b *BookingServiceMock) ExpirePending(cutoff time.Time) (int, error) {
+	args := b.Called(cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+func (b *BookingServiceMock) FindStatusHistory(bookingId string) ([]model.BookingStatusHistory, error) {
+	args := b.Called(bookingId)
+	return args.Get(0).([]model.BookingStatusHistory), args.Error(1)
+}
+
+func (b *BookingServiceMock) FindCustomerBookings(customerId string, upcoming bool, page int, size int) ([]model.Booking, dto.Paginate, error) {
+	args := b.Called(customerId, upcoming, page, size)
+	return args.Get(0).([]model.Booking), args.Get(1).(dto.Paginate), args.Error(2)
+}
+
+func (b *BookingServiceMock) FindCustomerBooking(customerId string, bookingId string) (model.Booking, error) {
+	args := b.Called(customerId, bookingId)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
+
+func (b *BookingServiceMock) CreateWalkIn(payload dto.CreateWalkInBookingRequest) (model.Booking, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
+
+func (b *BookingServiceMock) CreateGuestBooking(payload dto.CreateGuestBookingRequest) (model.Booking, string, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.Booking), args.String(1), args.Error(2)
+}
+
+func (b *BookingServiceMock) FindGuestBooking(token string) (model.Booking, error) {
+	args := b.Called(token)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
+
+func (b *BookingServiceMock) CancelGuestBooking(token string) (model.Booking, error) {
+	args := b.Called(token)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
+
+func (b *BookingServiceMock) CheckInQRCode(customerId string, bookingId string) ([]byte, error) {
+	args := b.Called(customerId, bookingId)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (b *BookingServiceMock) GuestCheckInQRCode(token string) ([]byte, error) {
+	args := b.Called(token)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (b *BookingServiceMock) CheckIn(payload dto.CheckInRequest) (model.Booking, error) {
+	args := b.Called(payload)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
+
+func (b *BookingServiceMock) MarkNoShows(cutoff time.Time) (int, error) {
+	args := b.Called(cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+func (b *BookingServiceMock) CountNoShows(customerId string) (int, error) {
+	args := b.Called(customerId)
+	return args.Int(0), args.Error(1)
+}
+
+func (b *BookingServiceMock) AcceptWaitlistOffer(customerId string, entryId string) (model.Booking, error) {
+	args := b.Called(customerId, entryId)
+	return args.Get(0).(model.Booking), args.Error(1)
+}
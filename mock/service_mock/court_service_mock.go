@@ -3,6 +3,7 @@ package servicemock
 import (
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -11,8 +12,8 @@ type CourtServiceMock struct {
 	mock.Mock
 }
 
-func (c *CourtServiceMock) CreateCourt(payload model.Court) (model.Court, error) {
-	args := c.Called(payload)
+func (c *CourtServiceMock) CreateCourt(payload model.Court, actor string) (model.Court, error) {
+	args := c.Called(payload, actor)
 	return args.Get(0).(model.Court), args.Error(1)
 }
 func (c *CourtServiceMock) FindAllCourts(page int, size int) ([]model.Court, dto.Paginate, error) {
@@ -23,11 +24,31 @@ func (c *CourtServiceMock) FindCourtById(id string) (model.Court, error) {
 	args := c.Called(id)
 	return args.Get(0).(model.Court), args.Error(1)
 }
-func (c *CourtServiceMock) UpdateCourt(id string, payload model.Court) (model.Court, error) {
-	args := c.Called(id, payload)
+func (c *CourtServiceMock) UpdateCourt(id string, payload model.Court, actor string) (model.Court, error) {
+	args := c.Called(id, payload, actor)
 	return args.Get(0).(model.Court), args.Error(1)
 }
-func (c *CourtServiceMock) DeleteCourt(id string) error {
-	args := c.Called(id)
+func (c *CourtServiceMock) DeleteCourt(id string, actor string) error {
+	args := c.Called(id, actor)
+	return args.Error(0)
+}
+
+func (c *CourtServiceMock) CreateClosure(payload dto.CreateCourtClosureRequest) (model.CourtClosure, []model.Booking, error) {
+	args := c.Called(payload)
+	return args.Get(0).(model.CourtClosure), args.Get(1).([]model.Booking), args.Error(2)
+}
+
+func (c *CourtServiceMock) FindClosures(courtId string) ([]model.CourtClosure, error) {
+	args := c.Called(courtId)
+	return args.Get(0).([]model.CourtClosure), args.Error(1)
+}
+
+func (c *CourtServiceMock) FindClosuresBetween(startDate, endDate time.Time) ([]model.CourtClosure, error) {
+	args := c.Called(startDate, endDate)
+	return args.Get(0).([]model.CourtClosure), args.Error(1)
+}
+
+func (c *CourtServiceMock) DeleteClosure(courtId string, closureId string) error {
+	args := c.Called(courtId, closureId)
 	return args.Error(0)
 }
@@ -0,0 +1,42 @@
+package servicemock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type LoyaltyServiceMock struct {
+	mock.Mock
+}
+
+func (l *LoyaltyServiceMock) FindSetting() (model.LoyaltySetting, error) {
+	args := l.Called()
+	return args.Get(0).(model.LoyaltySetting), args.Error(1)
+}
+
+func (l *LoyaltyServiceMock) UpdateSetting(payload dto.UpdateLoyaltySettingRequest) (model.LoyaltySetting, error) {
+	args := l.Called(payload)
+	return args.Get(0).(model.LoyaltySetting), args.Error(1)
+}
+
+func (l *LoyaltyServiceMock) FindPoints(customerId string, page int, size int) (model.PointBalance, dto.Paginate, error) {
+	args := l.Called(customerId, page, size)
+	return args.Get(0).(model.PointBalance), args.Get(1).(dto.Paginate), args.Error(2)
+}
+
+func (l *LoyaltyServiceMock) AdjustPoints(payload dto.AdjustPointsRequest) (model.PointEntry, error) {
+	args := l.Called(payload)
+	return args.Get(0).(model.PointEntry), args.Error(1)
+}
+
+func (l *LoyaltyServiceMock) QuoteRedemption(customerId string, total int) (model.PointEntry, error) {
+	args := l.Called(customerId, total)
+	return args.Get(0).(model.PointEntry), args.Error(1)
+}
+
+func (l *LoyaltyServiceMock) FindRedemption(bookingId string) (model.PointEntry, error) {
+	args := l.Called(bookingId)
+	return args.Get(0).(model.PointEntry), args.Error(1)
+}
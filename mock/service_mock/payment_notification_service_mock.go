@@ -0,0 +1,27 @@
+package servicemock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentNotificationServiceMock struct {
+	mock.Mock
+}
+
+func (p *PaymentNotificationServiceMock) Receive(payload dto.PaymentNotificationInput, raw []byte) error {
+	args := p.Called(payload, raw)
+	return args.Error(0)
+}
+
+func (p *PaymentNotificationServiceMock) Replay(id string) (model.PaymentNotification, error) {
+	args := p.Called(id)
+	return args.Get(0).(model.PaymentNotification), args.Error(1)
+}
+
+func (p *PaymentNotificationServiceMock) FindNotifications(status string, page int, size int) ([]model.PaymentNotification, dto.Paginate, error) {
+	args := p.Called(status, page, size)
+	return args.Get(0).([]model.PaymentNotification), args.Get(1).(dto.Paginate), args.Error(2)
+}
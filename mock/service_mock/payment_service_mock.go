@@ -20,3 +20,13 @@ func (m *PaymentGateServiceMock) PaymentProcess(payload dto.PaymentNotificationI
 	args := m.Called(payload)
 	return args.Get(0).(model.Payment), args.Error(1)
 }
+
+func (m *PaymentGateServiceMock) Refund(refund model.Payment) error {
+	args := m.Called(refund)
+	return args.Error(0)
+}
+
+func (m *PaymentGateServiceMock) Expire(orderId string) error {
+	args := m.Called(orderId)
+	return args.Error(0)
+}
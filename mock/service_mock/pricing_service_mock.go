@@ -0,0 +1,33 @@
+package servicemock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PricingServiceMock struct {
+	mock.Mock
+}
+
+func (p *PricingServiceMock) CreateRule(payload dto.CreatePricingRuleRequest) (model.PricingRule, error) {
+	args := p.Called(payload)
+	return args.Get(0).(model.PricingRule), args.Error(1)
+}
+
+func (p *PricingServiceMock) FindAllRules() ([]model.PricingRule, error) {
+	args := p.Called()
+	return args.Get(0).([]model.PricingRule), args.Error(1)
+}
+
+func (p *PricingServiceMock) DeleteRule(id string) error {
+	args := p.Called(id)
+	return args.Error(0)
+}
+
+func (p *PricingServiceMock) PriceBooking(court model.Court, bookingDate, startTime time.Time, hour int) ([]model.PriceItem, error) {
+	args := p.Called(court, bookingDate, startTime, hour)
+	return args.Get(0).([]model.PriceItem), args.Error(1)
+}
@@ -0,0 +1,43 @@
+package servicemock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PromoServiceMock struct {
+	mock.Mock
+}
+
+func (p *PromoServiceMock) CreatePromo(payload dto.CreatePromoCodeRequest) (model.PromoCode, error) {
+	args := p.Called(payload)
+	return args.Get(0).(model.PromoCode), args.Error(1)
+}
+
+func (p *PromoServiceMock) FindAllPromos() ([]model.PromoCode, error) {
+	args := p.Called()
+	return args.Get(0).([]model.PromoCode), args.Error(1)
+}
+
+func (p *PromoServiceMock) DeletePromo(id string) error {
+	args := p.Called(id)
+	return args.Error(0)
+}
+
+func (p *PromoServiceMock) ApplyPromo(code, customerId, courtId string, total int) (model.PromoRedemption, error) {
+	args := p.Called(code, customerId, courtId, total)
+	return args.Get(0).(model.PromoRedemption), args.Error(1)
+}
+
+func (p *PromoServiceMock) FindRedemption(bookingId string) (model.PromoRedemption, error) {
+	args := p.Called(bookingId)
+	return args.Get(0).(model.PromoRedemption), args.Error(1)
+}
+
+func (p *PromoServiceMock) FindPromoReport(startDate, endDate time.Time) ([]model.PromoReport, error) {
+	args := p.Called(startDate, endDate)
+	return args.Get(0).([]model.PromoReport), args.Error(1)
+}
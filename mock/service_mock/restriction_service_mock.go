@@ -0,0 +1,37 @@
+package servicemock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RestrictionServiceMock struct {
+	mock.Mock
+}
+
+func (r *RestrictionServiceMock) ImposeRestriction(payload dto.ImposeRestrictionRequest) (model.Restriction, error) {
+	args := r.Called(payload)
+	return args.Get(0).(model.Restriction), args.Error(1)
+}
+
+func (r *RestrictionServiceMock) LiftRestriction(id string, actor string) (model.Restriction, error) {
+	args := r.Called(id, actor)
+	return args.Get(0).(model.Restriction), args.Error(1)
+}
+
+func (r *RestrictionServiceMock) FindRestrictions(customerId string) ([]model.Restriction, error) {
+	args := r.Called(customerId)
+	return args.Get(0).([]model.Restriction), args.Error(1)
+}
+
+func (r *RestrictionServiceMock) FindActiveRestrictions(customerId string) ([]model.Restriction, error) {
+	args := r.Called(customerId)
+	return args.Get(0).([]model.Restriction), args.Error(1)
+}
+
+func (r *RestrictionServiceMock) ApplyNoShowPenalties(customerId string) ([]model.Restriction, error) {
+	args := r.Called(customerId)
+	return args.Get(0).([]model.Restriction), args.Error(1)
+}
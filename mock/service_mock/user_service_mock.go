@@ -11,16 +11,20 @@ type UserServiceMock struct {
 	mock.Mock
 }
 
-func (u *UserServiceMock) CreateAdmin(payload model.User) (model.User, error) {
-	args := u.Called(payload)
+func (u *UserServiceMock) CreateAdmin(payload model.User, actor string) (model.User, error) {
+	args := u.Called(payload, actor)
 	return args.Get(0).(model.User), args.Error(1)
 }
 func (u *UserServiceMock) CreateCustomer(payload model.User) (model.User, error) {
 	args := u.Called(payload)
 	return args.Get(0).(model.User), args.Error(1)
 }
-func (u *UserServiceMock) CreateEmployee(payload model.User) (model.User, error) {
-	args := u.Called(payload)
+func (u *UserServiceMock) CreateEmployee(payload model.User, actor string) (model.User, error) {
+	args := u.Called(payload, actor)
+	return args.Get(0).(model.User), args.Error(1)
+}
+func (u *UserServiceMock) FindOrCreateGuest(payload model.User, actor string) (model.User, error) {
+	args := u.Called(payload, actor)
 	return args.Get(0).(model.User), args.Error(1)
 }
 func (u *UserServiceMock) FindUserByRole(role string, page int, size int) ([]model.User, dto.Paginate, error) {
@@ -35,15 +39,19 @@ func (u *UserServiceMock) FindUserById(id string) (model.User, error) {
 	args := u.Called(id)
 	return args.Get(0).(model.User), args.Error(1)
 }
-func (u *UserServiceMock) UpdatedUser(id string, payload model.User) (model.User, error) {
-	args := u.Called(id, payload)
+func (u *UserServiceMock) UpdatedUser(id string, payload model.User, actor string) (model.User, error) {
+	args := u.Called(id, payload, actor)
 	return args.Get(0).(model.User), args.Error(1)
 }
-func (u *UserServiceMock) DeletedUser(id string) error {
-	args := u.Called(id)
+func (u *UserServiceMock) DeletedUser(id string, actor string) error {
+	args := u.Called(id, actor)
 	return args.Error(0)
 }
 func (u *UserServiceMock) Login(payload dto.LoginRequest) (dto.LoginResponse, error) {
 	args := u.Called(payload)
 	return args.Get(0).(dto.LoginResponse), args.Error(1)
 }
+func (u *UserServiceMock) ClaimGuestBookings(customerId string, token string) (int, error) {
+	args := u.Called(customerId, token)
+	return args.Int(0), args.Error(1)
+}
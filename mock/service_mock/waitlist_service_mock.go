@@ -0,0 +1,48 @@
+package servicemock
+
+import (
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WaitlistServiceMock struct {
+	mock.Mock
+}
+
+func (w *WaitlistServiceMock) JoinWaitlist(payload dto.JoinWaitlistRequest) (model.WaitlistEntry, error) {
+	args := w.Called(payload)
+	return args.Get(0).(model.WaitlistEntry), args.Error(1)
+}
+
+func (w *WaitlistServiceMock) FindWaitlist(customerId string) ([]model.WaitlistEntry, error) {
+	args := w.Called(customerId)
+	return args.Get(0).([]model.WaitlistEntry), args.Error(1)
+}
+
+func (w *WaitlistServiceMock) LeaveWaitlist(customerId string, entryId string) error {
+	args := w.Called(customerId, entryId)
+	return args.Error(0)
+}
+
+func (w *WaitlistServiceMock) OfferSlot(released model.Booking) (model.WaitlistEntry, error) {
+	args := w.Called(released)
+	return args.Get(0).(model.WaitlistEntry), args.Error(1)
+}
+
+func (w *WaitlistServiceMock) ClaimOffer(customerId string, entryId string) (model.WaitlistEntry, error) {
+	args := w.Called(customerId, entryId)
+	return args.Get(0).(model.WaitlistEntry), args.Error(1)
+}
+
+func (w *WaitlistServiceMock) FulfilOffer(entryId string, bookingId string) error {
+	args := w.Called(entryId, bookingId)
+	return args.Error(0)
+}
+
+func (w *WaitlistServiceMock) ExpireOffers(now time.Time) (int, error) {
+	args := w.Called(now)
+	return args.Int(0), args.Error(1)
+}
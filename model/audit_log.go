@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// AuditLog records who did what to which entity. Entries are only ever
+// inserted, never updated or deleted. Before and After hold the entity as
+// JSON around the change, and are empty when there was nothing on that side,
+// e.g. no Before for a create.
+type AuditLog struct {
+	Id         string    `json:"id"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	EntityType string    `json:"entityType"`
+	EntityId   string    `json:"entityId"`
+	Detail     string    `json:"detail"`
+	Before     string    `json:"before"`
+	After      string    `json:"after"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type CourtAvailability struct {
+	Court Court
+	Days  []DayAvailability
+}
+
+type DayAvailability struct {
+	Date  time.Time
+	Slots []Slot
+}
+
+type Slot struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Status    string
+}
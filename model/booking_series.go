@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type BookingSeries struct {
+	Id        string    `json:"id"`
+	Customer  User      `json:"customer"`
+	Court     Court     `json:"court"`
+	Frequency string    `json:"frequency"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Bookings  []Booking `json:"bookings"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (b BookingSeries) IsValidFrequency() bool {
+	return b.Frequency == "weekly" || b.Frequency == "biweekly"
+}
+
+// IntervalDays is the number of days between two occurrences of the series.
+func (b BookingSeries) IntervalDays() int {
+	if b.Frequency == "biweekly" {
+		return 14
+	}
+	return 7
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type BookingStatus string
+
+const (
+	BookingPending   BookingStatus = "pending"
+	BookingBooked    BookingStatus = "booked"
+	BookingPaid      BookingStatus = "paid"
+	BookingCheckedIn BookingStatus = "checked_in"
+	BookingDone      BookingStatus = "done"
+	BookingNoShow    BookingStatus = "no_show"
+	BookingCancel    BookingStatus = "cancel"
+	BookingRefunded  BookingStatus = "refunded"
+)
+
+// bookingTransitions lists the statuses a booking may move to from each
+// status. A pending booking is booked once its down payment is paid, or paid
+// when it was paid in full, online or at the desk. Paid, like booked, is still
+// to be played, while a done booking had its rest settled at the desk and so
+// cannot be missed. Refunded is final.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingPending:   {BookingBooked, BookingPaid, BookingCancel},
+	BookingBooked:    {BookingCheckedIn, BookingDone, BookingNoShow, BookingCancel},
+	BookingPaid:      {BookingCheckedIn, BookingNoShow, BookingCancel},
+	BookingCheckedIn: {BookingDone},
+	BookingDone:      {BookingCheckedIn, BookingRefunded},
+	BookingNoShow:    {BookingRefunded},
+	BookingCancel:    {BookingRefunded},
+}
+
+// BookingTransitionError is returned when a booking is asked to move to a
+// status its current status does not allow.
+type BookingTransitionError struct {
+	From BookingStatus
+	To   BookingStatus
+}
+
+func (e *BookingTransitionError) Error() string {
+	return fmt.Sprintf("cannot move booking from %s to %s", e.From, e.To)
+}
+
+func (s BookingStatus) CanTransitionTo(status BookingStatus) bool {
+	for _, next := range bookingTransitions[s] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition checks the move from s to status against the transition table.
+func (s BookingStatus) Transition(status BookingStatus) error {
+	if !s.CanTransitionTo(status) {
+		return &BookingTransitionError{From: s, To: status}
+	}
+	return nil
+}
+
+// ActiveBookingStatuses are the statuses in which a booking still holds its
+// court. Done is among them because a booking whose rest was paid at the desk
+// is done before it is played.
+var ActiveBookingStatuses = []BookingStatus{BookingPending, BookingBooked, BookingPaid, BookingCheckedIn, BookingDone}
+
+// IsActive reports whether a booking in this status still holds its court.
+func (s BookingStatus) IsActive() bool {
+	for _, active := range ActiveBookingStatuses {
+		if s == active {
+			return true
+		}
+	}
+	return false
+}
+
+// BookingStatusHistory is one recorded move of a booking between statuses.
+type BookingStatusHistory struct {
+	Id         string        `json:"id"`
+	BookingId  string        `json:"bookingId"`
+	FromStatus BookingStatus `json:"fromStatus"`
+	ToStatus   BookingStatus `json:"toStatus"`
+	ChangedBy  string        `json:"changedBy"`
+	Reason     string        `json:"reason"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
@@ -3,9 +3,33 @@ package model
 import "time"
 
 type Court struct {
-	Id        string    `json:"id"`
-	Name      string    `josn:"name"`
-	Price     int       `json:"price"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	Id              string        `json:"id"`
+	Name            string        `josn:"name"`
+	Price           int           `json:"price"`
+	OpeningHours    []OpeningHour `json:"openingHours"`
+	MinBookingHours int           `json:"minBookingHours"`
+	MaxBookingHours int           `json:"maxBookingHours"`
+	SlotMinutes     int           `json:"slotMinutes"`
+	DepositPercent  int           `json:"depositPercent"`
+	CreatedAt       time.Time     `json:"createdAt"`
+	UpdatedAt       time.Time     `json:"updatedAt"`
+}
+
+// OpeningHour is the operating time of a court on one weekday, where Weekday
+// follows time.Weekday (0 is Sunday). A court without any opening hour uses the
+// default schedule, and a court with opening hours is closed on missing days.
+type OpeningHour struct {
+	Weekday   int    `json:"weekday"`
+	OpenTime  string `json:"openTime"`
+	CloseTime string `json:"closeTime"`
+}
+
+func (c Court) OpeningHourOn(weekday time.Weekday) (OpeningHour, bool) {
+	for _, val := range c.OpeningHours {
+		if val.Weekday == int(weekday) {
+			return val, true
+		}
+	}
+
+	return OpeningHour{}, false
 }
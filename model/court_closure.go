@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// CourtClosure blocks a court for every day between StartDate and EndDate,
+// from StartTime until EndTime on each of those days.
+type CourtClosure struct {
+	Id        string    `json:"id"`
+	CourtId   string    `json:"courtId"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Covers reports whether the closure blocks any part of the given time range
+// on the given date.
+func (c CourtClosure) Covers(date, startTime, endTime time.Time) bool {
+	if date.Before(c.StartDate) || date.After(c.EndDate) {
+		return false
+	}
+
+	return c.StartTime.Before(endTime) && startTime.Before(c.EndTime)
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// AuditLogFilter narrows the audit log down. Empty fields and zero dates are
+// not filtered on, and EndDate includes the whole day.
+type AuditLogFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityId   string
+	StartDate  time.Time
+	EndDate    time.Time
+}
@@ -11,6 +11,10 @@ type LoginResponse struct {
 	Token string `json:"token"`
 }
 
+type ClaimGuestRequest struct {
+	LookupToken string `json:"lookupToken" binding:"required"`
+}
+
 type JwtTokenClaims struct {
 	jwt.RegisteredClaims
 	UserId string `json:"userId"`
@@ -6,6 +6,40 @@ type CreateBookingRequest struct {
 	StartTime   string `json:"startTime"`
 	Hour        int    `json:"hour"`
 	CustomerId  string `json:"customerId"`
+	PromoCode   string `json:"promoCode"`
+	UsePoints   bool   `json:"usePoints"`
+	PayInFull   bool   `json:"payInFull"`
+
+	// EmployeeId and PaymentMethod are only set for walk-in bookings made at
+	// the front desk, never from the customer's request body.
+	EmployeeId    string `json:"-"`
+	PaymentMethod string `json:"-"`
+}
+
+type CreateWalkInBookingRequest struct {
+	CourtId       string `json:"courtId"`
+	BookingDate   string `json:"bookingDate"`
+	StartTime     string `json:"startTime"`
+	Hour          int    `json:"hour"`
+	CustomerId    string `json:"customerId"`
+	Name          string `json:"name"`
+	PhoneNumber   string `json:"phoneNumber"`
+	PromoCode     string `json:"promoCode"`
+	PayInFull     bool   `json:"payInFull"`
+	PaymentMethod string `json:"paymentMethod"`
+	EmployeeId    string `json:"employeeId"`
+}
+
+type CreateGuestBookingRequest struct {
+	CourtId     string `json:"courtId"`
+	BookingDate string `json:"bookingDate"`
+	StartTime   string `json:"startTime"`
+	Hour        int    `json:"hour"`
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phoneNumber"`
+	Email       string `json:"email"`
+	PromoCode   string `json:"promoCode"`
+	PayInFull   bool   `json:"payInFull"`
 }
 
 type CreateRepayRequest struct {
@@ -14,11 +48,71 @@ type CreateRepayRequest struct {
 	PaymentMethod string `json:"paymentMethod"`
 }
 
+type CreateRefundRequest struct {
+	OrderId       string `json:"orderId"`
+	Amount        int    `json:"amount"`
+	Reason        string `json:"reason"`
+	PaymentMethod string `json:"paymentMethod"`
+	EmployeeId    string `json:"employeeId"`
+}
+
+type CancelBookingRequest struct {
+	BookingId string `json:"bookingId"`
+	UserId    string `json:"userId"`
+	Role      string `json:"role"`
+}
+
+type CheckInRequest struct {
+	Code       string `json:"code"`
+	EmployeeId string `json:"-"`
+}
+
+type RescheduleBookingRequest struct {
+	BookingId   string `json:"bookingId"`
+	CourtId     string `json:"courtId"`
+	BookingDate string `json:"bookingDate"`
+	StartTime   string `json:"startTime"`
+	Hour        int    `json:"hour"`
+	UserId      string `json:"userId"`
+	Role        string `json:"role"`
+}
+
+type CreateBookingSeriesRequest struct {
+	CourtId     string `json:"courtId"`
+	Frequency   string `json:"frequency"`
+	StartDate   string `json:"startDate"`
+	EndDate     string `json:"endDate"`
+	Occurrences int    `json:"occurrences"`
+	StartTime   string `json:"startTime"`
+	Hour        int    `json:"hour"`
+	CustomerId  string `json:"customerId"`
+}
+
+type CancelBookingSeriesRequest struct {
+	SeriesId string `json:"seriesId"`
+	UserId   string `json:"userId"`
+	Role     string `json:"role"`
+}
+
+type SeriesCollision struct {
+	BookingDate string `json:"bookingDate"`
+	Reason      string `json:"reason"`
+}
+
+type AvailabilityRequest struct {
+	StartDate string
+	EndDate   string
+	CourtId   string
+}
+
 type PaymentNotificationInput struct {
 	TransactionStatus string `json:"transaction_status"`
 	OrderId           string `json:"order_id"`
 	PaymentType       string `json:"payment_type"`
 	FraudStatus       string `json:"fraud_status"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
 }
 
 func (c CreateRepayRequest) IsValidMethod() bool {
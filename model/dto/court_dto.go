@@ -0,0 +1,10 @@
+package dto
+
+type CreateCourtClosureRequest struct {
+	CourtId   string `json:"courtId"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Reason    string `json:"reason"`
+}
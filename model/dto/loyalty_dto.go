@@ -0,0 +1,15 @@
+package dto
+
+type UpdateLoyaltySettingRequest struct {
+	EarnPoints   int `json:"earnPoints"`
+	EarnAmount   int `json:"earnAmount"`
+	RedeemPoints int `json:"redeemPoints"`
+	RedeemValue  int `json:"redeemValue"`
+	ExpiryDays   int `json:"expiryDays"`
+}
+
+type AdjustPointsRequest struct {
+	CustomerId  string `json:"customerId"`
+	Points      int    `json:"points"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type CreatePricingRuleRequest struct {
+	Name        string `json:"name"`
+	CourtId     string `json:"courtId"`
+	Weekdays    []int  `json:"weekdays"`
+	HolidayDate string `json:"holidayDate"`
+	StartTime   string `json:"startTime"`
+	EndTime     string `json:"endTime"`
+	Price       int    `json:"price"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+type CreatePromoCodeRequest struct {
+	Code             string   `json:"code"`
+	DiscountType     string   `json:"discountType"`
+	DiscountValue    int      `json:"discountValue"`
+	MaxDiscount      int      `json:"maxDiscount"`
+	ValidFrom        string   `json:"validFrom"`
+	ValidUntil       string   `json:"validUntil"`
+	UsageLimit       int      `json:"usageLimit"`
+	PerCustomerLimit int      `json:"perCustomerLimit"`
+	CourtIds         []string `json:"courtIds"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+type ImposeRestrictionRequest struct {
+	CustomerId string `json:"customerId"`
+	Type       string `json:"type"`
+	Days       int    `json:"days"`
+	Reason     string `json:"reason"`
+	AdminId    string `json:"-"`
+}
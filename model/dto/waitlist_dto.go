@@ -0,0 +1,10 @@
+package dto
+
+type JoinWaitlistRequest struct {
+	CourtId     string `json:"courtId"`
+	BookingDate string `json:"bookingDate"`
+	StartTime   string `json:"startTime"`
+	EndTime     string `json:"endTime"`
+	Hour        int    `json:"hour"`
+	CustomerId  string `json:"-"`
+}
@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// LoyaltySetting holds the admin-configured earn and redeem rules. With a
+// zero EarnAmount a completed booking earns EarnPoints flat, otherwise it
+// earns EarnPoints for every EarnAmount paid. Points are redeemed in blocks
+// of RedeemPoints worth RedeemValue each, and earned points expire after
+// ExpiryDays unless it is zero.
+type LoyaltySetting struct {
+	EarnPoints   int       `json:"earnPoints"`
+	EarnAmount   int       `json:"earnAmount"`
+	RedeemPoints int       `json:"redeemPoints"`
+	RedeemValue  int       `json:"redeemValue"`
+	ExpiryDays   int       `json:"expiryDays"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// DefaultLoyaltySetting is used until an admin saves a setting and matches
+// the original rule of 10 points per booking and 10,000 off for 100 points.
+var DefaultLoyaltySetting = LoyaltySetting{
+	EarnPoints:   10,
+	RedeemPoints: 100,
+	RedeemValue:  10000,
+}
+
+func (s LoyaltySetting) EarnedPoints(total int) int {
+	if s.EarnAmount <= 0 {
+		return s.EarnPoints
+	}
+
+	return total / s.EarnAmount * s.EarnPoints
+}
+
+// Redemption returns how many points to redeem out of balance and what they
+// are worth, using whole blocks only and never more than total.
+func (s LoyaltySetting) Redemption(balance, total int) (int, int) {
+	if s.RedeemPoints <= 0 || s.RedeemValue <= 0 {
+		return 0, 0
+	}
+
+	blocks := balance / s.RedeemPoints
+	if maxBlocks := total / s.RedeemValue; blocks > maxBlocks {
+		blocks = maxBlocks
+	}
+
+	return blocks * s.RedeemPoints, blocks * s.RedeemValue
+}
+
+// PointEntry is one line of a customer's points ledger. Type is earn, redeem,
+// expire or adjust, Points is signed and Amount is the rupiah value of a
+// redemption.
+type PointEntry struct {
+	Id          string    `json:"id"`
+	CustomerId  string    `json:"customerId"`
+	BookingId   string    `json:"bookingId"`
+	Type        string    `json:"type"`
+	Points      int       `json:"points"`
+	Amount      int       `json:"amount"`
+	Description string    `json:"description"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type PointBalance struct {
+	CustomerId string       `json:"customerId"`
+	Balance    int          `json:"balance"`
+	Entries    []PointEntry `json:"entries"`
+}
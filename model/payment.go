@@ -5,11 +5,29 @@ type Payment struct {
 	BookingId     string `json:"bookingId"`
 	User          User
 	Court         Court
-	OrderId       string `json:"orderId"`
-	Description   string `json:"description"`
-	PaymentMethod string `json:"paymentMethod"`
-	Price         int    `json:"price"`
-	Qty           int    `json:"qty"`
-	Status        string `json:"status"`
-	PaymentURL    string `json:"paymentURL"`
+	OrderId       string      `json:"orderId"`
+	Description   string      `json:"description"`
+	PaymentMethod string      `json:"paymentMethod"`
+	Price         int         `json:"price"`
+	Qty           int         `json:"qty"`
+	Status        string      `json:"status"`
+	PaymentURL    string      `json:"paymentURL"`
+	RefundOf      string      `json:"refundOf"`
+	Items         []PriceItem `json:"items"`
+}
+
+// paymentTransitions lists the statuses a gateway notification may move a
+// payment to. Paid, cancelled and refunded payments are final, so a late or
+// repeated notification cannot change them.
+var paymentTransitions = map[string][]string{
+	"unpaid": {"pending", "paid", "cancel"},
+}
+
+func (p Payment) CanTransitionTo(status string) bool {
+	for _, next := range paymentTransitions[p.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// PaymentNotification is a gateway notification kept in the inbox. There is
+// one per order id and transaction status; a redelivery bumps Attempts.
+//
+// Status is one of "received", "processing", "processed", "ignored" (a stale
+// or out of order notification), "rejected" (failed signature or amount
+// checks) or "failed". A notification left processing for longer than the
+// claim lease is treated as abandoned and may be claimed again.
+type PaymentNotification struct {
+	Id                string    `json:"id"`
+	OrderId           string    `json:"orderId"`
+	TransactionStatus string    `json:"transactionStatus"`
+	Payload           string    `json:"payload"`
+	Status            string    `json:"status"`
+	Attempts          int       `json:"attempts"`
+	Error             string    `json:"error"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
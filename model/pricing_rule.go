@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// PricingRule overrides the court hourly price for the hours starting inside
+// StartTime and EndTime. A rule with a HolidayDate only applies on that date and
+// wins over weekday rules; otherwise it applies on the listed Weekdays (0 is
+// Sunday, empty means every day). A rule without CourtId applies to all courts.
+type PricingRule struct {
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	CourtId     string    `json:"courtId"`
+	Weekdays    []int     `json:"weekdays"`
+	HolidayDate time.Time `json:"holidayDate"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	Price       int       `json:"price"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (r PricingRule) IsHoliday() bool {
+	return !r.HolidayDate.IsZero()
+}
+
+// Matches reports whether the rule prices the hour starting at hourStart on the
+// given date for the given court.
+func (r PricingRule) Matches(courtId string, date, hourStart time.Time) bool {
+	if r.CourtId != "" && r.CourtId != courtId {
+		return false
+	}
+
+	if hourStart.Before(r.StartTime) || !hourStart.Before(r.EndTime) {
+		return false
+	}
+
+	if r.IsHoliday() {
+		return r.HolidayDate.Format("02-01-2006") == date.Format("02-01-2006")
+	}
+
+	if len(r.Weekdays) == 0 {
+		return true
+	}
+
+	for _, val := range r.Weekdays {
+		if val == int(date.Weekday()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PriceItem is one priced hour of a booking, used as a line of the payment
+// breakdown sent to the payment gateway.
+type PriceItem struct {
+	Name      string    `json:"name"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Price     int       `json:"price"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// PromoCode gives a percentage or fixed discount on a booking total for
+// bookings made between ValidFrom and ValidUntil. A zero UsageLimit or
+// PerCustomerLimit means unlimited, and an empty CourtIds means every court.
+type PromoCode struct {
+	Id               string    `json:"id"`
+	Code             string    `json:"code"`
+	DiscountType     string    `json:"discountType"`
+	DiscountValue    int       `json:"discountValue"`
+	MaxDiscount      int       `json:"maxDiscount"`
+	ValidFrom        time.Time `json:"validFrom"`
+	ValidUntil       time.Time `json:"validUntil"`
+	UsageLimit       int       `json:"usageLimit"`
+	PerCustomerLimit int       `json:"perCustomerLimit"`
+	CourtIds         []string  `json:"courtIds"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// IsActiveOn reports whether the promo can be used on the given day. Both
+// ValidFrom and ValidUntil are inclusive.
+func (p PromoCode) IsActiveOn(date time.Time) bool {
+	return !date.Before(p.ValidFrom) && date.Before(p.ValidUntil.AddDate(0, 0, 1))
+}
+
+func (p PromoCode) AppliesTo(courtId string) bool {
+	if len(p.CourtIds) == 0 {
+		return true
+	}
+
+	for _, val := range p.CourtIds {
+		if val == courtId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DiscountFor returns the discount on the given total, never more than the
+// total itself.
+func (p PromoCode) DiscountFor(total int) int {
+	discount := p.DiscountValue
+
+	if p.DiscountType == "percentage" {
+		discount = total * p.DiscountValue / 100
+		if p.MaxDiscount > 0 && discount > p.MaxDiscount {
+			discount = p.MaxDiscount
+		}
+	}
+
+	if discount > total {
+		discount = total
+	}
+
+	return discount
+}
+
+// PromoRedemption records the discount a promo code gave on one booking.
+type PromoRedemption struct {
+	Id          string    `json:"id"`
+	PromoCodeId string    `json:"promoCodeId"`
+	Code        string    `json:"code"`
+	BookingId   string    `json:"bookingId"`
+	CustomerId  string    `json:"customerId"`
+	Discount    int       `json:"discount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// PromoReport sums the discount given by one promo code on paid bookings.
+type PromoReport struct {
+	PromoCodeId   string `json:"promoCodeId"`
+	Code          string `json:"code"`
+	Redemptions   int    `json:"redemptions"`
+	TotalDiscount int    `json:"totalDiscount"`
+}
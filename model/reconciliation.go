@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// ReconciliationReport is the outcome of one reconciliation run. Checked
+// counts the stale payments looked up at the gateway, Discrepancies lists the
+// ones the gateway disagreed on or that could not be checked.
+type ReconciliationReport struct {
+	RunAt         time.Time            `json:"runAt"`
+	Checked       int                  `json:"checked"`
+	Discrepancies []ReconciliationItem `json:"discrepancies"`
+}
+
+// ReconciliationItem is one payment whose gateway status differs from ours.
+// Action is "applied" when the gateway status was applied, or "failed" with
+// Error set.
+type ReconciliationItem struct {
+	OrderId       string `json:"orderId"`
+	BookingId     string `json:"bookingId"`
+	LocalStatus   string `json:"localStatus"`
+	GatewayStatus string `json:"gatewayStatus"`
+	Action        string `json:"action"`
+	Error         string `json:"error"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+type RestrictionType string
+
+const (
+	RestrictionBlockOnline    RestrictionType = "block_online"
+	RestrictionFullPrepayment RestrictionType = "full_prepayment"
+)
+
+func (t RestrictionType) IsValid() bool {
+	return t == RestrictionBlockOnline || t == RestrictionFullPrepayment
+}
+
+// Restriction limits how a customer may book between StartsAt and EndsAt,
+// unless an admin lifts it earlier. LiftedAt is zero while it still applies.
+type Restriction struct {
+	Id         string          `json:"id"`
+	CustomerId string          `json:"customerId"`
+	Type       RestrictionType `json:"type"`
+	Reason     string          `json:"reason"`
+	StartsAt   time.Time       `json:"startsAt"`
+	EndsAt     time.Time       `json:"endsAt"`
+	ImposedBy  string          `json:"imposedBy"`
+	LiftedBy   string          `json:"liftedBy"`
+	LiftedAt   time.Time       `json:"liftedAt"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+func (r Restriction) IsActiveAt(at time.Time) bool {
+	return r.LiftedAt.IsZero() && !at.Before(r.StartsAt) && at.Before(r.EndsAt)
+}
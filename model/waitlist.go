@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "waiting"
+	WaitlistOffered   WaitlistStatus = "offered"
+	WaitlistBooked    WaitlistStatus = "booked"
+	WaitlistExpired   WaitlistStatus = "expired"
+	WaitlistCancelled WaitlistStatus = "cancelled"
+)
+
+// WaitlistEntry registers a customer's interest in playing Hour hours on a
+// court, or on any court when CourtId is empty, somewhere between StartTime and
+// EndTime on BookingDate. Once a slot frees up the entry holds an offer for it
+// until OfferExpiresAt.
+type WaitlistEntry struct {
+	Id               string         `json:"id"`
+	CustomerId       string         `json:"customerId"`
+	CourtId          string         `json:"courtId"`
+	BookingDate      time.Time      `json:"bookingDate"`
+	StartTime        time.Time      `json:"startTime"`
+	EndTime          time.Time      `json:"endTime"`
+	Hour             int            `json:"hour"`
+	Status           WaitlistStatus `json:"status"`
+	OfferedCourtId   string         `json:"offeredCourtId"`
+	OfferedStartTime time.Time      `json:"offeredStartTime"`
+	OfferExpiresAt   time.Time      `json:"offerExpiresAt"`
+	BookingId        string         `json:"bookingId"`
+	CreatedAt        time.Time      `json:"createdAt"`
+}
+
+// SlotIn returns the earliest start that fits the requested hours inside both
+// the entry's window and the freed slot between start and end.
+func (w WaitlistEntry) SlotIn(start, end time.Time) (time.Time, bool) {
+	slotStart := start
+	if w.StartTime.After(slotStart) {
+		slotStart = w.StartTime
+	}
+
+	slotEnd := end
+	if w.EndTime.Before(slotEnd) {
+		slotEnd = w.EndTime
+	}
+
+	if slotStart.Add(time.Hour * time.Duration(w.Hour)).After(slotEnd) {
+		return time.Time{}, false
+	}
+
+	return slotStart, true
+}
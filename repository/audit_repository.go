@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"math"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+)
+
+// AuditRepository only inserts and reads, the audit log is append-only.
+type AuditRepository interface {
+	Create(payload model.AuditLog) (model.AuditLog, error)
+	FindAll(filter dto.AuditLogFilter, page int, size int) ([]model.AuditLog, dto.Paginate, error)
+}
+
+type auditRepository struct {
+	DB *sql.DB
+}
+
+const auditLogColumns = "id, actor, action, entity_type, entity_id, detail, COALESCE(before_value, ''), COALESCE(after_value, ''), created_at"
+
+const auditLogFilter = "WHERE ($1 = '' OR actor = $1) AND ($2 = '' OR action = $2) AND ($3 = '' OR entity_type = $3) AND ($4 = '' OR entity_id = $4) " +
+	"AND ($5::timestamptz IS NULL OR created_at >= $5) AND ($6::timestamptz IS NULL OR created_at < $6)"
+
+func scanAuditLog(row rowScanner) (model.AuditLog, error) {
+	var entry model.AuditLog
+
+	err := row.Scan(
+		&entry.Id,
+		&entry.Actor,
+		&entry.Action,
+		&entry.EntityType,
+		&entry.EntityId,
+		&entry.Detail,
+		&entry.Before,
+		&entry.After,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return model.AuditLog{}, err
+	}
+
+	return entry, nil
+}
+
+func (r *auditRepository) Create(payload model.AuditLog) (model.AuditLog, error) {
+	query := "INSERT INTO audit_logs (actor, action, entity_type, entity_id, detail, before_value, after_value) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING " + auditLogColumns
+
+	before := sql.NullString{String: payload.Before, Valid: payload.Before != ""}
+	after := sql.NullString{String: payload.After, Valid: payload.After != ""}
+
+	entry, err := scanAuditLog(r.DB.QueryRow(query, payload.Actor, payload.Action, payload.EntityType, payload.EntityId, payload.Detail, before, after))
+	if err != nil {
+		return model.AuditLog{}, err
+	}
+
+	return entry, nil
+}
+
+func (r *auditRepository) FindAll(filter dto.AuditLogFilter, page int, size int) ([]model.AuditLog, dto.Paginate, error) {
+	var entries []model.AuditLog
+
+	offset := (page - 1) * size
+
+	startDate := sql.NullTime{Time: filter.StartDate, Valid: !filter.StartDate.IsZero()}
+	endDate := sql.NullTime{Time: filter.EndDate.AddDate(0, 0, 1), Valid: !filter.EndDate.IsZero()}
+
+	rows, err := r.DB.Query("SELECT "+auditLogColumns+" FROM audit_logs "+auditLogFilter+" ORDER BY created_at DESC LIMIT $7 OFFSET $8", filter.Actor, filter.Action, filter.EntityType, filter.EntityId, startDate, endDate, size, offset)
+	if err != nil {
+		return []model.AuditLog{}, dto.Paginate{}, err
+	}
+
+	for rows.Next() {
+		entry, err := scanAuditLog(rows)
+		if err != nil {
+			return []model.AuditLog{}, dto.Paginate{}, err
+		}
+		entries = append(entries, entry)
+	}
+
+	var totalRows int
+	err = r.DB.QueryRow("SELECT COUNT(*) FROM audit_logs "+auditLogFilter, filter.Actor, filter.Action, filter.EntityType, filter.EntityId, startDate, endDate).Scan(&totalRows)
+	if err != nil {
+		return []model.AuditLog{}, dto.Paginate{}, err
+	}
+
+	paginate := dto.Paginate{
+		Page:       page,
+		Size:       size,
+		TotalRows:  totalRows,
+		TotalPages: int(math.Ceil(float64(totalRows) / float64(size))),
+	}
+
+	return entries, paginate, nil
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{
+		DB: db,
+	}
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var mockAuditLog = model.AuditLog{
+	Id:         "audit_1",
+	Actor:      "midtrans",
+	Action:     "payment.notification_rejected",
+	EntityType: "payment",
+	EntityId:   "Booking-1",
+	Detail:     "invalid signature",
+}
+
+var auditLogRowColumns = []string{"id", "actor", "action", "entity_type", "entity_id", "detail", "before_value", "after_value", "created_at"}
+
+type AuditRepositoryTestSuite struct {
+	suite.Suite
+	mockDb  *sql.DB
+	mockSql sqlmock.Sqlmock
+	repo    AuditRepository
+}
+
+func (suite *AuditRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.mockDb = db
+	suite.mockSql = mock
+	suite.repo = NewAuditRepository(suite.mockDb)
+}
+
+func TestAuditRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditRepositoryTestSuite))
+}
+
+func (suite *AuditRepositoryTestSuite) TestCreate_Success() {
+	suite.mockSql.ExpectQuery("INSERT INTO audit_logs").
+		WithArgs(mockAuditLog.Actor, mockAuditLog.Action, mockAuditLog.EntityType, mockAuditLog.EntityId, mockAuditLog.Detail, nil, nil).
+		WillReturnRows(sqlmock.NewRows(auditLogRowColumns).
+			AddRow(mockAuditLog.Id, mockAuditLog.Actor, mockAuditLog.Action, mockAuditLog.EntityType, mockAuditLog.EntityId, mockAuditLog.Detail, "", "", time.Time{}))
+
+	actual, err := suite.repo.Create(mockAuditLog)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockAuditLog, actual)
+}
+
+func (suite *AuditRepositoryTestSuite) TestCreate_Failed() {
+	suite.mockSql.ExpectQuery("INSERT INTO audit_logs").WillReturnError(errors.New("error"))
+
+	_, err := suite.repo.Create(mockAuditLog)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *AuditRepositoryTestSuite) TestCreate_WithBeforeAndAfter() {
+	entry := model.AuditLog{
+		Actor:      "employee_1",
+		Action:     "booking.cancel",
+		EntityType: "booking",
+		EntityId:   "1",
+		Before:     `{"status":"booked"}`,
+		After:      `{"status":"cancel"}`,
+	}
+
+	suite.mockSql.ExpectQuery("INSERT INTO audit_logs").
+		WithArgs(entry.Actor, entry.Action, entry.EntityType, entry.EntityId, "", entry.Before, entry.After).
+		WillReturnRows(sqlmock.NewRows(auditLogRowColumns).
+			AddRow("audit_2", entry.Actor, entry.Action, entry.EntityType, entry.EntityId, "", entry.Before, entry.After, time.Time{}))
+
+	actual, err := suite.repo.Create(entry)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entry.Before, actual.Before)
+	assert.Equal(suite.T(), entry.After, actual.After)
+}
+
+func (suite *AuditRepositoryTestSuite) TestFindAll_Success() {
+	filter := dto.AuditLogFilter{
+		EntityType: "booking",
+		StartDate:  time.Date(2030, 10, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2030, 10, 7, 0, 0, 0, 0, time.UTC),
+	}
+
+	suite.mockSql.ExpectQuery("SELECT id, actor, action, entity_type, entity_id, detail, COALESCE\\(before_value, ''\\), COALESCE\\(after_value, ''\\), created_at FROM audit_logs WHERE").
+		WithArgs("", "", "booking", "", filter.StartDate, time.Date(2030, 10, 8, 0, 0, 0, 0, time.UTC), 10, 0).
+		WillReturnRows(sqlmock.NewRows(auditLogRowColumns).
+			AddRow(mockAuditLog.Id, mockAuditLog.Actor, mockAuditLog.Action, "booking", "1", "", "", `{"status":"pending"}`, time.Time{}))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM audit_logs WHERE").
+		WithArgs("", "", "booking", "", filter.StartDate, time.Date(2030, 10, 8, 0, 0, 0, 0, time.UTC)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	entries, paginate, err := suite.repo.FindAll(filter, 1, 10)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(entries))
+	assert.Equal(suite.T(), 1, paginate.TotalRows)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *AuditRepositoryTestSuite) TestFindAll_WithoutDates() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM audit_logs WHERE").
+		WithArgs("admin_1", "", "", "", nil, nil, 10, 10).
+		WillReturnRows(sqlmock.NewRows(auditLogRowColumns))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM audit_logs WHERE").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	entries, _, err := suite.repo.FindAll(dto.AuditLogFilter{Actor: "admin_1"}, 2, 10)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), entries)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *AuditRepositoryTestSuite) TestFindAll_Failed() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM audit_logs WHERE").WillReturnError(errors.New("error"))
+
+	_, _, err := suite.repo.FindAll(dto.AuditLogFilter{}, 1, 10)
+	assert.Error(suite.T(), err)
+}
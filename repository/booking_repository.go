@@ -2,12 +2,44 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"math"
+	"strings"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
 	"time"
 )
 
+// ErrBookingConflict is returned when another booking took the same court and
+// time between the collision check and the insert.
+var ErrBookingConflict = errors.New("court is already booked by another customer at that time")
+
+// ErrCourtClosed is returned when an admin closed the court for the requested
+// time between the closure check and the insert.
+var ErrCourtClosed = errors.New("cannot book, court has been closed for that time")
+
+// ErrSlotOffered is returned when the requested time is held for a waitlist
+// customer whose offer has not expired yet. It wraps ErrBookingConflict.
+var ErrSlotOffered = fmt.Errorf("%w, the slot is held for a waitlist offer", ErrBookingConflict)
+
+// activeStatuses is model.ActiveBookingStatuses as an SQL list, for every query
+// that looks for the bookings still holding a court.
+var activeStatuses = statusList(model.ActiveBookingStatuses)
+
+// endingStatuses lists the bookings staff still have to look after on the day:
+// confirmed ones yet to be played and the ones on court. Unpaid holds and
+// settled bookings are left out.
+var endingStatuses = statusList([]model.BookingStatus{model.BookingBooked, model.BookingPaid, model.BookingCheckedIn})
+
+func statusList(statuses []model.BookingStatus) string {
+	quoted := make([]string, len(statuses))
+	for i, status := range statuses {
+		quoted[i] = "'" + string(status) + "'"
+	}
+	return "(" + strings.Join(quoted, ", ") + ")"
+}
+
 type bookingRepository struct {
 	DB *sql.DB
 }
@@ -17,23 +49,49 @@ type BookingRepository interface {
 	FindAll(page int, size int) ([]model.Booking, dto.Paginate, error)
 	FindByDate(bookingDate time.Time) ([]model.Booking, error)
 	FindById(bookingId string) (model.Booking, error)
+	FindByCustomer(customerId string, upcoming bool, page int, size int) ([]model.Booking, dto.Paginate, error)
 	FindTotal(customerId string) (int, error)
 	FindPaymentByOrderId(order_id string) (model.Payment, error)
+	FindOrderTotal(orderId string) (int, error)
+	FindPaymentsByBookingId(bookingId string) ([]model.Payment, error)
 	UpdateStatus(payload model.Payment) error
+	UpdatePaymentStatus(payload model.Payment) error
 	CreateRepay(payload model.Payment) (model.Payment, error)
+	CreateRefund(payload model.Payment) (model.Payment, error)
 	UpdateRepaymentStatus(payload model.Payment) error
 	FindBooked(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error)
 	FindEnding(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error)
 	FindPaymentReport(day, month, year, page, size int, filterType string) ([]model.Payment, dto.Paginate, int64, error)
+	Cancel(bookingId string, refunds []model.Payment, changedBy string) error
+	Reschedule(payload model.Booking) (model.Booking, error)
+	CreateSeries(payload model.BookingSeries) (model.BookingSeries, error)
+	FindSeriesById(seriesId string) (model.BookingSeries, error)
+	UpdateSeriesStatus(payload model.Payment) error
+	FindExpiredPending(cutoff time.Time) ([]model.Payment, error)
+	FindStalePayments(cutoff time.Time) ([]model.Payment, error)
+	ChangeStatus(bookingId string, status model.BookingStatus, changedBy string, reason string) error
+	FindStatusHistory(bookingId string) ([]model.BookingStatusHistory, error)
+	CheckInCode(bookingId string, code string) (string, error)
+	FindByCheckInCode(code string) (model.Booking, error)
+	FindMissedCheckIns(cutoff time.Time) ([]model.Booking, error)
+	CountNoShows(customerId string) (int, error)
 }
 
 func (r *bookingRepository) Create(payload model.Booking) (model.Booking, error) {
 	transaction, _ := r.DB.Begin()
 
+	err := reserveSlot(transaction, payload.Court.Id, payload.BookingDate, payload.StartTime, payload.EndTime, "", payload.Customer.Id)
+	if err != nil {
+		transaction.Rollback()
+		return model.Booking{}, err
+	}
+
 	var booking model.Booking
-	query := "INSERT INTO bookings (customer_id, court_id, booking_date, start_time, end_time, total_payment, status) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status"
+	query := "INSERT INTO bookings (customer_id, court_id, employee_id, booking_date, start_time, end_time, total_payment, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status"
 
-	err := transaction.QueryRow(query, payload.Customer.Id, payload.Court.Id, payload.BookingDate, payload.StartTime, payload.EndTime, payload.Total_Payment, "pending").Scan(
+	employeeId := sql.NullString{String: payload.Employee.Id, Valid: payload.Employee.Id != ""}
+
+	err = transaction.QueryRow(query, payload.Customer.Id, payload.Court.Id, employeeId, payload.BookingDate, payload.StartTime, payload.EndTime, payload.Total_Payment, "pending").Scan(
 		&booking.Id,
 		&booking.Customer.Id,
 		&booking.Court.Id,
@@ -49,9 +107,48 @@ func (r *bookingRepository) Create(payload model.Booking) (model.Booking, error)
 		return booking, err
 	}
 
+	if payload.Promo.PromoCodeId != "" {
+		redemption := payload.Promo
+		redemption.BookingId = booking.Id
+		redemption.CustomerId = booking.Customer.Id
+
+		err = redeemPromo(transaction, redemption)
+		if err != nil {
+			transaction.Rollback()
+			return model.Booking{}, err
+		}
+
+		booking.Promo = redemption
+	}
+
+	if payload.PointRedeemed.Points < 0 {
+		entry := payload.PointRedeemed
+		entry.BookingId = booking.Id
+		entry.CustomerId = booking.Customer.Id
+
+		entry, err = insertPointEntry(transaction, entry)
+		if err != nil {
+			transaction.Rollback()
+			return model.Booking{}, err
+		}
+
+		booking.PointRedeemed = entry
+	}
+
 	var payment model.Payment
 
-	depoPrice := booking.Total_Payment / 2
+	depoPrice := payload.PaymentDetails[0].Price
+
+	// A walk-in paid in cash at the desk is settled on the spot instead of
+	// waiting for a gateway notification, and so is a booking with nothing
+	// due upfront.
+	paidAtDesk := payload.PaymentDetails[0].PaymentMethod == "cash"
+	paymentMethod, paymentStatus := "mid", "unpaid"
+	if paidAtDesk {
+		paymentMethod, paymentStatus = "cash", "paid"
+	} else if depoPrice < 1 {
+		paymentStatus = "paid"
+	}
 
 	query = "INSERT INTO payments (booking_id, order_id, description, payment_method, price, status, payment_url) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, booking_id, order_id, description, payment_method, price, status, payment_url"
 	err = transaction.QueryRow(
@@ -59,9 +156,9 @@ func (r *bookingRepository) Create(payload model.Booking) (model.Booking, error)
 		booking.Id,
 		payload.PaymentDetails[0].OrderId,
 		payload.PaymentDetails[0].Description,
-		"mid",
+		paymentMethod,
 		depoPrice,
-		"unpaid",
+		paymentStatus,
 		payload.PaymentDetails[0].PaymentURL,
 	).Scan(
 		&payment.Id,
@@ -81,6 +178,53 @@ func (r *bookingRepository) Create(payload model.Booking) (model.Booking, error)
 
 	booking.PaymentDetails = append(booking.PaymentDetails, payment)
 
+	booking.Employee.Id = payload.Employee.Id
+
+	// A walk-in paid in full has not been played yet, so it is paid like a
+	// booking paid in full online and can still be checked in, cancelled or
+	// rescheduled.
+	if paidAtDesk {
+		_, err = transitionStatus(transaction, booking.Id, model.BookingPaid, payload.Employee.Id, "paid in full at the desk")
+		if err != nil {
+			transaction.Rollback()
+			return model.Booking{}, err
+		}
+
+		err = earnPoints(transaction, booking.Customer.Id, booking.Id)
+		if err != nil {
+			transaction.Rollback()
+			return model.Booking{}, err
+		}
+
+		booking.Status = model.BookingPaid
+	} else if depoPrice < 1 {
+		status := model.BookingBooked
+		if payload.Total_Payment < 1 {
+			status = model.BookingPaid
+		}
+
+		changedBy := payload.Employee.Id
+		if changedBy == "" {
+			changedBy = booking.Customer.Id
+		}
+
+		_, err = transitionStatus(transaction, booking.Id, status, changedBy, "nothing to pay upfront")
+		if err != nil {
+			transaction.Rollback()
+			return model.Booking{}, err
+		}
+
+		if status == model.BookingPaid {
+			err = earnPoints(transaction, booking.Customer.Id, booking.Id)
+			if err != nil {
+				transaction.Rollback()
+				return model.Booking{}, err
+			}
+		}
+
+		booking.Status = status
+	}
+
 	transaction.Commit()
 	return booking, nil
 }
@@ -246,6 +390,72 @@ func (r *bookingRepository) FindById(bookingId string) (model.Booking, error) {
 	return booking, nil
 }
 
+// customerUpcoming matches the bookings a customer still has ahead of them,
+// paid in full or not, until their end time has passed. Everything else,
+// whether played, cancelled or missed, is their history.
+var customerUpcoming = "b.booking_date + b.end_time > LOCALTIMESTAMP AND b.status IN " + activeStatuses
+
+func (r *bookingRepository) FindByCustomer(customerId string, upcoming bool, page int, size int) ([]model.Booking, dto.Paginate, error) {
+	var bookings []model.Booking
+
+	offset := (page - 1) * size
+
+	filter := "WHERE b.customer_id = $1 AND NOT (" + customerUpcoming + ")"
+	order := "ORDER BY b.booking_date DESC, b.start_time DESC"
+	if upcoming {
+		filter = "WHERE b.customer_id = $1 AND " + customerUpcoming
+		order = "ORDER BY b.booking_date, b.start_time"
+	}
+
+	query := "SELECT b.id, b.customer_id, b.court_id, c.name, c.price, b.employee_id, b.booking_date, b.start_time, b.end_time, b.total_payment, b.status, b.created_at, b.updated_at FROM bookings b JOIN courts c ON c.id = b.court_id " + filter + " " + order + " LIMIT $2 OFFSET $3"
+
+	rows, err := r.DB.Query(query, customerId, size, offset)
+	if err != nil {
+		return []model.Booking{}, dto.Paginate{}, err
+	}
+
+	var nullEmployee sql.NullString
+	for rows.Next() {
+		var b model.Booking
+		if err := rows.Scan(
+			&b.Id,
+			&b.Customer.Id,
+			&b.Court.Id,
+			&b.Court.Name,
+			&b.Court.Price,
+			&nullEmployee,
+			&b.BookingDate,
+			&b.StartTime,
+			&b.EndTime,
+			&b.Total_Payment,
+			&b.Status,
+			&b.CreatedAt,
+			&b.UpdatedAt,
+		); err != nil {
+			return []model.Booking{}, dto.Paginate{}, err
+		}
+		if nullEmployee.Valid {
+			b.Employee.Id = nullEmployee.String
+		}
+		bookings = append(bookings, b)
+	}
+
+	var totalRows int
+	err = r.DB.QueryRow("SELECT COUNT(*) FROM bookings b "+filter, customerId).Scan(&totalRows)
+	if err != nil {
+		return []model.Booking{}, dto.Paginate{}, err
+	}
+
+	paginate := dto.Paginate{
+		Page:       page,
+		Size:       size,
+		TotalRows:  totalRows,
+		TotalPages: int(math.Ceil(float64(totalRows) / float64(size))),
+	}
+
+	return bookings, paginate, nil
+}
+
 func (r *bookingRepository) FindTotal(customerId string) (int, error) {
 	var totalBooking int
 	query := "SELECT COUNT (*) AS total_booking FROM bookings WHERE customer_id = $1"
@@ -280,6 +490,116 @@ func (r *bookingRepository) FindPaymentByOrderId(order_id string) (model.Payment
 	return payment, nil
 }
 
+// FindOrderTotal sums every payment row charged under orderId. A series order
+// has one row per occurrence, so the gateway charges the sum, not any one row.
+func (r *bookingRepository) FindOrderTotal(orderId string) (int, error) {
+	var total int
+
+	err := r.DB.QueryRow("SELECT COALESCE(SUM(price), 0) FROM payments WHERE order_id = $1 AND status <> $2", orderId, "refund").Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *bookingRepository) FindPaymentsByBookingId(bookingId string) ([]model.Payment, error) {
+	var payments []model.Payment
+
+	query := "SELECT id, booking_id, order_id, description, payment_method, price, status, payment_url, COALESCE(refund_of, '') FROM payments WHERE booking_id = $1 ORDER BY created_at, id"
+
+	rows, err := r.DB.Query(query, bookingId)
+	if err != nil {
+		return []model.Payment{}, err
+	}
+
+	for rows.Next() {
+		var p model.Payment
+		if err := rows.Scan(
+			&p.Id,
+			&p.BookingId,
+			&p.OrderId,
+			&p.Description,
+			&p.PaymentMethod,
+			&p.Price,
+			&p.Status,
+			&p.PaymentURL,
+			&p.RefundOf,
+		); err != nil {
+			return []model.Payment{}, err
+		}
+
+		payments = append(payments, p)
+	}
+
+	return payments, nil
+}
+
+func (r *bookingRepository) FindExpiredPending(cutoff time.Time) ([]model.Payment, error) {
+	var payments []model.Payment
+
+	query := "SELECT p.id, p.booking_id, p.order_id, p.description, p.payment_method, p.price, p.status, p.payment_url FROM payments p JOIN bookings b ON b.id = p.booking_id WHERE b.status = $1 AND p.status = $2 AND b.created_at < $3 ORDER BY b.created_at"
+
+	rows, err := r.DB.Query(query, "pending", "unpaid", cutoff)
+	if err != nil {
+		return []model.Payment{}, err
+	}
+
+	for rows.Next() {
+		var p model.Payment
+		if err := rows.Scan(
+			&p.Id,
+			&p.BookingId,
+			&p.OrderId,
+			&p.Description,
+			&p.PaymentMethod,
+			&p.Price,
+			&p.Status,
+			&p.PaymentURL,
+		); err != nil {
+			return []model.Payment{}, err
+		}
+
+		payments = append(payments, p)
+	}
+
+	return payments, nil
+}
+
+// FindStalePayments returns one unpaid gateway payment for every order created
+// before cutoff, whatever kind of order it is. A series order has a row per
+// occurrence but is a single transaction at the gateway.
+func (r *bookingRepository) FindStalePayments(cutoff time.Time) ([]model.Payment, error) {
+	var payments []model.Payment
+
+	query := "SELECT DISTINCT ON (order_id) id, booking_id, order_id, description, payment_method, price, status, payment_url FROM payments WHERE status = $1 AND payment_url <> '' AND created_at < $2 ORDER BY order_id, created_at"
+
+	rows, err := r.DB.Query(query, "unpaid", cutoff)
+	if err != nil {
+		return []model.Payment{}, err
+	}
+
+	for rows.Next() {
+		var p model.Payment
+		if err := rows.Scan(
+			&p.Id,
+			&p.BookingId,
+			&p.OrderId,
+			&p.Description,
+			&p.PaymentMethod,
+			&p.Price,
+			&p.Status,
+			&p.PaymentURL,
+		); err != nil {
+			return []model.Payment{}, err
+		}
+
+		payments = append(payments, p)
+	}
+
+	return payments, nil
+}
+
 func (r *bookingRepository) UpdateStatus(payload model.Payment) error {
 	transaction, _ := r.DB.Begin()
 
@@ -302,24 +622,34 @@ func (r *bookingRepository) UpdateStatus(payload model.Payment) error {
 			return err
 		}
 
-		updateBooking := "UPDATE bookings SET status = $1, updated_at = $2 WHERE id = $3 RETURNING customer_id"
-		var customerId string
-
-		err = transaction.QueryRow(updateBooking, "booked", time.Now(), payload.BookingId).Scan(&customerId)
+		var totalPayment int
 
+		err = transaction.QueryRow("SELECT total_payment FROM bookings WHERE id = $1", payload.BookingId).Scan(&totalPayment)
 		if err != nil {
 			transaction.Rollback()
 			return err
 		}
 
-		updatePoints := "UPDATE users SET points = 0 WHERE id = $1"
-
-		_, err = transaction.Exec(updatePoints, customerId)
+		// A booking paid in full upfront is paid rather than booked, with
+		// nothing left to collect at the desk.
+		status := model.BookingBooked
+		if payload.Price >= totalPayment {
+			status = model.BookingPaid
+		}
 
+		customerId, err := transitionStatus(transaction, payload.BookingId, status, statusActor(payload), "payment completed")
 		if err != nil {
 			transaction.Rollback()
 			return err
 		}
+
+		if status == model.BookingPaid {
+			err = earnPoints(transaction, customerId, payload.BookingId)
+			if err != nil {
+				transaction.Rollback()
+				return err
+			}
+		}
 	}
 
 	if payload.Status == "cancel" {
@@ -332,10 +662,13 @@ func (r *bookingRepository) UpdateStatus(payload model.Payment) error {
 			return err
 		}
 
-		updateBooking := "UPDATE bookings SET status = $1, updated_at = $2 WHERE id = $3"
-
-		_, err = transaction.Exec(updateBooking, "cancel", time.Now(), payload.BookingId)
+		_, err = transitionStatus(transaction, payload.BookingId, model.BookingCancel, statusActor(payload), "payment cancelled or expired")
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
 
+		err = restorePoints(transaction, payload.BookingId)
 		if err != nil {
 			transaction.Rollback()
 			return err
@@ -346,6 +679,37 @@ func (r *bookingRepository) UpdateStatus(payload model.Payment) error {
 	return nil
 }
 
+func (r *bookingRepository) UpdatePaymentStatus(payload model.Payment) error {
+	if payload.Status == "pending" {
+		updatePayment := "UPDATE payments SET payment_method = $1, updated_at = $2 WHERE order_id = $3"
+
+		_, err := r.DB.Exec(updatePayment, payload.PaymentMethod, time.Now(), payload.OrderId)
+		if err != nil {
+			return err
+		}
+	}
+
+	if payload.Status == "paid" {
+		updatePayment := "UPDATE payments SET payment_method = $1, status = $2, payment_url = $3, updated_at = $4 WHERE order_id = $5"
+
+		_, err := r.DB.Exec(updatePayment, payload.PaymentMethod, payload.Status, "", time.Now(), payload.OrderId)
+		if err != nil {
+			return err
+		}
+	}
+
+	if payload.Status == "cancel" {
+		deletePayment := "DELETE FROM payments WHERE order_id = $1"
+
+		_, err := r.DB.Exec(deletePayment, payload.OrderId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (r *bookingRepository) CreateRepay(payload model.Payment) (model.Payment, error) {
 	transaction, _ := r.DB.Begin()
 
@@ -382,7 +746,7 @@ func (r *bookingRepository) CreateRepay(payload model.Payment) (model.Payment, e
 	_, err = transaction.Exec(updateBooking, payload.User.Id, time.Now(), payload.BookingId)
 	if err != nil {
 		transaction.Rollback()
-		return model.Payment{}, nil
+		return model.Payment{}, err
 	}
 
 	if payment.PaymentMethod == "cash" {
@@ -391,32 +755,19 @@ func (r *bookingRepository) CreateRepay(payload model.Payment) (model.Payment, e
 		_, err := transaction.Exec(updatePayment, "paid", time.Now(), payment.Id)
 		if err != nil {
 			transaction.Rollback()
-			return model.Payment{}, nil
-		}
-
-		updateBooking := "UPDATE bookings SET status = $1, updated_at = $2 WHERE id = $3 RETURNING customer_id"
-		var customerId string
-
-		err = transaction.QueryRow(updateBooking, "done", time.Now(), payload.BookingId).Scan(&customerId)
-		if err != nil {
-			transaction.Rollback()
-			return model.Payment{}, nil
+			return model.Payment{}, err
 		}
 
-		getPoints := "SELECT points FROM users WHERE id = $1"
-		var points int
-		err = transaction.QueryRow(getPoints, customerId).Scan(&points)
+		customerId, err := transitionStatus(transaction, payload.BookingId, model.BookingDone, payload.User.Id, "repayment paid in cash")
 		if err != nil {
 			transaction.Rollback()
-			return model.Payment{}, nil
+			return model.Payment{}, err
 		}
 
-		newPoints := points + 10
-		updatePoints := "UPDATE users SET points = $1 WHERE id = $2"
-		_, err = transaction.Exec(updatePoints, newPoints, customerId)
+		err = earnPoints(transaction, customerId, payload.BookingId)
 		if err != nil {
 			transaction.Rollback()
-			return model.Payment{}, nil
+			return model.Payment{}, err
 		}
 	}
 
@@ -424,48 +775,90 @@ func (r *bookingRepository) CreateRepay(payload model.Payment) (model.Payment, e
 	return payment, nil
 }
 
-func (r *bookingRepository) UpdateRepaymentStatus(payload model.Payment) error {
+// CreateRefund records money returned from a paid payment. The employee who
+// handled it is kept on the booking the same way CreateRepay does.
+func (r *bookingRepository) CreateRefund(payload model.Payment) (model.Payment, error) {
 	transaction, _ := r.DB.Begin()
 
-	if payload.Status == "pending" {
-		updatePayment := "UPDATE payments SET payment_method = $1, updated_at = $2 WHERE order_id = $3"
+	var payment model.Payment
 
-		_, err := transaction.Exec(updatePayment, payload.PaymentMethod, time.Now(), payload.OrderId)
-		if err != nil {
-			transaction.Rollback()
-			return err
-		}
+	query := "INSERT INTO payments (booking_id, order_id, description, payment_method, price, status, payment_url, refund_of) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, booking_id, order_id, description, payment_method, price, status, payment_url, refund_of"
+
+	err := transaction.QueryRow(
+		query,
+		payload.BookingId,
+		payload.OrderId,
+		payload.Description,
+		payload.PaymentMethod,
+		payload.Price,
+		"refund",
+		"",
+		payload.RefundOf,
+	).Scan(
+		&payment.Id,
+		&payment.BookingId,
+		&payment.OrderId,
+		&payment.Description,
+		&payment.PaymentMethod,
+		&payment.Price,
+		&payment.Status,
+		&payment.PaymentURL,
+		&payment.RefundOf,
+	)
+	if err != nil {
+		transaction.Rollback()
+		return model.Payment{}, err
 	}
 
-	if payload.Status == "paid" {
-		updatePayment := "UPDATE payments SET payment_method = $1, status = $2, payment_url = $3, updated_at = $4 WHERE order_id = $5"
+	updateBooking := "UPDATE bookings SET employee_id = $1, updated_at = $2 WHERE id = $3"
 
-		_, err := transaction.Exec(updatePayment, payload.PaymentMethod, payload.Status, "", time.Now(), payload.OrderId)
+	_, err = transaction.Exec(updateBooking, payload.User.Id, time.Now(), payload.BookingId)
+	if err != nil {
+		transaction.Rollback()
+		return model.Payment{}, err
+	}
+
+	transaction.Commit()
+	return payment, nil
+}
+
+func (r *bookingRepository) UpdateRepaymentStatus(payload model.Payment) error {
+	transaction, _ := r.DB.Begin()
+
+	if payload.Status == "pending" {
+		updatePayment := "UPDATE payments SET payment_method = $1, updated_at = $2 WHERE order_id = $3"
+
+		_, err := transaction.Exec(updatePayment, payload.PaymentMethod, time.Now(), payload.OrderId)
 		if err != nil {
 			transaction.Rollback()
 			return err
 		}
+	}
 
-		updateBooking := "UPDATE bookings SET employee_id = $1, status = $2, updated_at = $3 WHERE id = $4 RETURNING customer_id"
-		var customerId string
+	if payload.Status == "paid" {
+		updatePayment := "UPDATE payments SET payment_method = $1, status = $2, payment_url = $3, updated_at = $4 WHERE order_id = $5"
 
-		err = transaction.QueryRow(updateBooking, payload.User.Id, "done", time.Now(), payload.BookingId).Scan(&customerId)
+		_, err := transaction.Exec(updatePayment, payload.PaymentMethod, payload.Status, "", time.Now(), payload.OrderId)
 		if err != nil {
 			transaction.Rollback()
 			return err
 		}
 
-		getPoints := "SELECT points FROM users WHERE id = $1"
-		var points int
-		err = transaction.QueryRow(getPoints, customerId).Scan(&points)
+		updateBooking := "UPDATE bookings SET employee_id = $1, updated_at = $2 WHERE id = $3"
+
+		_, err = transaction.Exec(updateBooking, payload.User.Id, time.Now(), payload.BookingId)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+
+		customerId, err := transitionStatus(transaction, payload.BookingId, model.BookingDone, statusActor(payload), "repayment completed")
 		if err != nil {
 			transaction.Rollback()
 			return err
 		}
 
-		newPoints := points + 10
-		updatePoints := "UPDATE users SET points = $1 WHERE id = $2"
-		_, err = transaction.Exec(updatePoints, newPoints, customerId)
+		err = earnPoints(transaction, customerId, payload.BookingId)
 		if err != nil {
 			transaction.Rollback()
 			return err
@@ -479,7 +872,7 @@ func (r *bookingRepository) UpdateRepaymentStatus(payload model.Payment) error {
 func (r *bookingRepository) FindBooked(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error) {
 	var bookings []model.Booking
 
-	query := "SELECT court_id, booking_date, start_time, end_time, status FROM bookings WHERE booking_date = $1 AND status IN ('pending', 'booked') LIMIT $2 OFFSET $3"
+	query := "SELECT court_id, booking_date, start_time, end_time, status FROM bookings WHERE booking_date = $1 AND status IN " + activeStatuses + " LIMIT $2 OFFSET $3"
 
 	offset := (page - 1) * size
 	rows, err := r.DB.Query(query, bookingDate, size, offset)
@@ -518,7 +911,7 @@ func (r *bookingRepository) FindBooked(bookingDate time.Time, page int, size int
 func (r *bookingRepository) FindEnding(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error) {
 	var bookings []model.Booking
 
-	query := "SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE booking_date = $1 AND status = 'booked' LIMIT $2 OFFSET $3"
+	query := "SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE booking_date = $1 AND status IN " + endingStatuses + " LIMIT $2 OFFSET $3"
 
 	offset := (page - 1) * size
 
@@ -563,7 +956,8 @@ func (r *bookingRepository) FindPaymentReport(day, month, year, page, size int,
 	var rows *sql.Rows
 	var err error
 
-	query := "SELECT id, booking_id, order_id, description, payment_method, price FROM payments WHERE "
+	// Refunds are money going back out, so they count against the income.
+	query := "SELECT id, booking_id, order_id, description, payment_method, CASE WHEN status = 'refund' THEN -price ELSE price END FROM payments WHERE "
 
 	offset := (page - 1) * size
 
@@ -621,6 +1015,526 @@ func (r *bookingRepository) FindPaymentReport(day, month, year, page, size int,
 	return payments, paginate, totalIncome, nil
 }
 
+func (r *bookingRepository) Cancel(bookingId string, refunds []model.Payment, changedBy string) error {
+	transaction, _ := r.DB.Begin()
+
+	deletePayment := "DELETE FROM payments WHERE booking_id = $1 AND status = $2"
+
+	_, err := transaction.Exec(deletePayment, bookingId, "unpaid")
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	_, err = transitionStatus(transaction, bookingId, model.BookingCancel, changedBy, "cancelled")
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	err = restorePoints(transaction, bookingId)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	insertRefund := "INSERT INTO payments (booking_id, order_id, description, payment_method, price, status, payment_url, refund_of) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+
+	for _, refund := range refunds {
+		_, err = transaction.Exec(insertRefund, bookingId, refund.OrderId, refund.Description, refund.PaymentMethod, refund.Price, refund.Status, "", refund.RefundOf)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (r *bookingRepository) Reschedule(payload model.Booking) (model.Booking, error) {
+	transaction, _ := r.DB.Begin()
+
+	err := reserveSlot(transaction, payload.Court.Id, payload.BookingDate, payload.StartTime, payload.EndTime, payload.Id, payload.Customer.Id)
+	if err != nil {
+		transaction.Rollback()
+		return model.Booking{}, err
+	}
+
+	var booking model.Booking
+	query := "UPDATE bookings SET court_id = $1, booking_date = $2, start_time = $3, end_time = $4, total_payment = $5, updated_at = $6 WHERE id = $7 RETURNING id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status"
+
+	err = transaction.QueryRow(query, payload.Court.Id, payload.BookingDate, payload.StartTime, payload.EndTime, payload.Total_Payment, time.Now(), payload.Id).Scan(
+		&booking.Id,
+		&booking.Customer.Id,
+		&booking.Court.Id,
+		&booking.BookingDate,
+		&booking.StartTime,
+		&booking.EndTime,
+		&booking.Total_Payment,
+		&booking.Status,
+	)
+	if err != nil {
+		transaction.Rollback()
+		return model.Booking{}, err
+	}
+
+	query = "INSERT INTO payments (booking_id, order_id, description, payment_method, price, status, payment_url, refund_of) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, booking_id, order_id, description, payment_method, price, status, payment_url, refund_of"
+
+	for _, val := range payload.PaymentDetails {
+		var payment model.Payment
+
+		err = transaction.QueryRow(query, booking.Id, val.OrderId, val.Description, val.PaymentMethod, val.Price, val.Status, val.PaymentURL, val.RefundOf).Scan(
+			&payment.Id,
+			&payment.BookingId,
+			&payment.OrderId,
+			&payment.Description,
+			&payment.PaymentMethod,
+			&payment.Price,
+			&payment.Status,
+			&payment.PaymentURL,
+			&payment.RefundOf,
+		)
+		if err != nil {
+			transaction.Rollback()
+			return model.Booking{}, err
+		}
+
+		booking.PaymentDetails = append(booking.PaymentDetails, payment)
+	}
+
+	transaction.Commit()
+	return booking, nil
+}
+
+func (r *bookingRepository) CreateSeries(payload model.BookingSeries) (model.BookingSeries, error) {
+	transaction, _ := r.DB.Begin()
+
+	var series model.BookingSeries
+	query := "INSERT INTO booking_series (customer_id, court_id, frequency, start_date, end_date, start_time, end_time) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, customer_id, court_id, frequency, start_date, end_date, start_time, end_time, created_at, updated_at"
+
+	err := transaction.QueryRow(query, payload.Customer.Id, payload.Court.Id, payload.Frequency, payload.StartDate, payload.EndDate, payload.StartTime, payload.EndTime).Scan(
+		&series.Id,
+		&series.Customer.Id,
+		&series.Court.Id,
+		&series.Frequency,
+		&series.StartDate,
+		&series.EndDate,
+		&series.StartTime,
+		&series.EndTime,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+	)
+	if err != nil {
+		transaction.Rollback()
+		return model.BookingSeries{}, err
+	}
+
+	insertBooking := "INSERT INTO bookings (customer_id, court_id, series_id, booking_date, start_time, end_time, total_payment, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, customer_id, court_id, series_id, booking_date, start_time, end_time, total_payment, status"
+	insertPayment := "INSERT INTO payments (booking_id, order_id, description, payment_method, price, status, payment_url) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, booking_id, order_id, description, payment_method, price, status, payment_url"
+
+	for _, val := range payload.Bookings {
+		var booking model.Booking
+
+		err = reserveSlot(transaction, series.Court.Id, val.BookingDate, val.StartTime, val.EndTime, "", series.Customer.Id)
+		if err != nil {
+			transaction.Rollback()
+			return model.BookingSeries{}, err
+		}
+
+		err = transaction.QueryRow(insertBooking, series.Customer.Id, series.Court.Id, series.Id, val.BookingDate, val.StartTime, val.EndTime, val.Total_Payment, "pending").Scan(
+			&booking.Id,
+			&booking.Customer.Id,
+			&booking.Court.Id,
+			&booking.SeriesId,
+			&booking.BookingDate,
+			&booking.StartTime,
+			&booking.EndTime,
+			&booking.Total_Payment,
+			&booking.Status,
+		)
+		if err != nil {
+			transaction.Rollback()
+			return model.BookingSeries{}, err
+		}
+
+		var payment model.Payment
+
+		err = transaction.QueryRow(
+			insertPayment,
+			booking.Id,
+			val.PaymentDetails[0].OrderId,
+			val.PaymentDetails[0].Description,
+			"mid",
+			val.PaymentDetails[0].Price,
+			"unpaid",
+			val.PaymentDetails[0].PaymentURL,
+		).Scan(
+			&payment.Id,
+			&payment.BookingId,
+			&payment.OrderId,
+			&payment.Description,
+			&payment.PaymentMethod,
+			&payment.Price,
+			&payment.Status,
+			&payment.PaymentURL,
+		)
+		if err != nil {
+			transaction.Rollback()
+			return model.BookingSeries{}, err
+		}
+
+		booking.PaymentDetails = append(booking.PaymentDetails, payment)
+		series.Bookings = append(series.Bookings, booking)
+	}
+
+	transaction.Commit()
+	return series, nil
+}
+
+func (r *bookingRepository) FindSeriesById(seriesId string) (model.BookingSeries, error) {
+	var series model.BookingSeries
+
+	query := "SELECT id, customer_id, court_id, frequency, start_date, end_date, start_time, end_time, created_at, updated_at FROM booking_series WHERE id = $1"
+
+	err := r.DB.QueryRow(query, seriesId).Scan(
+		&series.Id,
+		&series.Customer.Id,
+		&series.Court.Id,
+		&series.Frequency,
+		&series.StartDate,
+		&series.EndDate,
+		&series.StartTime,
+		&series.EndTime,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+	)
+	if err != nil {
+		return model.BookingSeries{}, err
+	}
+
+	query = "SELECT id, customer_id, court_id, series_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE series_id = $1 ORDER BY booking_date"
+
+	rows, err := r.DB.Query(query, seriesId)
+	if err != nil {
+		return model.BookingSeries{}, err
+	}
+
+	for rows.Next() {
+		var b model.Booking
+		if err := rows.Scan(
+			&b.Id,
+			&b.Customer.Id,
+			&b.Court.Id,
+			&b.SeriesId,
+			&b.BookingDate,
+			&b.StartTime,
+			&b.EndTime,
+			&b.Total_Payment,
+			&b.Status,
+		); err != nil {
+			return model.BookingSeries{}, err
+		}
+
+		series.Bookings = append(series.Bookings, b)
+	}
+
+	return series, nil
+}
+
+// UpdateSeriesStatus applies a gateway notification to every occurrence that
+// shares the series down payment order.
+func (r *bookingRepository) UpdateSeriesStatus(payload model.Payment) error {
+	transaction, _ := r.DB.Begin()
+
+	if payload.Status == "pending" {
+		updatePayment := "UPDATE payments SET payment_method = $1, updated_at = $2 WHERE order_id = $3"
+
+		_, err := transaction.Exec(updatePayment, payload.PaymentMethod, time.Now(), payload.OrderId)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+
+	if payload.Status == "paid" {
+		updatePayment := "UPDATE payments SET payment_method = $1, status = $2, payment_url = $3, updated_at = $4 WHERE order_id = $5"
+
+		_, err := transaction.Exec(updatePayment, payload.PaymentMethod, payload.Status, "", time.Now(), payload.OrderId)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+
+		err = transitionSeries(transaction, payload.OrderId, model.BookingBooked, statusActor(payload), "series down payment completed")
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+
+	if payload.Status == "cancel" {
+		err := transitionSeries(transaction, payload.OrderId, model.BookingCancel, statusActor(payload), "series down payment cancelled or expired")
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+
+		deletePayment := "DELETE FROM payments WHERE order_id = $1"
+
+		_, err = transaction.Exec(deletePayment, payload.OrderId)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (r *bookingRepository) ChangeStatus(bookingId string, status model.BookingStatus, changedBy string, reason string) error {
+	transaction, _ := r.DB.Begin()
+
+	_, err := transitionStatus(transaction, bookingId, status, changedBy, reason)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (r *bookingRepository) FindStatusHistory(bookingId string) ([]model.BookingStatusHistory, error) {
+	var history []model.BookingStatusHistory
+
+	query := "SELECT id, booking_id, from_status, to_status, changed_by, reason, created_at FROM booking_status_history WHERE booking_id = $1 ORDER BY created_at"
+
+	rows, err := r.DB.Query(query, bookingId)
+	if err != nil {
+		return []model.BookingStatusHistory{}, err
+	}
+
+	for rows.Next() {
+		var h model.BookingStatusHistory
+		if err := rows.Scan(
+			&h.Id,
+			&h.BookingId,
+			&h.FromStatus,
+			&h.ToStatus,
+			&h.ChangedBy,
+			&h.Reason,
+			&h.CreatedAt,
+		); err != nil {
+			return []model.BookingStatusHistory{}, err
+		}
+
+		history = append(history, h)
+	}
+
+	return history, nil
+}
+
+// CheckInCode stores code as the booking's check-in code unless it already has
+// one, and returns the code the booking ends up with.
+func (r *bookingRepository) CheckInCode(bookingId string, code string) (string, error) {
+	var checkInCode string
+
+	query := "UPDATE bookings SET check_in_code = COALESCE(check_in_code, $1) WHERE id = $2 RETURNING check_in_code"
+
+	err := r.DB.QueryRow(query, code, bookingId).Scan(&checkInCode)
+	if err != nil {
+		return "", err
+	}
+
+	return checkInCode, nil
+}
+
+func (r *bookingRepository) FindByCheckInCode(code string) (model.Booking, error) {
+	var booking model.Booking
+
+	query := "SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE check_in_code = $1"
+
+	err := r.DB.QueryRow(query, code).Scan(
+		&booking.Id,
+		&booking.Customer.Id,
+		&booking.Court.Id,
+		&booking.BookingDate,
+		&booking.StartTime,
+		&booking.EndTime,
+		&booking.Total_Payment,
+		&booking.Status,
+	)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	return booking, nil
+}
+
+// FindMissedCheckIns returns the booked bookings, and the ones paid in full
+// online, that started before cutoff without anyone checking in. Done bookings
+// were settled at the desk, so the customer was there.
+func (r *bookingRepository) FindMissedCheckIns(cutoff time.Time) ([]model.Booking, error) {
+	var bookings []model.Booking
+
+	query := "SELECT b.id, b.customer_id, b.court_id, b.booking_date, b.start_time, b.end_time, b.total_payment, b.status FROM bookings b WHERE b.status IN ($1, $2) AND b.booking_date + b.start_time < $3 AND NOT EXISTS (SELECT 1 FROM booking_status_history h WHERE h.booking_id = b.id AND h.to_status = $4) ORDER BY b.booking_date, b.start_time"
+
+	rows, err := r.DB.Query(query, model.BookingBooked, model.BookingPaid, cutoff, model.BookingCheckedIn)
+	if err != nil {
+		return []model.Booking{}, err
+	}
+
+	for rows.Next() {
+		var b model.Booking
+		if err := rows.Scan(
+			&b.Id,
+			&b.Customer.Id,
+			&b.Court.Id,
+			&b.BookingDate,
+			&b.StartTime,
+			&b.EndTime,
+			&b.Total_Payment,
+			&b.Status,
+		); err != nil {
+			return []model.Booking{}, err
+		}
+		bookings = append(bookings, b)
+	}
+
+	return bookings, nil
+}
+
+func (r *bookingRepository) CountNoShows(customerId string) (int, error) {
+	var noShows int
+
+	err := r.DB.QueryRow("SELECT COUNT(*) FROM bookings WHERE customer_id = $1 AND status = $2", customerId, model.BookingNoShow).Scan(&noShows)
+	if err != nil {
+		return 0, err
+	}
+
+	return noShows, nil
+}
+
+// transitionStatus is the only place a booking changes status. It locks the
+// booking row, checks the move against the transition table and records it in
+// booking_status_history, returning the customer of the booking.
+func transitionStatus(transaction *sql.Tx, bookingId string, status model.BookingStatus, changedBy string, reason string) (string, error) {
+	var current model.BookingStatus
+	var customerId string
+
+	err := transaction.QueryRow("SELECT status, customer_id FROM bookings WHERE id = $1 FOR UPDATE", bookingId).Scan(&current, &customerId)
+	if err != nil {
+		return "", err
+	}
+
+	if err := current.Transition(status); err != nil {
+		return "", err
+	}
+
+	_, err = transaction.Exec("UPDATE bookings SET status = $1, updated_at = $2 WHERE id = $3", status, time.Now(), bookingId)
+	if err != nil {
+		return "", err
+	}
+
+	insertHistory := "INSERT INTO booking_status_history (booking_id, from_status, to_status, changed_by, reason) VALUES ($1, $2, $3, $4, $5)"
+
+	_, err = transaction.Exec(insertHistory, bookingId, current, status, changedBy, reason)
+	if err != nil {
+		return "", err
+	}
+
+	return customerId, nil
+}
+
+// transitionSeries moves every still pending occurrence paid by a series
+// order, leaving occurrences that were already cancelled on their own alone.
+func transitionSeries(transaction *sql.Tx, orderId string, status model.BookingStatus, changedBy string, reason string) error {
+	rows, err := transaction.Query("SELECT b.id FROM bookings b JOIN payments p ON p.booking_id = b.id WHERE p.order_id = $1 AND b.status = $2", orderId, model.BookingPending)
+	if err != nil {
+		return err
+	}
+
+	var bookingIds []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		bookingIds = append(bookingIds, id)
+	}
+	rows.Close()
+
+	for _, id := range bookingIds {
+		_, err = transitionStatus(transaction, id, status, changedBy, reason)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// statusActor is who a payment driven status change is recorded against, the
+// user on the payment when known and the payment gateway otherwise.
+func statusActor(payload model.Payment) string {
+	if payload.User.Id != "" {
+		return payload.User.Id
+	}
+	return "payment-gateway"
+}
+
+// reserveSlot locks the court row for the rest of the transaction and makes
+// sure neither a closure, another active booking nor a waitlist offer held for
+// another customer overlaps the requested time, so concurrent bookings,
+// closures and offers for the same court are checked one after another.
+func reserveSlot(transaction *sql.Tx, courtId string, bookingDate, startTime, endTime time.Time, excludeId string, customerId string) error {
+	var lockedId string
+
+	err := transaction.QueryRow("SELECT id FROM courts WHERE id = $1 FOR UPDATE", courtId).Scan(&lockedId)
+	if err != nil {
+		return err
+	}
+
+	var closed int
+	err = transaction.QueryRow("SELECT COUNT(*) FROM court_closures WHERE court_id = $1 AND start_date <= $2 AND end_date >= $2 AND start_time < $3 AND end_time > $4", courtId, bookingDate, endTime, startTime).Scan(&closed)
+	if err != nil {
+		return err
+	}
+
+	if closed > 0 {
+		return ErrCourtClosed
+	}
+
+	var overlap int
+	query := "SELECT COUNT(*) FROM bookings WHERE court_id = $1 AND booking_date = $2 AND start_time < $3 AND end_time > $4 AND status IN " + activeStatuses + " AND id::text <> $5"
+
+	err = transaction.QueryRow(query, courtId, bookingDate, endTime, startTime, excludeId).Scan(&overlap)
+	if err != nil {
+		return err
+	}
+
+	if overlap > 0 {
+		return ErrBookingConflict
+	}
+
+	var held int
+	query = "SELECT COUNT(*) FROM waitlist_entries WHERE offered_court_id = $1 AND booking_date = $2 AND offered_start_time < $3 AND offered_start_time + make_interval(hours => hour) > $4 AND status = $5 AND offer_expires_at > $6 AND customer_id::text <> $7"
+
+	err = transaction.QueryRow(query, courtId, bookingDate, endTime, startTime, model.WaitlistOffered, time.Now(), customerId).Scan(&held)
+	if err != nil {
+		return err
+	}
+
+	if held > 0 {
+		return ErrSlotOffered
+	}
+
+	return nil
+}
+
 func NewBookingRepository(db *sql.DB) BookingRepository {
 	return &bookingRepository{
 		DB: db,
@@ -1,10 +1,15 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"strings"
+	"sync"
 	"team2/shuttleslot/model"
 	"testing"
 	"time"
@@ -85,8 +90,22 @@ func TestBookingRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(BookingRepositoryTestSuite))
 }
 
+func (suite *BookingRepositoryTestSuite) expectSlotReserved(overlap int) {
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM court_closures WHERE court_id = \\$1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings WHERE court_id = \\$1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(overlap))
+	if overlap == 0 {
+		suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM waitlist_entries WHERE offered_court_id = \\$1").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	}
+}
+
 func (suite *BookingRepositoryTestSuite) TestCreateBooking_Success() {
 	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
 
 	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, mockBooking.Status)
 	suite.mockSql.ExpectQuery("INSERT INTO bookings").WillReturnRows(rows)
@@ -104,8 +123,66 @@ func (suite *BookingRepositoryTestSuite) TestCreateBooking_Success() {
 
 }
 
+func (suite *BookingRepositoryTestSuite) TestCreateBooking_PaidAtDesk() {
+	payload := mockBooking
+	payload.Employee = model.User{Id: "employee_1"}
+	payload.PaymentDetails = []model.Payment{{OrderId: "Booking00001-1", Description: "Pembayaran Booking", PaymentMethod: "cash", Price: 60000}}
+
+	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
+
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, 60000, "pending")
+	suite.mockSql.ExpectQuery("INSERT INTO bookings").
+		WithArgs(mockBooking.Customer.Id, mockBooking.Court.Id, "employee_1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "pending").
+		WillReturnRows(rows)
+	suite.mockSql.ExpectQuery("INSERT INTO payments").
+		WithArgs(mockBooking.Id, "Booking00001-1", "Pembayaran Booking", "cash", 60000, "paid", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url"}).AddRow("1", mockBooking.Id, "Booking00001-1", "Pembayaran Booking", "cash", 60000, "paid", ""))
+	suite.expectTransition(mockBooking.Id, model.BookingPending, model.BookingPaid)
+	suite.mockSql.ExpectQuery("SELECT earn_points, earn_amount, redeem_points, redeem_value, expiry_days, updated_at FROM loyalty_settings WHERE id = 1").
+		WillReturnRows(sqlmock.NewRows([]string{"earn_points", "earn_amount", "redeem_points", "redeem_value", "expiry_days", "updated_at"}).AddRow(0, 1, 1, 100, 0, time.Now()))
+	suite.mockSql.ExpectQuery("SELECT total_payment FROM bookings WHERE id = \\$1").
+		WithArgs(mockBooking.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"total_payment"}).AddRow(60000))
+	suite.mockSql.ExpectCommit()
+
+	actual, err := suite.repo.Create(payload)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.BookingPaid, actual.Status)
+	assert.Equal(suite.T(), "employee_1", actual.Employee.Id)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateBooking_NothingDue() {
+	payload := mockBooking
+	payload.Total_Payment = 0
+	payload.PaymentDetails = []model.Payment{{OrderId: "Booking00001-1", Description: "Pembayaran Booking", Price: 0}}
+
+	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
+
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, 0, "pending")
+	suite.mockSql.ExpectQuery("INSERT INTO bookings").WillReturnRows(rows)
+	suite.mockSql.ExpectQuery("INSERT INTO payments").
+		WithArgs(mockBooking.Id, "Booking00001-1", "Pembayaran Booking", "mid", 0, "paid", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url"}).AddRow("1", mockBooking.Id, "Booking00001-1", "Pembayaran Booking", "mid", 0, "paid", ""))
+	suite.expectTransition(mockBooking.Id, model.BookingPending, model.BookingPaid)
+	suite.mockSql.ExpectQuery("SELECT earn_points, earn_amount, redeem_points, redeem_value, expiry_days, updated_at FROM loyalty_settings WHERE id = 1").
+		WillReturnRows(sqlmock.NewRows([]string{"earn_points", "earn_amount", "redeem_points", "redeem_value", "expiry_days", "updated_at"}).AddRow(0, 1, 1, 100, 0, time.Now()))
+	suite.mockSql.ExpectQuery("SELECT total_payment FROM bookings WHERE id = \\$1").
+		WithArgs(mockBooking.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"total_payment"}).AddRow(0))
+	suite.mockSql.ExpectCommit()
+
+	actual, err := suite.repo.Create(payload)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.BookingPaid, actual.Status)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
 func (suite *BookingRepositoryTestSuite) TestCreatePayment_Failed() {
 	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
 
 	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, mockBooking.Status)
 	suite.mockSql.ExpectQuery("INSERT INTO bookings").WillReturnRows(rows)
@@ -118,8 +195,131 @@ func (suite *BookingRepositoryTestSuite) TestCreatePayment_Failed() {
 	}
 }
 
+func (suite *BookingRepositoryTestSuite) expectPromoLocked(usageLimit, perCustomerLimit, used, usedByCustomer int) {
+	suite.mockSql.ExpectQuery("SELECT usage_limit, per_customer_limit FROM promo_codes WHERE id = \\$1 FOR UPDATE").
+		WithArgs("promo_1").
+		WillReturnRows(sqlmock.NewRows([]string{"usage_limit", "per_customer_limit"}).AddRow(usageLimit, perCustomerLimit))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\), COUNT\\(\\*\\) FILTER").
+		WithArgs("promo_1", mockBooking.Customer.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(used, usedByCustomer))
+}
+
+// expectPointsRestored expects the lookup of points redeemed on booking 1,
+// and the entry giving them back when any were redeemed.
+func (suite *BookingRepositoryTestSuite) expectPointsRestored(redeemed int) {
+	rows := sqlmock.NewRows([]string{"customer_id", "redeemed", "earned"})
+	if redeemed != 0 {
+		rows.AddRow(mockBooking.Customer.Id, redeemed, 0)
+	}
+
+	suite.mockSql.ExpectQuery("SELECT customer_id, COALESCE\\(SUM\\(points\\) FILTER \\(WHERE type = 'redeem'\\), 0\\), COALESCE\\(SUM\\(points\\) FILTER \\(WHERE type = 'earn'\\), 0\\) FROM point_entries WHERE booking_id = \\$1").
+		WithArgs("1").
+		WillReturnRows(rows)
+
+	if redeemed != 0 {
+		suite.mockSql.ExpectQuery("SELECT points FROM users WHERE id = \\$1 FOR UPDATE").
+			WithArgs(mockBooking.Customer.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(0))
+		suite.mockSql.ExpectQuery("INSERT INTO point_entries").
+			WithArgs(mockBooking.Customer.Id, "1", "redeem", -redeemed, 0, sqlmock.AnyArg(), nil).
+			WillReturnRows(sqlmock.NewRows(pointEntryRowColumns).AddRow("entry_2", mockBooking.Customer.Id, "1", "redeem", -redeemed, 0, "returned", nil, time.Time{}))
+		suite.mockSql.ExpectExec("UPDATE users SET points = points \\+ \\$1 WHERE id = \\$2").
+			WithArgs(-redeemed, mockBooking.Customer.Id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
+// expectTransition expects the booking to be locked in status from, moved to
+// status to and the move written to its status history.
+func (suite *BookingRepositoryTestSuite) expectTransition(bookingId string, from, to model.BookingStatus) {
+	suite.mockSql.ExpectQuery("SELECT status, customer_id FROM bookings WHERE id = \\$1 FOR UPDATE").
+		WithArgs(bookingId).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "customer_id"}).AddRow(string(from), mockBooking.Customer.Id))
+	suite.mockSql.ExpectExec("UPDATE bookings SET status = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(string(to), sqlmock.AnyArg(), bookingId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("INSERT INTO booking_status_history").
+		WithArgs(bookingId, string(from), string(to), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func (suite *BookingRepositoryTestSuite) TestCancel_RestoresRedeemedPoints() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectExec("DELETE FROM payments WHERE booking_id = \\$1 AND status = \\$2").
+		WithArgs("1", "unpaid").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.expectTransition("1", model.BookingBooked, model.BookingCancel)
+	suite.expectPointsRestored(-100)
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.Cancel("1", nil, "customer_1")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateBooking_NotEnoughPoints() {
+	withPoints := mockBooking
+	withPoints.PointRedeemed = model.PointEntry{Type: "redeem", Points: -100, Amount: 10000, Description: "Points redeemed"}
+
+	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
+
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, mockBooking.Status)
+	suite.mockSql.ExpectQuery("INSERT INTO bookings").WillReturnRows(rows)
+	suite.mockSql.ExpectQuery("SELECT points FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs(mockBooking.Customer.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(50))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.Create(withPoints)
+	assert.ErrorIs(suite.T(), err, ErrInsufficientPoints)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateBooking_RedeemsPromo() {
+	withPromo := mockBooking
+	withPromo.Promo = model.PromoRedemption{PromoCodeId: "promo_1", Code: "HEMAT", Discount: 5000}
+
+	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
+
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, mockBooking.Status)
+	suite.mockSql.ExpectQuery("INSERT INTO bookings").WillReturnRows(rows)
+	suite.expectPromoLocked(10, 1, 9, 0)
+	suite.mockSql.ExpectExec("INSERT INTO promo_redemptions").
+		WithArgs("promo_1", mockBooking.Id, mockBooking.Customer.Id, 5000).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mb := mockBooking.PaymentDetails[0]
+	suite.mockSql.ExpectQuery("INSERT INTO payments").WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url"}).AddRow(mb.Id, mb.BookingId, mb.OrderId, mb.Description, mb.PaymentMethod, mb.Price, mb.Status, mb.PaymentURL))
+	suite.mockSql.ExpectCommit()
+
+	actual, err := suite.repo.Create(withPromo)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockBooking.Id, actual.Promo.BookingId)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateBooking_PromoUsedUp() {
+	withPromo := mockBooking
+	withPromo.Promo = model.PromoRedemption{PromoCodeId: "promo_1", Code: "HEMAT", Discount: 5000}
+
+	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
+
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, mockBooking.Status)
+	suite.mockSql.ExpectQuery("INSERT INTO bookings").WillReturnRows(rows)
+	suite.expectPromoLocked(10, 0, 10, 2)
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.Create(withPromo)
+	assert.ErrorIs(suite.T(), err, ErrPromoUsageLimit)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
 func (suite *BookingRepositoryTestSuite) TestCreate_Failed() {
 	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
 
 	sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, mockBooking.Status)
 	suite.mockSql.ExpectQuery("INSERT INTO bookings").WillReturnError(errors.New("Insert payments failed"))
@@ -373,6 +573,16 @@ func (suite *BookingRepositoryTestSuite) TestFindPaymentByOrderId_Success() {
 	assert.Equal(suite.T(), expectedPayment, actualPayment)
 }
 
+func (suite *BookingRepositoryTestSuite) TestFindOrderTotal_Success() {
+	suite.mockSql.ExpectQuery("SELECT COALESCE\\(SUM\\(price\\), 0\\) FROM payments WHERE order_id = \\$1 AND status <> \\$2").
+		WithArgs("Series-1", "refund").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(120000))
+
+	total, err := suite.repo.FindOrderTotal("Series-1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 120000, total)
+}
+
 func (suite *BookingRepositoryTestSuite) TestFindPaymentByOrderId_QueryError() {
 	orderId := "23"
 
@@ -430,9 +640,39 @@ func (suite *BookingRepositoryTestSuite) TestUpdateStatus_Success_Paid() {
 	suite.mockSql.ExpectExec(
 		"UPDATE payments SET payment_method = \\$1, status = \\$2, payment_url = \\$3, updated_at = \\$4 WHERE order_id = \\$5",
 	).WithArgs(payload.PaymentMethod, payload.Status, "", sqlmock.AnyArg(), payload.OrderId).WillReturnResult(sqlmock.NewResult(0, 1))
-	suite.mockSql.ExpectExec(
-		"UPDATE bookings SET status = \\$1, updated_at = \\$2 WHERE id = \\$3",
-	).WithArgs("booked", sqlmock.AnyArg(), payload.BookingId).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("SELECT total_payment FROM bookings WHERE id = \\$1").
+		WithArgs(payload.BookingId).
+		WillReturnRows(sqlmock.NewRows([]string{"total_payment"}).AddRow(60000))
+	suite.expectTransition(payload.BookingId, model.BookingPending, model.BookingBooked)
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.UpdateStatus(payload)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestUpdateStatus_Success_PaidInFull() {
+	payload := model.Payment{
+		BookingId:     "1",
+		OrderId:       "23",
+		PaymentMethod: "gopay",
+		Price:         60000,
+		Status:        "paid",
+	}
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectExec("UPDATE payments SET payment_method = \\$1, status = \\$2, payment_url = \\$3, updated_at = \\$4 WHERE order_id = \\$5").
+		WithArgs(payload.PaymentMethod, payload.Status, "", sqlmock.AnyArg(), payload.OrderId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("SELECT total_payment FROM bookings WHERE id = \\$1").
+		WithArgs(payload.BookingId).
+		WillReturnRows(sqlmock.NewRows([]string{"total_payment"}).AddRow(60000))
+	suite.expectTransition(payload.BookingId, model.BookingPending, model.BookingPaid)
+	suite.mockSql.ExpectQuery("SELECT earn_points, earn_amount, redeem_points, redeem_value, expiry_days, updated_at FROM loyalty_settings WHERE id = 1").
+		WillReturnRows(sqlmock.NewRows([]string{"earn_points", "earn_amount", "redeem_points", "redeem_value", "expiry_days", "updated_at"}).AddRow(0, 1, 1, 100, 0, time.Now()))
+	suite.mockSql.ExpectQuery("SELECT total_payment FROM bookings WHERE id = \\$1").
+		WithArgs(payload.BookingId).
+		WillReturnRows(sqlmock.NewRows([]string{"total_payment"}).AddRow(60000))
 	suite.mockSql.ExpectCommit()
 
 	err := suite.repo.UpdateStatus(payload)
@@ -451,9 +691,8 @@ func (suite *BookingRepositoryTestSuite) TestUpdateStatus_Success_Cancel() {
 	suite.mockSql.ExpectExec(
 		"DELETE FROM payments WHERE order_id = \\$1",
 	).WithArgs(payload.OrderId).WillReturnResult(sqlmock.NewResult(0, 1))
-	suite.mockSql.ExpectExec(
-		"UPDATE bookings SET status = \\$1, updated_at = \\$2 WHERE id = \\$3",
-	).WithArgs("cancel", sqlmock.AnyArg(), payload.BookingId).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.expectTransition(payload.BookingId, model.BookingPending, model.BookingCancel)
+	suite.expectPointsRestored(0)
 	suite.mockSql.ExpectCommit()
 
 	err := suite.repo.UpdateStatus(payload)
@@ -525,6 +764,94 @@ func (suite *BookingRepositoryTestSuite) TestCreateRepay_Success() {
 	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
 }
 
+func (suite *BookingRepositoryTestSuite) TestCreateRepay_CancelledBooking() {
+	payload := model.Payment{
+		BookingId:     "1",
+		OrderId:       "23",
+		Description:   "booking_field1",
+		PaymentMethod: "cash",
+		Price:         30000,
+		User:          model.User{Id: "2"},
+	}
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("INSERT INTO payments").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url"}).
+			AddRow("1", payload.BookingId, payload.OrderId, payload.Description, payload.PaymentMethod, payload.Price, "unpaid", ""))
+	suite.mockSql.ExpectExec("UPDATE bookings SET employee_id = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("UPDATE payments SET status = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("SELECT status, customer_id FROM bookings WHERE id = \\$1 FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "customer_id"}).AddRow("cancel", mockBooking.Customer.Id))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.CreateRepay(payload)
+
+	var transitionErr *model.BookingTransitionError
+	assert.ErrorAs(suite.T(), err, &transitionErr)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateRefund_Success() {
+	payload := model.Payment{
+		BookingId:     "1",
+		OrderId:       "Refund1-1",
+		Description:   "Refund court double booked",
+		PaymentMethod: "cash",
+		Price:         20000,
+		RefundOf:      "Booking00001-1",
+		User:          model.User{Id: "2"},
+	}
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery(
+		"INSERT INTO payments \\(booking_id, order_id, description, payment_method, price, status, payment_url, refund_of\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7, \\$8\\) RETURNING id, booking_id, order_id, description, payment_method, price, status, payment_url, refund_of",
+	).WithArgs(payload.BookingId, payload.OrderId, payload.Description, payload.PaymentMethod, payload.Price, "refund", "", payload.RefundOf).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url", "refund_of"}).
+			AddRow("5", payload.BookingId, payload.OrderId, payload.Description, payload.PaymentMethod, payload.Price, "refund", "", payload.RefundOf))
+	suite.mockSql.ExpectExec("UPDATE bookings SET employee_id = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(payload.User.Id, sqlmock.AnyArg(), payload.BookingId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectCommit()
+
+	payment, err := suite.repo.CreateRefund(payload)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "5", payment.Id)
+	assert.Equal(suite.T(), "refund", payment.Status)
+	assert.Equal(suite.T(), payload.RefundOf, payment.RefundOf)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateRefund_InsertError() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("INSERT INTO payments").
+		WillReturnError(errors.New("insert error"))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.CreateRefund(model.Payment{BookingId: "1", OrderId: "Refund1-1", Price: 20000})
+
+	assert.EqualError(suite.T(), err, "insert error")
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateRefund_UpdateBookingError() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("INSERT INTO payments").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url", "refund_of"}).
+			AddRow("5", "1", "Refund1-1", "Refund", "cash", 20000, "refund", "", "Booking00001-1"))
+	suite.mockSql.ExpectExec("UPDATE bookings SET employee_id").
+		WillReturnError(errors.New("update error"))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.CreateRefund(model.Payment{BookingId: "1", OrderId: "Refund1-1", Price: 20000})
+
+	assert.EqualError(suite.T(), err, "update error")
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
 func (suite *BookingRepositoryTestSuite) TestUpdateRepaymentStatus_Pending() {
 	payload := model.Payment{
 		OrderId:       "23",
@@ -654,7 +981,7 @@ func (suite *BookingRepositoryTestSuite) TestFindBooked_Success() {
 		AddRow(mockBooking.Court.Id, bookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Status).
 		AddRow(mockBooking.Court.Id, bookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Status)
 
-	suite.mockSql.ExpectQuery("SELECT court_id, booking_date, start_time, end_time, status FROM bookings WHERE booking_date = \\$1 AND status IN \\('pending', 'booked'\\) LIMIT \\$2 OFFSET \\$3").
+	suite.mockSql.ExpectQuery("SELECT court_id, booking_date, start_time, end_time, status FROM bookings WHERE booking_date = \\$1 AND status IN \\('pending', 'booked', 'paid', 'checked_in', 'done'\\) LIMIT \\$2 OFFSET \\$3").
 		WithArgs(bookingDate, size, offset).
 		WillReturnRows(rows)
 
@@ -675,7 +1002,7 @@ func (suite *BookingRepositoryTestSuite) TestFindBooked_QueryError() {
 	size := 10
 	bookingDate := time.Now()
 
-	suite.mockSql.ExpectQuery("SELECT court_id, booking_date, start_time, end_time, status FROM bookings WHERE booking_date = \\$1 AND status IN \\('pending', 'booked'\\) LIMIT \\$2 OFFSET \\$3").
+	suite.mockSql.ExpectQuery("SELECT court_id, booking_date, start_time, end_time, status FROM bookings WHERE booking_date = \\$1 AND status IN \\('pending', 'booked', 'paid', 'checked_in', 'done'\\) LIMIT \\$2 OFFSET \\$3").
 		WithArgs(bookingDate, size, (page-1)*size).
 		WillReturnError(errors.New("query error"))
 
@@ -692,7 +1019,7 @@ func (suite *BookingRepositoryTestSuite) TestFindBooked_ScanError() {
 	rows := sqlmock.NewRows([]string{"court_id", "booking_date", "start_time", "end_time", "status"}).
 		AddRow("invalid_id", bookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Status)
 
-	suite.mockSql.ExpectQuery("SELECT invalid_id, booking_date, start_time, end_time, status FROM bookings WHERE booking_date = \\$1 AND status IN \\('pending', 'booked'\\) LIMIT \\$2 OFFSET \\$3").
+	suite.mockSql.ExpectQuery("SELECT invalid_id, booking_date, start_time, end_time, status FROM bookings WHERE booking_date = \\$1 AND status IN \\('pending', 'booked', 'paid', 'checked_in', 'done'\\) LIMIT \\$2 OFFSET \\$3").
 		WithArgs(bookingDate, size, (page-1)*size).
 		WillReturnRows(rows)
 
@@ -710,7 +1037,7 @@ func (suite *BookingRepositoryTestSuite) TestFindEnding_Success() {
 		AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, bookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, "booked").
 		AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, bookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, "booked")
 
-	suite.mockSql.ExpectQuery("SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE booking_date = \\$1 AND status = 'booked' LIMIT \\$2 OFFSET \\$3").
+	suite.mockSql.ExpectQuery("SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE booking_date = \\$1 AND status IN \\('booked', 'paid', 'checked_in'\\) LIMIT \\$2 OFFSET \\$3").
 		WithArgs(bookingDate, size, offset).
 		WillReturnRows(rows)
 
@@ -731,7 +1058,7 @@ func (suite *BookingRepositoryTestSuite) TestFindEnding_QueryError() {
 	size := 10
 	bookingDate := time.Now()
 
-	suite.mockSql.ExpectQuery("SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE booking_date = \\$1 AND status = 'booked' LIMIT \\$2 OFFSET \\$3").
+	suite.mockSql.ExpectQuery("SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE booking_date = \\$1 AND status IN \\('booked', 'paid', 'checked_in'\\) LIMIT \\$2 OFFSET \\$3").
 		WithArgs(bookingDate, size, (page-1)*size).
 		WillReturnError(errors.New("query error"))
 
@@ -748,7 +1075,7 @@ func (suite *BookingRepositoryTestSuite) TestFindEnding_ScanError() {
 	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).
 		AddRow("invalid_id", mockBooking.Customer.Id, mockBooking.Court.Id, bookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, "booked")
 
-	suite.mockSql.ExpectQuery("SELECT invalid_id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE booking_date = \\$1 AND status = 'booked' LIMIT \\$2 OFFSET \\$3").
+	suite.mockSql.ExpectQuery("SELECT invalid_id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE booking_date = \\$1 AND status IN \\('booked', 'paid', 'checked_in'\\) LIMIT \\$2 OFFSET \\$3").
 		WithArgs(bookingDate, size, (page-1)*size).
 		WillReturnRows(rows)
 
@@ -768,7 +1095,7 @@ func (suite *BookingRepositoryTestSuite) TestFindPaymentReport_Daily_Success() {
 	rows := sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price"}).
 		AddRow(mockPayment.Id, mockPayment.BookingId, mockPayment.OrderId, mockPayment.Description, mockPayment.PaymentMethod, mockPayment.Price)
 
-	expectedQuery := `SELECT id, booking_id, order_id, description, payment_method, price FROM payments WHERE EXTRACT\(DAY FROM created_at\) = \$1  AND EXTRACT\(MONTH FROM created_at\) = \$2 AND EXTRACT\(YEAR FROM created_at\) = \$3 LIMIT \$4 OFFSET \$5`
+	expectedQuery := `SELECT id, booking_id, order_id, description, payment_method, CASE WHEN status = 'refund' THEN -price ELSE price END FROM payments WHERE EXTRACT\(DAY FROM created_at\) = \$1  AND EXTRACT\(MONTH FROM created_at\) = \$2 AND EXTRACT\(YEAR FROM created_at\) = \$3 LIMIT \$4 OFFSET \$5`
 
 	suite.mockSql.ExpectQuery(expectedQuery).
 		WithArgs(day, month, year, size, offset).
@@ -798,7 +1125,7 @@ func (suite *BookingRepositoryTestSuite) TestFindPaymentReport_Monthly_Success()
 	rows := sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price"}).
 		AddRow(mockPayment.Id, mockPayment.BookingId, mockPayment.OrderId, mockPayment.Description, mockPayment.PaymentMethod, mockPayment.Price)
 
-	expectedQuery := `SELECT id, booking_id, order_id, description, payment_method, price FROM payments WHERE EXTRACT\(MONTH FROM created_at\) = \$1 AND EXTRACT\(YEAR FROM created_at\) = \$2 LIMIT \$3 OFFSET \$4`
+	expectedQuery := `SELECT id, booking_id, order_id, description, payment_method, CASE WHEN status = 'refund' THEN -price ELSE price END FROM payments WHERE EXTRACT\(MONTH FROM created_at\) = \$1 AND EXTRACT\(YEAR FROM created_at\) = \$2 LIMIT \$3 OFFSET \$4`
 
 	suite.mockSql.ExpectQuery(expectedQuery).
 		WithArgs(month, year, size, offset).
@@ -827,7 +1154,7 @@ func (suite *BookingRepositoryTestSuite) TestFindPaymentReport_Yearly_Success()
 	rows := sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price"}).
 		AddRow(mockPayment.Id, mockPayment.BookingId, mockPayment.OrderId, mockPayment.Description, mockPayment.PaymentMethod, mockPayment.Price)
 
-	expectedQuery := `SELECT id, booking_id, order_id, description, payment_method, price FROM payments WHERE EXTRACT\(YEAR FROM created_at\) = \$1 LIMIT \$2 OFFSET \$3`
+	expectedQuery := `SELECT id, booking_id, order_id, description, payment_method, CASE WHEN status = 'refund' THEN -price ELSE price END FROM payments WHERE EXTRACT\(YEAR FROM created_at\) = \$1 LIMIT \$2 OFFSET \$3`
 
 	suite.mockSql.ExpectQuery(expectedQuery).
 		WithArgs(year, size, offset).
@@ -846,6 +1173,27 @@ func (suite *BookingRepositoryTestSuite) TestFindPaymentReport_Yearly_Success()
 	assert.Equal(suite.T(), int64(mockPayment.Price), totalIncome)
 }
 
+func (suite *BookingRepositoryTestSuite) TestFindPaymentReport_RefundReducesIncome() {
+	year := 2024
+	page := 1
+	size := 10
+	offset := (page - 1) * size
+
+	rows := sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price"}).
+		AddRow(mockPayment.Id, mockPayment.BookingId, mockPayment.OrderId, mockPayment.Description, mockPayment.PaymentMethod, 60000).
+		AddRow("2", mockPayment.BookingId, "Refund1-1", "Refund down payment", "gopay", -20000)
+
+	suite.mockSql.ExpectQuery(`SELECT id, booking_id, order_id, description, payment_method, CASE WHEN status = 'refund' THEN -price ELSE price END FROM payments WHERE EXTRACT\(YEAR FROM created_at\) = \$1 LIMIT \$2 OFFSET \$3`).
+		WithArgs(year, size, offset).
+		WillReturnRows(rows)
+
+	actualPayments, _, totalIncome, err := suite.repo.FindPaymentReport(0, 0, year, page, size, "yearly")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(actualPayments))
+	assert.Equal(suite.T(), -20000, actualPayments[1].Price)
+	assert.Equal(suite.T(), int64(40000), totalIncome)
+}
+
 func (suite *BookingRepositoryTestSuite) TestFindPaymentReport_QueryError() {
 	day := 1
 	month := 7
@@ -855,7 +1203,7 @@ func (suite *BookingRepositoryTestSuite) TestFindPaymentReport_QueryError() {
 	filterType := "daily"
 	offset := (page - 1) * size
 
-	suite.mockSql.ExpectQuery(`SELECT id, booking_id, order_id, description, payment_method, price FROM payments WHERE EXTRACT\(DAY FROM created_at\) = \$1 AND EXTRACT\(MONTH FROM created_at\) = \$2 AND EXTRACT\(YEAR FROM created_at\) = \$3 LIMIT \$4 OFFSET \$5`).
+	suite.mockSql.ExpectQuery(`SELECT id, booking_id, order_id, description, payment_method, CASE WHEN status = 'refund' THEN -price ELSE price END FROM payments WHERE EXTRACT\(DAY FROM created_at\) = \$1 AND EXTRACT\(MONTH FROM created_at\) = \$2 AND EXTRACT\(YEAR FROM created_at\) = \$3 LIMIT \$4 OFFSET \$5`).
 		WithArgs(day, month, year, size, offset).
 		WillReturnError(errors.New("query error"))
 
@@ -876,10 +1224,852 @@ func (suite *BookingRepositoryTestSuite) TestFindPaymentReport_ScanError() {
 	rows := sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price"}).
 		AddRow("invalid_id", mockPayment.BookingId, mockPayment.OrderId, mockPayment.Description, mockPayment.PaymentMethod, mockPayment.Price)
 
-	suite.mockSql.ExpectQuery("SELECT id, booking_id, order_id, description, payment_method, price FROM payments WHERE EXTRACT(DAY FROM created_at) = \\$1  AND EXTRACT(MONTH FROM created_at) = \\$2 AND EXTRACT(YEAR FROM created_at) = \\$3 LIMIT \\$4 OFFSET \\$5").
+	suite.mockSql.ExpectQuery("SELECT id, booking_id, order_id, description, payment_method, CASE WHEN status = 'refund' THEN -price ELSE price END FROM payments WHERE EXTRACT(DAY FROM created_at) = \\$1  AND EXTRACT(MONTH FROM created_at) = \\$2 AND EXTRACT(YEAR FROM created_at) = \\$3 LIMIT \\$4 OFFSET \\$5").
 		WithArgs(day, month, year, size, offset).
 		WillReturnRows(rows)
 
 	_, _, _, err := suite.repo.FindPaymentReport(day, month, year, page, size, filterType)
 	assert.Error(suite.T(), err)
 }
+
+func (suite *BookingRepositoryTestSuite) TestFindPaymentsByBookingId_Success() {
+	rows := sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url", "refund_of"}).
+		AddRow(mockPayment.Id, mockPayment.BookingId, mockPayment.OrderId, mockPayment.Description, mockPayment.PaymentMethod, mockPayment.Price, mockPayment.Status, mockPayment.PaymentURL, "")
+
+	suite.mockSql.ExpectQuery("SELECT id, booking_id, order_id, description, payment_method, price, status, payment_url, COALESCE\\(refund_of, ''\\) FROM payments WHERE booking_id = \\$1 ORDER BY created_at, id").
+		WithArgs(mockPayment.BookingId).
+		WillReturnRows(rows)
+
+	payments, err := suite.repo.FindPaymentsByBookingId(mockPayment.BookingId)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(payments))
+	assert.Equal(suite.T(), mockPayment.OrderId, payments[0].OrderId)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindPaymentsByBookingId_QueryError() {
+	suite.mockSql.ExpectQuery("SELECT id, booking_id, order_id, description, payment_method, price, status, payment_url, COALESCE\\(refund_of, ''\\) FROM payments WHERE booking_id = \\$1 ORDER BY created_at, id").
+		WithArgs(mockPayment.BookingId).
+		WillReturnError(errors.New("query error"))
+
+	_, err := suite.repo.FindPaymentsByBookingId(mockPayment.BookingId)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *BookingRepositoryTestSuite) TestCancel_WithRefund() {
+	refund := model.Payment{OrderId: "Refund1-1", Description: "Refund 100% down payment", PaymentMethod: "gopay", Price: 15000, Status: "refund", RefundOf: "Booking00001-1"}
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectExec("DELETE FROM payments WHERE booking_id = \\$1 AND status = \\$2").
+		WithArgs("1", "unpaid").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.expectTransition("1", model.BookingBooked, model.BookingCancel)
+	suite.expectPointsRestored(0)
+	suite.mockSql.ExpectExec("INSERT INTO payments").
+		WithArgs("1", refund.OrderId, refund.Description, refund.PaymentMethod, refund.Price, refund.Status, "", refund.RefundOf).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.Cancel("1", []model.Payment{refund}, "customer_1")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCancel_TakesBackEarnedPoints() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectExec("DELETE FROM payments WHERE booking_id = \\$1 AND status = \\$2").
+		WithArgs("1", "unpaid").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.expectTransition("1", model.BookingPaid, model.BookingCancel)
+	suite.mockSql.ExpectQuery("SELECT customer_id, COALESCE\\(SUM\\(points\\) FILTER").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"customer_id", "redeemed", "earned"}).AddRow(mockBooking.Customer.Id, 0, 10))
+	suite.mockSql.ExpectQuery("SELECT points FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs(mockBooking.Customer.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(4))
+	suite.mockSql.ExpectQuery("SELECT points FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs(mockBooking.Customer.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(4))
+	suite.mockSql.ExpectQuery("INSERT INTO point_entries").
+		WithArgs(mockBooking.Customer.Id, "1", "adjust", -4, 0, sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows(pointEntryRowColumns).AddRow("entry_3", mockBooking.Customer.Id, "1", "adjust", -4, 0, "taken back", nil, time.Time{}))
+	suite.mockSql.ExpectExec("UPDATE users SET points = points \\+ \\$1 WHERE id = \\$2").
+		WithArgs(-4, mockBooking.Customer.Id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.Cancel("1", nil, "customer_1")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCancel_WithoutRefund() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectExec("DELETE FROM payments WHERE booking_id = \\$1 AND status = \\$2").
+		WithArgs("1", "unpaid").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.expectTransition("1", model.BookingBooked, model.BookingCancel)
+	suite.expectPointsRestored(0)
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.Cancel("1", nil, "customer_1")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCancel_UpdateBookingError() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectExec("DELETE FROM payments WHERE booking_id = \\$1 AND status = \\$2").
+		WithArgs("1", "unpaid").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectQuery("SELECT status, customer_id FROM bookings WHERE id = \\$1 FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "customer_id"}).AddRow("booked", mockBooking.Customer.Id))
+	suite.mockSql.ExpectExec("UPDATE bookings SET status = \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs("cancel", sqlmock.AnyArg(), "1").
+		WillReturnError(errors.New("update booking error"))
+	suite.mockSql.ExpectRollback()
+
+	err := suite.repo.Cancel("1", nil, "customer_1")
+	assert.EqualError(suite.T(), err, "update booking error")
+}
+
+func (suite *BookingRepositoryTestSuite) TestCancel_AlreadyDone() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectExec("DELETE FROM payments WHERE booking_id = \\$1 AND status = \\$2").
+		WithArgs("1", "unpaid").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mockSql.ExpectQuery("SELECT status, customer_id FROM bookings WHERE id = \\$1 FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "customer_id"}).AddRow("done", mockBooking.Customer.Id))
+	suite.mockSql.ExpectRollback()
+
+	err := suite.repo.Cancel("1", nil, "customer_1")
+	var transitionErr *model.BookingTransitionError
+	assert.ErrorAs(suite.T(), err, &transitionErr)
+	assert.Equal(suite.T(), model.BookingDone, transitionErr.From)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestUpdatePaymentStatus_Paid() {
+	payload := model.Payment{OrderId: "Reschedule1-1", PaymentMethod: "gopay", Status: "paid"}
+
+	suite.mockSql.ExpectExec("UPDATE payments SET payment_method = \\$1, status = \\$2, payment_url = \\$3, updated_at = \\$4 WHERE order_id = \\$5").
+		WithArgs(payload.PaymentMethod, payload.Status, "", sqlmock.AnyArg(), payload.OrderId).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := suite.repo.UpdatePaymentStatus(payload)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestUpdatePaymentStatus_Cancel() {
+	payload := model.Payment{OrderId: "Reschedule1-1", Status: "cancel"}
+
+	suite.mockSql.ExpectExec("DELETE FROM payments WHERE order_id = \\$1").
+		WithArgs(payload.OrderId).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := suite.repo.UpdatePaymentStatus(payload)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestUpdatePaymentStatus_PendingError() {
+	payload := model.Payment{OrderId: "Reschedule1-1", PaymentMethod: "gopay", Status: "pending"}
+
+	suite.mockSql.ExpectExec("UPDATE payments SET payment_method = \\$1, updated_at = \\$2 WHERE order_id = \\$3").
+		WithArgs(payload.PaymentMethod, sqlmock.AnyArg(), payload.OrderId).
+		WillReturnError(errors.New("update payment error"))
+
+	err := suite.repo.UpdatePaymentStatus(payload)
+	assert.EqualError(suite.T(), err, "update payment error")
+}
+
+func (suite *BookingRepositoryTestSuite) TestReschedule_Success() {
+	payload := mockBooking
+	payload.PaymentDetails = []model.Payment{{OrderId: "Reschedule1-1", Description: "Selisih", PaymentMethod: "mid", Price: 5000, Status: "unpaid", PaymentURL: "url"}}
+
+	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).
+		AddRow(payload.Id, payload.Customer.Id, payload.Court.Id, payload.BookingDate, payload.StartTime, payload.EndTime, payload.Total_Payment, "booked")
+	suite.mockSql.ExpectQuery("UPDATE bookings SET court_id = \\$1, booking_date = \\$2, start_time = \\$3, end_time = \\$4, total_payment = \\$5, updated_at = \\$6 WHERE id = \\$7").
+		WithArgs(payload.Court.Id, payload.BookingDate, payload.StartTime, payload.EndTime, payload.Total_Payment, sqlmock.AnyArg(), payload.Id).
+		WillReturnRows(rows)
+	paymentRows := sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url", "refund_of"}).
+		AddRow("2", payload.Id, "Reschedule1-1", "Selisih", "mid", 5000, "unpaid", "url", "")
+	suite.mockSql.ExpectQuery("INSERT INTO payments \\(booking_id, order_id, description, payment_method, price, status, payment_url, refund_of\\)").
+		WithArgs(payload.Id, "Reschedule1-1", "Selisih", "mid", 5000, "unpaid", "url", "").
+		WillReturnRows(paymentRows)
+	suite.mockSql.ExpectCommit()
+
+	actual, err := suite.repo.Reschedule(payload)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.BookingBooked, actual.Status)
+	assert.Equal(suite.T(), 1, len(actual.PaymentDetails))
+}
+
+func (suite *BookingRepositoryTestSuite) TestReschedule_KeepsRefundOf() {
+	payload := mockBooking
+	payload.PaymentDetails = []model.Payment{{OrderId: "Refund1-1", Description: "Selisih", PaymentMethod: "mid", Price: 5000, Status: "refund", RefundOf: "Booking00001-1"}}
+
+	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).
+		AddRow(payload.Id, payload.Customer.Id, payload.Court.Id, payload.BookingDate, payload.StartTime, payload.EndTime, payload.Total_Payment, "booked")
+	suite.mockSql.ExpectQuery("UPDATE bookings SET court_id").WillReturnRows(rows)
+	paymentRows := sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url", "refund_of"}).
+		AddRow("2", payload.Id, "Refund1-1", "Selisih", "mid", 5000, "refund", "", "Booking00001-1")
+	suite.mockSql.ExpectQuery("INSERT INTO payments").
+		WithArgs(payload.Id, "Refund1-1", "Selisih", "mid", 5000, "refund", "", "Booking00001-1").
+		WillReturnRows(paymentRows)
+	suite.mockSql.ExpectCommit()
+
+	actual, err := suite.repo.Reschedule(payload)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Booking00001-1", actual.PaymentDetails[0].RefundOf)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestReschedule_UpdateError() {
+	suite.mockSql.ExpectBegin()
+	suite.expectSlotReserved(0)
+	suite.mockSql.ExpectQuery("UPDATE bookings SET court_id").
+		WillReturnError(errors.New("update booking error"))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.Reschedule(mockBooking)
+	assert.EqualError(suite.T(), err, "update booking error")
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateSeries_Success() {
+	series := model.BookingSeries{
+		Customer:  model.User{Id: "1"},
+		Court:     model.Court{Id: "1"},
+		Frequency: "weekly",
+		Bookings: []model.Booking{
+			{Total_Payment: 60000, PaymentDetails: []model.Payment{{OrderId: "Series1-1", Description: "desc", Price: 30000, PaymentURL: "url"}}},
+			{Total_Payment: 60000, PaymentDetails: []model.Payment{{OrderId: "Series1-1", Description: "desc", Price: 30000, PaymentURL: "url"}}},
+		},
+	}
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("INSERT INTO booking_series").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "court_id", "frequency", "start_date", "end_date", "start_time", "end_time", "created_at", "updated_at"}).
+			AddRow("s1", "1", "1", "weekly", time.Time{}, time.Time{}, time.Time{}, time.Time{}, time.Time{}, time.Time{}))
+
+	for i := range series.Bookings {
+		suite.expectSlotReserved(0)
+		suite.mockSql.ExpectQuery("INSERT INTO bookings").
+			WithArgs("1", "1", "s1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 60000, "pending").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "court_id", "series_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).
+				AddRow(fmt.Sprint(i+1), "1", "1", "s1", time.Time{}, time.Time{}, time.Time{}, 60000, "pending"))
+		suite.mockSql.ExpectQuery("INSERT INTO payments").
+			WithArgs(fmt.Sprint(i+1), "Series1-1", "desc", "mid", 30000, "unpaid", "url").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url"}).
+				AddRow(fmt.Sprint(i+1), fmt.Sprint(i+1), "Series1-1", "desc", "mid", 30000, "unpaid", "url"))
+	}
+	suite.mockSql.ExpectCommit()
+
+	actual, err := suite.repo.CreateSeries(series)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "s1", actual.Id)
+	assert.Equal(suite.T(), 2, len(actual.Bookings))
+	assert.Equal(suite.T(), "s1", actual.Bookings[1].SeriesId)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateSeries_BookingError() {
+	series := model.BookingSeries{
+		Bookings: []model.Booking{{PaymentDetails: []model.Payment{{OrderId: "Series1-1"}}}},
+	}
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("INSERT INTO booking_series").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "court_id", "frequency", "start_date", "end_date", "start_time", "end_time", "created_at", "updated_at"}).
+			AddRow("s1", "1", "1", "weekly", time.Time{}, time.Time{}, time.Time{}, time.Time{}, time.Time{}, time.Time{}))
+	suite.expectSlotReserved(0)
+	suite.mockSql.ExpectQuery("INSERT INTO bookings").WillReturnError(errors.New("insert booking error"))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.CreateSeries(series)
+	assert.EqualError(suite.T(), err, "insert booking error")
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindSeriesById_Success() {
+	suite.mockSql.ExpectQuery("SELECT id, customer_id, court_id, frequency, start_date, end_date, start_time, end_time, created_at, updated_at FROM booking_series WHERE id = \\$1").
+		WithArgs("s1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "court_id", "frequency", "start_date", "end_date", "start_time", "end_time", "created_at", "updated_at"}).
+			AddRow("s1", "1", "1", "weekly", time.Time{}, time.Time{}, time.Time{}, time.Time{}, time.Time{}, time.Time{}))
+	suite.mockSql.ExpectQuery("SELECT id, customer_id, court_id, series_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE series_id = \\$1").
+		WithArgs("s1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "court_id", "series_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).
+			AddRow("1", "1", "1", "s1", time.Time{}, time.Time{}, time.Time{}, 60000, "booked"))
+
+	series, err := suite.repo.FindSeriesById("s1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(series.Bookings))
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindSeriesById_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT id, customer_id, court_id, frequency").
+		WithArgs("s1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.repo.FindSeriesById("s1")
+	assert.Error(suite.T(), err)
+}
+
+// expectSeriesPending expects the lookup of the pending occurrences paid by a
+// series order.
+func (suite *BookingRepositoryTestSuite) expectSeriesPending(orderId string, bookingIds ...string) {
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, id := range bookingIds {
+		rows.AddRow(id)
+	}
+
+	suite.mockSql.ExpectQuery("SELECT b.id FROM bookings b JOIN payments p ON p.booking_id = b.id WHERE p.order_id = \\$1 AND b.status = \\$2").
+		WithArgs(orderId, "pending").
+		WillReturnRows(rows)
+}
+
+func (suite *BookingRepositoryTestSuite) TestUpdateSeriesStatus_Paid() {
+	payload := model.Payment{OrderId: "Series1-1", PaymentMethod: "gopay", Status: "paid"}
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectExec("UPDATE payments SET payment_method = \\$1, status = \\$2, payment_url = \\$3, updated_at = \\$4 WHERE order_id = \\$5").
+		WithArgs("gopay", "paid", "", sqlmock.AnyArg(), "Series1-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.expectSeriesPending("Series1-1", "2", "3")
+	suite.expectTransition("2", model.BookingPending, model.BookingBooked)
+	suite.expectTransition("3", model.BookingPending, model.BookingBooked)
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.UpdateSeriesStatus(payload)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestUpdateSeriesStatus_Cancel() {
+	payload := model.Payment{OrderId: "Series1-1", Status: "cancel"}
+
+	suite.mockSql.ExpectBegin()
+	suite.expectSeriesPending("Series1-1", "2", "3")
+	suite.expectTransition("2", model.BookingPending, model.BookingCancel)
+	suite.expectTransition("3", model.BookingPending, model.BookingCancel)
+	suite.mockSql.ExpectExec("DELETE FROM payments WHERE order_id = \\$1").
+		WithArgs("Series1-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.UpdateSeriesStatus(payload)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindExpiredPending_Success() {
+	cutoff := time.Date(2030, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectQuery("SELECT p.id, p.booking_id, p.order_id, p.description, p.payment_method, p.price, p.status, p.payment_url FROM payments p JOIN bookings b").
+		WithArgs("pending", "unpaid", cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url"}).
+			AddRow("1", "1", "Booking00001-1", "desc", "mid", 30000, "unpaid", "url"))
+
+	payments, err := suite.repo.FindExpiredPending(cutoff)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(payments))
+	assert.Equal(suite.T(), "Booking00001-1", payments[0].OrderId)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindExpiredPending_Failed() {
+	suite.mockSql.ExpectQuery("SELECT p.id, p.booking_id").
+		WillReturnError(errors.New("query error"))
+
+	_, err := suite.repo.FindExpiredPending(time.Now())
+	assert.Error(suite.T(), err)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindStalePayments_Success() {
+	cutoff := time.Date(2030, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectQuery("SELECT DISTINCT ON \\(order_id\\) id, booking_id, order_id, description, payment_method, price, status, payment_url FROM payments WHERE status = \\$1 AND payment_url <> '' AND created_at < \\$2 ORDER BY order_id, created_at").
+		WithArgs("unpaid", cutoff).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url"}).
+			AddRow("1", "1", "Repayment00001-1", "desc", "mid", 30000, "unpaid", "url"))
+
+	payments, err := suite.repo.FindStalePayments(cutoff)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(payments))
+	assert.Equal(suite.T(), "Repayment00001-1", payments[0].OrderId)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindStalePayments_Failed() {
+	suite.mockSql.ExpectQuery("SELECT DISTINCT ON \\(order_id\\) id, booking_id").
+		WillReturnError(errors.New("query error"))
+
+	_, err := suite.repo.FindStalePayments(time.Now())
+	assert.Error(suite.T(), err)
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateBooking_Conflict() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WithArgs(mockBooking.Court.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(mockBooking.Court.Id))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM court_closures").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings WHERE court_id = \\$1 AND booking_date = \\$2 AND start_time < \\$3 AND end_time > \\$4").
+		WithArgs(mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.EndTime, mockBooking.StartTime, "").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.Create(mockBooking)
+	assert.ErrorIs(suite.T(), err, ErrBookingConflict)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+// TestCreateBooking_ReservesUnderCourtLock checks that the court row is locked
+// before closures and overlapping bookings are counted, and that the booking is
+// inserted in that same transaction, so a concurrent request for the court has
+// to wait for the lock and then sees this booking.
+func (suite *BookingRepositoryTestSuite) TestCreateBooking_ReservesUnderCourtLock() {
+	suite.mockSql.MatchExpectationsInOrder(true)
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WithArgs(mockBooking.Court.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(mockBooking.Court.Id))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM court_closures").
+		WithArgs(mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.EndTime, mockBooking.StartTime).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings WHERE court_id = \\$1").
+		WithArgs(mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.EndTime, mockBooking.StartTime, "").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM waitlist_entries").
+		WithArgs(mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.EndTime, mockBooking.StartTime, model.WaitlistOffered, sqlmock.AnyArg(), mockBooking.Customer.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("INSERT INTO bookings").
+		WithArgs(mockBooking.Customer.Id, mockBooking.Court.Id, sqlmock.AnyArg(), mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, "pending").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).
+			AddRow(mockBooking.Id, mockBooking.Customer.Id, mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.StartTime, mockBooking.EndTime, mockBooking.Total_Payment, "pending"))
+	for _, mb := range mockBooking.PaymentDetails {
+		suite.mockSql.ExpectQuery("INSERT INTO payments").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "order_id", "description", "payment_method", "price", "status", "payment_url"}).
+				AddRow(mb.Id, mb.BookingId, mb.OrderId, mb.Description, mb.PaymentMethod, mb.Price, mb.Status, mb.PaymentURL))
+	}
+	suite.mockSql.ExpectCommit()
+
+	_, err := suite.repo.Create(mockBooking)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateBooking_SlotOffered() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(mockBooking.Court.Id))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM court_closures").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM waitlist_entries WHERE offered_court_id = \\$1 AND booking_date = \\$2 AND offered_start_time < \\$3 .* AND status = \\$5 AND offer_expires_at > \\$6 AND customer_id::text <> \\$7").
+		WithArgs(mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.EndTime, mockBooking.StartTime, model.WaitlistOffered, sqlmock.AnyArg(), mockBooking.Customer.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.Create(mockBooking)
+	assert.ErrorIs(suite.T(), err, ErrSlotOffered)
+	assert.ErrorIs(suite.T(), err, ErrBookingConflict)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestCreateBooking_CourtClosed() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WithArgs(mockBooking.Court.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(mockBooking.Court.Id))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM court_closures WHERE court_id = \\$1 AND start_date <= \\$2 AND end_date >= \\$2 AND start_time < \\$3 AND end_time > \\$4").
+		WithArgs(mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.EndTime, mockBooking.StartTime).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.Create(mockBooking)
+	assert.ErrorIs(suite.T(), err, ErrCourtClosed)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestReschedule_Conflict() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(mockBooking.Court.Id))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM court_closures").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings").
+		WithArgs(mockBooking.Court.Id, mockBooking.BookingDate, mockBooking.EndTime, mockBooking.StartTime, mockBooking.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.Reschedule(mockBooking)
+	assert.ErrorIs(suite.T(), err, ErrBookingConflict)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestChangeStatus_Success() {
+	suite.mockSql.ExpectBegin()
+	suite.expectTransition("1", model.BookingCancel, model.BookingRefunded)
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.ChangeStatus("1", model.BookingRefunded, "employee_1", "fully refunded")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestChangeStatus_IllegalMove() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT status, customer_id FROM bookings WHERE id = \\$1 FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "customer_id"}).AddRow("refunded", mockBooking.Customer.Id))
+	suite.mockSql.ExpectRollback()
+
+	err := suite.repo.ChangeStatus("1", model.BookingBooked, "employee_1", "")
+	assert.EqualError(suite.T(), err, "cannot move booking from refunded to booked")
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindByCustomer_Upcoming() {
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "name", "price", "employee_id", "booking_date", "start_time", "end_time", "total_payment", "status", "created_at", "updated_at"}).
+		AddRow("1", "customer_1", "court_1", "Court A", 50000, nil, time.Now(), time.Now(), time.Now(), 100000, "booked", time.Now(), time.Now())
+
+	suite.mockSql.ExpectQuery("SELECT b.id, b.customer_id, b.court_id, c.name, c.price, b.employee_id, b.booking_date, b.start_time, b.end_time, b.total_payment, b.status, b.created_at, b.updated_at FROM bookings b JOIN courts c ON c.id = b.court_id WHERE b.customer_id = \\$1 AND b.booking_date \\+ b.end_time > LOCALTIMESTAMP AND b.status IN \\('pending', 'booked', 'paid', 'checked_in', 'done'\\) ORDER BY b.booking_date, b.start_time LIMIT \\$2 OFFSET \\$3").
+		WithArgs("customer_1", 10, 0).
+		WillReturnRows(rows)
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings b WHERE b.customer_id = \\$1 AND b.booking_date \\+ b.end_time > LOCALTIMESTAMP").
+		WithArgs("customer_1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	bookings, paginate, err := suite.repo.FindByCustomer("customer_1", true, 1, 10)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(bookings))
+	assert.Equal(suite.T(), "Court A", bookings[0].Court.Name)
+	assert.Equal(suite.T(), dto.Paginate{Page: 1, Size: 10, TotalRows: 1, TotalPages: 1}, paginate)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindByCustomer_UpcomingPaidInFull() {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "name", "price", "employee_id", "booking_date", "start_time", "end_time", "total_payment", "status", "created_at", "updated_at"}).
+		AddRow("1", "customer_1", "court_1", "Court A", 50000, nil, tomorrow, tomorrow, tomorrow, 100000, "paid", time.Now(), time.Now())
+
+	suite.mockSql.ExpectQuery("FROM bookings b JOIN courts c ON c.id = b.court_id WHERE b.customer_id = \\$1 AND b.booking_date \\+ b.end_time > LOCALTIMESTAMP AND b.status IN \\(.*'paid'.*\\) ORDER BY b.booking_date, b.start_time").
+		WithArgs("customer_1", 10, 0).
+		WillReturnRows(rows)
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings b WHERE b.customer_id = \\$1 AND b.booking_date \\+ b.end_time > LOCALTIMESTAMP").
+		WithArgs("customer_1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	bookings, _, err := suite.repo.FindByCustomer("customer_1", true, 1, 10)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(bookings))
+	assert.Equal(suite.T(), model.BookingPaid, bookings[0].Status)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindByCustomer_Past() {
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "name", "price", "employee_id", "booking_date", "start_time", "end_time", "total_payment", "status", "created_at", "updated_at"}).
+		AddRow("1", "customer_1", "court_1", "Court A", 50000, "employee_1", time.Now(), time.Now(), time.Now(), 100000, "done", time.Now(), time.Now()).
+		AddRow("2", "customer_1", "court_1", "Court A", 50000, nil, time.Now(), time.Now(), time.Now(), 100000, "cancel", time.Now(), time.Now())
+
+	suite.mockSql.ExpectQuery("FROM bookings b JOIN courts c ON c.id = b.court_id WHERE b.customer_id = \\$1 AND NOT \\(b.booking_date \\+ b.end_time > LOCALTIMESTAMP AND b.status IN \\('pending', 'booked', 'paid', 'checked_in', 'done'\\)\\) ORDER BY b.booking_date DESC, b.start_time DESC LIMIT \\$2 OFFSET \\$3").
+		WithArgs("customer_1", 10, 10).
+		WillReturnRows(rows)
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings b WHERE b.customer_id = \\$1 AND NOT").
+		WithArgs("customer_1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	bookings, paginate, err := suite.repo.FindByCustomer("customer_1", false, 2, 10)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(bookings))
+	assert.Equal(suite.T(), "employee_1", bookings[0].Employee.Id)
+	assert.Equal(suite.T(), model.BookingCancel, bookings[1].Status)
+	assert.Equal(suite.T(), dto.Paginate{Page: 2, Size: 10, TotalRows: 12, TotalPages: 2}, paginate)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindByCustomer_QueryError() {
+	suite.mockSql.ExpectQuery("FROM bookings b JOIN courts c").
+		WillReturnError(errors.New("query error"))
+
+	_, _, err := suite.repo.FindByCustomer("customer_1", true, 1, 10)
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), "query error", err.Error())
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindStatusHistory_Success() {
+	suite.mockSql.ExpectQuery("SELECT id, booking_id, from_status, to_status, changed_by, reason, created_at FROM booking_status_history WHERE booking_id = \\$1 ORDER BY created_at").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "booking_id", "from_status", "to_status", "changed_by", "reason", "created_at"}).
+			AddRow("1", "1", "pending", "booked", "payment-gateway", "payment completed", time.Now()).
+			AddRow("2", "1", "booked", "cancel", "customer_1", "cancelled", time.Now()))
+
+	history, err := suite.repo.FindStatusHistory("1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(history))
+	assert.Equal(suite.T(), model.BookingCancel, history[1].ToStatus)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindStatusHistory_Failed() {
+	suite.mockSql.ExpectQuery("SELECT id, booking_id, from_status, to_status").
+		WillReturnError(errors.New("query error"))
+
+	_, err := suite.repo.FindStatusHistory("1")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *BookingRepositoryTestSuite) TestCheckInCode_Success() {
+	suite.mockSql.ExpectQuery("UPDATE bookings SET check_in_code = COALESCE\\(check_in_code, \\$1\\) WHERE id = \\$2 RETURNING check_in_code").
+		WithArgs("new-code", "1").
+		WillReturnRows(sqlmock.NewRows([]string{"check_in_code"}).AddRow("existing-code"))
+
+	code, err := suite.repo.CheckInCode("1", "new-code")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "existing-code", code)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindByCheckInCode_Success() {
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).
+		AddRow("1", "customer_1", "court_1", time.Now(), time.Now(), time.Now(), 100000, "booked")
+
+	suite.mockSql.ExpectQuery("SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE check_in_code = \\$1").
+		WithArgs("check-in-code").
+		WillReturnRows(rows)
+
+	booking, err := suite.repo.FindByCheckInCode("check-in-code")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", booking.Id)
+	assert.Equal(suite.T(), model.BookingBooked, booking.Status)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindByCheckInCode_NotFound() {
+	suite.mockSql.ExpectQuery("FROM bookings WHERE check_in_code = \\$1").
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.repo.FindByCheckInCode("unknown")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindMissedCheckIns_Success() {
+	cutoff := time.Date(2030, 10, 1, 9, 45, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).
+		AddRow("1", "customer_1", "court_1", time.Now(), time.Now(), time.Now(), 100000, "booked").
+		AddRow("2", "customer_2", "court_1", time.Now(), time.Now(), time.Now(), 100000, "paid")
+
+	suite.mockSql.ExpectQuery("FROM bookings b WHERE b.status IN \\(\\$1, \\$2\\) AND b.booking_date \\+ b.start_time < \\$3 AND NOT EXISTS \\(SELECT 1 FROM booking_status_history h WHERE h.booking_id = b.id AND h.to_status = \\$4\\)").
+		WithArgs(model.BookingBooked, model.BookingPaid, cutoff, model.BookingCheckedIn).
+		WillReturnRows(rows)
+
+	bookings, err := suite.repo.FindMissedCheckIns(cutoff)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(bookings))
+	assert.Equal(suite.T(), model.BookingPaid, bookings[1].Status)
+}
+
+func (suite *BookingRepositoryTestSuite) TestChangeStatus_CheckInPaidInFull() {
+	suite.mockSql.ExpectBegin()
+	suite.expectTransition("1", model.BookingPaid, model.BookingCheckedIn)
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.ChangeStatus("1", model.BookingCheckedIn, "employee_1", "checked in at the desk")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestChangeStatus_NoShowPaidInFull() {
+	suite.mockSql.ExpectBegin()
+	suite.expectTransition("1", model.BookingPaid, model.BookingNoShow)
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.ChangeStatus("1", model.BookingNoShow, "system", "not checked in within the grace period")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestChangeStatus_NoShowSettledAtDesk() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT status, customer_id FROM bookings WHERE id = \\$1 FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "customer_id"}).AddRow("done", mockBooking.Customer.Id))
+	suite.mockSql.ExpectRollback()
+
+	err := suite.repo.ChangeStatus("1", model.BookingNoShow, "system", "not checked in within the grace period")
+	assert.EqualError(suite.T(), err, "cannot move booking from done to no_show")
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *BookingRepositoryTestSuite) TestFindMissedCheckIns_QueryError() {
+	suite.mockSql.ExpectQuery("FROM bookings b WHERE b.status IN").
+		WillReturnError(errors.New("query error"))
+
+	_, err := suite.repo.FindMissedCheckIns(time.Now())
+	assert.EqualError(suite.T(), err, "query error")
+}
+
+func (suite *BookingRepositoryTestSuite) TestCountNoShows_Success() {
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings WHERE customer_id = \\$1 AND status = \\$2").
+		WithArgs("customer_1", model.BookingNoShow).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := suite.repo.CountNoShows("customer_1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, count)
+}
+
+// courtLockDB is a database/sql driver that behaves like the court row lock in
+// postgres: SELECT ... FOR UPDATE on the court blocks until the transaction
+// holding it commits or rolls back, and a booking only becomes visible to the
+// overlap count once its transaction committed.
+type courtLockDB struct {
+	lock   sync.Mutex
+	mu     sync.Mutex
+	booked int
+	nextId int
+}
+
+func (d *courtLockDB) Connect(context.Context) (driver.Conn, error) {
+	return &courtLockConn{db: d}, nil
+}
+
+func (d *courtLockDB) Driver() driver.Driver {
+	return d
+}
+
+func (d *courtLockDB) Open(string) (driver.Conn, error) {
+	return &courtLockConn{db: d}, nil
+}
+
+type courtLockConn struct {
+	db       *courtLockDB
+	locked   bool
+	inserted bool
+}
+
+func (c *courtLockConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("unexpected prepare: %s", query)
+}
+
+func (c *courtLockConn) Close() error {
+	return nil
+}
+
+func (c *courtLockConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *courtLockConn) Commit() error {
+	if c.inserted {
+		c.db.mu.Lock()
+		c.db.booked++
+		c.db.mu.Unlock()
+	}
+	return c.Rollback()
+}
+
+func (c *courtLockConn) Rollback() error {
+	if c.locked {
+		c.db.lock.Unlock()
+	}
+	c.locked, c.inserted = false, false
+	return nil
+}
+
+func (c *courtLockConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	arg := func(i int) driver.Value { return args[i].Value }
+
+	switch {
+	case strings.HasPrefix(query, "SELECT id FROM courts WHERE id = $1 FOR UPDATE"):
+		c.db.lock.Lock()
+		c.locked = true
+		return &fakeRows{cols: []string{"id"}, vals: []driver.Value{arg(0)}}, nil
+	case strings.HasPrefix(query, "SELECT COUNT(*) FROM bookings WHERE court_id = $1"):
+		c.db.mu.Lock()
+		booked := c.db.booked
+		c.db.mu.Unlock()
+		// Give a request that skipped the lock time to count the same slot.
+		time.Sleep(time.Millisecond)
+		return &fakeRows{cols: []string{"count"}, vals: []driver.Value{int64(booked)}}, nil
+	case strings.HasPrefix(query, "SELECT COUNT(*)"):
+		return &fakeRows{cols: []string{"count"}, vals: []driver.Value{int64(0)}}, nil
+	case strings.HasPrefix(query, "INSERT INTO bookings"):
+		c.db.mu.Lock()
+		c.db.nextId++
+		id := fmt.Sprint(c.db.nextId)
+		c.db.mu.Unlock()
+		c.inserted = true
+		return &fakeRows{cols: make([]string, 8), vals: []driver.Value{id, arg(0), arg(1), arg(3), arg(4), arg(5), arg(6), arg(7)}}, nil
+	case strings.HasPrefix(query, "INSERT INTO payments"):
+		return &fakeRows{cols: make([]string, 8), vals: []driver.Value{"1", arg(0), arg(1), arg(2), arg(3), arg(4), arg(5), arg(6)}}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+type fakeRows struct {
+	cols []string
+	vals []driver.Value
+	done bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.cols
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.vals)
+	return nil
+}
+
+// TestCreate_ConcurrentRequests fires several bookings for the same court and
+// time at once. The court lock makes them reserve the slot one after another,
+// so exactly one of them gets it and every other sees the conflict.
+func TestCreate_ConcurrentRequests(t *testing.T) {
+	const requests = 10
+
+	fake := &courtLockDB{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	repo := NewBookingRepository(db)
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, requests)
+
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, errs[i] = repo.Create(mockBooking)
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrBookingConflict)
+	}
+
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, fake.booked)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"math"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
@@ -14,17 +15,74 @@ type CourtRepository interface {
 	FindById(id string) (model.Court, error)
 	Update(id string, payload model.Court) (model.Court, error)
 	Deleted(id string) error
+	CreateClosure(payload model.CourtClosure) (model.CourtClosure, error)
+	FindClosures(courtId string) ([]model.CourtClosure, error)
+	FindClosuresBetween(startDate, endDate time.Time) ([]model.CourtClosure, error)
+	DeleteClosure(courtId string, closureId string) error
+	FindConflictingBookings(closure model.CourtClosure) ([]model.Booking, error)
 }
 
 type courtRepository struct {
 	DB *sql.DB
 }
 
-func (r *courtRepository) Create(payload model.Court) (model.Court, error) {
+const courtColumns = "id, name, price, opening_hours, COALESCE(min_booking_hours, 0), COALESCE(max_booking_hours, 0), COALESCE(slot_minutes, 0), COALESCE(deposit_percent, 0), created_at, updated_at"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCourt(row rowScanner) (model.Court, error) {
 	var court model.Court
+	var openingHours sql.NullString
+
+	err := row.Scan(
+		&court.Id,
+		&court.Name,
+		&court.Price,
+		&openingHours,
+		&court.MinBookingHours,
+		&court.MaxBookingHours,
+		&court.SlotMinutes,
+		&court.DepositPercent,
+		&court.CreatedAt,
+		&court.UpdatedAt,
+	)
+	if err != nil {
+		return model.Court{}, err
+	}
+
+	if openingHours.Valid && openingHours.String != "" {
+		if err := json.Unmarshal([]byte(openingHours.String), &court.OpeningHours); err != nil {
+			return model.Court{}, err
+		}
+	}
+
+	return court, nil
+}
+
+func marshalOpeningHours(openingHours []model.OpeningHour) (sql.NullString, error) {
+	if len(openingHours) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(openingHours)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func (r *courtRepository) Create(payload model.Court) (model.Court, error) {
+	openingHours, err := marshalOpeningHours(payload.OpeningHours)
+	if err != nil {
+		return model.Court{}, err
+	}
 
-	err := r.DB.QueryRow("INSERT INTO courts (name, price) VALUES ($1, $2) RETURNING id, name, price, created_at, updated_at", payload.Name, payload.Price).Scan(&court.Id, &court.Name, &court.Price, &court.CreatedAt, &court.UpdatedAt)
+	query := "INSERT INTO courts (name, price, opening_hours, min_booking_hours, max_booking_hours, slot_minutes, deposit_percent) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING " + courtColumns
 
+	court, err := scanCourt(r.DB.QueryRow(query, payload.Name, payload.Price, openingHours, payload.MinBookingHours, payload.MaxBookingHours, payload.SlotMinutes, payload.DepositPercent))
 	if err != nil {
 		return model.Court{}, err
 	}
@@ -38,15 +96,15 @@ func (r *courtRepository) FindAll(page int, size int) ([]model.Court, dto.Pagina
 	// rumus pagination
 	offset := (page - 1) * size
 
-	rows, err := r.DB.Query("SELECT id, name, price, created_at, updated_at FROM courts LIMIT $1 OFFSET $2", size, offset)
+	rows, err := r.DB.Query("SELECT "+courtColumns+" FROM courts LIMIT $1 OFFSET $2", size, offset)
 	if err != nil {
 		return []model.Court{}, dto.Paginate{}, err
 	}
 
 	totalRows := 0
 	for rows.Next() {
-		var c model.Court
-		if err := rows.Scan(&c.Id, &c.Name, &c.Price, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		c, err := scanCourt(rows)
+		if err != nil {
 			return []model.Court{}, dto.Paginate{}, err
 		}
 		courts = append(courts, c)
@@ -64,9 +122,7 @@ func (r *courtRepository) FindAll(page int, size int) ([]model.Court, dto.Pagina
 }
 
 func (r *courtRepository) FindById(id string) (model.Court, error) {
-	var court model.Court
-
-	err := r.DB.QueryRow("SELECT id, name, price, created_at, updated_at FROM courts WHERE id = $1", id).Scan(&court.Id, &court.Name, &court.Price, &court.CreatedAt, &court.UpdatedAt)
+	court, err := scanCourt(r.DB.QueryRow("SELECT "+courtColumns+" FROM courts WHERE id = $1", id))
 	if err != nil {
 		return model.Court{}, err
 	}
@@ -75,9 +131,14 @@ func (r *courtRepository) FindById(id string) (model.Court, error) {
 }
 
 func (r *courtRepository) Update(id string, payload model.Court) (model.Court, error) {
-	var court model.Court
+	openingHours, err := marshalOpeningHours(payload.OpeningHours)
+	if err != nil {
+		return model.Court{}, err
+	}
 
-	err := r.DB.QueryRow("UPDATE courts SET name = $1, price = $2, updated_at = $3 WHERE id = $4 RETURNING id, name, price, created_at, updated_at", payload.Name, payload.Price, time.Now(), id).Scan(&court.Id, &court.Name, &court.Price, &court.CreatedAt, &court.UpdatedAt)
+	query := "UPDATE courts SET name = $1, price = $2, opening_hours = $3, min_booking_hours = $4, max_booking_hours = $5, slot_minutes = $6, deposit_percent = $7, updated_at = $8 WHERE id = $9 RETURNING " + courtColumns
+
+	court, err := scanCourt(r.DB.QueryRow(query, payload.Name, payload.Price, openingHours, payload.MinBookingHours, payload.MaxBookingHours, payload.SlotMinutes, payload.DepositPercent, time.Now(), id))
 	if err != nil {
 		return model.Court{}, err
 	}
@@ -93,6 +154,147 @@ func (r *courtRepository) Deleted(id string) error {
 	return nil
 }
 
+const closureColumns = "id, court_id, start_date, end_date, start_time, end_time, reason, created_at, updated_at"
+
+func scanClosure(row rowScanner) (model.CourtClosure, error) {
+	var closure model.CourtClosure
+
+	err := row.Scan(
+		&closure.Id,
+		&closure.CourtId,
+		&closure.StartDate,
+		&closure.EndDate,
+		&closure.StartTime,
+		&closure.EndTime,
+		&closure.Reason,
+		&closure.CreatedAt,
+		&closure.UpdatedAt,
+	)
+	if err != nil {
+		return model.CourtClosure{}, err
+	}
+
+	return closure, nil
+}
+
+// CreateClosure takes the same court-row lock as a booking, so a booking that
+// is being made while the closure is inserted either lands before it and is
+// reported as conflicting, or sees the closure and is refused.
+func (r *courtRepository) CreateClosure(payload model.CourtClosure) (model.CourtClosure, error) {
+	transaction, err := r.DB.Begin()
+	if err != nil {
+		return model.CourtClosure{}, err
+	}
+
+	var lockedId string
+	err = transaction.QueryRow("SELECT id FROM courts WHERE id = $1 FOR UPDATE", payload.CourtId).Scan(&lockedId)
+	if err != nil {
+		transaction.Rollback()
+		return model.CourtClosure{}, err
+	}
+
+	query := "INSERT INTO court_closures (court_id, start_date, end_date, start_time, end_time, reason) VALUES ($1, $2, $3, $4, $5, $6) RETURNING " + closureColumns
+
+	closure, err := scanClosure(transaction.QueryRow(query, payload.CourtId, payload.StartDate, payload.EndDate, payload.StartTime, payload.EndTime, payload.Reason))
+	if err != nil {
+		transaction.Rollback()
+		return model.CourtClosure{}, err
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return model.CourtClosure{}, err
+	}
+
+	return closure, nil
+}
+
+func (r *courtRepository) FindClosures(courtId string) ([]model.CourtClosure, error) {
+	var closures []model.CourtClosure
+
+	rows, err := r.DB.Query("SELECT "+closureColumns+" FROM court_closures WHERE court_id = $1 ORDER BY start_date, start_time", courtId)
+	if err != nil {
+		return []model.CourtClosure{}, err
+	}
+
+	for rows.Next() {
+		closure, err := scanClosure(rows)
+		if err != nil {
+			return []model.CourtClosure{}, err
+		}
+		closures = append(closures, closure)
+	}
+
+	return closures, nil
+}
+
+func (r *courtRepository) FindClosuresBetween(startDate, endDate time.Time) ([]model.CourtClosure, error) {
+	var closures []model.CourtClosure
+
+	rows, err := r.DB.Query("SELECT "+closureColumns+" FROM court_closures WHERE start_date <= $1 AND end_date >= $2", endDate, startDate)
+	if err != nil {
+		return []model.CourtClosure{}, err
+	}
+
+	for rows.Next() {
+		closure, err := scanClosure(rows)
+		if err != nil {
+			return []model.CourtClosure{}, err
+		}
+		closures = append(closures, closure)
+	}
+
+	return closures, nil
+}
+
+func (r *courtRepository) DeleteClosure(courtId string, closureId string) error {
+	result, err := r.DB.Exec("DELETE FROM court_closures WHERE id = $1 AND court_id = $2", closureId, courtId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *courtRepository) FindConflictingBookings(closure model.CourtClosure) ([]model.Booking, error) {
+	var bookings []model.Booking
+
+	query := "SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE court_id = $1 AND booking_date BETWEEN $2 AND $3 AND start_time < $4 AND end_time > $5 AND status IN " + activeStatuses + " ORDER BY booking_date, start_time"
+
+	rows, err := r.DB.Query(query, closure.CourtId, closure.StartDate, closure.EndDate, closure.EndTime, closure.StartTime)
+	if err != nil {
+		return []model.Booking{}, err
+	}
+
+	for rows.Next() {
+		var b model.Booking
+		if err := rows.Scan(
+			&b.Id,
+			&b.Customer.Id,
+			&b.Court.Id,
+			&b.BookingDate,
+			&b.StartTime,
+			&b.EndTime,
+			&b.Total_Payment,
+			&b.Status,
+		); err != nil {
+			return []model.Booking{}, err
+		}
+		bookings = append(bookings, b)
+	}
+
+	return bookings, nil
+}
+
 func NewCourtRepository(db *sql.DB) CourtRepository {
 	return &courtRepository{
 		DB: db,
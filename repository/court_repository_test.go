@@ -23,6 +23,8 @@ var mockCourt = model.Court{
 	UpdatedAt: time.Time{},
 }
 
+var courtRowColumns = []string{"id", "name", "price", "opening_hours", "min_booking_hours", "max_booking_hours", "slot_minutes", "deposit_percent", "created_at", "updated_at"}
+
 type CourtRepositoryTestSuite struct {
 	suite.Suite
 	mockDb  *sql.DB
@@ -44,9 +46,9 @@ func TestCourtRepositoryTestSuite(t *testing.T) {
 
 func (suite *CourtRepositoryTestSuite) TestCreateCourt_Success() {
 	suite.mockSql.ExpectQuery("INSERT INTO courts").
-		WithArgs(mockCourt.Name, mockCourt.Price).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "created_at", "updated_at"}).
-			AddRow(mockCourt.Id, mockCourt.Name, mockCourt.Price, mockCourt.CreatedAt, mockCourt.UpdatedAt))
+		WithArgs(mockCourt.Name, mockCourt.Price, nil, 0, 0, 0, 0).
+		WillReturnRows(sqlmock.NewRows(courtRowColumns).
+			AddRow(mockCourt.Id, mockCourt.Name, mockCourt.Price, nil, 0, 0, 0, 0, mockCourt.CreatedAt, mockCourt.UpdatedAt))
 
 	actual, err := suite.repo.Create(mockCourt)
 	assert.NoError(suite.T(), err)
@@ -55,7 +57,7 @@ func (suite *CourtRepositoryTestSuite) TestCreateCourt_Success() {
 
 func (suite *CourtRepositoryTestSuite) TestCreateCourt_Failed() {
 	suite.mockSql.ExpectQuery("INSERT INTO courts").
-		WithArgs(mockCourt.Name, mockCourt.Price).
+		WithArgs(mockCourt.Name, mockCourt.Price, nil, 0, 0, 0, 0).
 		WillReturnError(errors.New("insert failed"))
 
 	_, err := suite.repo.Create(mockCourt)
@@ -67,10 +69,10 @@ func (suite *CourtRepositoryTestSuite) TestFindAll_Success() {
 	size := 10
 	offset := (page - 1) * size
 
-	suite.mockSql.ExpectQuery("SELECT id, name, price, created_at, updated_at ").
+	suite.mockSql.ExpectQuery("SELECT id, name, price, opening_hours").
 		WithArgs(size, offset).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "created_at", "updated_at"}).
-			AddRow(mockCourt.Id, mockCourt.Name, mockCourt.Price, mockCourt.CreatedAt, mockCourt.UpdatedAt))
+		WillReturnRows(sqlmock.NewRows(courtRowColumns).
+			AddRow(mockCourt.Id, mockCourt.Name, mockCourt.Price, nil, 0, 0, 0, 0, mockCourt.CreatedAt, mockCourt.UpdatedAt))
 
 	actual, _, err := suite.repo.FindAll(page, size)
 
@@ -85,7 +87,7 @@ func (suite *CourtRepositoryTestSuite) TestFindAll_Failed() {
 	size := 10
 	offset := (page - 1) * size
 
-	suite.mockSql.ExpectQuery("SELECT id, name, price, created_at, updated_at ").
+	suite.mockSql.ExpectQuery("SELECT id, name, price, opening_hours").
 		WithArgs(page, size, offset).
 		WillReturnError(fmt.Errorf("database error"))
 
@@ -101,10 +103,10 @@ func (suite *CourtRepositoryTestSuite) TestFindAll_ScanError() {
 	size := 10
 	offset := (page - 1) * size
 
-	suite.mockSql.ExpectQuery(regexp.QuoteMeta("SELECT id, name, price, created_at, updated_at FROM courts LIMIT $1 OFFSET $2")).
+	suite.mockSql.ExpectQuery(regexp.QuoteMeta("SELECT id, name, price, opening_hours, COALESCE(min_booking_hours, 0), COALESCE(max_booking_hours, 0), COALESCE(slot_minutes, 0), COALESCE(deposit_percent, 0), created_at, updated_at FROM courts LIMIT $1 OFFSET $2")).
 		WithArgs(size, offset).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "created_at", "updated_at"}).
-			AddRow(mockCourt.Id, mockCourt.Name, "invalid_price", mockCourt.CreatedAt, mockCourt.UpdatedAt))
+		WillReturnRows(sqlmock.NewRows(courtRowColumns).
+			AddRow(mockCourt.Id, mockCourt.Name, "invalid_price", nil, 0, 0, 0, 0, mockCourt.CreatedAt, mockCourt.UpdatedAt))
 
 	actual, paginate, err := suite.repo.FindAll(page, size)
 
@@ -116,8 +118,8 @@ func (suite *CourtRepositoryTestSuite) TestFindAll_ScanError() {
 func (suite *CourtRepositoryTestSuite) TestFindById_Success() {
 	suite.mockSql.ExpectQuery("SELECT").
 		WithArgs(mockCourt.Id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "created_at", "updated_at"}).
-			AddRow(mockCourt.Id, mockCourt.Name, mockCourt.Price, mockCourt.CreatedAt, mockCourt.UpdatedAt))
+		WillReturnRows(sqlmock.NewRows(courtRowColumns).
+			AddRow(mockCourt.Id, mockCourt.Name, mockCourt.Price, nil, 0, 0, 0, 0, mockCourt.CreatedAt, mockCourt.UpdatedAt))
 
 	actual, err := suite.repo.FindById(mockCourt.Id)
 	assert.NoError(suite.T(), err)
@@ -137,8 +139,8 @@ func (suite *CourtRepositoryTestSuite) TestUpdateCourt_Success() {
 	mockUpdatedAt := time.Now()
 	suite.mockSql.ExpectQuery("UPDATE courts SET ").
 		WithArgs(mockCourt.Name, mockCourt.Price, mockUpdatedAt, mockCourt.Id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "created_at", "updated_at"}).
-			AddRow(mockCourt.Id, mockCourt.Name, mockCourt.Price, mockCourt.CreatedAt, mockUpdatedAt))
+		WillReturnRows(sqlmock.NewRows(courtRowColumns).
+			AddRow(mockCourt.Id, mockCourt.Name, mockCourt.Price, nil, 0, 0, 0, 0, mockCourt.CreatedAt, mockUpdatedAt))
 
 	mockCourt.UpdatedAt = mockUpdatedAt
 
@@ -178,3 +180,113 @@ func (suite *CourtRepositoryTestSuite) TestDeleteUser_Failed() {
 	assert.EqualError(suite.T(), err, "delete failed")
 
 }
+
+func (suite *CourtRepositoryTestSuite) TestCreateCourt_WithSchedule() {
+	court := model.Court{
+		Name:            "field 2",
+		Price:           40000,
+		OpeningHours:    []model.OpeningHour{{Weekday: 1, OpenTime: "07:00:00", CloseTime: "22:00:00"}},
+		MinBookingHours: 1,
+		MaxBookingHours: 3,
+		SlotMinutes:     30,
+		DepositPercent:  100,
+	}
+	openingHours := `[{"weekday":1,"openTime":"07:00:00","closeTime":"22:00:00"}]`
+
+	suite.mockSql.ExpectQuery("INSERT INTO courts \\(name, price, opening_hours, min_booking_hours, max_booking_hours, slot_minutes, deposit_percent\\)").
+		WithArgs(court.Name, court.Price, openingHours, 1, 3, 30, 100).
+		WillReturnRows(sqlmock.NewRows(courtRowColumns).
+			AddRow("2", court.Name, court.Price, openingHours, 1, 3, 30, 100, time.Time{}, time.Time{}))
+
+	actual, err := suite.repo.Create(court)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), court.OpeningHours, actual.OpeningHours)
+	assert.Equal(suite.T(), 30, actual.SlotMinutes)
+	assert.Equal(suite.T(), 100, actual.DepositPercent)
+}
+
+func (suite *CourtRepositoryTestSuite) TestFindById_InvalidOpeningHours() {
+	suite.mockSql.ExpectQuery("SELECT").
+		WithArgs(mockCourt.Id).
+		WillReturnRows(sqlmock.NewRows(courtRowColumns).
+			AddRow(mockCourt.Id, mockCourt.Name, mockCourt.Price, "not json", 0, 0, 0, 0, mockCourt.CreatedAt, mockCourt.UpdatedAt))
+
+	_, err := suite.repo.FindById(mockCourt.Id)
+	assert.Error(suite.T(), err)
+}
+
+var closureRowColumns = []string{"id", "court_id", "start_date", "end_date", "start_time", "end_time", "reason", "created_at", "updated_at"}
+
+var mockClosure = model.CourtClosure{
+	Id:        "closure_1",
+	CourtId:   "1",
+	StartDate: time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC),
+	EndDate:   time.Date(2030, 1, 3, 0, 0, 0, 0, time.UTC),
+	StartTime: time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC),
+	EndTime:   time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC),
+	Reason:    "resurfacing",
+}
+
+func (suite *CourtRepositoryTestSuite) TestCreateClosure_Success() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WithArgs(mockClosure.CourtId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(mockClosure.CourtId))
+	suite.mockSql.ExpectQuery("INSERT INTO court_closures").
+		WithArgs(mockClosure.CourtId, mockClosure.StartDate, mockClosure.EndDate, mockClosure.StartTime, mockClosure.EndTime, mockClosure.Reason).
+		WillReturnRows(sqlmock.NewRows(closureRowColumns).
+			AddRow(mockClosure.Id, mockClosure.CourtId, mockClosure.StartDate, mockClosure.EndDate, mockClosure.StartTime, mockClosure.EndTime, mockClosure.Reason, time.Time{}, time.Time{}))
+
+	suite.mockSql.ExpectCommit()
+
+	actual, err := suite.repo.CreateClosure(mockClosure)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockClosure, actual)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *CourtRepositoryTestSuite) TestCreateClosure_CourtNotFound() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WithArgs(mockClosure.CourtId).
+		WillReturnError(sql.ErrNoRows)
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.CreateClosure(mockClosure)
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *CourtRepositoryTestSuite) TestFindClosuresBetween_Success() {
+	suite.mockSql.ExpectQuery("SELECT id, court_id, start_date, end_date, start_time, end_time, reason, created_at, updated_at FROM court_closures WHERE start_date <= \\$1 AND end_date >= \\$2").
+		WithArgs(mockClosure.EndDate, mockClosure.StartDate).
+		WillReturnRows(sqlmock.NewRows(closureRowColumns).
+			AddRow(mockClosure.Id, mockClosure.CourtId, mockClosure.StartDate, mockClosure.EndDate, mockClosure.StartTime, mockClosure.EndTime, mockClosure.Reason, time.Time{}, time.Time{}))
+
+	actual, err := suite.repo.FindClosuresBetween(mockClosure.StartDate, mockClosure.EndDate)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), actual, 1)
+}
+
+func (suite *CourtRepositoryTestSuite) TestDeleteClosure_NotFound() {
+	suite.mockSql.ExpectExec("DELETE FROM court_closures WHERE id = \\$1 AND court_id = \\$2").
+		WithArgs("closure_1", "1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.DeleteClosure("1", "closure_1")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *CourtRepositoryTestSuite) TestFindConflictingBookings_Success() {
+	suite.mockSql.ExpectQuery("SELECT id, customer_id, court_id, booking_date, start_time, end_time, total_payment, status FROM bookings WHERE court_id = \\$1 .* AND status IN \\('pending', 'booked', 'paid', 'checked_in', 'done'\\)").
+		WithArgs(mockClosure.CourtId, mockClosure.StartDate, mockClosure.EndDate, mockClosure.EndTime, mockClosure.StartTime).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "total_payment", "status"}).
+			AddRow("booking_1", "customer_1", "1", mockClosure.StartDate, mockClosure.StartTime, mockClosure.EndTime, 60000, "booked").
+			AddRow("booking_2", "customer_2", "1", mockClosure.StartDate, mockClosure.StartTime, mockClosure.EndTime, 60000, "done"))
+
+	actual, err := suite.repo.FindConflictingBookings(mockClosure)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), actual, 2)
+	assert.Equal(suite.T(), "customer_1", actual[0].Customer.Id)
+	assert.Equal(suite.T(), model.BookingDone, actual[1].Status)
+}
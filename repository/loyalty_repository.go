@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"time"
+)
+
+var ErrInsufficientPoints = errors.New("cannot book, not enough loyalty points")
+
+type LoyaltyRepository interface {
+	FindSetting() (model.LoyaltySetting, error)
+	SaveSetting(payload model.LoyaltySetting) (model.LoyaltySetting, error)
+	CreateEntry(payload model.PointEntry) (model.PointEntry, error)
+	FindEntries(customerId string, page int, size int) ([]model.PointEntry, dto.Paginate, error)
+	FindBalance(customerId string) (int, error)
+	ExpirePoints(customerId string, now time.Time) (int, error)
+	FindRedemptionByBookingId(bookingId string) (model.PointEntry, error)
+}
+
+type loyaltyRepository struct {
+	DB *sql.DB
+}
+
+const pointEntryColumns = "id, customer_id, booking_id, type, points, amount, description, expires_at, created_at"
+
+func scanPointEntry(row rowScanner) (model.PointEntry, error) {
+	var entry model.PointEntry
+	var bookingId sql.NullString
+	var expiresAt sql.NullTime
+
+	err := row.Scan(
+		&entry.Id,
+		&entry.CustomerId,
+		&bookingId,
+		&entry.Type,
+		&entry.Points,
+		&entry.Amount,
+		&entry.Description,
+		&expiresAt,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return model.PointEntry{}, err
+	}
+
+	entry.BookingId = bookingId.String
+	entry.ExpiresAt = expiresAt.Time
+
+	return entry, nil
+}
+
+func (r *loyaltyRepository) FindSetting() (model.LoyaltySetting, error) {
+	return findLoyaltySetting(r.DB)
+}
+
+func (r *loyaltyRepository) SaveSetting(payload model.LoyaltySetting) (model.LoyaltySetting, error) {
+	var setting model.LoyaltySetting
+
+	query := "INSERT INTO loyalty_settings (id, earn_points, earn_amount, redeem_points, redeem_value, expiry_days, updated_at) VALUES (1, $1, $2, $3, $4, $5, $6) ON CONFLICT (id) DO UPDATE SET earn_points = EXCLUDED.earn_points, earn_amount = EXCLUDED.earn_amount, redeem_points = EXCLUDED.redeem_points, redeem_value = EXCLUDED.redeem_value, expiry_days = EXCLUDED.expiry_days, updated_at = EXCLUDED.updated_at RETURNING earn_points, earn_amount, redeem_points, redeem_value, expiry_days, updated_at"
+
+	err := r.DB.QueryRow(query, payload.EarnPoints, payload.EarnAmount, payload.RedeemPoints, payload.RedeemValue, payload.ExpiryDays, time.Now()).Scan(
+		&setting.EarnPoints,
+		&setting.EarnAmount,
+		&setting.RedeemPoints,
+		&setting.RedeemValue,
+		&setting.ExpiryDays,
+		&setting.UpdatedAt,
+	)
+	if err != nil {
+		return model.LoyaltySetting{}, err
+	}
+
+	return setting, nil
+}
+
+func (r *loyaltyRepository) CreateEntry(payload model.PointEntry) (model.PointEntry, error) {
+	transaction, _ := r.DB.Begin()
+
+	entry, err := insertPointEntry(transaction, payload)
+	if err != nil {
+		transaction.Rollback()
+		return model.PointEntry{}, err
+	}
+
+	transaction.Commit()
+	return entry, nil
+}
+
+func (r *loyaltyRepository) FindEntries(customerId string, page int, size int) ([]model.PointEntry, dto.Paginate, error) {
+	var entries []model.PointEntry
+
+	offset := (page - 1) * size
+
+	rows, err := r.DB.Query("SELECT "+pointEntryColumns+" FROM point_entries WHERE customer_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3", customerId, size, offset)
+	if err != nil {
+		return []model.PointEntry{}, dto.Paginate{}, err
+	}
+
+	for rows.Next() {
+		entry, err := scanPointEntry(rows)
+		if err != nil {
+			return []model.PointEntry{}, dto.Paginate{}, err
+		}
+		entries = append(entries, entry)
+	}
+
+	var totalRows int
+	err = r.DB.QueryRow("SELECT COUNT(*) FROM point_entries WHERE customer_id = $1", customerId).Scan(&totalRows)
+	if err != nil {
+		return []model.PointEntry{}, dto.Paginate{}, err
+	}
+
+	paginate := dto.Paginate{
+		Page:       page,
+		Size:       size,
+		TotalRows:  totalRows,
+		TotalPages: int(math.Ceil(float64(totalRows) / float64(size))),
+	}
+
+	return entries, paginate, nil
+}
+
+func (r *loyaltyRepository) FindBalance(customerId string) (int, error) {
+	var points int
+
+	err := r.DB.QueryRow("SELECT points FROM users WHERE id = $1", customerId).Scan(&points)
+	if err != nil {
+		return 0, err
+	}
+
+	return points, nil
+}
+
+// ExpirePoints writes an expire entry for the earned points of a customer
+// that passed their expiry date. Redeemed and expired points are taken from
+// the oldest earned points first, so only what is left of them expires.
+func (r *loyaltyRepository) ExpirePoints(customerId string, now time.Time) (int, error) {
+	transaction, _ := r.DB.Begin()
+
+	balance, err := lockPoints(transaction, customerId)
+	if err != nil {
+		transaction.Rollback()
+		return 0, err
+	}
+
+	var expired int
+	query := "SELECT COALESCE(SUM(CASE WHEN type = 'earn' AND expires_at <= $2 THEN points WHEN type IN ('redeem', 'expire') THEN points WHEN type = 'adjust' AND points < 0 THEN points ELSE 0 END), 0) FROM point_entries WHERE customer_id = $1"
+
+	err = transaction.QueryRow(query, customerId, now).Scan(&expired)
+	if err != nil {
+		transaction.Rollback()
+		return 0, err
+	}
+
+	if expired > balance {
+		expired = balance
+	}
+
+	if expired <= 0 {
+		transaction.Rollback()
+		return 0, nil
+	}
+
+	_, err = insertPointEntry(transaction, model.PointEntry{
+		CustomerId:  customerId,
+		Type:        "expire",
+		Points:      -expired,
+		Description: "Points expired",
+	})
+	if err != nil {
+		transaction.Rollback()
+		return 0, err
+	}
+
+	transaction.Commit()
+	return expired, nil
+}
+
+func (r *loyaltyRepository) FindRedemptionByBookingId(bookingId string) (model.PointEntry, error) {
+	entry, err := scanPointEntry(r.DB.QueryRow("SELECT "+pointEntryColumns+" FROM point_entries WHERE booking_id = $1 AND type = 'redeem' AND points < 0 ORDER BY created_at LIMIT 1", bookingId))
+	if err != nil {
+		return model.PointEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// findLoyaltySetting returns the saved setting, or the default one when no
+// admin has saved a setting yet.
+func findLoyaltySetting(q queryRower) (model.LoyaltySetting, error) {
+	var setting model.LoyaltySetting
+
+	err := q.QueryRow("SELECT earn_points, earn_amount, redeem_points, redeem_value, expiry_days, updated_at FROM loyalty_settings WHERE id = 1").Scan(
+		&setting.EarnPoints,
+		&setting.EarnAmount,
+		&setting.RedeemPoints,
+		&setting.RedeemValue,
+		&setting.ExpiryDays,
+		&setting.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.DefaultLoyaltySetting, nil
+		}
+		return model.LoyaltySetting{}, err
+	}
+
+	return setting, nil
+}
+
+// lockPoints locks the customer row so ledger entries of the same customer
+// are written one at a time, and returns the current balance.
+func lockPoints(transaction *sql.Tx, customerId string) (int, error) {
+	var points int
+
+	err := transaction.QueryRow("SELECT points FROM users WHERE id = $1 FOR UPDATE", customerId).Scan(&points)
+	if err != nil {
+		return 0, err
+	}
+
+	return points, nil
+}
+
+// insertPointEntry adds a ledger entry and moves users.points with it, so the
+// balance always equals the sum of the ledger.
+func insertPointEntry(transaction *sql.Tx, payload model.PointEntry) (model.PointEntry, error) {
+	balance, err := lockPoints(transaction, payload.CustomerId)
+	if err != nil {
+		return model.PointEntry{}, err
+	}
+
+	if balance+payload.Points < 0 {
+		return model.PointEntry{}, ErrInsufficientPoints
+	}
+
+	bookingId := sql.NullString{String: payload.BookingId, Valid: payload.BookingId != ""}
+	expiresAt := sql.NullTime{Time: payload.ExpiresAt, Valid: !payload.ExpiresAt.IsZero()}
+
+	query := "INSERT INTO point_entries (customer_id, booking_id, type, points, amount, description, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING " + pointEntryColumns
+
+	entry, err := scanPointEntry(transaction.QueryRow(query, payload.CustomerId, bookingId, payload.Type, payload.Points, payload.Amount, payload.Description, expiresAt))
+	if err != nil {
+		return model.PointEntry{}, err
+	}
+
+	_, err = transaction.Exec("UPDATE users SET points = points + $1 WHERE id = $2", payload.Points, payload.CustomerId)
+	if err != nil {
+		return model.PointEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// earnPoints credits the customer of a completed booking using the current
+// loyalty setting.
+func earnPoints(transaction *sql.Tx, customerId string, bookingId string) error {
+	setting, err := findLoyaltySetting(transaction)
+	if err != nil {
+		return err
+	}
+
+	var total int
+	err = transaction.QueryRow("SELECT total_payment FROM bookings WHERE id = $1", bookingId).Scan(&total)
+	if err != nil {
+		return err
+	}
+
+	points := setting.EarnedPoints(total)
+	if points <= 0 {
+		return nil
+	}
+
+	entry := model.PointEntry{
+		CustomerId:  customerId,
+		BookingId:   bookingId,
+		Type:        "earn",
+		Points:      points,
+		Description: "Points earned from completed booking",
+	}
+
+	if setting.ExpiryDays > 0 {
+		entry.ExpiresAt = time.Now().AddDate(0, 0, setting.ExpiryDays)
+	}
+
+	_, err = insertPointEntry(transaction, entry)
+	return err
+}
+
+// restorePoints gives back the points redeemed on a booking that is being
+// cancelled, and takes back what a booking paid in full upfront already
+// earned, as far as the customer has not spent them.
+func restorePoints(transaction *sql.Tx, bookingId string) error {
+	var customerId string
+	var redeemed, earned int
+
+	query := "SELECT customer_id, COALESCE(SUM(points) FILTER (WHERE type = 'redeem'), 0), COALESCE(SUM(points) FILTER (WHERE type = 'earn'), 0) FROM point_entries WHERE booking_id = $1 AND type IN ('redeem', 'earn') GROUP BY customer_id"
+
+	err := transaction.QueryRow(query, bookingId).Scan(&customerId, &redeemed, &earned)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if redeemed < 0 {
+		_, err = insertPointEntry(transaction, model.PointEntry{
+			CustomerId:  customerId,
+			BookingId:   bookingId,
+			Type:        "redeem",
+			Points:      -redeemed,
+			Description: fmt.Sprintf("Points returned, booking %s cancelled", bookingId),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if earned <= 0 {
+		return nil
+	}
+
+	balance, err := lockPoints(transaction, customerId)
+	if err != nil {
+		return err
+	}
+
+	if earned > balance {
+		earned = balance
+	}
+
+	if earned <= 0 {
+		return nil
+	}
+
+	_, err = insertPointEntry(transaction, model.PointEntry{
+		CustomerId:  customerId,
+		BookingId:   bookingId,
+		Type:        "adjust",
+		Points:      -earned,
+		Description: fmt.Sprintf("Points taken back, booking %s cancelled", bookingId),
+	})
+	return err
+}
+
+func NewLoyaltyRepository(db *sql.DB) LoyaltyRepository {
+	return &loyaltyRepository{
+		DB: db,
+	}
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"team2/shuttleslot/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var pointEntryRowColumns = []string{"id", "customer_id", "booking_id", "type", "points", "amount", "description", "expires_at", "created_at"}
+
+var loyaltySettingRowColumns = []string{"earn_points", "earn_amount", "redeem_points", "redeem_value", "expiry_days", "updated_at"}
+
+type LoyaltyRepositoryTestSuite struct {
+	suite.Suite
+	mockDb  *sql.DB
+	mockSql sqlmock.Sqlmock
+	repo    LoyaltyRepository
+}
+
+func (suite *LoyaltyRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.mockDb = db
+	suite.mockSql = mock
+	suite.repo = NewLoyaltyRepository(suite.mockDb)
+}
+
+func TestLoyaltyRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(LoyaltyRepositoryTestSuite))
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestFindSetting_Default() {
+	suite.mockSql.ExpectQuery("SELECT earn_points, earn_amount, redeem_points, redeem_value, expiry_days, updated_at FROM loyalty_settings WHERE id = 1").
+		WillReturnError(sql.ErrNoRows)
+
+	actual, err := suite.repo.FindSetting()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.DefaultLoyaltySetting, actual)
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestFindSetting_Saved() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM loyalty_settings WHERE id = 1").
+		WillReturnRows(sqlmock.NewRows(loyaltySettingRowColumns).AddRow(1, 10000, 200, 25000, 90, time.Time{}))
+
+	actual, err := suite.repo.FindSetting()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.LoyaltySetting{EarnPoints: 1, EarnAmount: 10000, RedeemPoints: 200, RedeemValue: 25000, ExpiryDays: 90}, actual)
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestSaveSetting_Success() {
+	setting := model.LoyaltySetting{EarnPoints: 1, EarnAmount: 10000, RedeemPoints: 200, RedeemValue: 25000, ExpiryDays: 90}
+
+	suite.mockSql.ExpectQuery("INSERT INTO loyalty_settings (.+) ON CONFLICT \\(id\\) DO UPDATE").
+		WithArgs(1, 10000, 200, 25000, 90, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(loyaltySettingRowColumns).AddRow(1, 10000, 200, 25000, 90, time.Time{}))
+
+	actual, err := suite.repo.SaveSetting(setting)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), setting, actual)
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestCreateEntry_Success() {
+	entry := model.PointEntry{CustomerId: "1", Type: "adjust", Points: 50, Description: "Apology for court maintenance"}
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT points FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(20))
+	suite.mockSql.ExpectQuery("INSERT INTO point_entries").
+		WithArgs("1", nil, "adjust", 50, 0, entry.Description, nil).
+		WillReturnRows(sqlmock.NewRows(pointEntryRowColumns).AddRow("entry_1", "1", nil, "adjust", 50, 0, entry.Description, nil, time.Time{}))
+	suite.mockSql.ExpectExec("UPDATE users SET points = points \\+ \\$1 WHERE id = \\$2").
+		WithArgs(50, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectCommit()
+
+	actual, err := suite.repo.CreateEntry(entry)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "entry_1", actual.Id)
+	assert.Equal(suite.T(), "", actual.BookingId)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestCreateEntry_BelowZero() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT points FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(20))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.CreateEntry(model.PointEntry{CustomerId: "1", Type: "adjust", Points: -50})
+	assert.ErrorIs(suite.T(), err, ErrInsufficientPoints)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestFindEntries_Success() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM point_entries WHERE customer_id = \\$1 ORDER BY created_at DESC LIMIT \\$2 OFFSET \\$3").
+		WithArgs("1", 10, 0).
+		WillReturnRows(sqlmock.NewRows(pointEntryRowColumns).
+			AddRow("entry_2", "1", "booking_1", "redeem", -100, 10000, "Points redeemed", nil, time.Time{}).
+			AddRow("entry_1", "1", "booking_0", "earn", 10, 0, "Points earned", time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM point_entries WHERE customer_id = \\$1").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	actual, paginate, err := suite.repo.FindEntries("1", 1, 10)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), actual, 2)
+	assert.Equal(suite.T(), 2, paginate.TotalPages)
+	assert.Equal(suite.T(), 2031, actual[1].ExpiresAt.Year())
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestFindEntries_Failed() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM point_entries").
+		WillReturnError(errors.New("select failed"))
+
+	_, _, err := suite.repo.FindEntries("1", 1, 10)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestExpirePoints_CappedAtBalance() {
+	now := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT points FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(30))
+	suite.mockSql.ExpectQuery("SELECT COALESCE\\(SUM\\(CASE WHEN type = 'earn' AND expires_at <= \\$2").
+		WithArgs("1", now).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(40))
+	suite.mockSql.ExpectQuery("SELECT points FROM users WHERE id = \\$1 FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(30))
+	suite.mockSql.ExpectQuery("INSERT INTO point_entries").
+		WithArgs("1", nil, "expire", -30, 0, "Points expired", nil).
+		WillReturnRows(sqlmock.NewRows(pointEntryRowColumns).AddRow("entry_3", "1", nil, "expire", -30, 0, "Points expired", nil, time.Time{}))
+	suite.mockSql.ExpectExec("UPDATE users SET points = points \\+ \\$1 WHERE id = \\$2").
+		WithArgs(-30, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectCommit()
+
+	expired, err := suite.repo.ExpirePoints("1", now)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 30, expired)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestExpirePoints_NothingExpired() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT points FROM users WHERE id = \\$1 FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(30))
+	suite.mockSql.ExpectQuery("SELECT COALESCE\\(SUM").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(-20))
+	suite.mockSql.ExpectRollback()
+
+	expired, err := suite.repo.ExpirePoints("1", time.Now())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, expired)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *LoyaltyRepositoryTestSuite) TestFindRedemptionByBookingId_Success() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM point_entries WHERE booking_id = \\$1 AND type = 'redeem' AND points < 0").
+		WithArgs("booking_1").
+		WillReturnRows(sqlmock.NewRows(pointEntryRowColumns).AddRow("entry_2", "1", "booking_1", "redeem", -100, 10000, "Points redeemed", nil, time.Time{}))
+
+	actual, err := suite.repo.FindRedemptionByBookingId("booking_1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 10000, actual.Amount)
+}
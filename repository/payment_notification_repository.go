@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+	"math"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"time"
+)
+
+type PaymentNotificationRepository interface {
+	Save(payload model.PaymentNotification) (model.PaymentNotification, error)
+	Claim(id string) (bool, error)
+	Reclaim(id string) (bool, error)
+	Finish(id string, status string, message string) error
+	FindById(id string) (model.PaymentNotification, error)
+	FindAll(status string, page int, size int) ([]model.PaymentNotification, dto.Paginate, error)
+}
+
+type paymentNotificationRepository struct {
+	DB *sql.DB
+}
+
+const paymentNotificationColumns = "id, order_id, transaction_status, payload, status, attempts, COALESCE(error, ''), created_at, updated_at"
+
+func scanPaymentNotification(row rowScanner) (model.PaymentNotification, error) {
+	var notification model.PaymentNotification
+
+	err := row.Scan(
+		&notification.Id,
+		&notification.OrderId,
+		&notification.TransactionStatus,
+		&notification.Payload,
+		&notification.Status,
+		&notification.Attempts,
+		&notification.Error,
+		&notification.CreatedAt,
+		&notification.UpdatedAt,
+	)
+	if err != nil {
+		return model.PaymentNotification{}, err
+	}
+
+	return notification, nil
+}
+
+// Save stores a notification, or counts another delivery of one already in
+// the inbox. The stored payload is only replaced while the notification has
+// not been handled, so a forged redelivery cannot overwrite a processed one.
+func (r *paymentNotificationRepository) Save(payload model.PaymentNotification) (model.PaymentNotification, error) {
+	query := "INSERT INTO payment_notifications (order_id, transaction_status, payload, status, attempts) VALUES ($1, $2, $3, 'received', 1) " +
+		"ON CONFLICT (order_id, transaction_status) DO UPDATE SET attempts = payment_notifications.attempts + 1, " +
+		"payload = CASE WHEN payment_notifications.status IN ('processed', 'ignored') THEN payment_notifications.payload ELSE EXCLUDED.payload END, updated_at = $4 " +
+		"RETURNING " + paymentNotificationColumns
+
+	notification, err := scanPaymentNotification(r.DB.QueryRow(query, payload.OrderId, payload.TransactionStatus, payload.Payload, time.Now()))
+	if err != nil {
+		return model.PaymentNotification{}, err
+	}
+
+	return notification, nil
+}
+
+// claimLease is how long a notification may stay processing before it can be
+// claimed again, so one left behind by a crash or a timeout is not stuck.
+const claimLease = 5 * time.Minute
+
+// Claim marks a notification as processing when it has not been handled yet,
+// its last attempt did not go through or its claim outlived the lease. It
+// returns false for duplicates.
+func (r *paymentNotificationRepository) Claim(id string) (bool, error) {
+	return r.claim("UPDATE payment_notifications SET status = 'processing', claimed_at = $1, updated_at = $1 WHERE id = $2 AND (status IN ('received', 'failed', 'rejected') OR (status = 'processing' AND claimed_at < $3))", id)
+}
+
+// Reclaim marks any notification that is not being processed right now as
+// processing again, for an admin replay.
+func (r *paymentNotificationRepository) Reclaim(id string) (bool, error) {
+	return r.claim("UPDATE payment_notifications SET status = 'processing', claimed_at = $1, updated_at = $1 WHERE id = $2 AND (status <> 'processing' OR claimed_at < $3)", id)
+}
+
+func (r *paymentNotificationRepository) claim(query string, id string) (bool, error) {
+	now := time.Now()
+
+	result, err := r.DB.Exec(query, now, id, now.Add(-claimLease))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *paymentNotificationRepository) Finish(id string, status string, message string) error {
+	_, err := r.DB.Exec("UPDATE payment_notifications SET status = $1, error = $2, updated_at = $3 WHERE id = $4", status, sql.NullString{String: message, Valid: message != ""}, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *paymentNotificationRepository) FindById(id string) (model.PaymentNotification, error) {
+	notification, err := scanPaymentNotification(r.DB.QueryRow("SELECT "+paymentNotificationColumns+" FROM payment_notifications WHERE id = $1", id))
+	if err != nil {
+		return model.PaymentNotification{}, err
+	}
+
+	return notification, nil
+}
+
+func (r *paymentNotificationRepository) FindAll(status string, page int, size int) ([]model.PaymentNotification, dto.Paginate, error) {
+	var notifications []model.PaymentNotification
+
+	offset := (page - 1) * size
+
+	rows, err := r.DB.Query("SELECT "+paymentNotificationColumns+" FROM payment_notifications WHERE ($1 = '' OR status = $1) ORDER BY created_at DESC LIMIT $2 OFFSET $3", status, size, offset)
+	if err != nil {
+		return []model.PaymentNotification{}, dto.Paginate{}, err
+	}
+
+	for rows.Next() {
+		notification, err := scanPaymentNotification(rows)
+		if err != nil {
+			return []model.PaymentNotification{}, dto.Paginate{}, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	var totalRows int
+	err = r.DB.QueryRow("SELECT COUNT(*) FROM payment_notifications WHERE ($1 = '' OR status = $1)", status).Scan(&totalRows)
+	if err != nil {
+		return []model.PaymentNotification{}, dto.Paginate{}, err
+	}
+
+	paginate := dto.Paginate{
+		Page:       page,
+		Size:       size,
+		TotalRows:  totalRows,
+		TotalPages: int(math.Ceil(float64(totalRows) / float64(size))),
+	}
+
+	return notifications, paginate, nil
+}
+
+func NewPaymentNotificationRepository(db *sql.DB) PaymentNotificationRepository {
+	return &paymentNotificationRepository{
+		DB: db,
+	}
+}
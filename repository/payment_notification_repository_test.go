@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"team2/shuttleslot/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var mockPaymentNotification = model.PaymentNotification{
+	Id:                "1",
+	OrderId:           "Booking-1",
+	TransactionStatus: "settlement",
+	Payload:           `{"order_id":"Booking-1","transaction_status":"settlement"}`,
+	Status:            "received",
+	Attempts:          1,
+}
+
+var paymentNotificationRowColumns = []string{"id", "order_id", "transaction_status", "payload", "status", "attempts", "error", "created_at", "updated_at"}
+
+func paymentNotificationRow(n model.PaymentNotification) *sqlmock.Rows {
+	return sqlmock.NewRows(paymentNotificationRowColumns).
+		AddRow(n.Id, n.OrderId, n.TransactionStatus, n.Payload, n.Status, n.Attempts, n.Error, n.CreatedAt, n.UpdatedAt)
+}
+
+type PaymentNotificationRepositoryTestSuite struct {
+	suite.Suite
+	mockDb  *sql.DB
+	mockSql sqlmock.Sqlmock
+	repo    PaymentNotificationRepository
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.mockDb = db
+	suite.mockSql = mock
+	suite.repo = NewPaymentNotificationRepository(suite.mockDb)
+}
+
+func TestPaymentNotificationRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentNotificationRepositoryTestSuite))
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) TestSave_Success() {
+	suite.mockSql.ExpectQuery("INSERT INTO payment_notifications (.+) ON CONFLICT \\(order_id, transaction_status\\) DO UPDATE").
+		WithArgs(mockPaymentNotification.OrderId, mockPaymentNotification.TransactionStatus, mockPaymentNotification.Payload, sqlmock.AnyArg()).
+		WillReturnRows(paymentNotificationRow(mockPaymentNotification))
+
+	actual, err := suite.repo.Save(mockPaymentNotification)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockPaymentNotification, actual)
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) TestSave_Failed() {
+	suite.mockSql.ExpectQuery("INSERT INTO payment_notifications").WillReturnError(errors.New("error"))
+
+	_, err := suite.repo.Save(mockPaymentNotification)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) TestClaim_Claimed() {
+	suite.mockSql.ExpectExec("UPDATE payment_notifications SET status = 'processing'").
+		WithArgs(sqlmock.AnyArg(), "1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	claimed, err := suite.repo.Claim("1")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), claimed)
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) TestClaim_AlreadyHandled() {
+	suite.mockSql.ExpectExec("UPDATE payment_notifications SET status = 'processing'").
+		WithArgs(sqlmock.AnyArg(), "1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err := suite.repo.Claim("1")
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), claimed)
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) TestClaim_TakesOverExpiredLease() {
+	suite.mockSql.ExpectExec("UPDATE payment_notifications SET status = 'processing', claimed_at = \\$1, updated_at = \\$1 WHERE id = \\$2 AND \\(status IN \\('received', 'failed', 'rejected'\\) OR \\(status = 'processing' AND claimed_at < \\$3\\)\\)").
+		WithArgs(sqlmock.AnyArg(), "1", leaseExpiredBefore{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	claimed, err := suite.repo.Claim("1")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), claimed)
+}
+
+// leaseExpiredBefore matches the cutoff a claim compares claimed_at against,
+// which must lie one lease in the past.
+type leaseExpiredBefore struct{}
+
+func (leaseExpiredBefore) Match(v driver.Value) bool {
+	cutoff, ok := v.(time.Time)
+	if !ok {
+		return false
+	}
+	age := time.Since(cutoff)
+	return age >= claimLease && age < claimLease+time.Minute
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) TestReclaim_Failed() {
+	suite.mockSql.ExpectExec("UPDATE payment_notifications SET status = 'processing'").WillReturnError(errors.New("error"))
+
+	_, err := suite.repo.Reclaim("1")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) TestFinish_Success() {
+	suite.mockSql.ExpectExec("UPDATE payment_notifications SET status = \\$1, error = \\$2").
+		WithArgs("failed", "connection refused", sqlmock.AnyArg(), "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.Finish("1", "failed", "connection refused")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) TestFindById_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM payment_notifications WHERE id = \\$1").
+		WithArgs("9").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.repo.FindById("9")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *PaymentNotificationRepositoryTestSuite) TestFindAll_Success() {
+	processed := mockPaymentNotification
+	processed.Status = "processed"
+	processed.CreatedAt = time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM payment_notifications WHERE").
+		WithArgs("processed", 10, 0).
+		WillReturnRows(paymentNotificationRow(processed))
+	suite.mockSql.ExpectQuery("SELECT COUNT").
+		WithArgs("processed").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+
+	notifications, paginate, err := suite.repo.FindAll("processed", 1, 10)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []model.PaymentNotification{processed}, notifications)
+	assert.Equal(suite.T(), 2, paginate.TotalPages)
+}
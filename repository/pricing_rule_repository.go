@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"team2/shuttleslot/model"
+)
+
+type PricingRuleRepository interface {
+	Create(payload model.PricingRule) (model.PricingRule, error)
+	FindAll() ([]model.PricingRule, error)
+	Delete(id string) error
+}
+
+type pricingRuleRepository struct {
+	DB *sql.DB
+}
+
+const pricingRuleColumns = "id, name, court_id, weekdays, holiday_date, start_time, end_time, price, created_at, updated_at"
+
+func scanPricingRule(row rowScanner) (model.PricingRule, error) {
+	var rule model.PricingRule
+	var courtId, weekdays sql.NullString
+	var holidayDate sql.NullTime
+
+	err := row.Scan(
+		&rule.Id,
+		&rule.Name,
+		&courtId,
+		&weekdays,
+		&holidayDate,
+		&rule.StartTime,
+		&rule.EndTime,
+		&rule.Price,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return model.PricingRule{}, err
+	}
+
+	rule.CourtId = courtId.String
+	rule.HolidayDate = holidayDate.Time
+
+	if weekdays.Valid && weekdays.String != "" {
+		if err := json.Unmarshal([]byte(weekdays.String), &rule.Weekdays); err != nil {
+			return model.PricingRule{}, err
+		}
+	}
+
+	return rule, nil
+}
+
+func (r *pricingRuleRepository) Create(payload model.PricingRule) (model.PricingRule, error) {
+	courtId := sql.NullString{String: payload.CourtId, Valid: payload.CourtId != ""}
+	holidayDate := sql.NullTime{Time: payload.HolidayDate, Valid: payload.IsHoliday()}
+	weekdays := sql.NullString{}
+
+	if len(payload.Weekdays) > 0 {
+		data, err := json.Marshal(payload.Weekdays)
+		if err != nil {
+			return model.PricingRule{}, err
+		}
+		weekdays = sql.NullString{String: string(data), Valid: true}
+	}
+
+	query := "INSERT INTO pricing_rules (name, court_id, weekdays, holiday_date, start_time, end_time, price) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING " + pricingRuleColumns
+
+	rule, err := scanPricingRule(r.DB.QueryRow(query, payload.Name, courtId, weekdays, holidayDate, payload.StartTime, payload.EndTime, payload.Price))
+	if err != nil {
+		return model.PricingRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (r *pricingRuleRepository) FindAll() ([]model.PricingRule, error) {
+	var rules []model.PricingRule
+
+	rows, err := r.DB.Query("SELECT " + pricingRuleColumns + " FROM pricing_rules ORDER BY created_at")
+	if err != nil {
+		return []model.PricingRule{}, err
+	}
+
+	for rows.Next() {
+		rule, err := scanPricingRule(rows)
+		if err != nil {
+			return []model.PricingRule{}, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (r *pricingRuleRepository) Delete(id string) error {
+	result, err := r.DB.Exec("DELETE FROM pricing_rules WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func NewPricingRuleRepository(db *sql.DB) PricingRuleRepository {
+	return &pricingRuleRepository{
+		DB: db,
+	}
+}
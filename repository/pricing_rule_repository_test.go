@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"team2/shuttleslot/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var mockPricingRule = model.PricingRule{
+	Id:        "rule_1",
+	Name:      "Weekend peak",
+	CourtId:   "1",
+	Weekdays:  []int{6, 0},
+	StartTime: time.Date(0, 1, 1, 17, 0, 0, 0, time.UTC),
+	EndTime:   time.Date(0, 1, 1, 22, 0, 0, 0, time.UTC),
+	Price:     80000,
+}
+
+var pricingRuleRowColumns = []string{"id", "name", "court_id", "weekdays", "holiday_date", "start_time", "end_time", "price", "created_at", "updated_at"}
+
+type PricingRuleRepositoryTestSuite struct {
+	suite.Suite
+	mockDb  *sql.DB
+	mockSql sqlmock.Sqlmock
+	repo    PricingRuleRepository
+}
+
+func (suite *PricingRuleRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.mockDb = db
+	suite.mockSql = mock
+	suite.repo = NewPricingRuleRepository(suite.mockDb)
+}
+
+func TestPricingRuleRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PricingRuleRepositoryTestSuite))
+}
+
+func (suite *PricingRuleRepositoryTestSuite) TestCreate_Success() {
+	suite.mockSql.ExpectQuery("INSERT INTO pricing_rules").
+		WithArgs(mockPricingRule.Name, mockPricingRule.CourtId, "[6,0]", nil, mockPricingRule.StartTime, mockPricingRule.EndTime, mockPricingRule.Price).
+		WillReturnRows(sqlmock.NewRows(pricingRuleRowColumns).
+			AddRow(mockPricingRule.Id, mockPricingRule.Name, mockPricingRule.CourtId, "[6,0]", nil, mockPricingRule.StartTime, mockPricingRule.EndTime, mockPricingRule.Price, time.Time{}, time.Time{}))
+
+	actual, err := suite.repo.Create(mockPricingRule)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockPricingRule, actual)
+}
+
+func (suite *PricingRuleRepositoryTestSuite) TestCreate_HolidayForAllCourts() {
+	holiday := model.PricingRule{
+		Name:        "Independence day",
+		HolidayDate: time.Date(2030, 8, 17, 0, 0, 0, 0, time.UTC),
+		StartTime:   mockPricingRule.StartTime,
+		EndTime:     mockPricingRule.EndTime,
+		Price:       100000,
+	}
+
+	suite.mockSql.ExpectQuery("INSERT INTO pricing_rules").
+		WithArgs(holiday.Name, nil, nil, holiday.HolidayDate, holiday.StartTime, holiday.EndTime, holiday.Price).
+		WillReturnRows(sqlmock.NewRows(pricingRuleRowColumns).
+			AddRow("rule_2", holiday.Name, nil, nil, holiday.HolidayDate, holiday.StartTime, holiday.EndTime, holiday.Price, time.Time{}, time.Time{}))
+
+	actual, err := suite.repo.Create(holiday)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "", actual.CourtId)
+	assert.Nil(suite.T(), actual.Weekdays)
+	assert.True(suite.T(), actual.IsHoliday())
+}
+
+func (suite *PricingRuleRepositoryTestSuite) TestCreate_Failed() {
+	suite.mockSql.ExpectQuery("INSERT INTO pricing_rules").
+		WillReturnError(errors.New("insert failed"))
+
+	_, err := suite.repo.Create(mockPricingRule)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *PricingRuleRepositoryTestSuite) TestFindAll_Success() {
+	suite.mockSql.ExpectQuery("SELECT id, name, court_id, weekdays, holiday_date, start_time, end_time, price, created_at, updated_at FROM pricing_rules ORDER BY created_at").
+		WillReturnRows(sqlmock.NewRows(pricingRuleRowColumns).
+			AddRow(mockPricingRule.Id, mockPricingRule.Name, mockPricingRule.CourtId, "[6,0]", nil, mockPricingRule.StartTime, mockPricingRule.EndTime, mockPricingRule.Price, time.Time{}, time.Time{}))
+
+	actual, err := suite.repo.FindAll()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []model.PricingRule{mockPricingRule}, actual)
+}
+
+func (suite *PricingRuleRepositoryTestSuite) TestFindAll_Failed() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM pricing_rules").
+		WillReturnError(errors.New("select failed"))
+
+	_, err := suite.repo.FindAll()
+	assert.Error(suite.T(), err)
+}
+
+func (suite *PricingRuleRepositoryTestSuite) TestDelete_Success() {
+	suite.mockSql.ExpectExec("DELETE FROM pricing_rules WHERE id = \\$1").
+		WithArgs("rule_1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.Delete("rule_1")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *PricingRuleRepositoryTestSuite) TestDelete_NotFound() {
+	suite.mockSql.ExpectExec("DELETE FROM pricing_rules WHERE id = \\$1").
+		WithArgs("rule_1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.Delete("rule_1")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
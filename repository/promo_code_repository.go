@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"team2/shuttleslot/model"
+	"time"
+)
+
+var (
+	ErrPromoUsageLimit    = errors.New("cannot book, promo code usage limit reached")
+	ErrPromoCustomerLimit = errors.New("cannot book, promo code usage limit for this customer reached")
+)
+
+type PromoCodeRepository interface {
+	Create(payload model.PromoCode) (model.PromoCode, error)
+	FindAll() ([]model.PromoCode, error)
+	FindByCode(code string) (model.PromoCode, error)
+	Delete(id string) error
+	CountRedemptions(promoCodeId, customerId string) (int, int, error)
+	FindRedemptionByBookingId(bookingId string) (model.PromoRedemption, error)
+	FindReport(startDate, endDate time.Time) ([]model.PromoReport, error)
+}
+
+type promoCodeRepository struct {
+	DB *sql.DB
+}
+
+const promoCodeColumns = "id, code, discount_type, discount_value, max_discount, valid_from, valid_until, usage_limit, per_customer_limit, court_ids, created_at, updated_at"
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func scanPromoCode(row rowScanner) (model.PromoCode, error) {
+	var promo model.PromoCode
+	var courtIds sql.NullString
+
+	err := row.Scan(
+		&promo.Id,
+		&promo.Code,
+		&promo.DiscountType,
+		&promo.DiscountValue,
+		&promo.MaxDiscount,
+		&promo.ValidFrom,
+		&promo.ValidUntil,
+		&promo.UsageLimit,
+		&promo.PerCustomerLimit,
+		&courtIds,
+		&promo.CreatedAt,
+		&promo.UpdatedAt,
+	)
+	if err != nil {
+		return model.PromoCode{}, err
+	}
+
+	if courtIds.Valid && courtIds.String != "" {
+		if err := json.Unmarshal([]byte(courtIds.String), &promo.CourtIds); err != nil {
+			return model.PromoCode{}, err
+		}
+	}
+
+	return promo, nil
+}
+
+func (r *promoCodeRepository) Create(payload model.PromoCode) (model.PromoCode, error) {
+	courtIds := sql.NullString{}
+
+	if len(payload.CourtIds) > 0 {
+		data, err := json.Marshal(payload.CourtIds)
+		if err != nil {
+			return model.PromoCode{}, err
+		}
+		courtIds = sql.NullString{String: string(data), Valid: true}
+	}
+
+	query := "INSERT INTO promo_codes (code, discount_type, discount_value, max_discount, valid_from, valid_until, usage_limit, per_customer_limit, court_ids) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING " + promoCodeColumns
+
+	promo, err := scanPromoCode(r.DB.QueryRow(query, payload.Code, payload.DiscountType, payload.DiscountValue, payload.MaxDiscount, payload.ValidFrom, payload.ValidUntil, payload.UsageLimit, payload.PerCustomerLimit, courtIds))
+	if err != nil {
+		return model.PromoCode{}, err
+	}
+
+	return promo, nil
+}
+
+func (r *promoCodeRepository) FindAll() ([]model.PromoCode, error) {
+	var promos []model.PromoCode
+
+	rows, err := r.DB.Query("SELECT " + promoCodeColumns + " FROM promo_codes ORDER BY created_at DESC")
+	if err != nil {
+		return []model.PromoCode{}, err
+	}
+
+	for rows.Next() {
+		promo, err := scanPromoCode(rows)
+		if err != nil {
+			return []model.PromoCode{}, err
+		}
+		promos = append(promos, promo)
+	}
+
+	return promos, nil
+}
+
+func (r *promoCodeRepository) FindByCode(code string) (model.PromoCode, error) {
+	promo, err := scanPromoCode(r.DB.QueryRow("SELECT "+promoCodeColumns+" FROM promo_codes WHERE code = $1", code))
+	if err != nil {
+		return model.PromoCode{}, err
+	}
+
+	return promo, nil
+}
+
+func (r *promoCodeRepository) Delete(id string) error {
+	result, err := r.DB.Exec("DELETE FROM promo_codes WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *promoCodeRepository) CountRedemptions(promoCodeId, customerId string) (int, int, error) {
+	return countRedemptions(r.DB, promoCodeId, customerId)
+}
+
+func (r *promoCodeRepository) FindRedemptionByBookingId(bookingId string) (model.PromoRedemption, error) {
+	var redemption model.PromoRedemption
+
+	query := "SELECT pr.id, pr.promo_code_id, pc.code, pr.booking_id, pr.customer_id, pr.discount, pr.created_at FROM promo_redemptions pr JOIN promo_codes pc ON pc.id = pr.promo_code_id WHERE pr.booking_id = $1"
+
+	err := r.DB.QueryRow(query, bookingId).Scan(
+		&redemption.Id,
+		&redemption.PromoCodeId,
+		&redemption.Code,
+		&redemption.BookingId,
+		&redemption.CustomerId,
+		&redemption.Discount,
+		&redemption.CreatedAt,
+	)
+	if err != nil {
+		return model.PromoRedemption{}, err
+	}
+
+	return redemption, nil
+}
+
+// FindReport sums the discounts given on bookings that were paid, since a
+// pending booking that expires or is cancelled never cost anything.
+func (r *promoCodeRepository) FindReport(startDate, endDate time.Time) ([]model.PromoReport, error) {
+	var reports []model.PromoReport
+
+	query := "SELECT pc.id, pc.code, COUNT(pr.id), COALESCE(SUM(pr.discount), 0) FROM promo_redemptions pr JOIN promo_codes pc ON pc.id = pr.promo_code_id JOIN bookings b ON b.id = pr.booking_id WHERE b.status IN ('booked', 'paid', 'checked_in', 'done', 'no_show') AND pr.created_at >= $1 AND pr.created_at < $2 GROUP BY pc.id, pc.code ORDER BY pc.code"
+
+	rows, err := r.DB.Query(query, startDate, endDate)
+	if err != nil {
+		return []model.PromoReport{}, err
+	}
+
+	for rows.Next() {
+		var report model.PromoReport
+		if err := rows.Scan(&report.PromoCodeId, &report.Code, &report.Redemptions, &report.TotalDiscount); err != nil {
+			return []model.PromoReport{}, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// countRedemptions counts the redemptions of a promo code that still hold a
+// booking, overall and for one customer.
+func countRedemptions(q queryRower, promoCodeId, customerId string) (int, int, error) {
+	var total, byCustomer int
+
+	query := "SELECT COUNT(*), COUNT(*) FILTER (WHERE pr.customer_id = $2) FROM promo_redemptions pr JOIN bookings b ON b.id = pr.booking_id WHERE pr.promo_code_id = $1 AND b.status IN ('pending', 'booked', 'paid', 'checked_in', 'done', 'no_show')"
+
+	err := q.QueryRow(query, promoCodeId, customerId).Scan(&total, &byCustomer)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return total, byCustomer, nil
+}
+
+// redeemPromo locks the promo code row so concurrent bookings cannot both
+// take its last use, checks the usage limits again and records the
+// redemption.
+func redeemPromo(transaction *sql.Tx, redemption model.PromoRedemption) error {
+	var usageLimit, perCustomerLimit int
+
+	err := transaction.QueryRow("SELECT usage_limit, per_customer_limit FROM promo_codes WHERE id = $1 FOR UPDATE", redemption.PromoCodeId).Scan(&usageLimit, &perCustomerLimit)
+	if err != nil {
+		return err
+	}
+
+	total, byCustomer, err := countRedemptions(transaction, redemption.PromoCodeId, redemption.CustomerId)
+	if err != nil {
+		return err
+	}
+
+	if usageLimit > 0 && total >= usageLimit {
+		return ErrPromoUsageLimit
+	}
+
+	if perCustomerLimit > 0 && byCustomer >= perCustomerLimit {
+		return ErrPromoCustomerLimit
+	}
+
+	_, err = transaction.Exec("INSERT INTO promo_redemptions (promo_code_id, booking_id, customer_id, discount) VALUES ($1, $2, $3, $4)", redemption.PromoCodeId, redemption.BookingId, redemption.CustomerId, redemption.Discount)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewPromoCodeRepository(db *sql.DB) PromoCodeRepository {
+	return &promoCodeRepository{
+		DB: db,
+	}
+}
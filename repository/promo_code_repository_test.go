@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"team2/shuttleslot/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var mockPromoCode = model.PromoCode{
+	Id:               "promo_1",
+	Code:             "HEMAT",
+	DiscountType:     "percentage",
+	DiscountValue:    10,
+	MaxDiscount:      20000,
+	ValidFrom:        time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	ValidUntil:       time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC),
+	UsageLimit:       100,
+	PerCustomerLimit: 1,
+	CourtIds:         []string{"1"},
+}
+
+var promoCodeRowColumns = []string{"id", "code", "discount_type", "discount_value", "max_discount", "valid_from", "valid_until", "usage_limit", "per_customer_limit", "court_ids", "created_at", "updated_at"}
+
+func promoCodeRow(p model.PromoCode, courtIds any) *sqlmock.Rows {
+	return sqlmock.NewRows(promoCodeRowColumns).
+		AddRow(p.Id, p.Code, p.DiscountType, p.DiscountValue, p.MaxDiscount, p.ValidFrom, p.ValidUntil, p.UsageLimit, p.PerCustomerLimit, courtIds, time.Time{}, time.Time{})
+}
+
+type PromoCodeRepositoryTestSuite struct {
+	suite.Suite
+	mockDb  *sql.DB
+	mockSql sqlmock.Sqlmock
+	repo    PromoCodeRepository
+}
+
+func (suite *PromoCodeRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.mockDb = db
+	suite.mockSql = mock
+	suite.repo = NewPromoCodeRepository(suite.mockDb)
+}
+
+func TestPromoCodeRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(PromoCodeRepositoryTestSuite))
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestCreate_Success() {
+	suite.mockSql.ExpectQuery("INSERT INTO promo_codes").
+		WithArgs(mockPromoCode.Code, mockPromoCode.DiscountType, mockPromoCode.DiscountValue, mockPromoCode.MaxDiscount, mockPromoCode.ValidFrom, mockPromoCode.ValidUntil, mockPromoCode.UsageLimit, mockPromoCode.PerCustomerLimit, `["1"]`).
+		WillReturnRows(promoCodeRow(mockPromoCode, `["1"]`))
+
+	actual, err := suite.repo.Create(mockPromoCode)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockPromoCode, actual)
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestCreate_Failed() {
+	suite.mockSql.ExpectQuery("INSERT INTO promo_codes").
+		WillReturnError(errors.New("duplicate code"))
+
+	_, err := suite.repo.Create(mockPromoCode)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestFindByCode_AllCourts() {
+	allCourts := mockPromoCode
+	allCourts.CourtIds = nil
+
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM promo_codes WHERE code = \\$1").
+		WithArgs("HEMAT").
+		WillReturnRows(promoCodeRow(allCourts, nil))
+
+	actual, err := suite.repo.FindByCode("HEMAT")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), allCourts, actual)
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestFindByCode_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM promo_codes WHERE code = \\$1").
+		WithArgs("NOPE").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.repo.FindByCode("NOPE")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestFindAll_Success() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM promo_codes ORDER BY created_at DESC").
+		WillReturnRows(promoCodeRow(mockPromoCode, `["1"]`))
+
+	actual, err := suite.repo.FindAll()
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), actual, 1)
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestDelete_NotFound() {
+	suite.mockSql.ExpectExec("DELETE FROM promo_codes WHERE id = \\$1").
+		WithArgs("promo_1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.Delete("promo_1")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestCountRedemptions_Success() {
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\), COUNT\\(\\*\\) FILTER \\(WHERE pr.customer_id = \\$2\\) FROM promo_redemptions").
+		WithArgs("promo_1", "customer_1").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(7, 1))
+
+	total, byCustomer, err := suite.repo.CountRedemptions("promo_1", "customer_1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 7, total)
+	assert.Equal(suite.T(), 1, byCustomer)
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestFindRedemptionByBookingId_None() {
+	suite.mockSql.ExpectQuery("SELECT (.+) FROM promo_redemptions pr JOIN promo_codes pc ON pc.id = pr.promo_code_id WHERE pr.booking_id = \\$1").
+		WithArgs("1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.repo.FindRedemptionByBookingId("1")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestFindReport_Success() {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectQuery("SELECT pc.id, pc.code, COUNT\\(pr.id\\), COALESCE\\(SUM\\(pr.discount\\), 0\\) FROM promo_redemptions").
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "count", "sum"}).AddRow("promo_1", "HEMAT", 3, 45000))
+
+	actual, err := suite.repo.FindReport(start, end)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []model.PromoReport{{PromoCodeId: "promo_1", Code: "HEMAT", Redemptions: 3, TotalDiscount: 45000}}, actual)
+}
+
+func (suite *PromoCodeRepositoryTestSuite) TestFindReport_Failed() {
+	suite.mockSql.ExpectQuery("SELECT pc.id, pc.code").
+		WillReturnError(errors.New("select failed"))
+
+	_, err := suite.repo.FindReport(time.Time{}, time.Time{})
+	assert.Error(suite.T(), err)
+}
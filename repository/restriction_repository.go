@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"database/sql"
+	"team2/shuttleslot/model"
+	"time"
+)
+
+type RestrictionRepository interface {
+	Create(payload model.Restriction) (model.Restriction, error)
+	FindByCustomer(customerId string) ([]model.Restriction, error)
+	FindActive(customerId string, at time.Time) ([]model.Restriction, error)
+	Lift(id string, liftedBy string, at time.Time) (model.Restriction, error)
+	CountNoShowsSince(customerId string, since time.Time) (int, error)
+}
+
+type restrictionRepository struct {
+	DB *sql.DB
+}
+
+const restrictionColumns = "id, customer_id, type, reason, starts_at, ends_at, imposed_by, lifted_by, lifted_at, created_at"
+
+func scanRestriction(row rowScanner) (model.Restriction, error) {
+	var restriction model.Restriction
+	var liftedBy sql.NullString
+	var liftedAt sql.NullTime
+
+	err := row.Scan(
+		&restriction.Id,
+		&restriction.CustomerId,
+		&restriction.Type,
+		&restriction.Reason,
+		&restriction.StartsAt,
+		&restriction.EndsAt,
+		&restriction.ImposedBy,
+		&liftedBy,
+		&liftedAt,
+		&restriction.CreatedAt,
+	)
+	if err != nil {
+		return model.Restriction{}, err
+	}
+
+	restriction.LiftedBy = liftedBy.String
+	restriction.LiftedAt = liftedAt.Time
+
+	return restriction, nil
+}
+
+func (r *restrictionRepository) findMany(query string, args ...any) ([]model.Restriction, error) {
+	var restrictions []model.Restriction
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return []model.Restriction{}, err
+	}
+
+	for rows.Next() {
+		restriction, err := scanRestriction(rows)
+		if err != nil {
+			return []model.Restriction{}, err
+		}
+		restrictions = append(restrictions, restriction)
+	}
+
+	return restrictions, nil
+}
+
+func (r *restrictionRepository) Create(payload model.Restriction) (model.Restriction, error) {
+	query := "INSERT INTO customer_restrictions (customer_id, type, reason, starts_at, ends_at, imposed_by) VALUES ($1, $2, $3, $4, $5, $6) RETURNING " + restrictionColumns
+
+	restriction, err := scanRestriction(r.DB.QueryRow(query, payload.CustomerId, payload.Type, payload.Reason, payload.StartsAt, payload.EndsAt, payload.ImposedBy))
+	if err != nil {
+		return model.Restriction{}, err
+	}
+
+	return restriction, nil
+}
+
+func (r *restrictionRepository) FindByCustomer(customerId string) ([]model.Restriction, error) {
+	return r.findMany("SELECT "+restrictionColumns+" FROM customer_restrictions WHERE customer_id = $1 ORDER BY starts_at DESC", customerId)
+}
+
+func (r *restrictionRepository) FindActive(customerId string, at time.Time) ([]model.Restriction, error) {
+	return r.findMany("SELECT "+restrictionColumns+" FROM customer_restrictions WHERE customer_id = $1 AND lifted_at IS NULL AND starts_at <= $2 AND ends_at > $2 ORDER BY ends_at DESC", customerId, at)
+}
+
+// Lift ends a restriction early. It returns sql.ErrNoRows when the restriction
+// does not exist or was already lifted.
+func (r *restrictionRepository) Lift(id string, liftedBy string, at time.Time) (model.Restriction, error) {
+	query := "UPDATE customer_restrictions SET lifted_by = $1, lifted_at = $2 WHERE id = $3 AND lifted_at IS NULL RETURNING " + restrictionColumns
+
+	restriction, err := scanRestriction(r.DB.QueryRow(query, liftedBy, at, id))
+	if err != nil {
+		return model.Restriction{}, err
+	}
+
+	return restriction, nil
+}
+
+func (r *restrictionRepository) CountNoShowsSince(customerId string, since time.Time) (int, error) {
+	var noShows int
+
+	err := r.DB.QueryRow("SELECT COUNT(*) FROM bookings WHERE customer_id = $1 AND status = $2 AND booking_date >= $3", customerId, model.BookingNoShow, since).Scan(&noShows)
+	if err != nil {
+		return 0, err
+	}
+
+	return noShows, nil
+}
+
+func NewRestrictionRepository(db *sql.DB) RestrictionRepository {
+	return &restrictionRepository{
+		DB: db,
+	}
+}
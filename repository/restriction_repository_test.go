@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"team2/shuttleslot/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var mockRestriction = model.Restriction{
+	Id:         "restriction_1",
+	CustomerId: "customer_1",
+	Type:       model.RestrictionBlockOnline,
+	Reason:     "3 no-shows in 60 days",
+	StartsAt:   time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC),
+	EndsAt:     time.Date(2030, 1, 15, 10, 0, 0, 0, time.UTC),
+	ImposedBy:  "system",
+}
+
+var restrictionRowColumns = []string{"id", "customer_id", "type", "reason", "starts_at", "ends_at", "imposed_by", "lifted_by", "lifted_at", "created_at"}
+
+func restrictionRow(r model.Restriction, liftedBy any, liftedAt any) *sqlmock.Rows {
+	return sqlmock.NewRows(restrictionRowColumns).
+		AddRow(r.Id, r.CustomerId, r.Type, r.Reason, r.StartsAt, r.EndsAt, r.ImposedBy, liftedBy, liftedAt, time.Time{})
+}
+
+type RestrictionRepositoryTestSuite struct {
+	suite.Suite
+	mockDb  *sql.DB
+	mockSql sqlmock.Sqlmock
+	repo    RestrictionRepository
+}
+
+func (suite *RestrictionRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.mockDb = db
+	suite.mockSql = mock
+	suite.repo = NewRestrictionRepository(suite.mockDb)
+}
+
+func TestRestrictionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(RestrictionRepositoryTestSuite))
+}
+
+func (suite *RestrictionRepositoryTestSuite) TestCreate_Success() {
+	suite.mockSql.ExpectQuery("INSERT INTO customer_restrictions").
+		WithArgs(mockRestriction.CustomerId, mockRestriction.Type, mockRestriction.Reason, mockRestriction.StartsAt, mockRestriction.EndsAt, mockRestriction.ImposedBy).
+		WillReturnRows(restrictionRow(mockRestriction, nil, nil))
+
+	actual, err := suite.repo.Create(mockRestriction)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockRestriction.Id, actual.Id)
+	assert.True(suite.T(), actual.LiftedAt.IsZero())
+}
+
+func (suite *RestrictionRepositoryTestSuite) TestCreate_Failed() {
+	suite.mockSql.ExpectQuery("INSERT INTO customer_restrictions").
+		WillReturnError(errors.New("insert error"))
+
+	_, err := suite.repo.Create(mockRestriction)
+	assert.EqualError(suite.T(), err, "insert error")
+}
+
+func (suite *RestrictionRepositoryTestSuite) TestFindActive_Success() {
+	at := time.Date(2030, 1, 5, 10, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectQuery("FROM customer_restrictions WHERE customer_id = \\$1 AND lifted_at IS NULL AND starts_at <= \\$2 AND ends_at > \\$2").
+		WithArgs("customer_1", at).
+		WillReturnRows(restrictionRow(mockRestriction, nil, nil))
+
+	restrictions, err := suite.repo.FindActive("customer_1", at)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(restrictions))
+	assert.Equal(suite.T(), model.RestrictionBlockOnline, restrictions[0].Type)
+}
+
+func (suite *RestrictionRepositoryTestSuite) TestFindByCustomer_Success() {
+	liftedAt := time.Date(2030, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectQuery("FROM customer_restrictions WHERE customer_id = \\$1 ORDER BY starts_at DESC").
+		WithArgs("customer_1").
+		WillReturnRows(restrictionRow(mockRestriction, "admin_1", liftedAt))
+
+	restrictions, err := suite.repo.FindByCustomer("customer_1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "admin_1", restrictions[0].LiftedBy)
+	assert.Equal(suite.T(), liftedAt, restrictions[0].LiftedAt)
+}
+
+func (suite *RestrictionRepositoryTestSuite) TestFindByCustomer_QueryError() {
+	suite.mockSql.ExpectQuery("FROM customer_restrictions").
+		WillReturnError(errors.New("query error"))
+
+	_, err := suite.repo.FindByCustomer("customer_1")
+	assert.EqualError(suite.T(), err, "query error")
+}
+
+func (suite *RestrictionRepositoryTestSuite) TestLift_Success() {
+	at := time.Date(2030, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectQuery("UPDATE customer_restrictions SET lifted_by = \\$1, lifted_at = \\$2 WHERE id = \\$3 AND lifted_at IS NULL").
+		WithArgs("admin_1", at, "restriction_1").
+		WillReturnRows(restrictionRow(mockRestriction, "admin_1", at))
+
+	restriction, err := suite.repo.Lift("restriction_1", "admin_1", at)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), at, restriction.LiftedAt)
+}
+
+func (suite *RestrictionRepositoryTestSuite) TestLift_AlreadyLifted() {
+	suite.mockSql.ExpectQuery("UPDATE customer_restrictions").
+		WillReturnRows(sqlmock.NewRows(restrictionRowColumns))
+
+	_, err := suite.repo.Lift("restriction_1", "admin_1", time.Now())
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *RestrictionRepositoryTestSuite) TestCountNoShowsSince_Success() {
+	since := time.Date(2029, 11, 2, 10, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings WHERE customer_id = \\$1 AND status = \\$2 AND booking_date >= \\$3").
+		WithArgs("customer_1", model.BookingNoShow, since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := suite.repo.CountNoShowsSince("customer_1", since)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, count)
+}
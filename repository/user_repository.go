@@ -14,9 +14,11 @@ type UserRepository interface {
 	CreateAdmin(payload model.User) (model.User, error)
 	FindUserByUsername(username string) (model.User, error)
 	FindUserById(id string) (model.User, error)
+	FindUserByContact(phoneNumber string, email string) (model.User, error)
 	FindUserByRole(role string, page int, size int) ([]model.User, dto.Paginate, error)
 	UpdateUser(id string, payload model.User) (model.User, error)
 	DeleteUser(id string) error
+	ClaimGuest(bookingId string, customerId string) (int, error)
 }
 
 type userRepository struct {
@@ -78,6 +80,20 @@ func (r *userRepository) FindUserById(id string) (model.User, error) {
 	return user, nil
 }
 
+// FindUserByContact finds the customer or guest with the phone number, or with
+// the email when one is given. A registered customer is preferred over a
+// guest sharing the same contact.
+func (r *userRepository) FindUserByContact(phoneNumber string, email string) (model.User, error) {
+	var user model.User
+
+	err := r.DB.QueryRow("SELECT id, name, phone_number, email, username, password, points, role, created_at, updated_at FROM users WHERE (phone_number = $1 OR ($2 <> '' AND email = $2)) AND role IN ('customer', 'guest') ORDER BY role = 'guest' LIMIT 1", phoneNumber, email).Scan(&user.Id, &user.Name, &user.PhoneNumber, &user.Email, &user.Username, &user.Password, &user.Point, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
 func (r *userRepository) FindUserByRole(role string, page int, size int) ([]model.User, dto.Paginate, error) {
 	var users []model.User
 
@@ -129,6 +145,59 @@ func (r *userRepository) DeleteUser(id string) error {
 	return nil
 }
 
+// ClaimGuest moves the bookings, series, promo redemptions, points,
+// restrictions and waitlist entries of the guest who made the booking onto the
+// customer, then removes the guest. The guest must share the customer's phone
+// number or email. It returns how many bookings were claimed, or sql.ErrNoRows
+// when the booking does not belong to such a guest.
+func (r *userRepository) ClaimGuest(bookingId string, customerId string) (int, error) {
+	transaction, _ := r.DB.Begin()
+
+	var guest model.User
+	query := "SELECT u.id, u.points FROM users u JOIN bookings b ON b.customer_id = u.id JOIN users c ON c.id = $2 WHERE b.id = $1 AND u.role = 'guest' AND (u.phone_number = c.phone_number OR (c.email <> '' AND u.email = c.email)) FOR UPDATE OF u"
+
+	err := transaction.QueryRow(query, bookingId, customerId).Scan(&guest.Id, &guest.Point)
+	if err != nil {
+		transaction.Rollback()
+		return 0, err
+	}
+
+	result, err := transaction.Exec("UPDATE bookings SET customer_id = $1, updated_at = $2 WHERE customer_id = $3", customerId, time.Now(), guest.Id)
+	if err != nil {
+		transaction.Rollback()
+		return 0, err
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		transaction.Rollback()
+		return 0, err
+	}
+
+	for _, table := range []string{"booking_series", "promo_redemptions", "point_entries", "customer_restrictions", "waitlist_entries"} {
+		_, err = transaction.Exec("UPDATE "+table+" SET customer_id = $1 WHERE customer_id = $2", customerId, guest.Id)
+		if err != nil {
+			transaction.Rollback()
+			return 0, err
+		}
+	}
+
+	_, err = transaction.Exec("UPDATE users SET points = points + $1, updated_at = $2 WHERE id = $3", guest.Point, time.Now(), customerId)
+	if err != nil {
+		transaction.Rollback()
+		return 0, err
+	}
+
+	_, err = transaction.Exec("DELETE FROM users WHERE id = $1", guest.Id)
+	if err != nil {
+		transaction.Rollback()
+		return 0, err
+	}
+
+	transaction.Commit()
+	return int(claimed), nil
+}
+
 func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{
 		DB: db,
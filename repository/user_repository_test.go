@@ -147,6 +147,26 @@ func (suite *UserRepositoryTestSuite) TestFindUserById_Failed() {
 	assert.Error(suite.T(), err)
 }
 
+func (suite *UserRepositoryTestSuite) TestFindUserByContact_Success() {
+	suite.mockSql.ExpectQuery("SELECT id, name, phone_number, email, username, password, points, role, created_at, updated_at FROM users WHERE \\(phone_number = \\$1 OR \\(\\$2 <> '' AND email = \\$2\\)\\) AND role IN \\('customer', 'guest'\\)").
+		WithArgs(mockUser.PhoneNumber, mockUser.Email).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "phone_number", "email", "username", "password", "points", "role", "created_at", "updated_at"}).
+			AddRow(mockUser.Id, mockUser.Name, mockUser.PhoneNumber, mockUser.Email, mockUser.Username, mockUser.Password, mockUser.Point, mockUser.Role, mockUser.CreatedAt, mockUser.UpdatedAt))
+
+	actual, err := suite.repo.FindUserByContact(mockUser.PhoneNumber, mockUser.Email)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockUser, actual)
+}
+
+func (suite *UserRepositoryTestSuite) TestFindUserByContact_NotFound() {
+	suite.mockSql.ExpectQuery("SELECT id, name, phone_number, email, username, password, points, role").
+		WithArgs(mockUser.PhoneNumber, mockUser.Email).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.repo.FindUserByContact(mockUser.PhoneNumber, mockUser.Email)
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
 func (suite *UserRepositoryTestSuite) TestFindUserByRole_Success() {
 	page := 1
 	size := 10
@@ -247,3 +267,72 @@ func (suite *UserRepositoryTestSuite) TestDeleteUser_Failed() {
 	assert.Error(suite.T(), err)
 	assert.EqualError(suite.T(), err, "delete failed")
 }
+
+func (suite *UserRepositoryTestSuite) TestClaimGuest_Success() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT u.id, u.points FROM users u JOIN bookings b ON b.customer_id = u.id JOIN users c ON c.id = \\$2 WHERE b.id = \\$1 AND u.role = 'guest' AND \\(u.phone_number = c.phone_number OR \\(c.email <> '' AND u.email = c.email\\)\\) FOR UPDATE OF u").
+		WithArgs("booking_1", mockUser.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "points"}).AddRow("guest_1", 15))
+	suite.mockSql.ExpectExec("UPDATE bookings SET customer_id = \\$1, updated_at = \\$2 WHERE customer_id = \\$3").
+		WithArgs(mockUser.Id, sqlmock.AnyArg(), "guest_1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	for _, table := range []string{"booking_series", "promo_redemptions", "point_entries", "customer_restrictions", "waitlist_entries"} {
+		suite.mockSql.ExpectExec("UPDATE "+table+" SET customer_id = \\$1 WHERE customer_id = \\$2").
+			WithArgs(mockUser.Id, "guest_1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	suite.mockSql.ExpectExec("UPDATE users SET points = points \\+ \\$1, updated_at = \\$2 WHERE id = \\$3").
+		WithArgs(15, sqlmock.AnyArg(), mockUser.Id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectExec("DELETE FROM users WHERE id = \\$1").
+		WithArgs("guest_1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectCommit()
+
+	claimed, err := suite.repo.ClaimGuest("booking_1", mockUser.Id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, claimed)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestClaimGuest_NotAGuestBooking() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT u.id, u.points FROM users u").
+		WithArgs("booking_1", mockUser.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "points"}))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.ClaimGuest("booking_1", mockUser.Id)
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+// TestClaimGuest_DifferentContact covers a token for a guest booking made under
+// someone else's phone number and email: the guest row does not match the
+// customer's contact, so nothing is moved.
+func (suite *UserRepositoryTestSuite) TestClaimGuest_DifferentContact() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT u.id, u.points FROM users u .* AND \\(u.phone_number = c.phone_number OR \\(c.email <> '' AND u.email = c.email\\)\\)").
+		WithArgs("booking_2", mockUser.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "points"}))
+	suite.mockSql.ExpectRollback()
+
+	claimed, err := suite.repo.ClaimGuest("booking_2", mockUser.Id)
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+	assert.Equal(suite.T(), 0, claimed)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestClaimGuest_MoveBookingsFailed() {
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT u.id, u.points FROM users u").
+		WithArgs("booking_1", mockUser.Id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "points"}).AddRow("guest_1", 0))
+	suite.mockSql.ExpectExec("UPDATE bookings SET customer_id").
+		WillReturnError(errors.New("update failed"))
+	suite.mockSql.ExpectRollback()
+
+	_, err := suite.repo.ClaimGuest("booking_1", mockUser.Id)
+	assert.EqualError(suite.T(), err, "update failed")
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"database/sql"
+	"team2/shuttleslot/model"
+	"time"
+)
+
+type WaitlistRepository interface {
+	Create(payload model.WaitlistEntry) (model.WaitlistEntry, error)
+	FindById(id string) (model.WaitlistEntry, error)
+	FindByCustomer(customerId string) ([]model.WaitlistEntry, error)
+	FindWaiting(bookingDate time.Time, courtId string) ([]model.WaitlistEntry, error)
+	FindExpiredOffers(now time.Time) ([]model.WaitlistEntry, error)
+	Offer(payload model.WaitlistEntry) error
+	Fulfil(id string, bookingId string) error
+	Expire(id string) error
+	Leave(id string) error
+}
+
+type waitlistRepository struct {
+	DB *sql.DB
+}
+
+const waitlistColumns = "id, customer_id, court_id, booking_date, start_time, end_time, hour, status, offered_court_id, offered_start_time, offer_expires_at, booking_id, created_at"
+
+func scanWaitlistEntry(row rowScanner) (model.WaitlistEntry, error) {
+	var entry model.WaitlistEntry
+	var courtId, offeredCourtId, bookingId sql.NullString
+	var offeredStartTime, offerExpiresAt sql.NullTime
+
+	err := row.Scan(
+		&entry.Id,
+		&entry.CustomerId,
+		&courtId,
+		&entry.BookingDate,
+		&entry.StartTime,
+		&entry.EndTime,
+		&entry.Hour,
+		&entry.Status,
+		&offeredCourtId,
+		&offeredStartTime,
+		&offerExpiresAt,
+		&bookingId,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+
+	entry.CourtId = courtId.String
+	entry.OfferedCourtId = offeredCourtId.String
+	entry.OfferedStartTime = offeredStartTime.Time
+	entry.OfferExpiresAt = offerExpiresAt.Time
+	entry.BookingId = bookingId.String
+
+	return entry, nil
+}
+
+func (r *waitlistRepository) findMany(query string, args ...any) ([]model.WaitlistEntry, error) {
+	var entries []model.WaitlistEntry
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return []model.WaitlistEntry{}, err
+	}
+
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return []model.WaitlistEntry{}, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// updateOne runs an update guarded by the entry's current status and returns
+// sql.ErrNoRows when the entry had already moved on.
+func (r *waitlistRepository) updateOne(query string, args ...any) error {
+	result, err := r.DB.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *waitlistRepository) Create(payload model.WaitlistEntry) (model.WaitlistEntry, error) {
+	query := "INSERT INTO waitlist_entries (customer_id, court_id, booking_date, start_time, end_time, hour, status) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING " + waitlistColumns
+
+	courtId := sql.NullString{String: payload.CourtId, Valid: payload.CourtId != ""}
+
+	entry, err := scanWaitlistEntry(r.DB.QueryRow(query, payload.CustomerId, courtId, payload.BookingDate, payload.StartTime, payload.EndTime, payload.Hour, model.WaitlistWaiting))
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func (r *waitlistRepository) FindById(id string) (model.WaitlistEntry, error) {
+	entry, err := scanWaitlistEntry(r.DB.QueryRow("SELECT "+waitlistColumns+" FROM waitlist_entries WHERE id = $1", id))
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func (r *waitlistRepository) FindByCustomer(customerId string) ([]model.WaitlistEntry, error) {
+	return r.findMany("SELECT "+waitlistColumns+" FROM waitlist_entries WHERE customer_id = $1 ORDER BY booking_date DESC, start_time DESC", customerId)
+}
+
+// FindWaiting returns the entries still waiting for a slot on the given day
+// and court, or on any court, first come first served.
+func (r *waitlistRepository) FindWaiting(bookingDate time.Time, courtId string) ([]model.WaitlistEntry, error) {
+	return r.findMany("SELECT "+waitlistColumns+" FROM waitlist_entries WHERE status = $1 AND booking_date = $2 AND (court_id IS NULL OR court_id = $3) ORDER BY created_at", model.WaitlistWaiting, bookingDate, courtId)
+}
+
+func (r *waitlistRepository) FindExpiredOffers(now time.Time) ([]model.WaitlistEntry, error) {
+	return r.findMany("SELECT "+waitlistColumns+" FROM waitlist_entries WHERE status = $1 AND offer_expires_at < $2 ORDER BY offer_expires_at", model.WaitlistOffered, now)
+}
+
+// Offer holds the offered slot for the entry until its offer expires. The slot
+// is checked under the court lock first, so a slot that was booked, closed or
+// offered to someone else in the meantime is never handed out.
+func (r *waitlistRepository) Offer(payload model.WaitlistEntry) error {
+	transaction, _ := r.DB.Begin()
+
+	endTime := payload.OfferedStartTime.Add(time.Hour * time.Duration(payload.Hour))
+
+	err := reserveSlot(transaction, payload.OfferedCourtId, payload.BookingDate, payload.OfferedStartTime, endTime, "", payload.CustomerId)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	result, err := transaction.Exec("UPDATE waitlist_entries SET status = $1, offered_court_id = $2, offered_start_time = $3, offer_expires_at = $4 WHERE id = $5 AND status = $6", model.WaitlistOffered, payload.OfferedCourtId, payload.OfferedStartTime, payload.OfferExpiresAt, payload.Id, model.WaitlistWaiting)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	if affected == 0 {
+		transaction.Rollback()
+		return sql.ErrNoRows
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (r *waitlistRepository) Fulfil(id string, bookingId string) error {
+	return r.updateOne("UPDATE waitlist_entries SET status = $1, booking_id = $2 WHERE id = $3 AND status = $4", model.WaitlistBooked, bookingId, id, model.WaitlistOffered)
+}
+
+func (r *waitlistRepository) Expire(id string) error {
+	return r.updateOne("UPDATE waitlist_entries SET status = $1 WHERE id = $2 AND status = $3", model.WaitlistExpired, id, model.WaitlistOffered)
+}
+
+func (r *waitlistRepository) Leave(id string) error {
+	return r.updateOne("UPDATE waitlist_entries SET status = $1 WHERE id = $2 AND status IN ($3, $4)", model.WaitlistCancelled, id, model.WaitlistWaiting, model.WaitlistOffered)
+}
+
+func NewWaitlistRepository(db *sql.DB) WaitlistRepository {
+	return &waitlistRepository{
+		DB: db,
+	}
+}
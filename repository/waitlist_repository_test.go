@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"team2/shuttleslot/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var mockWaitlistEntry = model.WaitlistEntry{
+	Id:          "entry_1",
+	CustomerId:  "customer_1",
+	CourtId:     "court_1",
+	BookingDate: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	StartTime:   time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC),
+	EndTime:     time.Date(0, 1, 1, 14, 0, 0, 0, time.UTC),
+	Hour:        2,
+	Status:      model.WaitlistWaiting,
+}
+
+var waitlistRowColumns = []string{"id", "customer_id", "court_id", "booking_date", "start_time", "end_time", "hour", "status", "offered_court_id", "offered_start_time", "offer_expires_at", "booking_id", "created_at"}
+
+func waitlistRow(e model.WaitlistEntry, courtId any) *sqlmock.Rows {
+	return sqlmock.NewRows(waitlistRowColumns).
+		AddRow(e.Id, e.CustomerId, courtId, e.BookingDate, e.StartTime, e.EndTime, e.Hour, e.Status, nil, nil, nil, nil, time.Time{})
+}
+
+type WaitlistRepositoryTestSuite struct {
+	suite.Suite
+	mockDb  *sql.DB
+	mockSql sqlmock.Sqlmock
+	repo    WaitlistRepository
+}
+
+func (suite *WaitlistRepositoryTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.mockDb = db
+	suite.mockSql = mock
+	suite.repo = NewWaitlistRepository(suite.mockDb)
+}
+
+func TestWaitlistRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(WaitlistRepositoryTestSuite))
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestCreate_Success() {
+	suite.mockSql.ExpectQuery("INSERT INTO waitlist_entries").
+		WithArgs(mockWaitlistEntry.CustomerId, sql.NullString{String: "court_1", Valid: true}, mockWaitlistEntry.BookingDate, mockWaitlistEntry.StartTime, mockWaitlistEntry.EndTime, mockWaitlistEntry.Hour, model.WaitlistWaiting).
+		WillReturnRows(waitlistRow(mockWaitlistEntry, "court_1"))
+
+	actual, err := suite.repo.Create(mockWaitlistEntry)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockWaitlistEntry.Id, actual.Id)
+	assert.Equal(suite.T(), model.WaitlistWaiting, actual.Status)
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestCreate_AnyCourt() {
+	entry := mockWaitlistEntry
+	entry.CourtId = ""
+
+	suite.mockSql.ExpectQuery("INSERT INTO waitlist_entries").
+		WithArgs(entry.CustomerId, sql.NullString{}, entry.BookingDate, entry.StartTime, entry.EndTime, entry.Hour, model.WaitlistWaiting).
+		WillReturnRows(waitlistRow(entry, nil))
+
+	actual, err := suite.repo.Create(entry)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "", actual.CourtId)
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestCreate_Failed() {
+	suite.mockSql.ExpectQuery("INSERT INTO waitlist_entries").
+		WillReturnError(errors.New("insert error"))
+
+	_, err := suite.repo.Create(mockWaitlistEntry)
+	assert.EqualError(suite.T(), err, "insert error")
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestFindById_NotFound() {
+	suite.mockSql.ExpectQuery("FROM waitlist_entries WHERE id = \\$1").
+		WithArgs("entry_1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.repo.FindById("entry_1")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestFindWaiting_Success() {
+	suite.mockSql.ExpectQuery("FROM waitlist_entries WHERE status = \\$1 AND booking_date = \\$2 AND \\(court_id IS NULL OR court_id = \\$3\\) ORDER BY created_at").
+		WithArgs(model.WaitlistWaiting, mockWaitlistEntry.BookingDate, "court_1").
+		WillReturnRows(waitlistRow(mockWaitlistEntry, "court_1"))
+
+	entries, err := suite.repo.FindWaiting(mockWaitlistEntry.BookingDate, "court_1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(entries))
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestFindExpiredOffers_Failed() {
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectQuery("FROM waitlist_entries WHERE status = \\$1 AND offer_expires_at < \\$2").
+		WithArgs(model.WaitlistOffered, now).
+		WillReturnError(errors.New("query error"))
+
+	_, err := suite.repo.FindExpiredOffers(now)
+	assert.EqualError(suite.T(), err, "query error")
+}
+
+func (suite *WaitlistRepositoryTestSuite) expectOfferSlotChecked(overlap int) {
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("court_1"))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM court_closures").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(overlap))
+	if overlap == 0 {
+		suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM waitlist_entries").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	}
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestOffer_Success() {
+	entry := mockWaitlistEntry
+	entry.OfferedCourtId = "court_1"
+	entry.OfferedStartTime = time.Date(0, 1, 1, 11, 0, 0, 0, time.UTC)
+	entry.OfferExpiresAt = time.Date(2030, 1, 1, 9, 30, 0, 0, time.UTC)
+
+	suite.mockSql.ExpectBegin()
+	suite.mockSql.ExpectQuery("SELECT id FROM courts WHERE id = \\$1 FOR UPDATE").
+		WithArgs(entry.OfferedCourtId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("court_1"))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM court_closures").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM bookings").
+		WithArgs(entry.OfferedCourtId, entry.BookingDate, entry.OfferedStartTime.Add(2*time.Hour), entry.OfferedStartTime, "").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectQuery("SELECT COUNT\\(\\*\\) FROM waitlist_entries").
+		WithArgs(entry.OfferedCourtId, entry.BookingDate, entry.OfferedStartTime.Add(2*time.Hour), entry.OfferedStartTime, model.WaitlistOffered, sqlmock.AnyArg(), entry.CustomerId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mockSql.ExpectExec("UPDATE waitlist_entries SET status = \\$1").
+		WithArgs(model.WaitlistOffered, entry.OfferedCourtId, entry.OfferedStartTime, entry.OfferExpiresAt, entry.Id, model.WaitlistWaiting).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mockSql.ExpectCommit()
+
+	err := suite.repo.Offer(entry)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestOffer_NoLongerWaiting() {
+	suite.mockSql.ExpectBegin()
+	suite.expectOfferSlotChecked(0)
+	suite.mockSql.ExpectExec("UPDATE waitlist_entries SET status = \\$1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mockSql.ExpectRollback()
+
+	err := suite.repo.Offer(mockWaitlistEntry)
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestOffer_SlotRebooked() {
+	suite.mockSql.ExpectBegin()
+	suite.expectOfferSlotChecked(1)
+	suite.mockSql.ExpectRollback()
+
+	err := suite.repo.Offer(mockWaitlistEntry)
+	assert.ErrorIs(suite.T(), err, ErrBookingConflict)
+	assert.NoError(suite.T(), suite.mockSql.ExpectationsWereMet())
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestFulfil_Success() {
+	suite.mockSql.ExpectExec("UPDATE waitlist_entries SET status = \\$1, booking_id = \\$2 WHERE id = \\$3 AND status = \\$4").
+		WithArgs(model.WaitlistBooked, "booking_1", "entry_1", model.WaitlistOffered).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.Fulfil("entry_1", "booking_1")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestLeave_AlreadyClosed() {
+	suite.mockSql.ExpectExec("UPDATE waitlist_entries SET status = \\$1 WHERE id = \\$2 AND status IN").
+		WithArgs(model.WaitlistCancelled, "entry_1", model.WaitlistWaiting, model.WaitlistOffered).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.Leave("entry_1")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *WaitlistRepositoryTestSuite) TestExpire_Failed() {
+	suite.mockSql.ExpectExec("UPDATE waitlist_entries SET status = \\$1 WHERE id = \\$2 AND status = \\$3").
+		WithArgs(model.WaitlistExpired, "entry_1", model.WaitlistOffered).
+		WillReturnError(errors.New("update error"))
+
+	err := suite.repo.Expire("entry_1")
+	assert.EqualError(suite.T(), err, "update error")
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"team2/shuttleslot/config"
 	"team2/shuttleslot/controller"
 	"team2/shuttleslot/middleware"
 	"team2/shuttleslot/repository"
 	"team2/shuttleslot/service"
 	"team2/shuttleslot/util"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
@@ -19,6 +22,18 @@ type Server struct {
 	cS      service.CourtService
 	bS      service.BookingService
 	pGS     service.PaymentGateService
+	pNS     service.PaymentNotificationService
+	fake    *service.FakeProvider
+	pS      service.PricingService
+	prS     service.PromoService
+	lS      service.LoyaltyService
+	rS      service.RestrictionService
+	wS      service.WaitlistService
+	sweeper *service.BookingSweeper
+	noShow  *service.NoShowSweeper
+	wSweep  *service.WaitlistSweeper
+	pR      *service.PaymentReconciler
+	aS      service.AuditService
 	auth    middleware.AuthMiddleware
 	util    util.UtilInterface
 	engine  *gin.Engine
@@ -29,11 +44,26 @@ func (s *Server) initiateRoute() {
 	routerGroup := s.engine.Group("/api/v1")
 	controller.NewUserController(s.uS, s.auth, routerGroup).Route()
 	controller.NewCourtController(s.cS, s.auth, routerGroup).Route()
-	controller.NewBookingController(s.bS, s.auth, routerGroup).Route()
+	controller.NewBookingController(s.bS, s.pNS, s.auth, routerGroup).Route()
+	controller.NewPricingRuleController(s.pS, s.auth, routerGroup).Route()
+	controller.NewPromoCodeController(s.prS, s.auth, routerGroup).Route()
+	controller.NewLoyaltyController(s.lS, s.auth, routerGroup).Route()
+	controller.NewReconciliationController(s.pR, s.auth, routerGroup).Route()
+	controller.NewAuditController(s.aS, s.auth, routerGroup).Route()
+	controller.NewRestrictionController(s.rS, s.auth, routerGroup).Route()
+	controller.NewWaitlistController(s.wS, s.bS, s.auth, routerGroup).Route()
+
+	if s.fake != nil {
+		controller.NewFakeGatewayController(s.fake, routerGroup).Route()
+	}
 }
 
 func (s *Server) Start() {
 	s.initiateRoute()
+	s.sweeper.Start(context.Background())
+	s.noShow.Start(context.Background())
+	s.wSweep.Start(context.Background())
+	s.pR.Start(context.Background())
 	s.engine.Run(s.portApp)
 }
 
@@ -51,13 +81,43 @@ func NewServer() *Server {
 	userRepository := repository.NewUserRepository(db)
 	courtRepository := repository.NewCourtRepository(db)
 	bookingRepository := repository.NewBookingRepository(db)
+	pricingRuleRepository := repository.NewPricingRuleRepository(db)
+	promoCodeRepository := repository.NewPromoCodeRepository(db)
+	loyaltyRepository := repository.NewLoyaltyRepository(db)
+	auditRepository := repository.NewAuditRepository(db)
+	restrictionRepository := repository.NewRestrictionRepository(db)
+	waitlistRepository := repository.NewWaitlistRepository(db)
+	paymentNotificationRepository := repository.NewPaymentNotificationRepository(db)
+
+	paymentProvider, err := service.NewPaymentProvider(co.PayGateConfig)
+	if err != nil {
+		log.Fatalf("failed to set up payment provider: %v", err)
+	}
 
 	utilService := util.NewUtilService()
-	payGateService := service.NewPayGateService(co.PayGateConfig, bookingRepository)
+	auditService := service.NewAuditService(auditRepository)
+	payGateService := service.NewPayGateService(co.PayGateConfig, paymentProvider, bookingRepository, auditService)
 	authService := service.NewAuthService(co.SecurityConfig)
-	userService := service.NewUserService(userRepository, authService, utilService)
-	courtService := service.NewCourtService(courtRepository)
-	bookingService := service.NewBookingService(bookingRepository, userService, courtService, payGateService)
+	userService := service.NewUserService(userRepository, authService, utilService, auditService)
+	courtService := service.NewCourtService(courtRepository, auditService)
+	pricingService := service.NewPricingService(pricingRuleRepository)
+	promoService := service.NewPromoService(promoCodeRepository)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepository)
+	restrictionService := service.NewRestrictionService(restrictionRepository, userService, auditService, co.NoShowPenaltyConfig)
+	waitlistService := service.NewWaitlistService(waitlistRepository, courtService, restrictionService, co.WaitlistConfig)
+	bookingService := service.NewBookingService(bookingRepository, userService, courtService, payGateService, pricingService, promoService, loyaltyService, auditService, authService, restrictionService, waitlistService, co.CancelPolicyConfig, co.DepositConfig, co.ScheduleConfig)
+
+	paymentNotificationService := service.NewPaymentNotificationService(paymentNotificationRepository, bookingService)
+
+	fakeProvider, _ := paymentProvider.(*service.FakeProvider)
+	if fakeProvider != nil {
+		fakeProvider.SetNotifier(paymentNotificationService.Receive)
+	}
+
+	bookingSweeper := service.NewBookingSweeper(bookingService, co.ExpiryConfig, time.Now)
+	noShowSweeper := service.NewNoShowSweeper(bookingService, co.NoShowConfig, time.Now)
+	waitlistSweeper := service.NewWaitlistSweeper(waitlistService, co.WaitlistConfig, time.Now)
+	paymentReconciler := service.NewPaymentReconciler(bookingRepository, paymentProvider, paymentNotificationService, co.ReconcileConfig, time.Now)
 
 	authMiddleware := middleware.NewAuthMiddleware(authService)
 
@@ -67,6 +127,18 @@ func NewServer() *Server {
 		engine:  gin.Default(),
 		bS:      bookingService,
 		pGS:     payGateService,
+		pNS:     paymentNotificationService,
+		fake:    fakeProvider,
+		pS:      pricingService,
+		prS:     promoService,
+		lS:      loyaltyService,
+		rS:      restrictionService,
+		wS:      waitlistService,
+		sweeper: bookingSweeper,
+		noShow:  noShowSweeper,
+		wSweep:  waitlistSweeper,
+		pR:      paymentReconciler,
+		aS:      auditService,
 		auth:    authMiddleware,
 		portApp: portApp,
 	}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+)
+
+type AuditService interface {
+	Record(entry model.AuditLog, before any, after any)
+	FindAll(filter dto.AuditLogFilter, page int, size int) ([]model.AuditLog, dto.Paginate, error)
+}
+
+type auditService struct {
+	auditRepository repository.AuditRepository
+}
+
+// Record appends entry to the audit log with before and after stored as JSON,
+// either may be nil. The change being audited has already happened, so a
+// failure to write the entry is logged rather than returned.
+func (s *auditService) Record(entry model.AuditLog, before any, after any) {
+	if before != nil {
+		data, err := json.Marshal(before)
+		if err != nil {
+			log.Println("failed to encode audit before value:", err)
+		}
+		entry.Before = string(data)
+	}
+
+	if after != nil {
+		data, err := json.Marshal(after)
+		if err != nil {
+			log.Println("failed to encode audit after value:", err)
+		}
+		entry.After = string(data)
+	}
+
+	_, err := s.auditRepository.Create(entry)
+	if err != nil {
+		log.Printf("failed to audit %s on %s %s: %v\n", entry.Action, entry.EntityType, entry.EntityId, err)
+	}
+}
+
+func (s *auditService) FindAll(filter dto.AuditLogFilter, page int, size int) ([]model.AuditLog, dto.Paginate, error) {
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() && filter.StartDate.After(filter.EndDate) {
+		return []model.AuditLog{}, dto.Paginate{}, errors.New("invalid date range, startDate cannot be after endDate")
+	}
+
+	return s.auditRepository.FindAll(filter, page, size)
+}
+
+func NewAuditService(auditRepository repository.AuditRepository) AuditService {
+	return &auditService{
+		auditRepository: auditRepository,
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	repomock "team2/shuttleslot/mock/repo_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditServiceTestSuite struct {
+	suite.Suite
+	repoMock *repomock.AuditRepositoryMock
+	aS       AuditService
+}
+
+func (suite *AuditServiceTestSuite) SetupTest() {
+	suite.repoMock = new(repomock.AuditRepositoryMock)
+	suite.aS = NewAuditService(suite.repoMock)
+}
+
+func TestAuditServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditServiceTestSuite))
+}
+
+func (suite *AuditServiceTestSuite) TestRecord_EncodesBeforeAndAfter() {
+	entry := model.AuditLog{Actor: "employee_1", Action: "booking.cancel", EntityType: "booking", EntityId: "1"}
+	expected := entry
+	expected.Before = `{"id":"1","status":"booked"}`
+	expected.After = `{"id":"1","status":"cancel"}`
+	suite.repoMock.On("Create", expected).Return(expected, nil)
+
+	suite.aS.Record(entry, map[string]string{"id": "1", "status": "booked"}, map[string]string{"id": "1", "status": "cancel"})
+
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *AuditServiceTestSuite) TestRecord_WithoutBefore() {
+	entry := model.AuditLog{Actor: "admin_1", Action: "court.create", EntityType: "court", EntityId: "1"}
+	suite.repoMock.On("Create", mock.MatchedBy(func(e model.AuditLog) bool {
+		return e.Before == "" && e.After == `{"name":"field 1"}`
+	})).Return(model.AuditLog{}, nil)
+
+	suite.aS.Record(entry, nil, map[string]string{"name": "field 1"})
+
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *AuditServiceTestSuite) TestRecord_CreateErrorIsSwallowed() {
+	suite.repoMock.On("Create", mock.Anything).Return(model.AuditLog{}, errors.New("error"))
+
+	suite.NotPanics(func() {
+		suite.aS.Record(model.AuditLog{Action: "court.delete"}, nil, nil)
+	})
+}
+
+func (suite *AuditServiceTestSuite) TestFindAll_Success() {
+	filter := dto.AuditLogFilter{EntityType: "booking", EntityId: "1"}
+	entries := []model.AuditLog{{Id: "1", Action: "booking.create"}}
+	paginate := dto.Paginate{Page: 1, Size: 10, TotalRows: 1, TotalPages: 1}
+	suite.repoMock.On("FindAll", filter, 1, 10).Return(entries, paginate, nil)
+
+	result, resultPaginate, err := suite.aS.FindAll(filter, 1, 10)
+
+	suite.NoError(err)
+	suite.Equal(entries, result)
+	suite.Equal(paginate, resultPaginate)
+}
+
+func (suite *AuditServiceTestSuite) TestFindAll_InvalidDateRange() {
+	filter := dto.AuditLogFilter{
+		StartDate: time.Date(2030, 10, 7, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2030, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	_, _, err := suite.aS.FindAll(filter, 1, 10)
+
+	suite.ErrorContains(err, "invalid date range")
+	suite.repoMock.AssertNotCalled(suite.T(), "FindAll", mock.Anything, mock.Anything, mock.Anything)
+}
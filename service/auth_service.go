@@ -13,8 +13,19 @@ import (
 type AuthService interface {
 	GenerateToken(payload model.User) (dto.LoginResponse, error)
 	VerifyToken(token string) (jwt.MapClaims, error)
+	GenerateLookupToken(booking model.Booking) (string, error)
+	VerifyLookupToken(token string) (string, error)
 }
 
+const (
+	// lookupAudience marks tokens that only let a guest look up a single
+	// booking, so they are never accepted in place of a login token.
+	lookupAudience = "booking-lookup"
+	// lookupTokenDays is how long after the booking date a guest can still
+	// look the booking up.
+	lookupTokenDays = 30
+)
+
 type authService struct {
 	config config.SecurityConfig
 }
@@ -54,6 +65,32 @@ func (auth *authService) VerifyToken(tokenString string) (jwt.MapClaims, error)
 	return claims, nil
 }
 
+// GenerateLookupToken implements AuthService.
+func (auth *authService) GenerateLookupToken(booking model.Booking) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Issuer:    auth.config.Issuer,
+		Subject:   booking.Id,
+		Audience:  jwt.ClaimStrings{lookupAudience},
+		ExpiresAt: jwt.NewNumericDate(booking.BookingDate.AddDate(0, 0, lookupTokenDays)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(auth.config.Key))
+}
+
+// VerifyLookupToken implements AuthService. It returns the id of the booking
+// the token was issued for.
+func (auth *authService) VerifyLookupToken(tokenString string) (string, error) {
+	var claims jwt.RegisteredClaims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(auth.config.Key), nil
+	}, jwt.WithIssuer(auth.config.Issuer), jwt.WithAudience(lookupAudience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
+	if err != nil || !token.Valid || claims.Subject == "" {
+		return "", errors.New("forbidden, invalid or expired lookup token")
+	}
+	return claims.Subject, nil
+}
+
 func NewAuthService(authConfig config.SecurityConfig) AuthService {
 	return &authService{
 		config: authConfig,
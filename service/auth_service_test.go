@@ -2,7 +2,9 @@ package service
 
 import (
 	"team2/shuttleslot/config"
+	"team2/shuttleslot/model"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -60,3 +62,28 @@ func (suite *AuthServiceTestSuite) TestVerifyToken_Fail_InvalidIssuer() {
 	assert.Error(suite.T(), err)
 	assert.EqualError(suite.T(), err, "invalid issuer or claim")
 }
+
+func (suite *AuthServiceTestSuite) TestVerifyLookupToken_Success() {
+	token, err := suite.aS.GenerateLookupToken(model.Booking{Id: "booking_1", BookingDate: time.Now()})
+	assert.NoError(suite.T(), err)
+
+	bookingId, err := suite.aS.VerifyLookupToken(token)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "booking_1", bookingId)
+}
+
+func (suite *AuthServiceTestSuite) TestVerifyLookupToken_Expired() {
+	token, err := suite.aS.GenerateLookupToken(model.Booking{Id: "booking_1", BookingDate: time.Now().AddDate(0, 0, -31)})
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.aS.VerifyLookupToken(token)
+	assert.EqualError(suite.T(), err, "forbidden, invalid or expired lookup token")
+}
+
+func (suite *AuthServiceTestSuite) TestVerifyLookupToken_RejectsLoginToken() {
+	login, err := suite.aS.GenerateToken(mockUser)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.aS.VerifyLookupToken(login.Token)
+	assert.EqualError(suite.T(), err, "forbidden, invalid or expired lookup token")
+}
@@ -1,25 +1,66 @@
 package service
 
 import (
+	crand "crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
 	"math/rand"
 	"strings"
+	"team2/shuttleslot/config"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
 	"team2/shuttleslot/repository"
 	"team2/shuttleslot/util"
 	"time"
+
+	"github.com/skip2/go-qrcode"
 )
 
 type BookingService interface {
 	Create(payload dto.CreateBookingRequest) (model.Booking, error)
+	CreateWalkIn(payload dto.CreateWalkInBookingRequest) (model.Booking, error)
+	CreateGuestBooking(payload dto.CreateGuestBookingRequest) (model.Booking, string, error)
+	FindGuestBooking(token string) (model.Booking, error)
+	CancelGuestBooking(token string) (model.Booking, error)
 	UpdatePayment(payload dto.PaymentNotificationInput) error
 	CreateRepay(payload dto.CreateRepayRequest) (model.Payment, error)
+	Refund(payload dto.CreateRefundRequest) (model.Payment, error)
+	Cancel(payload dto.CancelBookingRequest) (model.Booking, error)
+	Reschedule(payload dto.RescheduleBookingRequest) (model.Booking, error)
+	CreateSeries(payload dto.CreateBookingSeriesRequest) (model.BookingSeries, error)
+	CancelSeries(payload dto.CancelBookingSeriesRequest) ([]model.Booking, error)
 	FindAllBookings(page int, size int) ([]model.Booking, dto.Paginate, error)
 	FindBookedCourt(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error)
 	FindEndingBookings(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error)
 	FindPaymentReport(day, month, year, page, size int, filterType string) ([]model.Payment, dto.Paginate, int64, error)
+	FindAvailability(payload dto.AvailabilityRequest, page int, size int) ([]model.CourtAvailability, dto.Paginate, error)
+	ExpirePending(cutoff time.Time) (int, error)
+	FindStatusHistory(bookingId string) ([]model.BookingStatusHistory, error)
+	FindCustomerBookings(customerId string, upcoming bool, page int, size int) ([]model.Booking, dto.Paginate, error)
+	FindCustomerBooking(customerId string, bookingId string) (model.Booking, error)
+	CheckInQRCode(customerId string, bookingId string) ([]byte, error)
+	GuestCheckInQRCode(token string) ([]byte, error)
+	CheckIn(payload dto.CheckInRequest) (model.Booking, error)
+	MarkNoShows(cutoff time.Time) (int, error)
+	CountNoShows(customerId string) (int, error)
+	AcceptWaitlistOffer(customerId string, entryId string) (model.Booking, error)
+}
+
+const (
+	maxSeriesOccurrences = 52
+	maxAvailabilityDays  = 31
+)
+
+// SeriesCollisionError lists every occurrence of a recurring booking that
+// cannot be booked because the court is already taken.
+type SeriesCollisionError struct {
+	Collisions []dto.SeriesCollision
+}
+
+func (e *SeriesCollisionError) Error() string {
+	return fmt.Sprintf("cannot book series, %d dates collide with another schedule", len(e.Collisions))
 }
 
 type bookingService struct {
@@ -27,6 +68,16 @@ type bookingService struct {
 	userServ          UserService
 	courtServ         CourtService
 	payGate           PaymentGateService
+	pricingServ       PricingService
+	promoServ         PromoService
+	loyaltyServ       LoyaltyService
+	audit             AuditService
+	auth              AuthService
+	restrictionServ   RestrictionService
+	waitlistServ      WaitlistService
+	cancelPolicy      config.CancelPolicyConfig
+	deposit           config.DepositConfig
+	schedule          config.ScheduleConfig
 }
 
 func (s *bookingService) Create(payload dto.CreateBookingRequest) (model.Booking, error) {
@@ -40,19 +91,12 @@ func (s *bookingService) Create(payload dto.CreateBookingRequest) (model.Booking
 	endTime := util.StringToTime(payload.StartTime).Add(time.Hour * time.Duration(payload.Hour))
 
 	for _, val := range existBooking {
-		if val.Customer.Id == payload.CustomerId && val.Status == "pending" {
+		if val.Customer.Id == payload.CustomerId && val.Status == model.BookingPending {
 			return model.Booking{}, errors.New("cannot book, there still payment to complete")
 		}
-		if val.Court.Id == payload.CourtId && util.DateToString(val.BookingDate) == payload.BookingDate && (val.Status == "pending" || val.Status == "booked" || val.Status == "done") {
-			if util.InTimeSpanStart(val.StartTime, val.EndTime, util.StringToTime(payload.StartTime)) {
-				err = errors.New("cannot book court in that time")
-
-			} else if util.InTimeSpanEnd(val.StartTime, val.EndTime, endTime) {
-				err = errors.New("cannot book that long, because the schedule collides with another schedule")
-
-			}
-		}
 	}
+
+	err = findCollision(existBooking, payload.CourtId, payload.BookingDate, util.StringToTime(payload.StartTime), endTime, "")
 	if err != nil {
 		return model.Booking{}, err
 	}
@@ -62,27 +106,64 @@ func (s *bookingService) Create(payload dto.CreateBookingRequest) (model.Booking
 		return model.Booking{}, err
 	}
 
+	err = s.checkRestrictions(customer.Id, payload.EmployeeId != "", payload.PayInFull || payload.PaymentMethod == "cash", false)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
 	court, err := s.courtServ.FindCourtById(payload.CourtId)
 	if err != nil {
 		return model.Booking{}, err
 	}
 
+	err = s.checkCourtSchedule(court, util.StringToDate(payload.BookingDate), util.StringToTime(payload.StartTime), payload.Hour)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
 	totalBooking, err := s.bookingRepository.FindTotal(payload.CustomerId)
 	if err != nil {
 		return model.Booking{}, err
 	}
 
+	items, err := s.pricingServ.PriceBooking(court, util.StringToDate(payload.BookingDate), util.StringToTime(payload.StartTime), payload.Hour)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	var redemption model.PromoRedemption
+
+	if payload.PromoCode != "" {
+		redemption, err = s.promoServ.ApplyPromo(payload.PromoCode, customer.Id, court.Id, totalPrice(items))
+		if err != nil {
+			return model.Booking{}, err
+		}
+
+		items = append(items, promoItem(redemption))
+	}
+
+	var pointRedeemed model.PointEntry
+
+	if payload.UsePoints {
+		pointRedeemed, err = s.loyaltyServ.QuoteRedemption(customer.Id, totalPrice(items))
+		if err != nil {
+			return model.Booking{}, err
+		}
+
+		items = append(items, pointsItem(pointRedeemed))
+	}
+
 	random := rand.New(rand.NewSource(time.Now().UnixNano()))
 	orderId := fmt.Sprintf("Booking%s-%d", fmt.Sprintf("%05d", totalBooking+1), random.Int())
 	desc := fmt.Sprintf("Pembayaran Booking %s", court.Name)
-	realCourtPrice := 0
+	totalPayment := totalPrice(items)
 
-	if customer.Point >= 100 {
-		realCourtPrice = court.Price - 10000
-		court.Price = realCourtPrice / 2
-	} else {
-		realCourtPrice = court.Price
-		court.Price = court.Price / 2
+	// Paying in full charges the whole total now, so the booking is settled by
+	// the gateway and needs no repayment at the court. Cash at the desk is
+	// always the whole total.
+	depositPrice := s.depositPrice(court, totalPayment)
+	if payload.PayInFull || payload.PaymentMethod == "cash" {
+		depositPrice = totalPayment
 	}
 
 	payment := model.Payment{
@@ -90,27 +171,39 @@ func (s *bookingService) Create(payload dto.CreateBookingRequest) (model.Booking
 		Description: desc,
 		Court:       court,
 		User:        customer,
-		Price:       (realCourtPrice * payload.Hour) / 2,
+		Price:       depositPrice,
 		Qty:         payload.Hour,
+		Items:       depositItems(items, depositPrice),
 	}
 
-	paymentURL, err := s.payGate.GetPaymentURL(payment)
-	if err != nil {
-		return model.Booking{}, err
+	// A promo or points covering everything due upfront leave nothing for the
+	// gateway to charge, so the booking is settled without a transaction.
+	var paymentURL string
+	if payload.PaymentMethod != "cash" && depositPrice > 0 {
+		paymentURL, err = s.payGate.GetPaymentURL(payment)
+		if err != nil {
+			return model.Booking{}, err
+		}
 	}
 
 	newPayload = model.Booking{
-		Customer:      customer,
-		Court:         court,
-		Total_Payment: realCourtPrice * payload.Hour,
-		BookingDate:   util.StringToDate(payload.BookingDate),
-		StartTime:     util.StringToTime(payload.StartTime),
-		EndTime:       endTime,
+		Customer:       customer,
+		Employee:       model.User{Id: payload.EmployeeId},
+		Court:          court,
+		Total_Payment:  totalPayment,
+		BookingDate:    util.StringToDate(payload.BookingDate),
+		StartTime:      util.StringToTime(payload.StartTime),
+		EndTime:        endTime,
+		PriceBreakdown: items,
+		Promo:          redemption,
+		PointRedeemed:  pointRedeemed,
 		PaymentDetails: []model.Payment{
 			{
-				OrderId:     payment.OrderId,
-				Description: payment.Description,
-				PaymentURL:  paymentURL,
+				OrderId:       payment.OrderId,
+				Description:   payment.Description,
+				PaymentMethod: payload.PaymentMethod,
+				Price:         payment.Price,
+				PaymentURL:    paymentURL,
 			},
 		},
 	}
@@ -123,33 +216,240 @@ func (s *bookingService) Create(payload dto.CreateBookingRequest) (model.Booking
 	booking.PaymentDetails[0].PaymentURL = paymentURL
 	booking.Court = court
 	booking.Customer = customer
+	booking.PriceBreakdown = items
+
+	actor := payload.CustomerId
+	if payload.EmployeeId != "" {
+		actor = payload.EmployeeId
+	}
+
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "booking.create", EntityType: "booking", EntityId: booking.Id}, nil, auditedBooking(booking))
 
 	return booking, nil
 }
 
+// CreateWalkIn books on behalf of a customer at the front desk. The customer is
+// either an existing account or a guest looked up, or created, by phone number.
+func (s *bookingService) CreateWalkIn(payload dto.CreateWalkInBookingRequest) (model.Booking, error) {
+	customerId := payload.CustomerId
+
+	if customerId != "" {
+		_, err := s.userServ.FindUserById(customerId)
+		if err != nil {
+			return model.Booking{}, errors.New("customer not found")
+		}
+	} else {
+		guest, err := s.userServ.FindOrCreateGuest(model.User{Name: payload.Name, PhoneNumber: payload.PhoneNumber}, payload.EmployeeId)
+		if err != nil {
+			return model.Booking{}, err
+		}
+		customerId = guest.Id
+	}
+
+	return s.Create(dto.CreateBookingRequest{
+		CourtId:       payload.CourtId,
+		BookingDate:   payload.BookingDate,
+		StartTime:     payload.StartTime,
+		Hour:          payload.Hour,
+		CustomerId:    customerId,
+		PromoCode:     payload.PromoCode,
+		PayInFull:     payload.PayInFull,
+		EmployeeId:    payload.EmployeeId,
+		PaymentMethod: payload.PaymentMethod,
+	})
+}
+
+// CreateGuestBooking books for someone without an account. The booking is kept
+// on a guest found or created by phone number or email, and the returned
+// lookup token is how the guest views or cancels it.
+func (s *bookingService) CreateGuestBooking(payload dto.CreateGuestBookingRequest) (model.Booking, string, error) {
+	guest, err := s.userServ.FindOrCreateGuest(model.User{Name: payload.Name, PhoneNumber: payload.PhoneNumber, Email: payload.Email}, "guest")
+	if err != nil {
+		return model.Booking{}, "", err
+	}
+
+	if guest.Role != "guest" {
+		return model.Booking{}, "", errors.New("cannot book as guest, an account with this phone number or email already exists, please log in")
+	}
+
+	booking, err := s.Create(dto.CreateBookingRequest{
+		CourtId:     payload.CourtId,
+		BookingDate: payload.BookingDate,
+		StartTime:   payload.StartTime,
+		Hour:        payload.Hour,
+		CustomerId:  guest.Id,
+		PromoCode:   payload.PromoCode,
+		PayInFull:   payload.PayInFull,
+	})
+	if err != nil {
+		return model.Booking{}, "", err
+	}
+
+	token, err := s.auth.GenerateLookupToken(booking)
+	if err != nil {
+		return model.Booking{}, "", err
+	}
+
+	return booking, token, nil
+}
+
 func (s *bookingService) UpdatePayment(payload dto.PaymentNotificationInput) error {
 	payment, err := s.payGate.PaymentProcess(payload)
 	if err != nil {
 		return err
 	}
 
+	current, err := s.bookingRepository.FindPaymentByOrderId(payload.OrderId)
+	if err != nil {
+		return err
+	}
+
 	if strings.Contains(payload.OrderId, "Booking") {
 		err = s.bookingRepository.UpdateStatus(payment)
+	} else if strings.Contains(payload.OrderId, "Series") {
+		err = s.bookingRepository.UpdateSeriesStatus(payment)
+	} else if strings.Contains(payload.OrderId, "Reschedule") {
+		err = s.bookingRepository.UpdatePaymentStatus(payment)
+	} else {
+		err = s.bookingRepository.UpdateRepaymentStatus(payment)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(model.AuditLog{Actor: "payment-gateway", Action: "payment.update", EntityType: "payment", EntityId: payment.OrderId}, auditedPayment(current), auditedPayment(payment))
+
+	if strings.Contains(payload.OrderId, "Booking") && payment.Status == "cancel" {
+		s.releaseBooking(current.BookingId)
+	}
+
+	return nil
+}
+
+// ExpirePending cancels every pending booking created before cutoff whose down
+// payment is still unpaid, the same way an expire notification would. The
+// gateway transaction is closed first so a late payment cannot settle an order
+// that is no longer held; one the gateway refuses to close, e.g. because it was
+// paid in the meantime, is left for the payment notification or reconciler.
+func (s *bookingService) ExpirePending(cutoff time.Time) (int, error) {
+	payments, err := s.bookingRepository.FindExpiredPending(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	expiredOrders := make(map[string]bool)
+	var errs []error
+
+	for _, payment := range payments {
+		before := payment
+		payment.Status = "cancel"
+		payment.User.Id = "system"
+
+		entry := model.AuditLog{Actor: "system", Action: "payment.expire", EntityType: "payment", EntityId: payment.OrderId}
+
+		if strings.Contains(payment.OrderId, "Series") {
+			if !expiredOrders[payment.OrderId] {
+				err = s.payGate.Expire(payment.OrderId)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+
+				err = s.bookingRepository.UpdateSeriesStatus(payment)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				expiredOrders[payment.OrderId] = true
+				s.audit.Record(entry, before, payment)
+			}
+
+			expired++
+			continue
+		}
+
+		err = s.payGate.Expire(payment.OrderId)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		err = s.bookingRepository.UpdateStatus(payment)
 		if err != nil {
-			return err
+			errs = append(errs, err)
+			continue
 		}
 
-		return nil
+		s.audit.Record(entry, before, payment)
+		s.releaseBooking(payment.BookingId)
+		expired++
 	}
 
-	err = s.bookingRepository.UpdateRepaymentStatus(payment)
+	return expired, errors.Join(errs...)
+}
+
+// checkRestrictions refuses a booking the customer's active restrictions do
+// not allow. Bookings made by an employee at the desk are not online bookings.
+// A series is always booked with a down payment, so a customer who must
+// prepay in full is sent to single bookings instead.
+func (s *bookingService) checkRestrictions(customerId string, atDesk bool, payInFull bool, series bool) error {
+	restrictions, err := s.restrictionServ.FindActiveRestrictions(customerId)
 	if err != nil {
 		return err
 	}
 
+	for _, val := range restrictions {
+		if val.Type == model.RestrictionBlockOnline && !atDesk {
+			return fmt.Errorf("cannot book online, your account is restricted until %s: %s", util.DateToString(val.EndsAt), val.Reason)
+		}
+		if val.Type == model.RestrictionFullPrepayment && series {
+			return fmt.Errorf("cannot book series, recurring bookings are unavailable while your account requires full prepayment until %s: %s, book single sessions paid in full instead", util.DateToString(val.EndsAt), val.Reason)
+		}
+		if val.Type == model.RestrictionFullPrepayment && !payInFull {
+			return fmt.Errorf("cannot book with a down payment, your account requires full prepayment until %s: %s", util.DateToString(val.EndsAt), val.Reason)
+		}
+	}
+
 	return nil
 }
 
+// MarkNoShows moves every booked booking that started before cutoff without a
+// check-in to no-show and penalises the customer when the rules say so. A
+// booking checked in while the job runs is skipped.
+func (s *bookingService) MarkNoShows(cutoff time.Time) (int, error) {
+	bookings, err := s.bookingRepository.FindMissedCheckIns(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	marked := 0
+	var errs []error
+
+	for _, booking := range bookings {
+		err = s.bookingRepository.ChangeStatus(booking.Id, model.BookingNoShow, "system", "not checked in within the grace period")
+		if err != nil {
+			var transitionErr *model.BookingTransitionError
+			if !errors.As(err, &transitionErr) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		before := booking
+		booking.Status = model.BookingNoShow
+		s.audit.Record(model.AuditLog{Actor: "system", Action: "booking.no_show", EntityType: "booking", EntityId: booking.Id}, auditedBooking(before), auditedBooking(booking))
+		marked++
+
+		_, err = s.restrictionServ.ApplyNoShowPenalties(booking.Customer.Id)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return marked, errors.Join(errs...)
+}
+
 func (s *bookingService) CreateRepay(payload dto.CreateRepayRequest) (model.Payment, error) {
 	var newPayload model.Payment
 
@@ -158,14 +458,33 @@ func (s *bookingService) CreateRepay(payload dto.CreateRepayRequest) (model.Paym
 		return model.Payment{}, err
 	}
 
-	if booking.Status != "booked" {
-		if booking.Status == "done" {
+	if booking.Status != model.BookingBooked && booking.Status != model.BookingCheckedIn {
+		if booking.Status == model.BookingDone {
 			return model.Payment{}, errors.New("this booking already completed")
 
 		}
+		if booking.Status == model.BookingPaid {
+			return model.Payment{}, errors.New("this booking is already paid in full")
+		}
 		return model.Payment{}, errors.New("this booking still not booked")
 	}
 
+	payments, err := s.bookingRepository.FindPaymentsByBookingId(booking.Id)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	for _, val := range payments {
+		if val.Status == "unpaid" {
+			return model.Payment{}, errors.New("this booking still has payment to complete")
+		}
+	}
+
+	remaining := booking.Total_Payment - paidAmount(payments)
+	if remaining < 1 {
+		return model.Payment{}, errors.New("this booking has no remaining payment")
+	}
+
 	customer, err := s.userServ.FindUserById(booking.Customer.Id)
 	if err != nil {
 		return model.Payment{}, err
@@ -185,19 +504,20 @@ func (s *bookingService) CreateRepay(payload dto.CreateRepayRequest) (model.Paym
 	orderId := fmt.Sprintf("Repayment%s-%d", fmt.Sprintf("%05d", totalBooking), random.Int())
 	desc := fmt.Sprintf("Pelunasan Booking %s", court.Name)
 
-	realCourtPrice := booking.Total_Payment / 2
-	court.Price = realCourtPrice / 2
+	court.Price = remaining
 
 	newPayload = model.Payment{
 		BookingId:     payload.BookingId,
 		OrderId:       orderId,
 		Description:   desc,
 		PaymentMethod: payload.PaymentMethod,
-		Price:         booking.Total_Payment / 2,
-		Qty:           booking.Total_Payment / realCourtPrice,
+		Price:         remaining,
+		Qty:           1,
 		Court:         court,
 	}
 
+	entry := model.AuditLog{Actor: payload.EmployeeId, Action: "booking.repay", EntityType: "booking", EntityId: booking.Id}
+
 	if payload.PaymentMethod != "mid" {
 		newPayload.User.Id = payload.EmployeeId
 		payment, err := s.bookingRepository.CreateRepay(newPayload)
@@ -205,6 +525,8 @@ func (s *bookingService) CreateRepay(payload dto.CreateRepayRequest) (model.Paym
 			return model.Payment{}, err
 		}
 
+		s.audit.Record(entry, auditedBooking(booking), auditedPayment(payment))
+
 		return payment, nil
 	}
 
@@ -223,65 +545,1183 @@ func (s *bookingService) CreateRepay(payload dto.CreateRepayRequest) (model.Paym
 		return model.Payment{}, err
 	}
 
+	s.audit.Record(entry, auditedBooking(booking), auditedPayment(payment))
+
 	return payment, nil
 }
 
-func (s *bookingService) FindAllBookings(page int, size int) ([]model.Booking, dto.Paginate, error) {
+// sendRefund pays a refund back through the gateway before it is recorded,
+// unless it is handed back in cash at the desk, which is only recorded.
+func (s *bookingService) sendRefund(refund model.Payment) error {
+	if refund.PaymentMethod == "cash" {
+		return nil
+	}
 
-	return s.bookingRepository.FindAll(page, size)
+	return s.payGate.Refund(refund)
 }
 
-func (s *bookingService) FindBookedCourt(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error) {
-	bookings, paginate, err := s.bookingRepository.FindBooked(bookingDate, page, size)
+// Refund returns all or part of a paid payment. Online payments are refunded
+// through the payment gateway first, cash is handed back by the employee, and
+// either way a refund row tied to the original payment is recorded.
+func (s *bookingService) Refund(payload dto.CreateRefundRequest) (model.Payment, error) {
+	original, err := s.bookingRepository.FindPaymentByOrderId(payload.OrderId)
 	if err != nil {
-		return []model.Booking{}, dto.Paginate{}, err
+		return model.Payment{}, errors.New("payment not found")
 	}
 
-	for i, val := range bookings {
-		court, err := s.courtServ.FindCourtById(val.Court.Id)
+	if original.Status != "paid" {
+		return model.Payment{}, fmt.Errorf("cannot refund payment with status %s", original.Status)
+	}
+
+	payments, err := s.bookingRepository.FindPaymentsByBookingId(original.BookingId)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	refundable := original.Price
+	for _, val := range payments {
+		if val.Status == "refund" && val.RefundOf == original.OrderId {
+			refundable -= val.Price
+		}
+	}
+
+	if refundable < 1 {
+		return model.Payment{}, errors.New("cannot refund, this payment is already fully refunded")
+	}
+
+	amount := payload.Amount
+	if amount == 0 {
+		amount = refundable
+	}
+
+	if amount < 0 || amount > refundable {
+		return model.Payment{}, fmt.Errorf("invalid refund amount, %d of this payment is refundable", refundable)
+	}
+
+	paymentMethod := payload.PaymentMethod
+	if paymentMethod == "" {
+		paymentMethod = "mid"
+		if original.PaymentMethod == "cash" {
+			paymentMethod = "cash"
+		}
+	}
+
+	if paymentMethod == "mid" && original.PaymentMethod == "cash" {
+		return model.Payment{}, errors.New("cannot refund a cash payment through the payment gateway")
+	}
+
+	description := payload.Reason
+	if description == "" {
+		description = fmt.Sprintf("Refund %s", original.Description)
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	refund := model.Payment{
+		BookingId:     original.BookingId,
+		OrderId:       fmt.Sprintf("Refund%s-%d", original.BookingId, random.Int()),
+		Description:   description,
+		PaymentMethod: "cash",
+		Price:         amount,
+		Status:        "refund",
+		RefundOf:      original.OrderId,
+	}
+
+	if paymentMethod == "mid" {
+		refund.PaymentMethod = original.PaymentMethod
+	}
+
+	err = s.sendRefund(refund)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	refund.User.Id = payload.EmployeeId
+
+	payment, err := s.bookingRepository.CreateRefund(refund)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	s.audit.Record(model.AuditLog{Actor: payload.EmployeeId, Action: "payment.refund", EntityType: "payment", EntityId: original.OrderId}, auditedPayment(original), auditedPayment(payment))
+
+	// Once everything paid for a finished booking has gone back, the booking
+	// itself is refunded. A booking still to be played keeps its status.
+	if paidAmount(payments)-amount <= 0 {
+		booking, err := s.bookingRepository.FindById(original.BookingId)
 		if err != nil {
-			return []model.Booking{}, dto.Paginate{}, err
+			return model.Payment{}, err
 		}
 
-		bookings[i].Court = court
+		if booking.Status.CanTransitionTo(model.BookingRefunded) {
+			err = s.bookingRepository.ChangeStatus(booking.Id, model.BookingRefunded, payload.EmployeeId, "fully refunded")
+			if err != nil {
+				return model.Payment{}, err
+			}
+		}
 	}
 
-	return bookings, paginate, nil
+	return payment, nil
 }
 
-func (s *bookingService) FindEndingBookings(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error) {
-	bookings, paginate, err := s.bookingRepository.FindEnding(bookingDate, page, size)
+func (s *bookingService) Cancel(payload dto.CancelBookingRequest) (model.Booking, error) {
+	booking, err := s.bookingRepository.FindById(payload.BookingId)
 	if err != nil {
-		return []model.Booking{}, dto.Paginate{}, err
+		return model.Booking{}, errors.New("booking not found")
 	}
 
-	for i, val := range bookings {
-		customer, err := s.userServ.FindUserById(val.Customer.Id)
+	if payload.Role == "customer" && booking.Customer.Id != payload.UserId {
+		return model.Booking{}, errors.New("forbidden, this booking belongs to another customer")
+	}
+
+	if !booking.Status.CanTransitionTo(model.BookingCancel) {
+		return model.Booking{}, fmt.Errorf("cannot cancel booking with status %s", booking.Status)
+	}
+
+	payments, err := s.bookingRepository.FindPaymentsByBookingId(booking.Id)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	for _, val := range payments {
+		if val.Status == "unpaid" && strings.Contains(val.OrderId, "Series") {
+			return model.Booking{}, errors.New("cannot cancel one occurrence before the series down payment is completed, cancel the series instead")
+		}
+	}
+
+	// Unpaid orders are removed with the booking, so their gateway
+	// transactions are closed first; a late payment would otherwise settle an
+	// order that no longer belongs to any booking.
+	for _, val := range payments {
+		if val.Status != "unpaid" || val.PaymentMethod == "cash" {
+			continue
+		}
+
+		err = s.payGate.Expire(val.OrderId)
 		if err != nil {
-			return []model.Booking{}, dto.Paginate{}, err
+			return model.Booking{}, fmt.Errorf("cannot cancel, failed to close payment %s: %w", val.OrderId, err)
 		}
+	}
 
-		court, err := s.courtServ.FindCourtById(val.Court.Id)
+	now := time.Now()
+	hoursBefore := util.CombineDateTime(booking.BookingDate, booking.StartTime).Sub(util.CombineDateTime(now, now)).Hours()
+	refundPercent := s.refundPercent(hoursBefore)
+
+	refunds := splitRefund(booking.Id, payments, paidAmount(payments)*refundPercent/100, fmt.Sprintf("Refund %d%% down payment", refundPercent))
+
+	for _, refund := range refunds {
+		err = s.sendRefund(refund)
 		if err != nil {
-			return []model.Booking{}, dto.Paginate{}, err
+			return model.Booking{}, err
 		}
+	}
 
-		bookings[i].Customer = customer
-		bookings[i].Court = court
+	err = s.bookingRepository.Cancel(booking.Id, refunds, payload.UserId)
+	if err != nil {
+		return model.Booking{}, err
 	}
 
-	return bookings, paginate, nil
+	before := booking
+	booking.Status = model.BookingCancel
+	booking.PaymentDetails = append([]model.Payment{}, refunds...)
+
+	s.audit.Record(model.AuditLog{Actor: payload.UserId, Action: "booking.cancel", EntityType: "booking", EntityId: booking.Id}, auditedBooking(before), auditedBooking(booking))
+
+	s.releaseSlot(before)
+
+	return booking, nil
+}
+
+func (s *bookingService) releaseBooking(bookingId string) {
+	booking, err := s.bookingRepository.FindById(bookingId)
+	if err != nil {
+		log.Println("failed to offer released slot to the waitlist:", err)
+		return
+	}
+
+	s.releaseSlot(booking)
+}
+
+// releaseSlot offers the court time a booking gave up to the waitlist. The
+// booking is already cancelled, so a failed offer is only logged.
+func (s *bookingService) releaseSlot(booking model.Booking) {
+	entry, err := s.waitlistServ.OfferSlot(booking)
+	if err != nil {
+		log.Println("failed to offer released slot to the waitlist:", err)
+		return
+	}
+
+	if entry.Id != "" {
+		s.audit.Record(model.AuditLog{Actor: "system", Action: "waitlist.offer", EntityType: "waitlist", EntityId: entry.Id, Detail: "released by booking " + booking.Id}, nil, entry)
+	}
+}
+
+// AcceptWaitlistOffer books the slot a waitlist offer holds through the normal
+// booking path, leaving the customer with a pending booking to pay for.
+func (s *bookingService) AcceptWaitlistOffer(customerId string, entryId string) (model.Booking, error) {
+	entry, err := s.waitlistServ.ClaimOffer(customerId, entryId)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	// A customer who must prepay in full is still offered the slot, so the
+	// booking asks for the whole price instead of a down payment.
+	restrictions, err := s.restrictionServ.FindActiveRestrictions(customerId)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	booking, err := s.Create(dto.CreateBookingRequest{
+		CourtId:     entry.OfferedCourtId,
+		BookingDate: util.DateToString(entry.BookingDate),
+		StartTime:   util.TimeToString(entry.OfferedStartTime),
+		Hour:        entry.Hour,
+		CustomerId:  customerId,
+		PayInFull:   hasRestriction(restrictions, model.RestrictionFullPrepayment),
+	})
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	err = s.waitlistServ.FulfilOffer(entry.Id, booking.Id)
+	if err != nil {
+		log.Println("failed to mark waitlist offer as booked:", err)
+	}
+
+	return booking, nil
+}
+
+// CancelGuestBooking cancels the booking named by a guest's lookup token under
+// the same policy as a customer cancelling their own booking.
+func (s *bookingService) CancelGuestBooking(token string) (model.Booking, error) {
+	bookingId, err := s.auth.VerifyLookupToken(token)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	booking, err := s.bookingRepository.FindById(bookingId)
+	if err != nil {
+		return model.Booking{}, errors.New("booking not found")
+	}
+
+	return s.Cancel(dto.CancelBookingRequest{BookingId: booking.Id, UserId: booking.Customer.Id, Role: "customer"})
+}
+
+func (s *bookingService) Reschedule(payload dto.RescheduleBookingRequest) (model.Booking, error) {
+	booking, err := s.bookingRepository.FindById(payload.BookingId)
+	if err != nil {
+		return model.Booking{}, errors.New("booking not found")
+	}
+
+	if payload.Role == "customer" && booking.Customer.Id != payload.UserId {
+		return model.Booking{}, errors.New("forbidden, this booking belongs to another customer")
+	}
+
+	if booking.Status != model.BookingBooked && booking.Status != model.BookingPaid {
+		return model.Booking{}, fmt.Errorf("cannot reschedule booking with status %s", booking.Status)
+	}
+
+	if payload.CourtId == "" {
+		payload.CourtId = booking.Court.Id
+	}
+
+	if payload.Hour < 1 {
+		payload.Hour = int(booking.EndTime.Sub(booking.StartTime).Hours())
+	}
+
+	existBooking, err := s.bookingRepository.FindByDate(util.StringToDate(payload.BookingDate))
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	startTime := util.StringToTime(payload.StartTime)
+	endTime := startTime.Add(time.Hour * time.Duration(payload.Hour))
+
+	err = findCollision(existBooking, payload.CourtId, payload.BookingDate, startTime, endTime, booking.Id)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	customer, err := s.userServ.FindUserById(booking.Customer.Id)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	court, err := s.courtServ.FindCourtById(payload.CourtId)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	err = s.checkCourtSchedule(court, util.StringToDate(payload.BookingDate), startTime, payload.Hour)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	payments, err := s.bookingRepository.FindPaymentsByBookingId(booking.Id)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	for _, val := range payments {
+		if val.Status == "unpaid" {
+			return model.Booking{}, errors.New("cannot reschedule, there still payment to complete")
+		}
+	}
+
+	items, err := s.pricingServ.PriceBooking(court, util.StringToDate(payload.BookingDate), startTime, payload.Hour)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	// A promo or points redeemed on the original booking keep the same
+	// discount on the new schedule.
+	redemption, err := s.promoServ.FindRedemption(booking.Id)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	if redemption.Discount > 0 {
+		if total := totalPrice(items); redemption.Discount > total {
+			redemption.Discount = total
+		}
+		items = append(items, promoItem(redemption))
+	}
+
+	pointRedeemed, err := s.loyaltyServ.FindRedemption(booking.Id)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	if pointRedeemed.Amount > 0 {
+		if total := totalPrice(items); pointRedeemed.Amount > total {
+			pointRedeemed.Amount = total
+		}
+		items = append(items, pointsItem(pointRedeemed))
+	}
+
+	paid := paidAmount(payments)
+	totalPayment := totalPrice(items)
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	newPayload := model.Booking{
+		Id:             booking.Id,
+		Customer:       customer,
+		Court:          court,
+		BookingDate:    util.StringToDate(payload.BookingDate),
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Total_Payment:  totalPayment,
+		PriceBreakdown: items,
+	}
+
+	// The original down payment stays on the booking. A higher down payment
+	// for the new schedule is charged now, a lower one is credited against
+	// the repayment, and anything paid above the new total is refunded. A
+	// booking paid in full is charged the whole difference so it stays paid.
+	due := s.depositPrice(court, totalPayment)
+	if booking.Status == model.BookingPaid {
+		due = totalPayment
+	}
+
+	if difference := due - paid; difference > 0 {
+		adjustment := model.Payment{
+			OrderId:     fmt.Sprintf("Reschedule%s-%d", booking.Id, random.Int()),
+			Description: fmt.Sprintf("Selisih Reschedule Booking %s", court.Name),
+			Court:       model.Court{Name: court.Name, Price: difference},
+			User:        customer,
+			Price:       difference,
+			Qty:         1,
+		}
+
+		paymentURL, err := s.payGate.GetPaymentURL(adjustment)
+		if err != nil {
+			return model.Booking{}, err
+		}
+
+		adjustment.PaymentMethod = "mid"
+		adjustment.Status = "unpaid"
+		adjustment.PaymentURL = paymentURL
+		newPayload.PaymentDetails = append(newPayload.PaymentDetails, adjustment)
+	} else if paid > totalPayment {
+		refunds := splitRefund(booking.Id, payments, paid-totalPayment, "Refund Reschedule Booking")
+
+		for _, refund := range refunds {
+			err = s.sendRefund(refund)
+			if err != nil {
+				return model.Booking{}, err
+			}
+		}
+
+		newPayload.PaymentDetails = append(newPayload.PaymentDetails, refunds...)
+	}
+
+	rescheduled, err := s.bookingRepository.Reschedule(newPayload)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	rescheduled.Customer = customer
+	rescheduled.Court = court
+	rescheduled.PriceBreakdown = items
+	rescheduled.PaymentDetails = append(payments, rescheduled.PaymentDetails...)
+
+	s.audit.Record(model.AuditLog{Actor: payload.UserId, Action: "booking.reschedule", EntityType: "booking", EntityId: booking.Id}, auditedBooking(booking), auditedBooking(rescheduled))
+
+	return rescheduled, nil
+}
+
+func (s *bookingService) CreateSeries(payload dto.CreateBookingSeriesRequest) (model.BookingSeries, error) {
+	series := model.BookingSeries{Frequency: payload.Frequency}
+
+	err := s.checkRestrictions(payload.CustomerId, false, false, true)
+	if err != nil {
+		return model.BookingSeries{}, err
+	}
+
+	dates := seriesDates(util.StringToDate(payload.StartDate), util.StringToDate(payload.EndDate), payload.Occurrences, series.IntervalDays())
+	if len(dates) == 0 {
+		return model.BookingSeries{}, errors.New("cannot book series, there is no occurrence to book")
+	}
+
+	if len(dates) > maxSeriesOccurrences {
+		return model.BookingSeries{}, fmt.Errorf("cannot book series with more than %d occurrences", maxSeriesOccurrences)
+	}
+
+	startTime := util.StringToTime(payload.StartTime)
+	endTime := startTime.Add(time.Hour * time.Duration(payload.Hour))
+
+	var collisions []dto.SeriesCollision
+	for _, date := range dates {
+		existBooking, err := s.bookingRepository.FindByDate(date)
+		if err != nil {
+			return model.BookingSeries{}, err
+		}
+
+		for _, val := range existBooking {
+			if val.Customer.Id == payload.CustomerId && val.Status == model.BookingPending {
+				return model.BookingSeries{}, errors.New("cannot book, there still payment to complete")
+			}
+		}
+
+		err = findCollision(existBooking, payload.CourtId, util.DateToString(date), startTime, endTime, "")
+		if err != nil {
+			collisions = append(collisions, dto.SeriesCollision{
+				BookingDate: util.DateToString(date),
+				Reason:      err.Error(),
+			})
+		}
+	}
+
+	if len(collisions) > 0 {
+		return model.BookingSeries{}, &SeriesCollisionError{Collisions: collisions}
+	}
+
+	customer, err := s.userServ.FindUserById(payload.CustomerId)
+	if err != nil {
+		return model.BookingSeries{}, err
+	}
+
+	court, err := s.courtServ.FindCourtById(payload.CourtId)
+	if err != nil {
+		return model.BookingSeries{}, err
+	}
+
+	for _, date := range dates {
+		err = s.checkCourtSchedule(court, date, startTime, payload.Hour)
+		if err != nil {
+			return model.BookingSeries{}, fmt.Errorf("%s on %s", err.Error(), util.DateToString(date))
+		}
+	}
+
+	totalBooking, err := s.bookingRepository.FindTotal(payload.CustomerId)
+	if err != nil {
+		return model.BookingSeries{}, err
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	orderId := fmt.Sprintf("Series%s-%d", fmt.Sprintf("%05d", totalBooking+1), random.Int())
+	desc := fmt.Sprintf("Pembayaran Booking Rutin %s", court.Name)
+
+	// Every occurrence is priced on its own date, so a holiday in the series
+	// only changes the price of that occurrence.
+	occurrenceItems := make([][]model.PriceItem, len(dates))
+	payment := model.Payment{
+		OrderId:     orderId,
+		Description: desc,
+		Court:       court,
+		User:        customer,
+		Qty:         len(dates),
+	}
+
+	for i, date := range dates {
+		occurrenceItems[i], err = s.pricingServ.PriceBooking(court, date, startTime, payload.Hour)
+		if err != nil {
+			return model.BookingSeries{}, err
+		}
+
+		deposit := s.depositPrice(court, totalPrice(occurrenceItems[i]))
+		payment.Price += deposit
+		payment.Items = append(payment.Items, model.PriceItem{
+			Name:      fmt.Sprintf("%s %s", court.Name, util.DateToString(date)),
+			StartTime: startTime,
+			EndTime:   endTime,
+			Price:     deposit,
+		})
+	}
+
+	paymentURL, err := s.payGate.GetPaymentURL(payment)
+	if err != nil {
+		return model.BookingSeries{}, err
+	}
+
+	series.Customer = customer
+	series.Court = court
+	series.StartDate = dates[0]
+	series.EndDate = dates[len(dates)-1]
+	series.StartTime = startTime
+	series.EndTime = endTime
+
+	for i, date := range dates {
+		series.Bookings = append(series.Bookings, model.Booking{
+			Customer:       customer,
+			Court:          court,
+			BookingDate:    date,
+			StartTime:      startTime,
+			EndTime:        endTime,
+			Total_Payment:  totalPrice(occurrenceItems[i]),
+			PriceBreakdown: occurrenceItems[i],
+			PaymentDetails: []model.Payment{
+				{
+					OrderId:     orderId,
+					Description: desc,
+					Price:       s.depositPrice(court, totalPrice(occurrenceItems[i])),
+					PaymentURL:  paymentURL,
+				},
+			},
+		})
+	}
+
+	created, err := s.bookingRepository.CreateSeries(series)
+	if err != nil {
+		return model.BookingSeries{}, err
+	}
+
+	created.Customer = customer
+	created.Court = court
+
+	for _, val := range created.Bookings {
+		s.audit.Record(model.AuditLog{Actor: payload.CustomerId, Action: "booking.create", EntityType: "booking", EntityId: val.Id, Detail: "series " + created.Id}, nil, auditedBooking(val))
+	}
+
+	return created, nil
+}
+
+func (s *bookingService) CancelSeries(payload dto.CancelBookingSeriesRequest) ([]model.Booking, error) {
+	series, err := s.bookingRepository.FindSeriesById(payload.SeriesId)
+	if err != nil {
+		return []model.Booking{}, errors.New("booking series not found")
+	}
+
+	if payload.Role == "customer" && series.Customer.Id != payload.UserId {
+		return []model.Booking{}, errors.New("forbidden, this booking series belongs to another customer")
+	}
+
+	now := time.Now()
+	var cancelled []model.Booking
+	cancelledOrders := make(map[string]bool)
+
+	for _, val := range series.Bookings {
+		if !val.Status.CanTransitionTo(model.BookingCancel) {
+			continue
+		}
+		if util.CombineDateTime(val.BookingDate, val.StartTime).Before(util.CombineDateTime(now, now)) {
+			continue
+		}
+
+		// A series whose down payment is still open is cancelled as a whole,
+		// the same way an expired gateway transaction would. The gateway
+		// transaction is closed first so a late payment cannot settle it.
+		if val.Status == model.BookingPending {
+			payments, err := s.bookingRepository.FindPaymentsByBookingId(val.Id)
+			if err != nil {
+				return []model.Booking{}, err
+			}
+
+			for _, p := range payments {
+				if p.Status != "unpaid" || !strings.Contains(p.OrderId, "Series") || cancelledOrders[p.OrderId] {
+					continue
+				}
+
+				err = s.payGate.Expire(p.OrderId)
+				if err != nil {
+					return []model.Booking{}, fmt.Errorf("cannot cancel series, failed to close payment %s: %w", p.OrderId, err)
+				}
+
+				err = s.bookingRepository.UpdateSeriesStatus(model.Payment{OrderId: p.OrderId, Status: "cancel", User: model.User{Id: payload.UserId}})
+				if err != nil {
+					return []model.Booking{}, err
+				}
+				cancelledOrders[p.OrderId] = true
+			}
+
+			before := val
+			val.Status = model.BookingCancel
+			cancelled = append(cancelled, val)
+
+			s.audit.Record(model.AuditLog{Actor: payload.UserId, Action: "booking.cancel", EntityType: "booking", EntityId: val.Id, Detail: "series " + series.Id}, auditedBooking(before), auditedBooking(val))
+
+			s.releaseSlot(before)
+			continue
+		}
+
+		booking, err := s.Cancel(dto.CancelBookingRequest{BookingId: val.Id, UserId: payload.UserId, Role: payload.Role})
+		if err != nil {
+			return []model.Booking{}, err
+		}
+
+		cancelled = append(cancelled, booking)
+	}
+
+	if len(cancelled) == 0 {
+		return []model.Booking{}, errors.New("cannot cancel series, there is no remaining occurrence")
+	}
+
+	return cancelled, nil
+}
+
+// auditedBooking drops the password hashes that come along with the users on
+// a booking before it is written to the audit log.
+func auditedBooking(booking model.Booking) model.Booking {
+	booking.Customer = withoutPassword(booking.Customer)
+	booking.Employee = withoutPassword(booking.Employee)
+
+	payments := make([]model.Payment, len(booking.PaymentDetails))
+	for i, val := range booking.PaymentDetails {
+		payments[i] = auditedPayment(val)
+	}
+	booking.PaymentDetails = payments
+
+	return booking
+}
+
+func auditedPayment(payment model.Payment) model.Payment {
+	payment.User = withoutPassword(payment.User)
+	return payment
+}
+
+// seriesDates lists the occurrence dates of a recurring booking, stopping at
+// the end date or after the requested number of occurrences, whichever comes
+// first. It never returns more than one date past maxSeriesOccurrences.
+func seriesDates(startDate, endDate time.Time, occurrences int, intervalDays int) []time.Time {
+	var dates []time.Time
+
+	if occurrences < 1 || occurrences > maxSeriesOccurrences {
+		occurrences = maxSeriesOccurrences + 1
+	}
+
+	for date := startDate; len(dates) < occurrences; date = date.AddDate(0, 0, intervalDays) {
+		if !endDate.IsZero() && date.After(endDate) {
+			break
+		}
+		dates = append(dates, date)
+	}
+
+	return dates
+}
+
+// findCollision checks the bookings of a single date for one that holds the
+// same court in the requested time, ignoring the booking with excludeId.
+func findCollision(existBooking []model.Booking, courtId string, bookingDate string, startTime, endTime time.Time, excludeId string) error {
+	var err error
+
+	for _, val := range existBooking {
+		if val.Id != "" && val.Id == excludeId {
+			continue
+		}
+		if val.Court.Id == courtId && util.DateToString(val.BookingDate) == bookingDate && val.Status.IsActive() {
+			if util.InTimeSpanStart(val.StartTime, val.EndTime, startTime) {
+				err = errors.New("cannot book court in that time")
+
+			} else if util.InTimeSpanEnd(val.StartTime, val.EndTime, endTime) {
+				err = errors.New("cannot book that long, because the schedule collides with another schedule")
+
+			}
+		}
+	}
+
+	return err
+}
+
+// paidAmount sums what the customer has actually paid for a booking, minus
+// anything already refunded.
+func paidAmount(payments []model.Payment) int {
+	paid := 0
+
+	for _, val := range payments {
+		if val.Status == "paid" {
+			paid += val.Price
+		}
+		if val.Status == "refund" {
+			paid -= val.Price
+		}
+	}
+
+	return paid
+}
+
+// splitRefund spreads amount over the paid payments of a booking, oldest first,
+// taking no more from each than is left of it after earlier refunds. Every
+// refund goes back against a single order, which is all a gateway accepts.
+func splitRefund(bookingId string, payments []model.Payment, amount int, description string) []model.Payment {
+	var refunds []model.Payment
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for _, original := range payments {
+		if amount < 1 {
+			break
+		}
+		if original.Status != "paid" {
+			continue
+		}
+
+		refundable := original.Price
+		for _, val := range payments {
+			if val.Status == "refund" && val.RefundOf == original.OrderId {
+				refundable -= val.Price
+			}
+		}
+
+		price := min(amount, refundable)
+		if price < 1 {
+			continue
+		}
+
+		refunds = append(refunds, model.Payment{
+			BookingId:     bookingId,
+			OrderId:       fmt.Sprintf("Refund%s-%d", bookingId, random.Int()),
+			Description:   description,
+			PaymentMethod: original.PaymentMethod,
+			Price:         price,
+			Status:        "refund",
+			RefundOf:      original.OrderId,
+		})
+		amount -= price
+	}
+
+	return refunds
+}
+
+// refundPercent returns how much of the paid down payment goes back to the
+// customer when the booking is cancelled hoursBefore its start time.
+func (s *bookingService) refundPercent(hoursBefore float64) int {
+	if hoursBefore >= float64(s.cancelPolicy.FullRefundHours) {
+		return 100
+	}
+
+	if hoursBefore >= float64(s.cancelPolicy.PartialRefundHours) {
+		return s.cancelPolicy.PartialRefundPercent
+	}
+
+	return 0
+}
+
+// depositPrice is the down payment charged upfront for a booking of the given
+// total, using the court's own percentage when it has one.
+func (s *bookingService) depositPrice(court model.Court, totalPayment int) int {
+	percent := s.deposit.Percent
+	if court.DepositPercent > 0 {
+		percent = court.DepositPercent
+	}
+
+	return totalPayment * percent / 100
+}
+
+func (s *bookingService) FindAllBookings(page int, size int) ([]model.Booking, dto.Paginate, error) {
+
+	return s.bookingRepository.FindAll(page, size)
+}
+
+func (s *bookingService) FindBookedCourt(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error) {
+	bookings, paginate, err := s.bookingRepository.FindBooked(bookingDate, page, size)
+	if err != nil {
+		return []model.Booking{}, dto.Paginate{}, err
+	}
+
+	for i, val := range bookings {
+		court, err := s.courtServ.FindCourtById(val.Court.Id)
+		if err != nil {
+			return []model.Booking{}, dto.Paginate{}, err
+		}
+
+		bookings[i].Court = court
+	}
+
+	return bookings, paginate, nil
+}
+
+func (s *bookingService) FindAvailability(payload dto.AvailabilityRequest, page int, size int) ([]model.CourtAvailability, dto.Paginate, error) {
+	startDate := util.StringToDate(payload.StartDate)
+	endDate := startDate
+	if payload.EndDate != "" {
+		endDate = util.StringToDate(payload.EndDate)
+	}
+
+	if endDate.Before(startDate) {
+		return []model.CourtAvailability{}, dto.Paginate{}, errors.New("cannot check availability, endDate is before startDate")
+	}
+
+	if endDate.Sub(startDate).Hours()/24 >= maxAvailabilityDays {
+		return []model.CourtAvailability{}, dto.Paginate{}, fmt.Errorf("cannot check availability for more than %d days", maxAvailabilityDays)
+	}
+
+	var courts []model.Court
+	var paginate dto.Paginate
+
+	if payload.CourtId != "" {
+		court, err := s.courtServ.FindCourtById(payload.CourtId)
+		if err != nil {
+			return []model.CourtAvailability{}, dto.Paginate{}, errors.New("court not found")
+		}
+
+		courts = append(courts, court)
+		paginate = dto.Paginate{Page: 1, Size: 1, TotalRows: 1, TotalPages: 1}
+
+	} else {
+		var err error
+		courts, paginate, err = s.courtServ.FindAllCourts(page, size)
+		if err != nil {
+			return []model.CourtAvailability{}, dto.Paginate{}, err
+		}
+	}
+
+	availabilities := make([]model.CourtAvailability, len(courts))
+	for i, court := range courts {
+		availabilities[i].Court = court
+	}
+
+	closures, err := s.courtServ.FindClosuresBetween(startDate, endDate)
+	if err != nil {
+		return []model.CourtAvailability{}, dto.Paginate{}, err
+	}
+
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		existBooking, err := s.bookingRepository.FindByDate(date)
+		if err != nil {
+			return []model.CourtAvailability{}, dto.Paginate{}, err
+		}
+
+		for i, court := range courts {
+			availabilities[i].Days = append(availabilities[i].Days, model.DayAvailability{
+				Date:  date,
+				Slots: s.courtSlots(existBooking, closures, court, date),
+			})
+		}
+	}
+
+	return availabilities, paginate, nil
+}
+
+// courtSlots splits the opening hours of a day into fixed slots and marks the
+// ones blocked by a closure or overlapping a booking that still holds the court.
+func (s *bookingService) courtSlots(existBooking []model.Booking, closures []model.CourtClosure, court model.Court, date time.Time) []model.Slot {
+	var slots []model.Slot
+
+	openTime, closeTime, open := s.openingHours(court, date)
+	granularity := time.Minute * time.Duration(s.slotMinutes(court))
+	if !open || granularity <= 0 {
+		return slots
+	}
+
+	for start := openTime; !start.Add(granularity).After(closeTime); start = start.Add(granularity) {
+		slot := model.Slot{StartTime: start, EndTime: start.Add(granularity), Status: "free"}
+
+		for _, val := range closures {
+			if val.CourtId == court.Id && val.Covers(date, slot.StartTime, slot.EndTime) {
+				slot.Status = "closed"
+				break
+			}
+		}
+
+		for _, val := range existBooking {
+			if slot.Status == "closed" || val.Court.Id != court.Id || util.DateToString(val.BookingDate) != util.DateToString(date) || !val.Status.IsActive() {
+				continue
+			}
+
+			if val.StartTime.Before(slot.EndTime) && slot.StartTime.Before(val.EndTime) {
+				slot.Status = "taken"
+				break
+			}
+		}
+
+		slots = append(slots, slot)
+	}
+
+	return slots
+}
+
+// openingHours returns when the court opens and closes on the given date, using
+// the default schedule for courts without their own opening hours.
+func (s *bookingService) openingHours(court model.Court, date time.Time) (time.Time, time.Time, bool) {
+	if len(court.OpeningHours) == 0 {
+		return util.StringToTime(s.schedule.OpenTime), util.StringToTime(s.schedule.CloseTime), true
+	}
+
+	hour, ok := court.OpeningHourOn(date.Weekday())
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return util.StringToTime(hour.OpenTime), util.StringToTime(hour.CloseTime), true
+}
+
+func (s *bookingService) slotMinutes(court model.Court) int {
+	if court.SlotMinutes > 0 {
+		return court.SlotMinutes
+	}
+
+	return s.schedule.SlotMinutes
+}
+
+// checkCourtSchedule makes sure a booking fits the court opening hours, booking
+// length limits, slot granularity and closures.
+func (s *bookingService) checkCourtSchedule(court model.Court, bookingDate, startTime time.Time, hour int) error {
+	if hour < 1 || (court.MinBookingHours > 0 && hour < court.MinBookingHours) {
+		return fmt.Errorf("cannot book less than %d hours", max(court.MinBookingHours, 1))
+	}
+
+	if court.MaxBookingHours > 0 && hour > court.MaxBookingHours {
+		return fmt.Errorf("cannot book more than %d hours", court.MaxBookingHours)
+	}
+
+	openTime, closeTime, open := s.openingHours(court, bookingDate)
+	if !open {
+		return fmt.Errorf("cannot book, court is closed on %s", strings.ToLower(bookingDate.Weekday().String()))
+	}
+
+	endTime := startTime.Add(time.Hour * time.Duration(hour))
+	if startTime.Before(openTime) || endTime.After(closeTime) {
+		return fmt.Errorf("cannot book outside opening hours %s - %s", util.TimeToString(openTime), util.TimeToString(closeTime))
+	}
+
+	slot := time.Minute * time.Duration(s.slotMinutes(court))
+	if slot > 0 && startTime.Sub(openTime)%slot != 0 {
+		return fmt.Errorf("cannot book, start time must follow %d minute slots from %s", s.slotMinutes(court), util.TimeToString(openTime))
+	}
+
+	closures, err := s.courtServ.FindClosuresBetween(bookingDate, bookingDate)
+	if err != nil {
+		return err
+	}
+
+	for _, val := range closures {
+		if val.CourtId == court.Id && val.Covers(bookingDate, startTime, endTime) {
+			return fmt.Errorf("cannot book, court is closed from %s to %s for %s", util.TimeToString(val.StartTime), util.TimeToString(val.EndTime), val.Reason)
+		}
+	}
+
+	return nil
+}
+
+func (s *bookingService) FindEndingBookings(bookingDate time.Time, page int, size int) ([]model.Booking, dto.Paginate, error) {
+	bookings, paginate, err := s.bookingRepository.FindEnding(bookingDate, page, size)
+	if err != nil {
+		return []model.Booking{}, dto.Paginate{}, err
+	}
+
+	for i, val := range bookings {
+		customer, err := s.userServ.FindUserById(val.Customer.Id)
+		if err != nil {
+			return []model.Booking{}, dto.Paginate{}, err
+		}
+
+		court, err := s.courtServ.FindCourtById(val.Court.Id)
+		if err != nil {
+			return []model.Booking{}, dto.Paginate{}, err
+		}
+
+		bookings[i].Customer = customer
+		bookings[i].Court = court
+	}
+
+	return bookings, paginate, nil
+}
+
+func (s *bookingService) FindStatusHistory(bookingId string) ([]model.BookingStatusHistory, error) {
+	_, err := s.bookingRepository.FindById(bookingId)
+	if err != nil {
+		return []model.BookingStatusHistory{}, errors.New("booking not found")
+	}
+
+	return s.bookingRepository.FindStatusHistory(bookingId)
+}
+
+func (s *bookingService) FindCustomerBookings(customerId string, upcoming bool, page int, size int) ([]model.Booking, dto.Paginate, error) {
+	bookings, paginate, err := s.bookingRepository.FindByCustomer(customerId, upcoming, page, size)
+	if err != nil {
+		return []model.Booking{}, dto.Paginate{}, err
+	}
+
+	for i, val := range bookings {
+		payments, err := s.bookingRepository.FindPaymentsByBookingId(val.Id)
+		if err != nil {
+			return []model.Booking{}, dto.Paginate{}, err
+		}
+
+		bookings[i].PaymentDetails = payments
+	}
+
+	return bookings, paginate, nil
+}
+
+func (s *bookingService) FindCustomerBooking(customerId string, bookingId string) (model.Booking, error) {
+	booking, err := s.bookingRepository.FindById(bookingId)
+	if err != nil {
+		return model.Booking{}, errors.New("booking not found")
+	}
+
+	if booking.Customer.Id != customerId {
+		return model.Booking{}, errors.New("forbidden, this booking belongs to another customer")
+	}
+
+	return s.bookingDetail(booking)
+}
+
+func (s *bookingService) FindGuestBooking(token string) (model.Booking, error) {
+	bookingId, err := s.auth.VerifyLookupToken(token)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	booking, err := s.bookingRepository.FindById(bookingId)
+	if err != nil {
+		return model.Booking{}, errors.New("booking not found")
+	}
+
+	return s.bookingDetail(booking)
+}
+
+func (s *bookingService) CheckInQRCode(customerId string, bookingId string) ([]byte, error) {
+	booking, err := s.bookingRepository.FindById(bookingId)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+
+	if booking.Customer.Id != customerId {
+		return nil, errors.New("forbidden, this booking belongs to another customer")
+	}
+
+	return s.checkInQRCode(booking)
+}
+
+func (s *bookingService) GuestCheckInQRCode(token string) ([]byte, error) {
+	bookingId, err := s.auth.VerifyLookupToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	booking, err := s.bookingRepository.FindById(bookingId)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+
+	return s.checkInQRCode(booking)
+}
+
+// checkInQRCode renders the booking's check-in code as a PNG QR code, giving
+// the booking a code the first time one is asked for.
+func (s *bookingService) checkInQRCode(booking model.Booking) ([]byte, error) {
+	if booking.Status != model.BookingBooked && booking.Status != model.BookingPaid && booking.Status != model.BookingDone && booking.Status != model.BookingCheckedIn {
+		return nil, fmt.Errorf("cannot issue a check-in code for a booking with status %s", booking.Status)
+	}
+
+	code, err := newCheckInCode()
+	if err != nil {
+		return nil, err
+	}
+
+	code, err = s.bookingRepository.CheckInCode(booking.Id, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return qrcode.Encode(code, qrcode.Medium, 256)
+}
+
+func newCheckInCode() (string, error) {
+	code := make([]byte, 16)
+	if _, err := crand.Read(code); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(code), nil
+}
+
+// CheckIn marks the booking holding the scanned code as checked in and returns
+// it with its payments, so the desk sees what is left to collect. Codes are
+// only accepted on the day of the booking.
+func (s *bookingService) CheckIn(payload dto.CheckInRequest) (model.Booking, error) {
+	booking, err := s.bookingRepository.FindByCheckInCode(payload.Code)
+	if err != nil {
+		return model.Booking{}, errors.New("booking not found")
+	}
+
+	if booking.BookingDate.Format("02-01-2006") != time.Now().Format("02-01-2006") {
+		return model.Booking{}, fmt.Errorf("cannot check in, booking is scheduled for %s", booking.BookingDate.Format("02-01-2006"))
+	}
+
+	err = s.bookingRepository.ChangeStatus(booking.Id, model.BookingCheckedIn, payload.EmployeeId, "checked in at the desk")
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	before := booking
+	booking.Status = model.BookingCheckedIn
+	booking.Employee.Id = payload.EmployeeId
+
+	s.audit.Record(model.AuditLog{Actor: payload.EmployeeId, Action: "booking.check_in", EntityType: "booking", EntityId: booking.Id}, auditedBooking(before), auditedBooking(booking))
+
+	return s.bookingDetail(booking)
+}
+
+func (s *bookingService) CountNoShows(customerId string) (int, error) {
+	return s.bookingRepository.CountNoShows(customerId)
+}
+
+// bookingDetail fills in the court and every payment of a single booking.
+func (s *bookingService) bookingDetail(booking model.Booking) (model.Booking, error) {
+	court, err := s.courtServ.FindCourtById(booking.Court.Id)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	payments, err := s.bookingRepository.FindPaymentsByBookingId(booking.Id)
+	if err != nil {
+		return model.Booking{}, err
+	}
+
+	booking.Court = court
+	booking.PaymentDetails = payments
+
+	return booking, nil
 }
 
 func (s *bookingService) FindPaymentReport(day, month, year, page, size int, filterType string) ([]model.Payment, dto.Paginate, int64, error) {
 	return s.bookingRepository.FindPaymentReport(day, month, year, page, size, filterType)
 }
 
-func NewBookingService(bookingRepository repository.BookingRepository, userService UserService, courtService CourtService, payGate PaymentGateService) BookingService {
+func NewBookingService(bookingRepository repository.BookingRepository, userService UserService, courtService CourtService, payGate PaymentGateService, pricingService PricingService, promoService PromoService, loyaltyService LoyaltyService, auditService AuditService, authService AuthService, restrictionService RestrictionService, waitlistService WaitlistService, cancelPolicy config.CancelPolicyConfig, deposit config.DepositConfig, schedule config.ScheduleConfig) BookingService {
 	return &bookingService{
 		bookingRepository: bookingRepository,
 		userServ:          userService,
 		courtServ:         courtService,
 		payGate:           payGate,
+		pricingServ:       pricingService,
+		promoServ:         promoService,
+		loyaltyServ:       loyaltyService,
+		audit:             auditService,
+		auth:              authService,
+		restrictionServ:   restrictionService,
+		waitlistServ:      waitlistService,
+		cancelPolicy:      cancelPolicy,
+		deposit:           deposit,
+		schedule:          schedule,
 	}
 }
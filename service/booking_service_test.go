@@ -2,10 +2,13 @@ package service
 
 import (
 	"errors"
+	"team2/shuttleslot/config"
+	authmock "team2/shuttleslot/mock/auth_mock"
 	repomock "team2/shuttleslot/mock/repo_mock"
 	servicemock "team2/shuttleslot/mock/service_mock"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/util"
 	"testing"
 	"time"
 
@@ -21,6 +24,14 @@ type BookingServiceTestSuite struct {
 	uS       *servicemock.UserServiceMock
 	cS       *servicemock.CourtServiceMock
 	pS       *servicemock.PaymentGateServiceMock
+	ruleRepo *repomock.PricingRuleRepositoryMock
+	prS      PricingService
+	promo    *servicemock.PromoServiceMock
+	loyalty  *servicemock.LoyaltyServiceMock
+	audit    *servicemock.AuditServiceMock
+	auth     *authmock.AuthServiceMock
+	restrict *servicemock.RestrictionServiceMock
+	waitlist *servicemock.WaitlistServiceMock
 }
 
 type UserServiceMock struct {
@@ -32,17 +43,74 @@ func (suite *BookingServiceTestSuite) SetupTest() {
 	suite.uS = new(servicemock.UserServiceMock)
 	suite.cS = new(servicemock.CourtServiceMock)
 	suite.pS = new(servicemock.PaymentGateServiceMock)
-	suite.bS = NewBookingService(suite.repoMock, suite.uS, suite.cS, suite.pS)
+	suite.ruleRepo = new(repomock.PricingRuleRepositoryMock)
+	suite.ruleRepo.On("FindAll").Return([]model.PricingRule{}, nil).Maybe()
+	suite.prS = NewPricingService(suite.ruleRepo)
+	suite.promo = new(servicemock.PromoServiceMock)
+	suite.promo.On("FindRedemption", mock.Anything).Return(model.PromoRedemption{}, nil).Maybe()
+	suite.loyalty = new(servicemock.LoyaltyServiceMock)
+	suite.loyalty.On("FindRedemption", mock.Anything).Return(model.PointEntry{}, nil).Maybe()
+	suite.audit = new(servicemock.AuditServiceMock)
+	suite.audit.On("Record", mock.Anything, mock.Anything, mock.Anything).Maybe()
+	suite.auth = new(authmock.AuthServiceMock)
+	suite.restrict = new(servicemock.RestrictionServiceMock)
+	suite.restrict.On("FindActiveRestrictions", mock.Anything).Return([]model.Restriction{}, nil).Maybe()
+	suite.restrict.On("ApplyNoShowPenalties", mock.Anything).Return([]model.Restriction{}, nil).Maybe()
+	suite.waitlist = new(servicemock.WaitlistServiceMock)
+	suite.waitlist.On("OfferSlot", mock.Anything).Return(model.WaitlistEntry{}, nil).Maybe()
+	suite.bS = NewBookingService(suite.repoMock, suite.uS, suite.cS, suite.pS, suite.prS, suite.promo, suite.loyalty, suite.audit, suite.auth, suite.restrict, suite.waitlist, cancelPolicy, depositConfig, schedule)
+	suite.cS.On("FindClosuresBetween", mock.Anything, mock.Anything).Return([]model.CourtClosure{}, nil).Maybe()
+}
+
+// withClosures rebuilds the service so FindClosuresBetween returns the given
+// closures instead of the empty default.
+func (suite *BookingServiceTestSuite) withClosures(closures []model.CourtClosure) {
+	suite.cS = new(servicemock.CourtServiceMock)
+	suite.cS.On("FindClosuresBetween", mock.Anything, mock.Anything).Return(closures, nil)
+	suite.bS = NewBookingService(suite.repoMock, suite.uS, suite.cS, suite.pS, suite.prS, suite.promo, suite.loyalty, suite.audit, suite.auth, suite.restrict, suite.waitlist, cancelPolicy, depositConfig, schedule)
+}
+
+// withRestrictions rebuilds the service so the customer has the given active
+// restrictions instead of none.
+func (suite *BookingServiceTestSuite) withRestrictions(restrictions []model.Restriction) {
+	suite.restrict = new(servicemock.RestrictionServiceMock)
+	suite.restrict.On("FindActiveRestrictions", mock.Anything).Return(restrictions, nil)
+	suite.bS = NewBookingService(suite.repoMock, suite.uS, suite.cS, suite.pS, suite.prS, suite.promo, suite.loyalty, suite.audit, suite.auth, suite.restrict, suite.waitlist, cancelPolicy, depositConfig, schedule)
+}
+
+// withPricingRules rebuilds the service so bookings are priced with the given
+// rules instead of the plain court price.
+func (suite *BookingServiceTestSuite) withPricingRules(rules []model.PricingRule) {
+	suite.ruleRepo = new(repomock.PricingRuleRepositoryMock)
+	suite.ruleRepo.On("FindAll").Return(rules, nil)
+	suite.prS = NewPricingService(suite.ruleRepo)
+	suite.bS = NewBookingService(suite.repoMock, suite.uS, suite.cS, suite.pS, suite.prS, suite.promo, suite.loyalty, suite.audit, suite.auth, suite.restrict, suite.waitlist, cancelPolicy, depositConfig, schedule)
 }
 
 func TestBookingServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(BookingServiceTestSuite))
 }
 
+var cancelPolicy = config.CancelPolicyConfig{
+	FullRefundHours:      24,
+	PartialRefundHours:   6,
+	PartialRefundPercent: 50,
+}
+
+var depositConfig = config.DepositConfig{
+	Percent: 50,
+}
+
+var schedule = config.ScheduleConfig{
+	OpenTime:    "08:00:00",
+	CloseTime:   "23:00:00",
+	SlotMinutes: 60,
+}
+
 var payload = dto.CreateBookingRequest{
 	CourtId:     "court_id",
-	BookingDate: "2006-01-02",
-	StartTime:   "10:00",
+	BookingDate: "02-01-2030",
+	StartTime:   "10:00:00",
 	Hour:        2,
 	CustomerId:  "customer_id",
 }
@@ -91,6 +159,15 @@ var expectedPayment = model.Payment{
 	User:          model.User{Id: createRepayRequest.EmployeeId},
 	PaymentURL:    "http://test-payment-url.com",
 }
+var deposit = model.Payment{
+	Id:            "1",
+	BookingId:     "1",
+	OrderId:       "Booking_1",
+	PaymentMethod: "gopay",
+	Price:         15000,
+	Status:        "paid",
+}
+
 var user = model.User{
 	Id:   "customer_id",
 	Name: "Test Customer",
@@ -116,6 +193,217 @@ func (suite *BookingServiceTestSuite) TestCreate_Success() {
 	assert.NoError(suite.T(), err, "Expected no error")
 }
 
+func (suite *BookingServiceTestSuite) TestCreate_CourtDepositPercent() {
+	custom := court
+	custom.DepositPercent = 30
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", payload.CustomerId).Return(user, nil)
+	suite.cS.On("FindCourtById", payload.CourtId).Return(custom, nil)
+	suite.repoMock.On("FindTotal", payload.CustomerId).Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.Price == 36000 && totalPrice(payment.Items) == 36000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(booking model.Booking) bool {
+		return booking.Total_Payment == 120000 && booking.PaymentDetails[0].Price == 36000
+	})).Return(model.Booking{
+		PaymentDetails: []model.Payment{{PaymentURL: "http://test-payment-url.com"}},
+	}, nil)
+
+	_, err := suite.bS.Create(payload)
+
+	suite.NoError(err)
+	suite.pS.AssertExpectations(suite.T())
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_PayInFull() {
+	request := payload
+	request.PayInFull = true
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", request.CustomerId).Return(user, nil)
+	suite.cS.On("FindCourtById", request.CourtId).Return(court, nil)
+	suite.repoMock.On("FindTotal", request.CustomerId).Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(payment model.Payment) bool {
+		return payment.Price == 120000 && totalPrice(payment.Items) == 120000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(booking model.Booking) bool {
+		return booking.PaymentDetails[0].Price == booking.Total_Payment
+	})).Return(model.Booking{
+		PaymentDetails: []model.Payment{{PaymentURL: "http://test-payment-url.com"}},
+	}, nil)
+
+	_, err := suite.bS.Create(request)
+
+	suite.NoError(err)
+	suite.pS.AssertExpectations(suite.T())
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_BlockedOnline() {
+	suite.withRestrictions([]model.Restriction{{Type: model.RestrictionBlockOnline, Reason: "3 no-shows in 60 days", EndsAt: time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC)}})
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", payload.CustomerId).Return(user, nil)
+
+	_, err := suite.bS.Create(payload)
+
+	suite.EqualError(err, "cannot book online, your account is restricted until 15-01-2030: 3 no-shows in 60 days")
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_FullPrepaymentRequired() {
+	suite.withRestrictions([]model.Restriction{{Type: model.RestrictionFullPrepayment, Reason: "2 no-shows in 60 days", EndsAt: time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)}})
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", payload.CustomerId).Return(user, nil)
+
+	_, err := suite.bS.Create(payload)
+
+	suite.EqualError(err, "cannot book with a down payment, your account requires full prepayment until 31-01-2030: 2 no-shows in 60 days")
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_FullPrepaymentPaidInFull() {
+	suite.withRestrictions([]model.Restriction{{Type: model.RestrictionFullPrepayment, EndsAt: time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)}})
+	request := payload
+	request.PayInFull = true
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", request.CustomerId).Return(user, nil)
+	suite.cS.On("FindCourtById", request.CourtId).Return(court, nil)
+	suite.repoMock.On("FindTotal", request.CustomerId).Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.Anything).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.Anything).Return(model.Booking{
+		PaymentDetails: []model.Payment{{PaymentURL: "http://test-payment-url.com"}},
+	}, nil)
+
+	_, err := suite.bS.Create(request)
+
+	suite.NoError(err)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateWalkIn_BlockedCustomerAtDesk() {
+	suite.withRestrictions([]model.Restriction{{Type: model.RestrictionBlockOnline, EndsAt: time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC)}})
+	request := dto.CreateWalkInBookingRequest{
+		CourtId:       "court_id",
+		BookingDate:   "02-01-2030",
+		StartTime:     "10:00:00",
+		Hour:          2,
+		CustomerId:    "customer_id",
+		PaymentMethod: "cash",
+		EmployeeId:    "employee_id",
+	}
+
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.repoMock.On("Create", mock.Anything).Return(model.Booking{
+		Id:             "1",
+		Status:         model.BookingDone,
+		PaymentDetails: []model.Payment{{PaymentMethod: "cash", Status: "paid"}},
+	}, nil)
+
+	_, err := suite.bS.CreateWalkIn(request)
+
+	suite.NoError(err)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateWalkIn_GuestPaysCash() {
+	request := dto.CreateWalkInBookingRequest{
+		CourtId:       "court_id",
+		BookingDate:   "02-01-2030",
+		StartTime:     "10:00:00",
+		Hour:          2,
+		Name:          "walk in",
+		PhoneNumber:   "0812",
+		PaymentMethod: "cash",
+		EmployeeId:    "employee_id",
+	}
+	guest := model.User{Id: "guest_id", Name: "walk in", PhoneNumber: "0812", Role: "guest"}
+
+	suite.uS.On("FindOrCreateGuest", model.User{Name: "walk in", PhoneNumber: "0812"}, "employee_id").Return(guest, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "guest_id").Return(guest, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "guest_id").Return(0, nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(booking model.Booking) bool {
+		return booking.Employee.Id == "employee_id" && booking.PaymentDetails[0].PaymentMethod == "cash" && booking.PaymentDetails[0].Price == booking.Total_Payment && booking.PaymentDetails[0].PaymentURL == ""
+	})).Return(model.Booking{
+		Id:             "1",
+		Status:         model.BookingDone,
+		PaymentDetails: []model.Payment{{PaymentMethod: "cash", Status: "paid"}},
+	}, nil)
+
+	booking, err := suite.bS.CreateWalkIn(request)
+
+	suite.NoError(err)
+	suite.Equal(model.BookingDone, booking.Status)
+	suite.Equal(guest, booking.Customer)
+	suite.pS.AssertNotCalled(suite.T(), "GetPaymentURL", mock.Anything)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreateWalkIn_CustomerNotFound() {
+	request := dto.CreateWalkInBookingRequest{
+		CourtId:       "court_id",
+		BookingDate:   "02-01-2030",
+		StartTime:     "10:00:00",
+		Hour:          2,
+		CustomerId:    "missing",
+		PaymentMethod: "mid",
+		EmployeeId:    "employee_id",
+	}
+	suite.uS.On("FindUserById", "missing").Return(model.User{}, errors.New("sql: no rows in result set"))
+
+	_, err := suite.bS.CreateWalkIn(request)
+
+	suite.EqualError(err, "customer not found")
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateGuestBooking_Success() {
+	request := dto.CreateGuestBookingRequest{
+		CourtId:     "court_id",
+		BookingDate: "02-01-2030",
+		StartTime:   "10:00:00",
+		Hour:        2,
+		Name:        "first timer",
+		PhoneNumber: "0812",
+		Email:       "first@mail.com",
+	}
+	guest := model.User{Id: "guest_id", Name: "first timer", PhoneNumber: "0812", Email: "first@mail.com", Role: "guest"}
+	created := model.Booking{Id: "1", PaymentDetails: []model.Payment{{PaymentURL: "http://test-payment-url.com"}}}
+
+	suite.uS.On("FindOrCreateGuest", model.User{Name: "first timer", PhoneNumber: "0812", Email: "first@mail.com"}, "guest").Return(guest, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "guest_id").Return(guest, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "guest_id").Return(0, nil)
+	suite.pS.On("GetPaymentURL", mock.Anything).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(booking model.Booking) bool {
+		return booking.Customer.Id == "guest_id" && booking.PaymentDetails[0].PaymentURL == "http://test-payment-url.com"
+	})).Return(created, nil)
+	suite.auth.On("GenerateLookupToken", mock.MatchedBy(func(booking model.Booking) bool {
+		return booking.Id == "1"
+	})).Return("lookup-token", nil)
+
+	booking, token, err := suite.bS.CreateGuestBooking(request)
+
+	suite.NoError(err)
+	suite.Equal("1", booking.Id)
+	suite.Equal("lookup-token", token)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateGuestBooking_RegisteredContact() {
+	request := dto.CreateGuestBookingRequest{Name: "lala", PhoneNumber: "123", Email: "lala@mail.com"}
+	suite.uS.On("FindOrCreateGuest", model.User{Name: "lala", PhoneNumber: "123", Email: "lala@mail.com"}, "guest").Return(user, nil)
+
+	_, _, err := suite.bS.CreateGuestBooking(request)
+
+	suite.EqualError(err, "cannot book as guest, an account with this phone number or email already exists, please log in")
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
 func (suite *BookingServiceTestSuite) TestCreate_Failure() {
 	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, errors.New("error"))
 	_, err := suite.bS.Create(payload)
@@ -176,6 +464,7 @@ func (suite *BookingServiceTestSuite) TestCreate_Failure6() {
 
 func (suite *BookingServiceTestSuite) TestUpdatePayment_Success_Booking() {
 	suite.pS.On("PaymentProcess", paymentNotif).Return(payment, nil)
+	suite.repoMock.On("FindPaymentByOrderId", paymentNotif.OrderId).Return(payment, nil)
 	suite.repoMock.On("UpdateStatus", payment).Return(nil)
 
 	err := suite.bS.UpdatePayment(paymentNotif)
@@ -206,6 +495,7 @@ func (suite *BookingServiceTestSuite) TestUpdatePayment_Success_Repayment() {
 	}
 
 	suite.pS.On("PaymentProcess", paymentNotif).Return(payment, nil)
+	suite.repoMock.On("FindPaymentByOrderId", paymentNotif.OrderId).Return(payment, nil)
 	suite.repoMock.On("UpdateRepaymentStatus", payment).Return(nil)
 
 	err := suite.bS.UpdatePayment(paymentNotif)
@@ -213,6 +503,18 @@ func (suite *BookingServiceTestSuite) TestUpdatePayment_Success_Repayment() {
 	suite.pS.AssertExpectations(suite.T())
 	suite.repoMock.AssertExpectations(suite.T())
 }
+func (suite *BookingServiceTestSuite) TestUpdatePayment_Audited() {
+	unpaid := payment
+	unpaid.Status = "unpaid"
+	suite.pS.On("PaymentProcess", paymentNotif).Return(payment, nil)
+	suite.repoMock.On("FindPaymentByOrderId", paymentNotif.OrderId).Return(unpaid, nil)
+	suite.repoMock.On("UpdateStatus", payment).Return(nil)
+
+	err := suite.bS.UpdatePayment(paymentNotif)
+	suite.NoError(err)
+	suite.audit.AssertCalled(suite.T(), "Record", model.AuditLog{Actor: "payment-gateway", Action: "payment.update", EntityType: "payment", EntityId: payment.OrderId}, auditedPayment(unpaid), auditedPayment(payment))
+}
+
 func (suite *BookingServiceTestSuite) TestUpdatePayment_Failed_Booking() {
 	suite.pS.On("PaymentProcess", paymentNotif).Return(model.Payment{}, errors.New("error"))
 	suite.repoMock.On("UpdateStatus", payment).Return(errors.New("error"))
@@ -222,6 +524,7 @@ func (suite *BookingServiceTestSuite) TestUpdatePayment_Failed_Booking() {
 }
 func (suite *BookingServiceTestSuite) TestUpdatePayment_Failed_Booking2() {
 	suite.pS.On("PaymentProcess", paymentNotif).Return(payment, nil)
+	suite.repoMock.On("FindPaymentByOrderId", paymentNotif.OrderId).Return(payment, nil)
 	suite.repoMock.On("UpdateStatus", payment).Return(errors.New("error"))
 
 	err := suite.bS.UpdatePayment(paymentNotif)
@@ -251,6 +554,7 @@ func (suite *BookingServiceTestSuite) TestUpdatePayment_Failed_Repayment() {
 	}
 
 	suite.pS.On("PaymentProcess", paymentNotif).Return(payment, nil)
+	suite.repoMock.On("FindPaymentByOrderId", paymentNotif.OrderId).Return(payment, nil)
 	suite.repoMock.On("UpdateRepaymentStatus", payment).Return(errors.New("error"))
 
 	err := suite.bS.UpdatePayment(paymentNotif)
@@ -259,6 +563,7 @@ func (suite *BookingServiceTestSuite) TestUpdatePayment_Failed_Repayment() {
 
 func (suite *BookingServiceTestSuite) TestCreateRepay_PaymentMethodNotMid() {
 	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{deposit}, nil)
 	suite.uS.On("FindUserById", booking.Customer.Id).Return(user, nil)
 	suite.cS.On("FindCourtById", booking.Court.Id).Return(court, nil)
 	suite.repoMock.On("FindTotal", booking.Customer.Id).Return(1, nil)
@@ -276,6 +581,7 @@ func (suite *BookingServiceTestSuite) TestCreateRepay_PaymentMethodNotMid() {
 }
 func (suite *BookingServiceTestSuite) TestCreateRepay_PaymentMethodMid() {
 	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{deposit}, nil)
 	suite.uS.On("FindUserById", booking.Customer.Id).Return(user, nil)
 	suite.cS.On("FindCourtById", booking.Court.Id).Return(court, nil)
 	suite.repoMock.On("FindTotal", booking.Customer.Id).Return(1, nil)
@@ -316,6 +622,7 @@ func (suite *BookingServiceTestSuite) TestCreateRepay_Failed1() {
 }
 func (suite *BookingServiceTestSuite) TestCreateRepay_Failed2() {
 	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{deposit}, nil)
 	suite.uS.On("FindUserById", booking.Customer.Id).Return(model.User{}, errors.New("error"))
 	_, err := suite.bS.CreateRepay(createRepayRequest)
 	suite.Error(err)
@@ -323,6 +630,7 @@ func (suite *BookingServiceTestSuite) TestCreateRepay_Failed2() {
 
 func (suite *BookingServiceTestSuite) TestCreateRepay_Failed3() {
 	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{deposit}, nil)
 	suite.uS.On("FindUserById", booking.Customer.Id).Return(user, nil)
 	suite.cS.On("FindCourtById", booking.Court.Id).Return(model.Court{}, errors.New("error"))
 	_, err := suite.bS.CreateRepay(createRepayRequest)
@@ -331,6 +639,7 @@ func (suite *BookingServiceTestSuite) TestCreateRepay_Failed3() {
 
 func (suite *BookingServiceTestSuite) TestCreateRepay_Failed4() {
 	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{deposit}, nil)
 	suite.uS.On("FindUserById", booking.Customer.Id).Return(user, nil)
 	suite.cS.On("FindCourtById", booking.Court.Id).Return(court, nil)
 	suite.repoMock.On("FindTotal", booking.Customer.Id).Return(0, errors.New("error"))
@@ -340,6 +649,7 @@ func (suite *BookingServiceTestSuite) TestCreateRepay_Failed4() {
 
 func (suite *BookingServiceTestSuite) TestCreateRepay_Failed5() {
 	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{deposit}, nil)
 	suite.uS.On("FindUserById", booking.Customer.Id).Return(user, nil)
 	suite.cS.On("FindCourtById", booking.Court.Id).Return(court, nil)
 	suite.repoMock.On("FindTotal", booking.Customer.Id).Return(1, nil)
@@ -350,6 +660,7 @@ func (suite *BookingServiceTestSuite) TestCreateRepay_Failed5() {
 
 func (suite *BookingServiceTestSuite) TestCreateRepay_Failed6() {
 	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{deposit}, nil)
 	suite.uS.On("FindUserById", booking.Customer.Id).Return(user, nil)
 	suite.cS.On("FindCourtById", booking.Court.Id).Return(court, nil)
 	suite.repoMock.On("FindTotal", booking.Customer.Id).Return(1, nil)
@@ -361,6 +672,7 @@ func (suite *BookingServiceTestSuite) TestCreateRepay_Failed6() {
 
 func (suite *BookingServiceTestSuite) TestCreateRepay_Failed_ScheduleTooLong() {
 	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{deposit}, nil)
 	suite.uS.On("FindUserById", booking.Customer.Id).Return(user, nil)
 	suite.cS.On("FindCourtById", booking.Court.Id).Return(court, nil)
 	suite.repoMock.On("FindTotal", booking.Customer.Id).Return(1, nil)
@@ -561,3 +873,1680 @@ func (suite *BookingServiceTestSuite) TestFindPaymentReport_Failed() {
 	suite.Error(err)
 	suite.repoMock.AssertExpectations(suite.T())
 }
+
+func (suite *BookingServiceTestSuite) TestCancel_FullRefund() {
+	booked := booking
+	booked.BookingDate = time.Now().AddDate(0, 0, 3)
+
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.repoMock.On("Cancel", "1", mock.MatchedBy(func(refunds []model.Payment) bool {
+		return len(refunds) == 1 && refunds[0].Price == 15000 && refunds[0].PaymentMethod == "gopay" && refunds[0].Status == "refund" && refunds[0].RefundOf == deposit.OrderId
+	}), "customer_id").Return(nil)
+	suite.pS.On("Refund", mock.MatchedBy(func(refund model.Payment) bool {
+		return refund.Price == 15000 && refund.RefundOf == deposit.OrderId
+	})).Return(nil)
+
+	result, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.NoError(err)
+	suite.Equal(model.BookingCancel, result.Status)
+	suite.Equal(15000, result.PaymentDetails[0].Price)
+	suite.repoMock.AssertExpectations(suite.T())
+	suite.pS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_RefundsEachPaidOrder() {
+	booked := booking
+	booked.BookingDate = time.Now().AddDate(0, 0, 3)
+	topUp := model.Payment{Id: "2", BookingId: "1", OrderId: "Reschedule1-1", PaymentMethod: "mid", Price: 5000, Status: "paid"}
+	earlier := model.Payment{Id: "3", BookingId: "1", OrderId: "Refund1-1", PaymentMethod: "gopay", Price: 3000, Status: "refund", RefundOf: deposit.OrderId}
+
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit, topUp, earlier}, nil)
+	suite.repoMock.On("Cancel", "1", mock.MatchedBy(func(refunds []model.Payment) bool {
+		return len(refunds) == 2 &&
+			refunds[0].RefundOf == deposit.OrderId && refunds[0].Price == 12000 &&
+			refunds[1].RefundOf == topUp.OrderId && refunds[1].Price == 5000 &&
+			refunds[0].OrderId != refunds[1].OrderId
+	}), "customer_id").Return(nil)
+	suite.pS.On("Refund", mock.MatchedBy(func(refund model.Payment) bool {
+		return refund.RefundOf == deposit.OrderId && refund.Price == 12000
+	})).Return(nil).Once()
+	suite.pS.On("Refund", mock.MatchedBy(func(refund model.Payment) bool {
+		return refund.RefundOf == topUp.OrderId && refund.Price == 5000
+	})).Return(nil).Once()
+
+	result, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.NoError(err)
+	suite.Len(result.PaymentDetails, 2)
+	suite.repoMock.AssertExpectations(suite.T())
+	suite.pS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_PaidInFull() {
+	prepaid := booking
+	prepaid.Status = model.BookingPaid
+	prepaid.BookingDate = time.Now().AddDate(0, 0, 3)
+	full := deposit
+	full.Price = 30000
+
+	suite.repoMock.On("FindById", "1").Return(prepaid, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{full}, nil)
+	suite.repoMock.On("Cancel", "1", mock.MatchedBy(func(refunds []model.Payment) bool {
+		return len(refunds) == 1 && refunds[0].Price == 30000
+	}), "customer_id").Return(nil)
+	suite.pS.On("Refund", mock.Anything).Return(nil)
+
+	result, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.NoError(err)
+	suite.Equal(model.BookingCancel, result.Status)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_CashRefundOnlyRecorded() {
+	booked := booking
+	booked.BookingDate = time.Now().AddDate(0, 0, 3)
+	cash := deposit
+	cash.PaymentMethod = "cash"
+
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{cash}, nil)
+	suite.repoMock.On("Cancel", "1", mock.MatchedBy(func(refunds []model.Payment) bool {
+		return len(refunds) == 1 && refunds[0].Price == 15000 && refunds[0].PaymentMethod == "cash"
+	}), "customer_id").Return(nil)
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.NoError(err)
+	suite.pS.AssertNotCalled(suite.T(), "Refund", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_GatewayRefundFailed() {
+	booked := booking
+	booked.BookingDate = time.Now().AddDate(0, 0, 3)
+
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.pS.On("Refund", mock.Anything).Return(errors.New("gateway error"))
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.EqualError(err, "gateway error")
+	suite.repoMock.AssertNotCalled(suite.T(), "Cancel", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCancelGuestBooking_Success() {
+	booked := booking
+	booked.BookingDate = time.Now().AddDate(0, 0, 3)
+
+	suite.auth.On("VerifyLookupToken", "lookup-token").Return("1", nil)
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.repoMock.On("Cancel", "1", mock.Anything, "customer_id").Return(nil)
+	suite.pS.On("Refund", mock.Anything).Return(nil)
+
+	result, err := suite.bS.CancelGuestBooking("lookup-token")
+
+	suite.NoError(err)
+	suite.Equal(model.BookingCancel, result.Status)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCancelGuestBooking_InvalidToken() {
+	suite.auth.On("VerifyLookupToken", "forged").Return("", errors.New("forbidden, invalid or expired lookup token"))
+
+	_, err := suite.bS.CancelGuestBooking("forged")
+
+	suite.EqualError(err, "forbidden, invalid or expired lookup token")
+	suite.repoMock.AssertNotCalled(suite.T(), "FindById", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestFindGuestBooking_Success() {
+	suite.auth.On("VerifyLookupToken", "lookup-token").Return("1", nil)
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+
+	result, err := suite.bS.FindGuestBooking("lookup-token")
+
+	suite.NoError(err)
+	suite.Equal(court, result.Court)
+	suite.Equal([]model.Payment{deposit}, result.PaymentDetails)
+}
+
+func (suite *BookingServiceTestSuite) TestFindGuestBooking_NotFound() {
+	suite.auth.On("VerifyLookupToken", "lookup-token").Return("1", nil)
+	suite.repoMock.On("FindById", "1").Return(model.Booking{}, errors.New("sql: no rows in result set"))
+
+	_, err := suite.bS.FindGuestBooking("lookup-token")
+
+	suite.EqualError(err, "booking not found")
+}
+
+func (suite *BookingServiceTestSuite) TestCheckInQRCode_Success() {
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.repoMock.On("CheckInCode", "1", mock.AnythingOfType("string")).Return("check-in-code", nil)
+
+	png, err := suite.bS.CheckInQRCode("customer_id", "1")
+
+	suite.NoError(err)
+	suite.Equal([]byte("\x89PNG"), png[:4])
+}
+
+func (suite *BookingServiceTestSuite) TestCheckInQRCode_PaidInFull() {
+	paid := booking
+	paid.Status = model.BookingPaid
+	suite.repoMock.On("FindById", "1").Return(paid, nil)
+	suite.repoMock.On("CheckInCode", "1", mock.AnythingOfType("string")).Return("check-in-code", nil)
+
+	png, err := suite.bS.CheckInQRCode("customer_id", "1")
+
+	suite.NoError(err)
+	suite.Equal([]byte("\x89PNG"), png[:4])
+}
+
+func (suite *BookingServiceTestSuite) TestCheckInQRCode_Forbidden() {
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+
+	_, err := suite.bS.CheckInQRCode("other_customer", "1")
+
+	suite.EqualError(err, "forbidden, this booking belongs to another customer")
+	suite.repoMock.AssertNotCalled(suite.T(), "CheckInCode", mock.Anything, mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCheckInQRCode_NotBooked() {
+	pending := booking
+	pending.Status = model.BookingPending
+	suite.repoMock.On("FindById", "1").Return(pending, nil)
+
+	_, err := suite.bS.CheckInQRCode("customer_id", "1")
+
+	suite.EqualError(err, "cannot issue a check-in code for a booking with status pending")
+}
+
+func (suite *BookingServiceTestSuite) TestGuestCheckInQRCode_InvalidToken() {
+	suite.auth.On("VerifyLookupToken", "bad-token").Return("", errors.New("forbidden, invalid or expired lookup token"))
+
+	_, err := suite.bS.GuestCheckInQRCode("bad-token")
+
+	suite.EqualError(err, "forbidden, invalid or expired lookup token")
+}
+
+func (suite *BookingServiceTestSuite) TestCheckIn_Success() {
+	today := booking
+	today.BookingDate = time.Now()
+	suite.repoMock.On("FindByCheckInCode", "check-in-code").Return(today, nil)
+	suite.repoMock.On("ChangeStatus", "1", model.BookingCheckedIn, "employee_id", "checked in at the desk").Return(nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+
+	result, err := suite.bS.CheckIn(dto.CheckInRequest{Code: "check-in-code", EmployeeId: "employee_id"})
+
+	suite.NoError(err)
+	suite.Equal(model.BookingCheckedIn, result.Status)
+	suite.Equal([]model.Payment{deposit}, result.PaymentDetails)
+}
+
+func (suite *BookingServiceTestSuite) TestCheckIn_NotToday() {
+	tomorrow := booking
+	tomorrow.BookingDate = time.Now().AddDate(0, 0, 1)
+	suite.repoMock.On("FindByCheckInCode", "check-in-code").Return(tomorrow, nil)
+
+	_, err := suite.bS.CheckIn(dto.CheckInRequest{Code: "check-in-code", EmployeeId: "employee_id"})
+
+	suite.ErrorContains(err, "cannot check in, booking is scheduled for")
+	suite.repoMock.AssertNotCalled(suite.T(), "ChangeStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCheckIn_UnknownCode() {
+	suite.repoMock.On("FindByCheckInCode", "unknown").Return(model.Booking{}, errors.New("sql: no rows in result set"))
+
+	_, err := suite.bS.CheckIn(dto.CheckInRequest{Code: "unknown", EmployeeId: "employee_id"})
+
+	suite.EqualError(err, "booking not found")
+}
+
+func (suite *BookingServiceTestSuite) TestMarkNoShows_SkipsCheckedIn() {
+	cutoff := time.Date(2030, 10, 1, 9, 45, 0, 0, time.UTC)
+	missed := booking
+	checkedIn := booking
+	checkedIn.Id = "2"
+	suite.repoMock.On("FindMissedCheckIns", cutoff).Return([]model.Booking{missed, checkedIn}, nil)
+	suite.repoMock.On("ChangeStatus", "1", model.BookingNoShow, "system", "not checked in within the grace period").Return(nil)
+	suite.repoMock.On("ChangeStatus", "2", model.BookingNoShow, "system", "not checked in within the grace period").Return(&model.BookingTransitionError{From: model.BookingCheckedIn, To: model.BookingNoShow})
+
+	marked, err := suite.bS.MarkNoShows(cutoff)
+
+	suite.NoError(err)
+	suite.Equal(1, marked)
+}
+
+func (suite *BookingServiceTestSuite) TestMarkNoShows_PaidInFull() {
+	cutoff := time.Date(2030, 10, 1, 9, 45, 0, 0, time.UTC)
+	paid := booking
+	paid.Status = model.BookingPaid
+	suite.repoMock.On("FindMissedCheckIns", cutoff).Return([]model.Booking{paid}, nil)
+	suite.repoMock.On("ChangeStatus", "1", model.BookingNoShow, "system", "not checked in within the grace period").Return(nil)
+
+	marked, err := suite.bS.MarkNoShows(cutoff)
+
+	suite.NoError(err)
+	suite.Equal(1, marked)
+	suite.audit.AssertCalled(suite.T(), "Record", mock.MatchedBy(func(e model.AuditLog) bool {
+		return e.Action == "booking.no_show"
+	}), mock.MatchedBy(func(before model.Booking) bool {
+		return before.Status == model.BookingPaid
+	}), mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCheckIn_PaidInFull() {
+	today := booking
+	today.Status = model.BookingPaid
+	today.BookingDate = time.Now()
+	suite.repoMock.On("FindByCheckInCode", "check-in-code").Return(today, nil)
+	suite.repoMock.On("ChangeStatus", "1", model.BookingCheckedIn, "employee_id", "checked in at the desk").Return(nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{}, nil)
+
+	result, err := suite.bS.CheckIn(dto.CheckInRequest{Code: "check-in-code", EmployeeId: "employee_id"})
+
+	suite.NoError(err)
+	suite.Equal(model.BookingCheckedIn, result.Status)
+}
+
+func (suite *BookingServiceTestSuite) TestMarkNoShows_AppliesPenalties() {
+	cutoff := time.Date(2030, 10, 1, 9, 45, 0, 0, time.UTC)
+	suite.repoMock.On("FindMissedCheckIns", cutoff).Return([]model.Booking{booking}, nil)
+	suite.repoMock.On("ChangeStatus", "1", model.BookingNoShow, "system", "not checked in within the grace period").Return(nil)
+
+	_, err := suite.bS.MarkNoShows(cutoff)
+
+	suite.NoError(err)
+	suite.restrict.AssertCalled(suite.T(), "ApplyNoShowPenalties", "customer_id")
+}
+
+func (suite *BookingServiceTestSuite) TestMarkNoShows_Failed() {
+	cutoff := time.Date(2030, 10, 1, 9, 45, 0, 0, time.UTC)
+	suite.repoMock.On("FindMissedCheckIns", cutoff).Return([]model.Booking{booking}, nil)
+	suite.repoMock.On("ChangeStatus", "1", model.BookingNoShow, "system", "not checked in within the grace period").Return(errors.New("error"))
+
+	marked, err := suite.bS.MarkNoShows(cutoff)
+
+	suite.Error(err)
+	suite.Equal(0, marked)
+}
+
+func (suite *BookingServiceTestSuite) TestRefund_PartialThroughGateway() {
+	earlier := model.Payment{BookingId: "1", OrderId: "Refund1-1", Price: 5000, Status: "refund", RefundOf: deposit.OrderId}
+
+	suite.repoMock.On("FindPaymentByOrderId", deposit.OrderId).Return(deposit, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit, earlier}, nil)
+	suite.pS.On("Refund", mock.MatchedBy(func(refund model.Payment) bool {
+		return refund.Price == 4000 && refund.RefundOf == deposit.OrderId && refund.PaymentMethod == "gopay"
+	})).Return(nil)
+	suite.repoMock.On("CreateRefund", mock.MatchedBy(func(refund model.Payment) bool {
+		return refund.Price == 4000 && refund.Status == "refund" && refund.User.Id == "employee_id" && refund.Description == "court light broken"
+	})).Return(model.Payment{OrderId: "Refund1-2", Price: 4000, Status: "refund", RefundOf: deposit.OrderId}, nil)
+
+	result, err := suite.bS.Refund(dto.CreateRefundRequest{OrderId: deposit.OrderId, Amount: 4000, Reason: "court light broken", EmployeeId: "employee_id"})
+
+	suite.NoError(err)
+	suite.Equal(deposit.OrderId, result.RefundOf)
+	suite.pS.AssertExpectations(suite.T())
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestRefund_FullCash() {
+	cash := deposit
+	cash.PaymentMethod = "cash"
+
+	suite.repoMock.On("FindPaymentByOrderId", cash.OrderId).Return(cash, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{cash}, nil)
+	suite.repoMock.On("CreateRefund", mock.MatchedBy(func(refund model.Payment) bool {
+		return refund.Price == 15000 && refund.PaymentMethod == "cash" && refund.User.Id == "employee_id"
+	})).Return(model.Payment{Price: 15000, Status: "refund"}, nil)
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+
+	_, err := suite.bS.Refund(dto.CreateRefundRequest{OrderId: cash.OrderId, EmployeeId: "employee_id"})
+
+	suite.NoError(err)
+	suite.pS.AssertNotCalled(suite.T(), "Refund", mock.Anything)
+	suite.repoMock.AssertNotCalled(suite.T(), "ChangeStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestRefund_FullyRefundedCancelledBooking() {
+	cancelled := booking
+	cancelled.Status = model.BookingCancel
+
+	suite.repoMock.On("FindPaymentByOrderId", deposit.OrderId).Return(deposit, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.pS.On("Refund", mock.Anything).Return(nil)
+	suite.repoMock.On("CreateRefund", mock.Anything).Return(model.Payment{Price: 15000, Status: "refund"}, nil)
+	suite.repoMock.On("FindById", "1").Return(cancelled, nil)
+	suite.repoMock.On("ChangeStatus", "1", model.BookingRefunded, "employee_id", "fully refunded").Return(nil)
+
+	_, err := suite.bS.Refund(dto.CreateRefundRequest{OrderId: deposit.OrderId, EmployeeId: "employee_id"})
+
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestRefund_ExceedsRefundable() {
+	earlier := model.Payment{BookingId: "1", OrderId: "Refund1-1", Price: 10000, Status: "refund", RefundOf: deposit.OrderId}
+
+	suite.repoMock.On("FindPaymentByOrderId", deposit.OrderId).Return(deposit, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit, earlier}, nil)
+
+	_, err := suite.bS.Refund(dto.CreateRefundRequest{OrderId: deposit.OrderId, Amount: 6000})
+
+	suite.EqualError(err, "invalid refund amount, 5000 of this payment is refundable")
+	suite.repoMock.AssertNotCalled(suite.T(), "CreateRefund", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestRefund_AlreadyRefunded() {
+	earlier := model.Payment{BookingId: "1", OrderId: "Refund1-1", Price: 15000, Status: "refund", RefundOf: deposit.OrderId}
+
+	suite.repoMock.On("FindPaymentByOrderId", deposit.OrderId).Return(deposit, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit, earlier}, nil)
+
+	_, err := suite.bS.Refund(dto.CreateRefundRequest{OrderId: deposit.OrderId})
+
+	suite.EqualError(err, "cannot refund, this payment is already fully refunded")
+}
+
+func (suite *BookingServiceTestSuite) TestRefund_CashThroughGateway() {
+	cash := deposit
+	cash.PaymentMethod = "cash"
+
+	suite.repoMock.On("FindPaymentByOrderId", cash.OrderId).Return(cash, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{cash}, nil)
+
+	_, err := suite.bS.Refund(dto.CreateRefundRequest{OrderId: cash.OrderId, PaymentMethod: "mid"})
+
+	suite.EqualError(err, "cannot refund a cash payment through the payment gateway")
+}
+
+func (suite *BookingServiceTestSuite) TestRefund_NotPaid() {
+	unpaid := deposit
+	unpaid.Status = "unpaid"
+
+	suite.repoMock.On("FindPaymentByOrderId", unpaid.OrderId).Return(unpaid, nil)
+
+	_, err := suite.bS.Refund(dto.CreateRefundRequest{OrderId: unpaid.OrderId})
+
+	suite.EqualError(err, "cannot refund payment with status unpaid")
+}
+
+func (suite *BookingServiceTestSuite) TestRefund_GatewayError() {
+	suite.repoMock.On("FindPaymentByOrderId", deposit.OrderId).Return(deposit, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.pS.On("Refund", mock.Anything).Return(errors.New("gateway error"))
+
+	_, err := suite.bS.Refund(dto.CreateRefundRequest{OrderId: deposit.OrderId})
+
+	suite.EqualError(err, "gateway error")
+	suite.repoMock.AssertNotCalled(suite.T(), "CreateRefund", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestRefund_NotFound() {
+	suite.repoMock.On("FindPaymentByOrderId", "unknown").Return(model.Payment{}, errors.New("sql: no rows in result set"))
+
+	_, err := suite.bS.Refund(dto.CreateRefundRequest{OrderId: "unknown"})
+
+	suite.EqualError(err, "payment not found")
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_Audited() {
+	booked := booking
+	booked.BookingDate = time.Now().AddDate(0, 0, 3)
+
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{}, nil)
+	suite.repoMock.On("Cancel", "1", []model.Payment(nil), "customer_id").Return(nil)
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.NoError(err)
+	suite.audit.AssertCalled(suite.T(), "Record", model.AuditLog{Actor: "customer_id", Action: "booking.cancel", EntityType: "booking", EntityId: "1"}, mock.MatchedBy(func(before model.Booking) bool {
+		return before.Status == model.BookingBooked && before.Customer.Password == ""
+	}), mock.MatchedBy(func(after model.Booking) bool {
+		return after.Status == model.BookingCancel
+	}))
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_PartialRefund() {
+	booked := booking
+	now := time.Now()
+	booked.BookingDate = now.Add(time.Hour * 12)
+	booked.StartTime = now.Add(time.Hour * 12)
+
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.repoMock.On("Cancel", "1", mock.MatchedBy(func(refunds []model.Payment) bool {
+		return len(refunds) == 1 && refunds[0].Price == 7500
+	}), "employee_id").Return(nil)
+	suite.pS.On("Refund", mock.MatchedBy(func(refund model.Payment) bool {
+		return refund.Price == 7500
+	})).Return(nil)
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "employee_id", Role: "employee"})
+
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+	suite.pS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_PendingNoRefund() {
+	pending := booking
+	pending.Status = "pending"
+	pending.BookingDate = time.Now().AddDate(0, 0, 3)
+	unpaid := model.Payment{Id: "1", BookingId: "1", OrderId: "Booking_1", PaymentMethod: "mid", Price: 15000, Status: "unpaid"}
+
+	suite.repoMock.On("FindById", "1").Return(pending, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{unpaid}, nil)
+	suite.pS.On("Expire", "Booking_1").Return(nil)
+	suite.repoMock.On("Cancel", "1", []model.Payment(nil), "customer_id").Return(nil)
+
+	result, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.NoError(err)
+	suite.Empty(result.PaymentDetails)
+	suite.repoMock.AssertExpectations(suite.T())
+	suite.pS.AssertExpectations(suite.T())
+}
+
+// TestCancel_PendingGatewayRefusesExpire keeps the booking when its unpaid
+// order cannot be closed, e.g. because the customer paid it in the meantime.
+func (suite *BookingServiceTestSuite) TestCancel_PendingGatewayRefusesExpire() {
+	pending := booking
+	pending.Status = "pending"
+	pending.BookingDate = time.Now().AddDate(0, 0, 3)
+	unpaid := model.Payment{Id: "1", BookingId: "1", OrderId: "Booking_1", PaymentMethod: "mid", Price: 15000, Status: "unpaid"}
+
+	suite.repoMock.On("FindById", "1").Return(pending, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{unpaid}, nil)
+	suite.pS.On("Expire", "Booking_1").Return(errors.New("cannot expire transaction with status settlement"))
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.EqualError(err, "cannot cancel, failed to close payment Booking_1: cannot expire transaction with status settlement")
+	suite.repoMock.AssertNotCalled(suite.T(), "Cancel", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_NotOwner() {
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "other_customer", Role: "customer"})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "forbidden")
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_InvalidStatus() {
+	done := booking
+	done.Status = "done"
+	suite.repoMock.On("FindById", "1").Return(done, nil)
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.EqualError(err, "cannot cancel booking with status done")
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_NotFound() {
+	suite.repoMock.On("FindById", "1").Return(model.Booking{}, errors.New("sql: no rows in result set"))
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1"})
+
+	suite.EqualError(err, "booking not found")
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_Failed() {
+	booked := booking
+	booked.BookingDate = time.Now().AddDate(0, 0, 3)
+
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{}, nil)
+	suite.repoMock.On("Cancel", "1", []model.Payment(nil), "customer_id").Return(errors.New("error"))
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.Error(err)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateRepay_UnpaidAdjustment() {
+	unpaid := model.Payment{Id: "2", BookingId: "1", OrderId: "Reschedule1-1", Price: 5000, Status: "unpaid"}
+	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{deposit, unpaid}, nil)
+
+	_, err := suite.bS.CreateRepay(createRepayRequest)
+	suite.EqualError(err, "this booking still has payment to complete")
+}
+
+func (suite *BookingServiceTestSuite) TestCreateRepay_ChargesRemainingBalance() {
+	suite.repoMock.On("FindById", createRepayRequest.BookingId).Return(booking, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", booking.Id).Return([]model.Payment{{Price: 20000, Status: "paid"}}, nil)
+	suite.uS.On("FindUserById", booking.Customer.Id).Return(user, nil)
+	suite.cS.On("FindCourtById", booking.Court.Id).Return(court, nil)
+	suite.repoMock.On("FindTotal", booking.Customer.Id).Return(1, nil)
+	suite.repoMock.On("CreateRepay", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 10000 && p.Court.Price*p.Qty == p.Price
+	})).Return(expectedPayment, nil)
+
+	request := createRepayRequest
+	request.PaymentMethod = "cash"
+	_, err := suite.bS.CreateRepay(request)
+
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+var rescheduleRequest = dto.RescheduleBookingRequest{
+	BookingId:   "1",
+	CourtId:     "court_id_2",
+	BookingDate: "10-10-2030",
+	StartTime:   "10:00:00",
+	Hour:        2,
+	UserId:      "customer_id",
+	Role:        "customer",
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_ChargesDifference() {
+	booked := booking
+	booked.StartTime = time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC)
+	booked.EndTime = time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)
+	pricier := model.Court{Id: "court_id_2", Name: "VIP Court", Price: 40000}
+
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id_2").Return(pricier, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 25000 && p.Court.Price*p.Qty == p.Price
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Reschedule", mock.MatchedBy(func(b model.Booking) bool {
+		return b.Total_Payment == 80000 && len(b.PaymentDetails) == 1 && b.PaymentDetails[0].Status == "unpaid"
+	})).Return(model.Booking{Id: "1", Total_Payment: 80000, PaymentDetails: []model.Payment{{Price: 25000, Status: "unpaid"}}}, nil)
+
+	result, err := suite.bS.Reschedule(rescheduleRequest)
+
+	suite.NoError(err)
+	suite.Equal(2, len(result.PaymentDetails))
+	suite.repoMock.AssertExpectations(suite.T())
+	suite.pS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_PaidInFullChargesWholeDifference() {
+	prepaid := booking
+	prepaid.Status = model.BookingPaid
+	prepaid.StartTime = time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC)
+	prepaid.EndTime = time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)
+	pricier := model.Court{Id: "court_id_2", Name: "VIP Court", Price: 40000}
+	full := deposit
+	full.Price = 40000
+
+	suite.repoMock.On("FindById", "1").Return(prepaid, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id_2").Return(pricier, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{full}, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 40000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Reschedule", mock.MatchedBy(func(b model.Booking) bool {
+		return b.Total_Payment == 80000 && len(b.PaymentDetails) == 1 && b.PaymentDetails[0].Price == 40000
+	})).Return(model.Booking{Id: "1", Total_Payment: 80000, Status: model.BookingPaid}, nil)
+
+	_, err := suite.bS.Reschedule(rescheduleRequest)
+
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+	suite.pS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_CreditsDifference() {
+	cheaper := model.Court{Id: "court_id_2", Name: "Court B", Price: 10000}
+
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id_2").Return(cheaper, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.repoMock.On("Reschedule", mock.MatchedBy(func(b model.Booking) bool {
+		return b.Total_Payment == 20000 && len(b.PaymentDetails) == 0
+	})).Return(model.Booking{Id: "1", Total_Payment: 20000}, nil)
+
+	_, err := suite.bS.Reschedule(rescheduleRequest)
+
+	suite.NoError(err)
+	suite.pS.AssertNotCalled(suite.T(), "GetPaymentURL", mock.Anything)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_RefundsOverpayment() {
+	cheaper := model.Court{Id: "court_id_2", Name: "Court B", Price: 5000}
+	request := rescheduleRequest
+	request.Hour = 1
+
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id_2").Return(cheaper, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.repoMock.On("Reschedule", mock.MatchedBy(func(b model.Booking) bool {
+		return len(b.PaymentDetails) == 1 && b.PaymentDetails[0].Status == "refund" && b.PaymentDetails[0].Price == 10000
+	})).Return(model.Booking{Id: "1", Total_Payment: 5000}, nil)
+	suite.pS.On("Refund", mock.MatchedBy(func(refund model.Payment) bool {
+		return refund.Price == 10000 && refund.RefundOf == deposit.OrderId && refund.PaymentMethod == "gopay"
+	})).Return(nil)
+
+	_, err := suite.bS.Reschedule(request)
+
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+	suite.pS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_RefundsOverpaymentPerOrder() {
+	cheaper := model.Court{Id: "court_id_2", Name: "Court B", Price: 1000}
+	request := rescheduleRequest
+	request.Hour = 1
+	topUp := model.Payment{Id: "2", BookingId: "1", OrderId: "Reschedule1-1", PaymentMethod: "mid", Price: 5000, Status: "paid"}
+
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id_2").Return(cheaper, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit, topUp}, nil)
+	suite.repoMock.On("Reschedule", mock.MatchedBy(func(b model.Booking) bool {
+		return len(b.PaymentDetails) == 2 &&
+			b.PaymentDetails[0].RefundOf == deposit.OrderId && b.PaymentDetails[0].Price == 15000 &&
+			b.PaymentDetails[1].RefundOf == topUp.OrderId && b.PaymentDetails[1].Price == 4000
+	})).Return(model.Booking{Id: "1", Total_Payment: 1000}, nil)
+	suite.pS.On("Refund", mock.Anything).Return(nil).Twice()
+
+	_, err := suite.bS.Reschedule(request)
+
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+	suite.pS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_GatewayRefundFailed() {
+	cheaper := model.Court{Id: "court_id_2", Name: "Court B", Price: 5000}
+	request := rescheduleRequest
+	request.Hour = 1
+
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id_2").Return(cheaper, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.pS.On("Refund", mock.Anything).Return(errors.New("gateway error"))
+
+	_, err := suite.bS.Reschedule(request)
+
+	suite.EqualError(err, "gateway error")
+	suite.repoMock.AssertNotCalled(suite.T(), "Reschedule", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_Collision() {
+	taken := model.Booking{
+		Id:          "2",
+		Court:       model.Court{Id: "court_id_2"},
+		BookingDate: time.Date(2030, 10, 10, 0, 0, 0, 0, time.UTC),
+		StartTime:   time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(0, 1, 1, 11, 0, 0, 0, time.UTC),
+		Status:      "booked",
+	}
+
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{taken}, nil)
+
+	_, err := suite.bS.Reschedule(rescheduleRequest)
+
+	suite.EqualError(err, "cannot book court in that time")
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_IgnoresOwnSchedule() {
+	own := booking
+	own.Court = model.Court{Id: "court_id_2"}
+	own.BookingDate = time.Date(2030, 10, 10, 0, 0, 0, 0, time.UTC)
+	own.StartTime = time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)
+	own.EndTime = time.Date(0, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	suite.repoMock.On("FindById", "1").Return(own, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{own}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id_2").Return(court, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{{Price: 60000, Status: "paid"}}, nil)
+	suite.repoMock.On("Reschedule", mock.Anything).Return(model.Booking{Id: "1"}, nil)
+
+	_, err := suite.bS.Reschedule(rescheduleRequest)
+
+	suite.NoError(err)
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_NotBooked() {
+	pending := booking
+	pending.Status = "pending"
+	suite.repoMock.On("FindById", "1").Return(pending, nil)
+
+	_, err := suite.bS.Reschedule(rescheduleRequest)
+
+	suite.EqualError(err, "cannot reschedule booking with status pending")
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_NotOwner() {
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	request := rescheduleRequest
+	request.UserId = "other_customer"
+
+	_, err := suite.bS.Reschedule(request)
+
+	suite.Contains(err.Error(), "forbidden")
+}
+
+func (suite *BookingServiceTestSuite) TestUpdatePayment_Success_Reschedule() {
+	notif := dto.PaymentNotificationInput{TransactionStatus: "settlement", OrderId: "Reschedule1-1", PaymentType: "gopay"}
+	adjustment := model.Payment{BookingId: "1", OrderId: "Reschedule1-1", PaymentMethod: "gopay", Status: "paid"}
+
+	suite.pS.On("PaymentProcess", notif).Return(adjustment, nil)
+	suite.repoMock.On("FindPaymentByOrderId", notif.OrderId).Return(adjustment, nil)
+	suite.repoMock.On("UpdatePaymentStatus", adjustment).Return(nil)
+
+	err := suite.bS.UpdatePayment(notif)
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+var seriesRequest = dto.CreateBookingSeriesRequest{
+	CourtId:     "court_id",
+	Frequency:   "weekly",
+	StartDate:   "01-10-2030",
+	Occurrences: 3,
+	StartTime:   "19:00:00",
+	Hour:        2,
+	CustomerId:  "customer_id",
+}
+
+func (suite *BookingServiceTestSuite) TestCreateSeries_Success() {
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 180000 && p.Qty == 3 && len(p.Items) == 3 && p.Items[0].Price == 60000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("CreateSeries", mock.MatchedBy(func(s model.BookingSeries) bool {
+		return len(s.Bookings) == 3 &&
+			s.Bookings[1].BookingDate.Equal(time.Date(2030, 10, 8, 0, 0, 0, 0, time.UTC)) &&
+			s.Bookings[2].PaymentDetails[0].OrderId == s.Bookings[0].PaymentDetails[0].OrderId
+	})).Return(model.BookingSeries{Id: "series_1"}, nil)
+
+	result, err := suite.bS.CreateSeries(seriesRequest)
+
+	suite.NoError(err)
+	suite.Equal("series_1", result.Id)
+	suite.repoMock.AssertNumberOfCalls(suite.T(), "FindByDate", 3)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreateSeries_ReportsCollisions() {
+	taken := model.Booking{
+		Court:       model.Court{Id: "court_id"},
+		BookingDate: time.Date(2030, 10, 8, 0, 0, 0, 0, time.UTC),
+		StartTime:   time.Date(0, 1, 1, 20, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(0, 1, 1, 22, 0, 0, 0, time.UTC),
+		Status:      "booked",
+	}
+	suite.repoMock.On("FindByDate", time.Date(2030, 10, 8, 0, 0, 0, 0, time.UTC)).Return([]model.Booking{taken}, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+
+	_, err := suite.bS.CreateSeries(seriesRequest)
+
+	var collision *SeriesCollisionError
+	suite.ErrorAs(err, &collision)
+	suite.Equal(1, len(collision.Collisions))
+	suite.Equal("08-10-2030", collision.Collisions[0].BookingDate)
+	suite.repoMock.AssertNotCalled(suite.T(), "CreateSeries", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateSeries_UntilEndDate() {
+	request := seriesRequest
+	request.Occurrences = 0
+	request.Frequency = "biweekly"
+	request.EndDate = "31-10-2030"
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.Anything).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("CreateSeries", mock.MatchedBy(func(s model.BookingSeries) bool {
+		return len(s.Bookings) == 3 && s.EndDate.Equal(time.Date(2030, 10, 29, 0, 0, 0, 0, time.UTC))
+	})).Return(model.BookingSeries{Id: "series_1"}, nil)
+
+	_, err := suite.bS.CreateSeries(request)
+
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreateSeries_TooManyOccurrences() {
+	request := seriesRequest
+	request.Occurrences = 0
+	request.EndDate = "01-10-2032"
+
+	_, err := suite.bS.CreateSeries(request)
+
+	suite.EqualError(err, "cannot book series with more than 52 occurrences")
+}
+
+func (suite *BookingServiceTestSuite) TestCreateSeries_Restricted() {
+	suite.withRestrictions([]model.Restriction{{Type: model.RestrictionFullPrepayment, Reason: "2 no-shows in 60 days", EndsAt: time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)}})
+
+	_, err := suite.bS.CreateSeries(seriesRequest)
+
+	suite.EqualError(err, "cannot book series, recurring bookings are unavailable while your account requires full prepayment until 31-01-2030: 2 no-shows in 60 days, book single sessions paid in full instead")
+	suite.repoMock.AssertNotCalled(suite.T(), "FindByDate", mock.Anything)
+	suite.restrict.AssertNumberOfCalls(suite.T(), "FindActiveRestrictions", 1)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateSeries_BlockedOnline() {
+	suite.withRestrictions([]model.Restriction{{Type: model.RestrictionBlockOnline, Reason: "3 no-shows in 60 days", EndsAt: time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC)}})
+
+	_, err := suite.bS.CreateSeries(seriesRequest)
+
+	suite.EqualError(err, "cannot book online, your account is restricted until 15-01-2030: 3 no-shows in 60 days")
+	suite.repoMock.AssertNotCalled(suite.T(), "FindByDate", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCreateSeries_Failed() {
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.Anything).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("CreateSeries", mock.Anything).Return(model.BookingSeries{}, errors.New("error"))
+
+	_, err := suite.bS.CreateSeries(seriesRequest)
+
+	suite.Error(err)
+}
+
+func (suite *BookingServiceTestSuite) TestCancelSeries_PendingSeries() {
+	future := time.Now().AddDate(0, 0, 7)
+	series := model.BookingSeries{
+		Id:       "series_1",
+		Customer: model.User{Id: "customer_id"},
+		Bookings: []model.Booking{
+			{Id: "1", BookingDate: future, Status: "pending"},
+			{Id: "2", BookingDate: future.AddDate(0, 0, 7), Status: "pending"},
+		},
+	}
+	seriesPayment := model.Payment{BookingId: "1", OrderId: "Series00001-1", Status: "unpaid"}
+
+	suite.repoMock.On("FindSeriesById", "series_1").Return(series, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", mock.Anything).Return([]model.Payment{seriesPayment}, nil)
+	suite.pS.On("Expire", "Series00001-1").Return(nil)
+	suite.repoMock.On("UpdateSeriesStatus", model.Payment{OrderId: "Series00001-1", Status: "cancel", User: model.User{Id: "customer_id"}}).Return(nil)
+
+	cancelled, err := suite.bS.CancelSeries(dto.CancelBookingSeriesRequest{SeriesId: "series_1", UserId: "customer_id", Role: "customer"})
+
+	suite.NoError(err)
+	suite.Equal(2, len(cancelled))
+	suite.repoMock.AssertExpectations(suite.T())
+	suite.pS.AssertNumberOfCalls(suite.T(), "Expire", 1)
+	suite.repoMock.AssertNumberOfCalls(suite.T(), "UpdateSeriesStatus", 1)
+	suite.waitlist.AssertCalled(suite.T(), "OfferSlot", mock.MatchedBy(func(b model.Booking) bool { return b.Id == "1" }))
+	suite.waitlist.AssertCalled(suite.T(), "OfferSlot", mock.MatchedBy(func(b model.Booking) bool { return b.Id == "2" }))
+}
+
+func (suite *BookingServiceTestSuite) TestCancelSeries_PendingGatewayRefusesExpire() {
+	future := time.Now().AddDate(0, 0, 7)
+	series := model.BookingSeries{
+		Id:       "series_1",
+		Customer: model.User{Id: "customer_id"},
+		Bookings: []model.Booking{
+			{Id: "1", BookingDate: future, Status: "pending"},
+		},
+	}
+	seriesPayment := model.Payment{BookingId: "1", OrderId: "Series00001-1", Status: "unpaid"}
+
+	suite.repoMock.On("FindSeriesById", "series_1").Return(series, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{seriesPayment}, nil)
+	suite.pS.On("Expire", "Series00001-1").Return(errors.New("cannot expire transaction with status settlement"))
+
+	_, err := suite.bS.CancelSeries(dto.CancelBookingSeriesRequest{SeriesId: "series_1", UserId: "customer_id", Role: "customer"})
+
+	suite.EqualError(err, "cannot cancel series, failed to close payment Series00001-1: cannot expire transaction with status settlement")
+	suite.repoMock.AssertNotCalled(suite.T(), "UpdateSeriesStatus", mock.Anything)
+	suite.waitlist.AssertNotCalled(suite.T(), "OfferSlot", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCancelSeries_RemainingOccurrences() {
+	series := model.BookingSeries{
+		Id:       "series_1",
+		Customer: model.User{Id: "customer_id"},
+		Bookings: []model.Booking{
+			{Id: "1", BookingDate: time.Now().AddDate(0, 0, -7), Status: "done"},
+			{Id: "2", BookingDate: time.Now().AddDate(0, 0, 7), Status: "booked"},
+		},
+	}
+	upcoming := booking
+	upcoming.Id = "2"
+	upcoming.BookingDate = time.Now().AddDate(0, 0, 7)
+
+	suite.repoMock.On("FindSeriesById", "series_1").Return(series, nil)
+	suite.repoMock.On("FindById", "2").Return(upcoming, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "2").Return([]model.Payment{deposit}, nil)
+	suite.repoMock.On("Cancel", "2", mock.Anything, "customer_id").Return(nil)
+	suite.pS.On("Refund", mock.Anything).Return(nil)
+
+	cancelled, err := suite.bS.CancelSeries(dto.CancelBookingSeriesRequest{SeriesId: "series_1", UserId: "customer_id", Role: "customer"})
+
+	suite.NoError(err)
+	suite.Equal(1, len(cancelled))
+	suite.Equal("2", cancelled[0].Id)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCancelSeries_NothingLeft() {
+	series := model.BookingSeries{
+		Id:       "series_1",
+		Customer: model.User{Id: "customer_id"},
+		Bookings: []model.Booking{{Id: "1", BookingDate: time.Now().AddDate(0, 0, -7), Status: "done"}},
+	}
+	suite.repoMock.On("FindSeriesById", "series_1").Return(series, nil)
+
+	_, err := suite.bS.CancelSeries(dto.CancelBookingSeriesRequest{SeriesId: "series_1", UserId: "customer_id", Role: "customer"})
+
+	suite.EqualError(err, "cannot cancel series, there is no remaining occurrence")
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_PendingSeriesOccurrence() {
+	pending := booking
+	pending.Status = "pending"
+	pending.BookingDate = time.Now().AddDate(0, 0, 3)
+
+	suite.repoMock.On("FindById", "1").Return(pending, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{{OrderId: "Series00001-1", Status: "unpaid"}}, nil)
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "cancel the series instead")
+}
+
+func (suite *BookingServiceTestSuite) TestUpdatePayment_Success_Series() {
+	notif := dto.PaymentNotificationInput{TransactionStatus: "settlement", OrderId: "Series00001-1", PaymentType: "gopay"}
+	seriesPayment := model.Payment{BookingId: "1", OrderId: "Series00001-1", PaymentMethod: "gopay", Status: "paid"}
+
+	suite.pS.On("PaymentProcess", notif).Return(seriesPayment, nil)
+	suite.repoMock.On("FindPaymentByOrderId", notif.OrderId).Return(seriesPayment, nil)
+	suite.repoMock.On("UpdateSeriesStatus", seriesPayment).Return(nil)
+
+	err := suite.bS.UpdatePayment(notif)
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestFindAvailability_SingleCourt() {
+	date := time.Date(2030, 10, 1, 0, 0, 0, 0, time.UTC)
+	existBooking := []model.Booking{
+		{
+			Court:       model.Court{Id: "court_id"},
+			BookingDate: date,
+			StartTime:   time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC),
+			EndTime:     time.Date(0, 1, 1, 10, 30, 0, 0, time.UTC),
+			Status:      "booked",
+		},
+		{
+			Court:       model.Court{Id: "court_id"},
+			BookingDate: date,
+			StartTime:   time.Date(0, 1, 1, 8, 0, 0, 0, time.UTC),
+			EndTime:     time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC),
+			Status:      "cancel",
+		},
+		{
+			Court:       model.Court{Id: "other_court"},
+			BookingDate: date,
+			StartTime:   time.Date(0, 1, 1, 11, 0, 0, 0, time.UTC),
+			EndTime:     time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC),
+			Status:      "pending",
+		},
+	}
+
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindByDate", date).Return(existBooking, nil)
+
+	result, paginate, err := suite.bS.FindAvailability(dto.AvailabilityRequest{StartDate: "01-10-2030", CourtId: "court_id"}, 1, 10)
+
+	suite.NoError(err)
+	suite.Equal(1, paginate.TotalRows)
+	suite.Equal(1, len(result))
+	suite.Equal(1, len(result[0].Days))
+
+	var statuses []string
+	for _, slot := range result[0].Days[0].Slots[:4] {
+		statuses = append(statuses, slot.Status)
+	}
+	suite.Equal(15, len(result[0].Days[0].Slots))
+	suite.Equal([]string{"free", "taken", "taken", "free"}, statuses)
+}
+
+func (suite *BookingServiceTestSuite) TestFindAvailability_AllCourtsDateRange() {
+	courts := []model.Court{court, {Id: "other_court", Name: "Other Court"}}
+	paginate := dto.Paginate{Page: 1, Size: 10, TotalRows: 2, TotalPages: 1}
+
+	suite.cS.On("FindAllCourts", 1, 10).Return(courts, paginate, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+
+	result, _, err := suite.bS.FindAvailability(dto.AvailabilityRequest{StartDate: "01-10-2030", EndDate: "03-10-2030"}, 1, 10)
+
+	suite.NoError(err)
+	suite.Equal(2, len(result))
+	suite.Equal(3, len(result[1].Days))
+	suite.Equal(15, len(result[1].Days[2].Slots))
+	suite.repoMock.AssertNumberOfCalls(suite.T(), "FindByDate", 3)
+}
+
+func (suite *BookingServiceTestSuite) TestFindAvailability_InvalidRange() {
+	_, _, err := suite.bS.FindAvailability(dto.AvailabilityRequest{StartDate: "03-10-2030", EndDate: "01-10-2030"}, 1, 10)
+	suite.EqualError(err, "cannot check availability, endDate is before startDate")
+
+	_, _, err = suite.bS.FindAvailability(dto.AvailabilityRequest{StartDate: "01-10-2030", EndDate: "01-11-2030"}, 1, 10)
+	suite.EqualError(err, "cannot check availability for more than 31 days")
+}
+
+func (suite *BookingServiceTestSuite) TestFindAvailability_CourtNotFound() {
+	suite.cS.On("FindCourtById", "missing").Return(model.Court{}, errors.New("sql: no rows in result set"))
+
+	_, _, err := suite.bS.FindAvailability(dto.AvailabilityRequest{StartDate: "01-10-2030", CourtId: "missing"}, 1, 10)
+	suite.EqualError(err, "court not found")
+}
+
+func (suite *BookingServiceTestSuite) TestExpirePending_Success() {
+	cutoff := time.Date(2030, 10, 1, 10, 0, 0, 0, time.UTC)
+	expired := []model.Payment{
+		{BookingId: "1", OrderId: "Booking00001-1", Status: "unpaid"},
+		{BookingId: "2", OrderId: "Series00002-1", Status: "unpaid"},
+		{BookingId: "3", OrderId: "Series00002-1", Status: "unpaid"},
+	}
+
+	suite.repoMock.On("FindExpiredPending", cutoff).Return(expired, nil)
+	suite.pS.On("Expire", "Booking00001-1").Return(nil)
+	suite.pS.On("Expire", "Series00002-1").Return(nil)
+	suite.repoMock.On("UpdateStatus", model.Payment{BookingId: "1", OrderId: "Booking00001-1", Status: "cancel", User: model.User{Id: "system"}}).Return(nil)
+	suite.repoMock.On("UpdateSeriesStatus", model.Payment{BookingId: "2", OrderId: "Series00002-1", Status: "cancel", User: model.User{Id: "system"}}).Return(nil)
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+
+	total, err := suite.bS.ExpirePending(cutoff)
+
+	suite.NoError(err)
+	suite.Equal(3, total)
+	suite.repoMock.AssertNumberOfCalls(suite.T(), "UpdateSeriesStatus", 1)
+	suite.pS.AssertNumberOfCalls(suite.T(), "Expire", 2)
+	suite.waitlist.AssertCalled(suite.T(), "OfferSlot", booking)
+}
+
+func (suite *BookingServiceTestSuite) TestExpirePending_ContinuesAfterError() {
+	cutoff := time.Date(2030, 10, 1, 10, 0, 0, 0, time.UTC)
+	expired := []model.Payment{
+		{BookingId: "1", OrderId: "Booking00001-1"},
+		{BookingId: "2", OrderId: "Booking00002-1"},
+	}
+
+	suite.repoMock.On("FindExpiredPending", cutoff).Return(expired, nil)
+	suite.pS.On("Expire", mock.Anything).Return(nil)
+	suite.repoMock.On("UpdateStatus", model.Payment{BookingId: "1", OrderId: "Booking00001-1", Status: "cancel", User: model.User{Id: "system"}}).Return(errors.New("error"))
+	suite.repoMock.On("UpdateStatus", model.Payment{BookingId: "2", OrderId: "Booking00002-1", Status: "cancel", User: model.User{Id: "system"}}).Return(nil)
+	suite.repoMock.On("FindById", "2").Return(model.Booking{}, errors.New("sql: no rows in result set"))
+
+	total, err := suite.bS.ExpirePending(cutoff)
+
+	suite.Error(err)
+	suite.Equal(1, total)
+}
+
+func (suite *BookingServiceTestSuite) TestExpirePending_GatewayRefused() {
+	cutoff := time.Date(2030, 10, 1, 10, 0, 0, 0, time.UTC)
+	expired := []model.Payment{
+		{BookingId: "1", OrderId: "Booking00001-1"},
+		{BookingId: "2", OrderId: "Booking00002-1"},
+	}
+
+	suite.repoMock.On("FindExpiredPending", cutoff).Return(expired, nil)
+	suite.pS.On("Expire", "Booking00001-1").Return(errors.New("cannot expire transaction with status settlement"))
+	suite.pS.On("Expire", "Booking00002-1").Return(nil)
+	suite.repoMock.On("UpdateStatus", model.Payment{BookingId: "2", OrderId: "Booking00002-1", Status: "cancel", User: model.User{Id: "system"}}).Return(nil)
+	suite.repoMock.On("FindById", "2").Return(model.Booking{}, errors.New("sql: no rows in result set"))
+
+	total, err := suite.bS.ExpirePending(cutoff)
+
+	suite.EqualError(err, "cannot expire transaction with status settlement")
+	suite.Equal(1, total)
+	suite.repoMock.AssertNumberOfCalls(suite.T(), "UpdateStatus", 1)
+}
+
+func (suite *BookingServiceTestSuite) TestExpirePending_Failed() {
+	suite.repoMock.On("FindExpiredPending", mock.Anything).Return([]model.Payment{}, errors.New("error"))
+
+	_, err := suite.bS.ExpirePending(time.Now())
+
+	suite.Error(err)
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_OutsideCourtSchedule() {
+	// 02-01-2030 is a wednesday
+	scheduled := court
+	scheduled.OpeningHours = []model.OpeningHour{{Weekday: 3, OpenTime: "09:00:00", CloseTime: "21:00:00"}}
+	scheduled.MaxBookingHours = 3
+	scheduled.SlotMinutes = 30
+
+	cases := []struct {
+		startDate string
+		startTime string
+		hour      int
+		expected  string
+	}{
+		{"02-01-2030", "08:00:00", 2, "cannot book outside opening hours 09:00:00 - 21:00:00"},
+		{"02-01-2030", "19:30:00", 2, "cannot book outside opening hours 09:00:00 - 21:00:00"},
+		{"02-01-2030", "10:00:00", 4, "cannot book more than 3 hours"},
+		{"02-01-2030", "10:10:00", 1, "cannot book, start time must follow 30 minute slots from 09:00:00"},
+		{"03-01-2030", "10:00:00", 1, "cannot book, court is closed on thursday"},
+	}
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(scheduled, nil)
+
+	for _, val := range cases {
+		request := dto.CreateBookingRequest{CourtId: "court_id", BookingDate: val.startDate, StartTime: val.startTime, Hour: val.hour, CustomerId: "customer_id"}
+
+		_, err := suite.bS.Create(request)
+		suite.EqualError(err, val.expected)
+	}
+	suite.pS.AssertNotCalled(suite.T(), "GetPaymentURL", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestFindAvailability_CourtOpeningHours() {
+	scheduled := court
+	scheduled.OpeningHours = []model.OpeningHour{{Weekday: 2, OpenTime: "16:00:00", CloseTime: "20:00:00"}}
+	scheduled.SlotMinutes = 30
+
+	suite.cS.On("FindCourtById", "court_id").Return(scheduled, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+
+	// 01-10-2030 is a tuesday and 02-10-2030 is a wednesday
+	result, _, err := suite.bS.FindAvailability(dto.AvailabilityRequest{StartDate: "01-10-2030", EndDate: "02-10-2030", CourtId: "court_id"}, 1, 10)
+
+	suite.NoError(err)
+	suite.Equal(8, len(result[0].Days[0].Slots))
+	suite.Equal("16:30:00", util.TimeToString(result[0].Days[0].Slots[1].StartTime))
+	suite.Empty(result[0].Days[1].Slots)
+}
+
+var maintenance = model.CourtClosure{
+	Id:        "closure_1",
+	CourtId:   "court_id",
+	StartDate: time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC),
+	EndDate:   time.Date(2030, 1, 3, 0, 0, 0, 0, time.UTC),
+	StartTime: time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC),
+	EndTime:   time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC),
+	Reason:    "resurfacing",
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_CourtClosed() {
+	suite.withClosures([]model.CourtClosure{maintenance})
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+
+	_, err := suite.bS.Create(payload)
+
+	suite.EqualError(err, "cannot book, court is closed from 09:00:00 to 12:00:00 for resurfacing")
+	suite.pS.AssertNotCalled(suite.T(), "GetPaymentURL", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_OutsideClosure() {
+	afterClosure := payload
+	afterClosure.StartTime = "12:00:00"
+
+	suite.withClosures([]model.CourtClosure{maintenance})
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.Anything).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.Anything).Return(model.Booking{Id: "1", PaymentDetails: []model.Payment{{}}}, nil)
+
+	_, err := suite.bS.Create(afterClosure)
+
+	suite.NoError(err)
+}
+
+func (suite *BookingServiceTestSuite) TestFindAvailability_MarksClosedSlots() {
+	suite.withClosures([]model.CourtClosure{maintenance})
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+
+	result, _, err := suite.bS.FindAvailability(dto.AvailabilityRequest{StartDate: "02-01-2030", CourtId: "court_id"}, 1, 10)
+
+	suite.NoError(err)
+	var statuses []string
+	for _, slot := range result[0].Days[0].Slots[:5] {
+		statuses = append(statuses, slot.Status)
+	}
+	suite.Equal([]string{"free", "closed", "closed", "closed", "free"}, statuses)
+}
+
+var peakRule = model.PricingRule{
+	Id:        "rule_1",
+	Name:      "Peak",
+	StartTime: util.StringToTime("11:00:00"),
+	EndTime:   util.StringToTime("23:00:00"),
+	Price:     100000,
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_PeakPricing() {
+	suite.withPricingRules([]model.PricingRule{peakRule})
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 80000 && len(p.Items) == 2 && p.Items[0].Price == 30000 && p.Items[1].Price == 50000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(b model.Booking) bool {
+		return b.Total_Payment == 160000 && len(b.PriceBreakdown) == 2
+	})).Return(model.Booking{Id: "1", PaymentDetails: []model.Payment{{}}}, nil)
+
+	result, err := suite.bS.Create(payload)
+
+	suite.NoError(err)
+	suite.Equal("Test Court 10:00-11:00", result.PriceBreakdown[0].Name)
+	suite.Equal("Test Court 11:00-12:00 (Peak)", result.PriceBreakdown[1].Name)
+	suite.pS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreateSeries_HolidayPricing() {
+	holiday := model.PricingRule{
+		Id:          "rule_2",
+		Name:        "Holiday",
+		HolidayDate: time.Date(2030, 10, 8, 0, 0, 0, 0, time.UTC),
+		StartTime:   util.StringToTime("00:00:00"),
+		EndTime:     util.StringToTime("23:59:59"),
+		Price:       90000,
+	}
+
+	suite.withPricingRules([]model.PricingRule{holiday})
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 210000 && len(p.Items) == 3 && p.Items[1].Price == 90000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("CreateSeries", mock.MatchedBy(func(s model.BookingSeries) bool {
+		return s.Bookings[0].Total_Payment == 120000 && s.Bookings[1].Total_Payment == 180000
+	})).Return(model.BookingSeries{Id: "series_1"}, nil)
+
+	_, err := suite.bS.CreateSeries(seriesRequest)
+
+	suite.NoError(err)
+	suite.pS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_PricingRulesError() {
+	suite.ruleRepo = new(repomock.PricingRuleRepositoryMock)
+	suite.ruleRepo.On("FindAll").Return([]model.PricingRule{}, errors.New("error"))
+	suite.bS = NewBookingService(suite.repoMock, suite.uS, suite.cS, suite.pS, NewPricingService(suite.ruleRepo), suite.promo, suite.loyalty, suite.audit, suite.auth, suite.restrict, suite.waitlist, cancelPolicy, depositConfig, schedule)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+
+	_, err := suite.bS.Create(payload)
+
+	suite.Error(err)
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+var promoRedemption = model.PromoRedemption{
+	PromoCodeId: "promo_1",
+	Code:        "HEMAT",
+	CustomerId:  "customer_id",
+	Discount:    20000,
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_WithPromo() {
+	withPromo := payload
+	withPromo.PromoCode = "hemat"
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.promo.On("ApplyPromo", "hemat", "customer_id", "court_id", 120000).Return(promoRedemption, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 50000 && len(p.Items) == 3 && p.Items[2].Name == "Promo HEMAT" && p.Items[2].Price == -10000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(b model.Booking) bool {
+		return b.Total_Payment == 100000 && b.Promo.PromoCodeId == "promo_1"
+	})).Return(model.Booking{Id: "1", PaymentDetails: []model.Payment{{}}}, nil)
+
+	_, err := suite.bS.Create(withPromo)
+
+	suite.NoError(err)
+	suite.pS.AssertExpectations(suite.T())
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_PromoCoversTotal() {
+	withPromo := payload
+	withPromo.PromoCode = "gratis"
+	free := promoRedemption
+	free.Discount = 120000
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.promo.On("ApplyPromo", "gratis", "customer_id", "court_id", 120000).Return(free, nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(b model.Booking) bool {
+		return b.Total_Payment == 0 && b.PaymentDetails[0].Price == 0 && b.PaymentDetails[0].PaymentURL == ""
+	})).Return(model.Booking{Id: "1", Status: model.BookingPaid, PaymentDetails: []model.Payment{{}}}, nil)
+
+	result, err := suite.bS.Create(withPromo)
+
+	suite.NoError(err)
+	suite.Equal(model.BookingPaid, result.Status)
+	suite.pS.AssertNotCalled(suite.T(), "GetPaymentURL", mock.Anything)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_PromoRejected() {
+	withPromo := payload
+	withPromo.PromoCode = "EXPIRED"
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.promo.On("ApplyPromo", "EXPIRED", "customer_id", "court_id", 120000).Return(model.PromoRedemption{}, errors.New("cannot book, promo code is only valid from 01-01-2020 to 31-01-2020"))
+
+	_, err := suite.bS.Create(withPromo)
+
+	suite.ErrorContains(err, "cannot book, promo code")
+	suite.pS.AssertNotCalled(suite.T(), "GetPaymentURL", mock.Anything)
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_KeepsPromoDiscount() {
+	pricier := model.Court{Id: "court_id_2", Name: "VIP Court", Price: 40000}
+
+	suite.promo = new(servicemock.PromoServiceMock)
+	suite.promo.On("FindRedemption", "1").Return(promoRedemption, nil)
+	suite.bS = NewBookingService(suite.repoMock, suite.uS, suite.cS, suite.pS, suite.prS, suite.promo, suite.loyalty, suite.audit, suite.auth, suite.restrict, suite.waitlist, cancelPolicy, depositConfig, schedule)
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id_2").Return(pricier, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 15000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Reschedule", mock.MatchedBy(func(b model.Booking) bool {
+		return b.Total_Payment == 60000 && len(b.PriceBreakdown) == 3
+	})).Return(model.Booking{Id: "1", Total_Payment: 60000}, nil)
+
+	_, err := suite.bS.Reschedule(rescheduleRequest)
+
+	suite.NoError(err)
+	suite.pS.AssertExpectations(suite.T())
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+var pointRedeemed = model.PointEntry{
+	CustomerId:  "customer_id",
+	Type:        "redeem",
+	Points:      -100,
+	Amount:      10000,
+	Description: "Redeemed 100 points for Rp 10000 off",
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_WithPoints() {
+	withPoints := payload
+	withPoints.UsePoints = true
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.loyalty.On("QuoteRedemption", "customer_id", 120000).Return(pointRedeemed, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 55000 && len(p.Items) == 3 && p.Items[2].Name == "Loyalty points (100)" && p.Items[2].Price == -5000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(b model.Booking) bool {
+		return b.Total_Payment == 110000 && b.PointRedeemed.Points == -100
+	})).Return(model.Booking{Id: "1", PaymentDetails: []model.Payment{{}}}, nil)
+
+	_, err := suite.bS.Create(withPoints)
+
+	suite.NoError(err)
+	suite.pS.AssertExpectations(suite.T())
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestCreate_NotEnoughPoints() {
+	withPoints := payload
+	withPoints.UsePoints = true
+
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindTotal", "customer_id").Return(1, nil)
+	suite.loyalty.On("QuoteRedemption", "customer_id", 120000).Return(model.PointEntry{}, errors.New("cannot book, not enough loyalty points, 100 points needed and 40 available"))
+
+	_, err := suite.bS.Create(withPoints)
+
+	suite.ErrorContains(err, "not enough loyalty points")
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestReschedule_KeepsPointsDiscount() {
+	pricier := model.Court{Id: "court_id_2", Name: "VIP Court", Price: 40000}
+	redeemed := pointRedeemed
+	redeemed.Amount = 20000
+
+	suite.loyalty = new(servicemock.LoyaltyServiceMock)
+	suite.loyalty.On("FindRedemption", "1").Return(redeemed, nil)
+	suite.bS = NewBookingService(suite.repoMock, suite.uS, suite.cS, suite.pS, suite.prS, suite.promo, suite.loyalty, suite.audit, suite.auth, suite.restrict, suite.waitlist, cancelPolicy, depositConfig, schedule)
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", "customer_id").Return(user, nil)
+	suite.cS.On("FindCourtById", "court_id_2").Return(pricier, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+	suite.pS.On("GetPaymentURL", mock.MatchedBy(func(p model.Payment) bool {
+		return p.Price == 15000
+	})).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Reschedule", mock.MatchedBy(func(b model.Booking) bool {
+		return b.Total_Payment == 60000 && len(b.PriceBreakdown) == 3
+	})).Return(model.Booking{Id: "1", Total_Payment: 60000}, nil)
+
+	_, err := suite.bS.Reschedule(rescheduleRequest)
+
+	suite.NoError(err)
+	suite.pS.AssertExpectations(suite.T())
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestFindCustomerBookings_Success() {
+	expectedPaginate := dto.Paginate{Page: 1, Size: 10, TotalRows: 1, TotalPages: 1}
+	suite.repoMock.On("FindByCustomer", "customer_id", true, 1, 10).Return([]model.Booking{booking}, expectedPaginate, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+
+	bookings, paginate, err := suite.bS.FindCustomerBookings("customer_id", true, 1, 10)
+
+	suite.NoError(err)
+	suite.Equal(expectedPaginate, paginate)
+	suite.Equal([]model.Payment{deposit}, bookings[0].PaymentDetails)
+}
+
+func (suite *BookingServiceTestSuite) TestFindCustomerBookings_Failed() {
+	suite.repoMock.On("FindByCustomer", "customer_id", false, 1, 10).Return([]model.Booking{}, dto.Paginate{}, errors.New("query error"))
+
+	_, _, err := suite.bS.FindCustomerBookings("customer_id", false, 1, 10)
+
+	suite.EqualError(err, "query error")
+	suite.repoMock.AssertNotCalled(suite.T(), "FindPaymentsByBookingId", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestFindCustomerBooking_Success() {
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.cS.On("FindCourtById", "court_id").Return(court, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{deposit}, nil)
+
+	result, err := suite.bS.FindCustomerBooking("customer_id", "1")
+
+	suite.NoError(err)
+	suite.Equal(court, result.Court)
+	suite.Equal([]model.Payment{deposit}, result.PaymentDetails)
+}
+
+func (suite *BookingServiceTestSuite) TestFindCustomerBooking_Forbidden() {
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+
+	_, err := suite.bS.FindCustomerBooking("other_customer", "1")
+
+	suite.EqualError(err, "forbidden, this booking belongs to another customer")
+	suite.repoMock.AssertNotCalled(suite.T(), "FindPaymentsByBookingId", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestFindCustomerBooking_NotFound() {
+	suite.repoMock.On("FindById", "1").Return(model.Booking{}, errors.New("sql: no rows in result set"))
+
+	_, err := suite.bS.FindCustomerBooking("customer_id", "1")
+
+	suite.EqualError(err, "booking not found")
+}
+
+func (suite *BookingServiceTestSuite) TestFindStatusHistory_Success() {
+	history := []model.BookingStatusHistory{{Id: "1", BookingId: "1", FromStatus: model.BookingPending, ToStatus: model.BookingBooked}}
+	suite.repoMock.On("FindById", "1").Return(booking, nil)
+	suite.repoMock.On("FindStatusHistory", "1").Return(history, nil)
+
+	result, err := suite.bS.FindStatusHistory("1")
+
+	suite.NoError(err)
+	suite.Equal(history, result)
+}
+
+func (suite *BookingServiceTestSuite) TestFindStatusHistory_NotFound() {
+	suite.repoMock.On("FindById", "1").Return(model.Booking{}, errors.New("sql: no rows in result set"))
+
+	_, err := suite.bS.FindStatusHistory("1")
+
+	suite.EqualError(err, "booking not found")
+	suite.repoMock.AssertNotCalled(suite.T(), "FindStatusHistory", mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestAcceptWaitlistOffer_Success() {
+	offer := model.WaitlistEntry{
+		Id:               "entry_1",
+		CustomerId:       payload.CustomerId,
+		BookingDate:      util.StringToDate(payload.BookingDate),
+		Hour:             payload.Hour,
+		Status:           model.WaitlistOffered,
+		OfferedCourtId:   payload.CourtId,
+		OfferedStartTime: util.StringToTime(payload.StartTime),
+	}
+
+	suite.waitlist.On("ClaimOffer", payload.CustomerId, "entry_1").Return(offer, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", payload.CustomerId).Return(user, nil)
+	suite.cS.On("FindCourtById", payload.CourtId).Return(court, nil)
+	suite.repoMock.On("FindTotal", payload.CustomerId).Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.Anything).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(booking model.Booking) bool {
+		return booking.Court.Id == payload.CourtId && booking.StartTime.Equal(util.StringToTime(payload.StartTime))
+	})).Return(model.Booking{
+		Id:             "booking_9",
+		PaymentDetails: []model.Payment{{PaymentURL: "http://test-payment-url.com"}},
+	}, nil)
+	suite.waitlist.On("FulfilOffer", "entry_1", "booking_9").Return(nil)
+
+	result, err := suite.bS.AcceptWaitlistOffer(payload.CustomerId, "entry_1")
+
+	suite.NoError(err)
+	suite.Equal("booking_9", result.Id)
+	suite.waitlist.AssertExpectations(suite.T())
+}
+
+// TestAcceptWaitlistOffer_FullPrepayment books the offered slot paid in full
+// for a customer who must prepay, instead of being turned down for asking a
+// down payment while the offer keeps holding the slot.
+func (suite *BookingServiceTestSuite) TestAcceptWaitlistOffer_FullPrepayment() {
+	suite.withRestrictions([]model.Restriction{{Type: model.RestrictionFullPrepayment, Reason: "2 no-shows", EndsAt: time.Now().AddDate(0, 0, 30)}})
+	offer := model.WaitlistEntry{
+		Id:               "entry_1",
+		CustomerId:       payload.CustomerId,
+		BookingDate:      util.StringToDate(payload.BookingDate),
+		Hour:             payload.Hour,
+		Status:           model.WaitlistOffered,
+		OfferedCourtId:   payload.CourtId,
+		OfferedStartTime: util.StringToTime(payload.StartTime),
+	}
+
+	suite.waitlist.On("ClaimOffer", payload.CustomerId, "entry_1").Return(offer, nil)
+	suite.repoMock.On("FindByDate", mock.Anything).Return([]model.Booking{}, nil)
+	suite.uS.On("FindUserById", payload.CustomerId).Return(user, nil)
+	suite.cS.On("FindCourtById", payload.CourtId).Return(court, nil)
+	suite.repoMock.On("FindTotal", payload.CustomerId).Return(1, nil)
+	suite.pS.On("GetPaymentURL", mock.Anything).Return("http://test-payment-url.com", nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(booking model.Booking) bool {
+		return booking.Total_Payment > 0 && booking.PaymentDetails[0].Price == booking.Total_Payment
+	})).Return(model.Booking{
+		Id:             "booking_9",
+		PaymentDetails: []model.Payment{{PaymentURL: "http://test-payment-url.com"}},
+	}, nil)
+	suite.waitlist.On("FulfilOffer", "entry_1", "booking_9").Return(nil)
+
+	result, err := suite.bS.AcceptWaitlistOffer(payload.CustomerId, "entry_1")
+
+	suite.NoError(err)
+	suite.Equal("booking_9", result.Id)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *BookingServiceTestSuite) TestAcceptWaitlistOffer_Expired() {
+	suite.waitlist.On("ClaimOffer", payload.CustomerId, "entry_1").Return(model.WaitlistEntry{}, errors.New("cannot accept, the offer has expired"))
+
+	_, err := suite.bS.AcceptWaitlistOffer(payload.CustomerId, "entry_1")
+
+	suite.EqualError(err, "cannot accept, the offer has expired")
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+	suite.waitlist.AssertNotCalled(suite.T(), "FulfilOffer", mock.Anything, mock.Anything)
+}
+
+func (suite *BookingServiceTestSuite) TestCancel_OffersSlotToWaitlist() {
+	booked := booking
+	booked.BookingDate = time.Now().AddDate(0, 0, 3)
+
+	suite.repoMock.On("FindById", "1").Return(booked, nil)
+	suite.repoMock.On("FindPaymentsByBookingId", "1").Return([]model.Payment{}, nil)
+	suite.repoMock.On("Cancel", "1", []model.Payment(nil), "customer_id").Return(nil)
+
+	_, err := suite.bS.Cancel(dto.CancelBookingRequest{BookingId: "1", UserId: "customer_id", Role: "customer"})
+
+	suite.NoError(err)
+	suite.waitlist.AssertCalled(suite.T(), "OfferSlot", mock.MatchedBy(func(released model.Booking) bool {
+		return released.Id == "1" && released.Court.Id == booked.Court.Id
+	}))
+}
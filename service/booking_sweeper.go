@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"log"
+	"team2/shuttleslot/config"
+	"time"
+)
+
+// BookingSweeper periodically expires pending bookings whose down payment was
+// not completed within the hold time, so a missed Midtrans expire notification
+// does not keep the court and the customer blocked.
+type BookingSweeper struct {
+	bookingService BookingService
+	holdTime       time.Duration
+	interval       time.Duration
+	now            func() time.Time
+}
+
+func (s *BookingSweeper) Sweep() (int, error) {
+	return s.bookingService.ExpirePending(s.now().Add(-s.holdTime))
+}
+
+func (s *BookingSweeper) Start(ctx context.Context) {
+	runEvery(ctx, s.interval, func() {
+		expired, err := s.Sweep()
+		if err != nil {
+			log.Println("failed to expire pending bookings:", err)
+		}
+		if expired > 0 {
+			log.Printf("expired %d pending bookings\n", expired)
+		}
+	})
+}
+
+// runEvery calls run every interval until ctx is done. A non-positive
+// interval turns the job off.
+func runEvery(ctx context.Context, interval time.Duration, run func()) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
+
+func NewBookingSweeper(bookingService BookingService, expiryConfig config.ExpiryConfig, now func() time.Time) *BookingSweeper {
+	return &BookingSweeper{
+		bookingService: bookingService,
+		holdTime:       expiryConfig.HoldTime,
+		interval:       expiryConfig.SweepInterval,
+		now:            now,
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"team2/shuttleslot/config"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type BookingSweeperTestSuite struct {
+	suite.Suite
+	bS  *servicemock.BookingServiceMock
+	now time.Time
+}
+
+func (suite *BookingSweeperTestSuite) SetupTest() {
+	suite.bS = new(servicemock.BookingServiceMock)
+	suite.now = time.Date(2030, 10, 1, 10, 0, 0, 0, time.UTC)
+}
+
+func (suite *BookingSweeperTestSuite) clock() time.Time {
+	return suite.now
+}
+
+func TestBookingSweeperTestSuite(t *testing.T) {
+	suite.Run(t, new(BookingSweeperTestSuite))
+}
+
+func (suite *BookingSweeperTestSuite) TestSweep_UsesHoldTime() {
+	sweeper := NewBookingSweeper(suite.bS, config.ExpiryConfig{HoldTime: 30 * time.Minute}, suite.clock)
+
+	suite.bS.On("ExpirePending", time.Date(2030, 10, 1, 9, 30, 0, 0, time.UTC)).Return(2, nil).Once()
+	expired, err := sweeper.Sweep()
+	suite.NoError(err)
+	suite.Equal(2, expired)
+
+	suite.now = suite.now.Add(time.Hour)
+	suite.bS.On("ExpirePending", time.Date(2030, 10, 1, 10, 30, 0, 0, time.UTC)).Return(0, nil).Once()
+	_, err = sweeper.Sweep()
+	suite.NoError(err)
+
+	suite.bS.AssertExpectations(suite.T())
+}
+
+func (suite *BookingSweeperTestSuite) TestSweep_Failed() {
+	sweeper := NewBookingSweeper(suite.bS, config.ExpiryConfig{HoldTime: 30 * time.Minute}, suite.clock)
+
+	suite.bS.On("ExpirePending", mock.Anything).Return(0, errors.New("error"))
+
+	_, err := sweeper.Sweep()
+	suite.Error(err)
+}
+
+func (suite *BookingSweeperTestSuite) TestStart_RunsUntilCancelled() {
+	sweeper := NewBookingSweeper(suite.bS, config.ExpiryConfig{HoldTime: 30 * time.Minute, SweepInterval: time.Millisecond}, suite.clock)
+
+	swept := make(chan struct{}, 1)
+	suite.bS.On("ExpirePending", mock.Anything).Return(0, nil).Run(func(args mock.Arguments) {
+		select {
+		case swept <- struct{}{}:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sweeper.Start(ctx)
+
+	select {
+	case <-swept:
+	case <-time.After(time.Second):
+		suite.Fail("sweeper did not run")
+	}
+	cancel()
+}
@@ -1,30 +1,45 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
 	"team2/shuttleslot/repository"
+	"team2/shuttleslot/util"
+	"time"
 )
 
 type CourtService interface {
-	CreateCourt(payload model.Court) (model.Court, error)
+	CreateCourt(payload model.Court, actor string) (model.Court, error)
 	FindAllCourts(page int, size int) ([]model.Court, dto.Paginate, error)
 	FindCourtById(id string) (model.Court, error)
-	UpdateCourt(id string, payload model.Court) (model.Court, error)
-	DeleteCourt(id string) error
+	UpdateCourt(id string, payload model.Court, actor string) (model.Court, error)
+	DeleteCourt(id string, actor string) error
+	CreateClosure(payload dto.CreateCourtClosureRequest) (model.CourtClosure, []model.Booking, error)
+	FindClosures(courtId string) ([]model.CourtClosure, error)
+	FindClosuresBetween(startDate, endDate time.Time) ([]model.CourtClosure, error)
+	DeleteClosure(courtId string, closureId string) error
 }
 
 type courtService struct {
 	courtRepository repository.CourtRepository
+	audit           AuditService
 }
 
-func (s *courtService) CreateCourt(payload model.Court) (model.Court, error) {
+func (s *courtService) CreateCourt(payload model.Court, actor string) (model.Court, error) {
+	if err := validateCourtSchedule(payload); err != nil {
+		return model.Court{}, err
+	}
+
 	court, err := s.courtRepository.Create(payload)
 	if err != nil {
 		return model.Court{}, err
 	}
 
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "court.create", EntityType: "court", EntityId: court.Id}, nil, court)
+
 	return court, nil
 }
 
@@ -41,7 +56,7 @@ func (s *courtService) FindCourtById(id string) (model.Court, error) {
 	return court, nil
 }
 
-func (s *courtService) UpdateCourt(id string, payload model.Court) (model.Court, error) {
+func (s *courtService) UpdateCourt(id string, payload model.Court, actor string) (model.Court, error) {
 
 	court, err := s.courtRepository.FindById(id)
 	if err != nil {
@@ -56,16 +71,42 @@ func (s *courtService) UpdateCourt(id string, payload model.Court) (model.Court,
 		payload.Price = court.Price
 	}
 
+	if payload.OpeningHours == nil {
+		payload.OpeningHours = court.OpeningHours
+	}
+
+	if payload.MinBookingHours < 1 {
+		payload.MinBookingHours = court.MinBookingHours
+	}
+
+	if payload.MaxBookingHours < 1 {
+		payload.MaxBookingHours = court.MaxBookingHours
+	}
+
+	if payload.SlotMinutes < 1 {
+		payload.SlotMinutes = court.SlotMinutes
+	}
+
+	if payload.DepositPercent < 1 {
+		payload.DepositPercent = court.DepositPercent
+	}
+
+	if err := validateCourtSchedule(payload); err != nil {
+		return model.Court{}, err
+	}
+
 	courtUpdate, err := s.courtRepository.Update(id, payload)
 	if err != nil {
 		return model.Court{}, err
 	}
 
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "court.update", EntityType: "court", EntityId: id}, court, courtUpdate)
+
 	return courtUpdate, nil
 }
 
-func (s *courtService) DeleteCourt(id string) error {
-	_, err := s.courtRepository.FindById(id)
+func (s *courtService) DeleteCourt(id string, actor string) error {
+	court, err := s.courtRepository.FindById(id)
 	if err != nil {
 		return errors.New("court not found")
 	}
@@ -75,9 +116,130 @@ func (s *courtService) DeleteCourt(id string) error {
 		return err
 	}
 
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "court.delete", EntityType: "court", EntityId: id}, court, nil)
+
+	return nil
+}
+
+// CreateClosure blocks the court for the given period and reports the pending
+// and booked bookings that already fall inside it, so admins can contact the
+// customers or cancel them.
+func (s *courtService) CreateClosure(payload dto.CreateCourtClosureRequest) (model.CourtClosure, []model.Booking, error) {
+	_, err := s.courtRepository.FindById(payload.CourtId)
+	if err != nil {
+		return model.CourtClosure{}, []model.Booking{}, errors.New("court not found")
+	}
+
+	closure := model.CourtClosure{
+		CourtId:   payload.CourtId,
+		StartDate: util.StringToDate(payload.StartDate),
+		EndDate:   util.StringToDate(payload.StartDate),
+		StartTime: util.StringToTime("00:00:00"),
+		EndTime:   util.StringToTime("23:59:59"),
+		Reason:    payload.Reason,
+	}
+
+	if payload.EndDate != "" {
+		closure.EndDate = util.StringToDate(payload.EndDate)
+	}
+
+	if payload.StartTime != "" {
+		closure.StartTime = util.StringToTime(payload.StartTime)
+	}
+
+	if payload.EndTime != "" {
+		closure.EndTime = util.StringToTime(payload.EndTime)
+	}
+
+	if closure.EndDate.Before(closure.StartDate) {
+		return model.CourtClosure{}, []model.Booking{}, errors.New("invalid closure, endDate is before startDate")
+	}
+
+	if !closure.StartTime.Before(closure.EndTime) {
+		return model.CourtClosure{}, []model.Booking{}, errors.New("invalid closure, startTime must be before endTime")
+	}
+
+	closure, err = s.courtRepository.CreateClosure(closure)
+	if err != nil {
+		return model.CourtClosure{}, []model.Booking{}, err
+	}
+
+	conflicts, err := s.courtRepository.FindConflictingBookings(closure)
+	if err != nil {
+		return model.CourtClosure{}, []model.Booking{}, err
+	}
+
+	return closure, conflicts, nil
+}
+
+func (s *courtService) FindClosures(courtId string) ([]model.CourtClosure, error) {
+	_, err := s.courtRepository.FindById(courtId)
+	if err != nil {
+		return []model.CourtClosure{}, errors.New("court not found")
+	}
+
+	return s.courtRepository.FindClosures(courtId)
+}
+
+func (s *courtService) FindClosuresBetween(startDate, endDate time.Time) ([]model.CourtClosure, error) {
+	return s.courtRepository.FindClosuresBetween(startDate, endDate)
+}
+
+func (s *courtService) DeleteClosure(courtId string, closureId string) error {
+	err := s.courtRepository.DeleteClosure(courtId, closureId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("court closure not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// validateCourtSchedule rejects opening hours and booking limits that would
+// make a court impossible to book.
+func validateCourtSchedule(payload model.Court) error {
+	days := make(map[int]bool)
+
+	for _, val := range payload.OpeningHours {
+		if val.Weekday < 0 || val.Weekday > 6 {
+			return errors.New("invalid opening hours, weekday must be between 0 (sunday) and 6 (saturday)")
+		}
+
+		if days[val.Weekday] {
+			return fmt.Errorf("invalid opening hours, weekday %d is set more than once", val.Weekday)
+		}
+		days[val.Weekday] = true
+
+		if !util.IsValidTime(val.OpenTime) || !util.IsValidTime(val.CloseTime) {
+			return errors.New("invalid opening hours, use 'hh:mm:ss' for openTime and closeTime")
+		}
+
+		if !util.StringToTime(val.OpenTime).Before(util.StringToTime(val.CloseTime)) {
+			return errors.New("invalid opening hours, openTime must be before closeTime")
+		}
+	}
+
+	if payload.MinBookingHours < 0 || payload.MaxBookingHours < 0 || payload.SlotMinutes < 0 {
+		return errors.New("invalid booking length or slot, values cannot be negative")
+	}
+
+	if payload.MaxBookingHours > 0 && payload.MinBookingHours > payload.MaxBookingHours {
+		return errors.New("invalid booking length, minBookingHours cannot exceed maxBookingHours")
+	}
+
+	if payload.SlotMinutes > 0 && 60%payload.SlotMinutes != 0 && payload.SlotMinutes%60 != 0 {
+		return errors.New("invalid slot, slotMinutes must divide an hour or be whole hours")
+	}
+
+	if payload.DepositPercent < 0 || payload.DepositPercent > 100 {
+		return errors.New("invalid down payment, depositPercent must be between 0 and 100")
+	}
+
 	return nil
 }
 
-func NewCourtService(courtRepository repository.CourtRepository) CourtService {
-	return &courtService{courtRepository: courtRepository}
+func NewCourtService(courtRepository repository.CourtRepository, auditService AuditService) CourtService {
+	return &courtService{courtRepository: courtRepository, audit: auditService}
 }
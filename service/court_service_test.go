@@ -1,8 +1,10 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
 	repomock "team2/shuttleslot/mock/repo_mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
 	"testing"
@@ -31,12 +33,15 @@ var payloadCourt = model.Court{
 type CourtServiceTestSuite struct {
 	suite.Suite
 	repoCourtMock *repomock.CourtRepositoryMock
+	auditMock     *servicemock.AuditServiceMock
 	cS            CourtService
 }
 
 func (suite *CourtServiceTestSuite) SetupTest() {
 	suite.repoCourtMock = new(repomock.CourtRepositoryMock)
-	suite.cS = NewCourtService(suite.repoCourtMock)
+	suite.auditMock = new(servicemock.AuditServiceMock)
+	suite.auditMock.On("Record", mock.Anything, mock.Anything, mock.Anything).Maybe()
+	suite.cS = NewCourtService(suite.repoCourtMock, suite.auditMock)
 }
 
 func TestCourtServiceTestSuite(t *testing.T) {
@@ -46,7 +51,7 @@ func TestCourtServiceTestSuite(t *testing.T) {
 func (suite *CourtServiceTestSuite) TestCreateCourt_Success() {
 	suite.repoCourtMock.On("Create", mockCourt).Return(mockCourt, nil)
 
-	court, err := suite.cS.CreateCourt(mockCourt)
+	court, err := suite.cS.CreateCourt(mockCourt, "admin_id")
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), mockCourt, court)
@@ -55,7 +60,7 @@ func (suite *CourtServiceTestSuite) TestCreateCourt_Success() {
 func (suite *CourtServiceTestSuite) TestCreateCourt_Fail() {
 	suite.repoCourtMock.On("Create", mockCourt).Return(model.Court{}, errors.New("error creating court"))
 
-	court, err := suite.cS.CreateCourt(mockCourt)
+	court, err := suite.cS.CreateCourt(mockCourt, "admin_id")
 
 	assert.Error(suite.T(), err)
 	assert.EqualError(suite.T(), err, "error creating court")
@@ -120,7 +125,7 @@ func (suite *CourtServiceTestSuite) TestUpdateCourt_Success() {
 	suite.repoCourtMock.On("FindById", "court_id").Return(mockCourt, nil)
 	suite.repoCourtMock.On("Update", "court_id", payloadCourt).Return(updatedCourt, nil)
 
-	_, err := suite.cS.UpdateCourt("court_id", payloadCourt)
+	_, err := suite.cS.UpdateCourt("court_id", payloadCourt, "admin_id")
 
 	assert.NoError(suite.T(), err)
 	suite.repoCourtMock.AssertExpectations(suite.T())
@@ -129,7 +134,7 @@ func (suite *CourtServiceTestSuite) TestUpdateCourt_Success() {
 func (suite *CourtServiceTestSuite) TestUpdateCourt_FailFindById() {
 	suite.repoCourtMock.On("FindById", "non_existing_id").Return(model.Court{}, errors.New("court not found"))
 
-	_, err := suite.cS.UpdateCourt("non_existing_id", model.Court{Name: "Updated Court", Price: 60})
+	_, err := suite.cS.UpdateCourt("non_existing_id", model.Court{Name: "Updated Court", Price: 60}, "admin_id")
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), "court not found", err.Error())
@@ -140,7 +145,7 @@ func (suite *CourtServiceTestSuite) TestUpdateCourt_FailUpdate() {
 	suite.repoCourtMock.On("FindById", "court_id").Return(mockCourt, nil)
 	suite.repoCourtMock.On("Update", "court_id", payloadCourt).Return(model.Court{}, errors.New("failed to update court"))
 
-	_, err := suite.cS.UpdateCourt("court_id", payloadCourt)
+	_, err := suite.cS.UpdateCourt("court_id", payloadCourt, "admin_id")
 
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), "failed to update court", err.Error())
@@ -168,7 +173,7 @@ func (suite *CourtServiceTestSuite) TestUpdateCourt_EmptyPayloadFields() {
 		return c.Name == "Old Court" && c.Price == 50
 	})).Return(updatedCourt, nil)
 
-	_, err := suite.cS.UpdateCourt("court_id", payload)
+	_, err := suite.cS.UpdateCourt("court_id", payload, "admin_id")
 
 	assert.NoError(suite.T(), err)
 	suite.repoCourtMock.AssertExpectations(suite.T())
@@ -178,15 +183,34 @@ func (suite *CourtServiceTestSuite) TestDeleteCourt_Success() {
 	suite.repoCourtMock.On("FindById", mockCourt.Id).Return(mockCourt, nil)
 	suite.repoCourtMock.On("Deleted", mockCourt.Id).Return(nil)
 
-	err := suite.cS.DeleteCourt(mockCourt.Id)
+	err := suite.cS.DeleteCourt(mockCourt.Id, "admin_id")
 
 	assert.NoError(suite.T(), err)
 }
 
+func (suite *CourtServiceTestSuite) TestCreateCourt_Audited() {
+	suite.repoCourtMock.On("Create", mockCourt).Return(mockCourt, nil)
+
+	_, err := suite.cS.CreateCourt(mockCourt, "admin_id")
+
+	assert.NoError(suite.T(), err)
+	suite.auditMock.AssertCalled(suite.T(), "Record", model.AuditLog{Actor: "admin_id", Action: "court.create", EntityType: "court", EntityId: mockCourt.Id}, nil, mockCourt)
+}
+
+func (suite *CourtServiceTestSuite) TestDeleteCourt_Audited() {
+	suite.repoCourtMock.On("FindById", mockCourt.Id).Return(mockCourt, nil)
+	suite.repoCourtMock.On("Deleted", mockCourt.Id).Return(nil)
+
+	err := suite.cS.DeleteCourt(mockCourt.Id, "admin_id")
+
+	assert.NoError(suite.T(), err)
+	suite.auditMock.AssertCalled(suite.T(), "Record", model.AuditLog{Actor: "admin_id", Action: "court.delete", EntityType: "court", EntityId: mockCourt.Id}, mockCourt, nil)
+}
+
 func (suite *CourtServiceTestSuite) TestDeleteCourt_Fail() {
 	suite.repoCourtMock.On("FindById", mockCourt.Id).Return(model.Court{}, errors.New("court not found"))
 
-	err := suite.cS.DeleteCourt(mockCourt.Id)
+	err := suite.cS.DeleteCourt(mockCourt.Id, "admin_id")
 
 	assert.Error(suite.T(), err)
 	assert.EqualError(suite.T(), err, "court not found")
@@ -196,9 +220,121 @@ func (suite *CourtServiceTestSuite) TestDeleteCourt_ErrorDeleting() {
 	suite.repoCourtMock.On("FindById", mockCourt.Id).Return(model.Court{}, nil)
 	suite.repoCourtMock.On("Deleted", mockCourt.Id).Return(errors.New("error deleting court"))
 
-	err := suite.cS.DeleteCourt(mockCourt.Id)
+	err := suite.cS.DeleteCourt(mockCourt.Id, "admin_id")
 
 	assert.Error(suite.T(), err)
 	assert.EqualError(suite.T(), err, "error deleting court")
 	suite.repoCourtMock.AssertExpectations(suite.T())
 }
+
+func (suite *CourtServiceTestSuite) TestCreateCourt_InvalidSchedule() {
+	invalid := []model.Court{
+		{Name: "Court", OpeningHours: []model.OpeningHour{{Weekday: 7, OpenTime: "08:00:00", CloseTime: "22:00:00"}}},
+		{Name: "Court", OpeningHours: []model.OpeningHour{{Weekday: 1, OpenTime: "22:00:00", CloseTime: "08:00:00"}}},
+		{Name: "Court", OpeningHours: []model.OpeningHour{{Weekday: 1, OpenTime: "8am", CloseTime: "22:00:00"}}},
+		{Name: "Court", OpeningHours: []model.OpeningHour{
+			{Weekday: 1, OpenTime: "08:00:00", CloseTime: "12:00:00"},
+			{Weekday: 1, OpenTime: "13:00:00", CloseTime: "22:00:00"},
+		}},
+		{Name: "Court", MinBookingHours: 3, MaxBookingHours: 2},
+		{Name: "Court", SlotMinutes: 45},
+		{Name: "Court", DepositPercent: 120},
+	}
+
+	for _, court := range invalid {
+		_, err := suite.cS.CreateCourt(court, "admin_id")
+		assert.Error(suite.T(), err)
+		assert.Contains(suite.T(), err.Error(), "invalid")
+	}
+	suite.repoCourtMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *CourtServiceTestSuite) TestUpdateCourt_KeepsSchedule() {
+	existing := model.Court{
+		Id:              "court_id",
+		Name:            "Old Court",
+		Price:           50,
+		OpeningHours:    []model.OpeningHour{{Weekday: 1, OpenTime: "08:00:00", CloseTime: "22:00:00"}},
+		MinBookingHours: 1,
+		MaxBookingHours: 3,
+		SlotMinutes:     30,
+	}
+
+	suite.repoCourtMock.On("FindById", "court_id").Return(existing, nil)
+	suite.repoCourtMock.On("Update", "court_id", mock.MatchedBy(func(c model.Court) bool {
+		return c.Price == 70 && len(c.OpeningHours) == 1 && c.MaxBookingHours == 3 && c.SlotMinutes == 30
+	})).Return(existing, nil)
+
+	_, err := suite.cS.UpdateCourt("court_id", model.Court{Price: 70}, "admin_id")
+
+	assert.NoError(suite.T(), err)
+	suite.repoCourtMock.AssertExpectations(suite.T())
+}
+
+var closureRequest = dto.CreateCourtClosureRequest{
+	CourtId:   "court_id",
+	StartDate: "02-01-2030",
+	EndDate:   "03-01-2030",
+	StartTime: "09:00:00",
+	EndTime:   "12:00:00",
+	Reason:    "resurfacing",
+}
+
+func (suite *CourtServiceTestSuite) TestCreateClosure_Success() {
+	conflicts := []model.Booking{{Id: "booking_1", Status: "booked"}}
+
+	suite.repoCourtMock.On("FindById", "court_id").Return(mockCourt, nil)
+	suite.repoCourtMock.On("CreateClosure", mock.MatchedBy(func(c model.CourtClosure) bool {
+		return c.CourtId == "court_id" && c.EndDate.Day() == 3 && c.StartTime.Hour() == 9 && c.EndTime.Hour() == 12
+	})).Return(model.CourtClosure{Id: "closure_1", CourtId: "court_id"}, nil)
+	suite.repoCourtMock.On("FindConflictingBookings", model.CourtClosure{Id: "closure_1", CourtId: "court_id"}).Return(conflicts, nil)
+
+	closure, actual, err := suite.cS.CreateClosure(closureRequest)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "closure_1", closure.Id)
+	assert.Equal(suite.T(), conflicts, actual)
+}
+
+func (suite *CourtServiceTestSuite) TestCreateClosure_WholeDay() {
+	request := dto.CreateCourtClosureRequest{CourtId: "court_id", StartDate: "02-01-2030", Reason: "tournament"}
+
+	suite.repoCourtMock.On("FindById", "court_id").Return(mockCourt, nil)
+	suite.repoCourtMock.On("CreateClosure", mock.MatchedBy(func(c model.CourtClosure) bool {
+		return c.StartDate.Equal(c.EndDate) && c.StartTime.Hour() == 0 && c.EndTime.Hour() == 23
+	})).Return(model.CourtClosure{Id: "closure_1"}, nil)
+	suite.repoCourtMock.On("FindConflictingBookings", mock.Anything).Return([]model.Booking{}, nil)
+
+	_, _, err := suite.cS.CreateClosure(request)
+
+	assert.NoError(suite.T(), err)
+	suite.repoCourtMock.AssertExpectations(suite.T())
+}
+
+func (suite *CourtServiceTestSuite) TestCreateClosure_InvalidRange() {
+	request := closureRequest
+	request.StartTime = "13:00:00"
+
+	suite.repoCourtMock.On("FindById", "court_id").Return(mockCourt, nil)
+
+	_, _, err := suite.cS.CreateClosure(request)
+
+	assert.EqualError(suite.T(), err, "invalid closure, startTime must be before endTime")
+	suite.repoCourtMock.AssertNotCalled(suite.T(), "CreateClosure", mock.Anything)
+}
+
+func (suite *CourtServiceTestSuite) TestCreateClosure_CourtNotFound() {
+	suite.repoCourtMock.On("FindById", "court_id").Return(model.Court{}, errors.New("sql: no rows in result set"))
+
+	_, _, err := suite.cS.CreateClosure(closureRequest)
+
+	assert.EqualError(suite.T(), err, "court not found")
+}
+
+func (suite *CourtServiceTestSuite) TestDeleteClosure_NotFound() {
+	suite.repoCourtMock.On("DeleteClosure", "court_id", "closure_1").Return(sql.ErrNoRows)
+
+	err := suite.cS.DeleteClosure("court_id", "closure_1")
+
+	assert.EqualError(suite.T(), err, "court closure not found")
+}
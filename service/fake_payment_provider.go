@@ -0,0 +1,215 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"time"
+)
+
+// FakeTransaction is a payment issued by the fake provider.
+type FakeTransaction struct {
+	OrderId   string    `json:"orderId"`
+	Amount    int       `json:"amount"`
+	Status    string    `json:"status"`
+	Refunded  int       `json:"refunded"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FakeProvider is an in-process payment gateway for local testing. It keeps
+// the issued transactions in memory and, when driven through Emit, sends the
+// same notifications Midtrans would send to the registered notifier.
+type FakeProvider struct {
+	mu           sync.Mutex
+	baseURL      string
+	serverKey    string
+	transactions map[string]FakeTransaction
+	refunds      map[string]int
+	notify       func(payload dto.PaymentNotificationInput, raw []byte) error
+}
+
+func (f *FakeProvider) CreateTransaction(payment model.Payment) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.transactions[payment.OrderId] = FakeTransaction{
+		OrderId:   payment.OrderId,
+		Amount:    payment.Price,
+		Status:    "pending",
+		CreatedAt: time.Now(),
+	}
+
+	return strings.TrimSuffix(f.baseURL, "/") + "/" + payment.OrderId, nil
+}
+
+// SetNotifier registers the handler that receives emitted notifications,
+// normally PaymentNotificationService.Receive.
+func (f *FakeProvider) SetNotifier(notify func(payload dto.PaymentNotificationInput, raw []byte) error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.notify = notify
+}
+
+func (f *FakeProvider) FindTransaction(orderId string) (FakeTransaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[orderId]
+	if !ok {
+		return FakeTransaction{}, ErrTransactionNotFound
+	}
+
+	return transaction, nil
+}
+
+// CheckTransaction returns the current state of a transaction the same way
+// the notification for it would look.
+func (f *FakeProvider) CheckTransaction(orderId string) (dto.PaymentNotificationInput, error) {
+	transaction, err := f.FindTransaction(orderId)
+	if err != nil {
+		return dto.PaymentNotificationInput{}, err
+	}
+
+	return f.notification(transaction), nil
+}
+
+// UpdateStatus moves a transaction to the given status without sending a
+// notification, as if the webhook never reached us.
+func (f *FakeProvider) UpdateStatus(orderId string, status string) (FakeTransaction, error) {
+	if status != "settlement" && status != "cancel" && status != "expire" && status != "pending" {
+		return FakeTransaction{}, errors.New("invalid status, use settlement, cancel, expire or pending")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[orderId]
+	if !ok {
+		return FakeTransaction{}, ErrTransactionNotFound
+	}
+
+	transaction.Status = status
+	f.transactions[orderId] = transaction
+
+	return transaction, nil
+}
+
+// Emit moves a transaction to the given status and sends the matching
+// notification, signed with the server key like a Midtrans one.
+func (f *FakeProvider) Emit(orderId string, status string) (dto.PaymentNotificationInput, error) {
+	transaction, err := f.UpdateStatus(orderId, status)
+	if err != nil {
+		return dto.PaymentNotificationInput{}, err
+	}
+
+	f.mu.Lock()
+	notify := f.notify
+	f.mu.Unlock()
+
+	payload := f.notification(transaction)
+
+	if notify == nil {
+		return payload, errors.New("no notifier registered for the fake payment provider")
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return payload, err
+	}
+
+	if err := notify(payload, raw); err != nil {
+		return payload, err
+	}
+
+	return payload, nil
+}
+
+// Refund returns part or all of a settled transaction. Like Midtrans, a refund
+// key that was already used is accepted again without refunding twice.
+func (f *FakeProvider) Refund(refund model.Payment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.refunds[refund.OrderId]; ok {
+		return nil
+	}
+
+	transaction, ok := f.transactions[refund.RefundOf]
+	if !ok {
+		return ErrTransactionNotFound
+	}
+
+	if transaction.Status != "settlement" && transaction.Status != "partial_refund" {
+		return fmt.Errorf("cannot refund transaction with status %s", transaction.Status)
+	}
+
+	if refund.Price < 1 || transaction.Refunded+refund.Price > transaction.Amount {
+		return fmt.Errorf("invalid refund amount, %d of the transaction is refundable", transaction.Amount-transaction.Refunded)
+	}
+
+	transaction.Refunded += refund.Price
+	transaction.Status = "partial_refund"
+	if transaction.Refunded == transaction.Amount {
+		transaction.Status = "refund"
+	}
+
+	f.transactions[refund.RefundOf] = transaction
+	f.refunds[refund.OrderId] = refund.Price
+
+	return nil
+}
+
+// Expire closes a pending transaction so it can no longer be paid.
+func (f *FakeProvider) Expire(orderId string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[orderId]
+	if !ok {
+		return ErrTransactionNotFound
+	}
+
+	if transaction.Status != "pending" {
+		return fmt.Errorf("cannot expire transaction with status %s", transaction.Status)
+	}
+
+	transaction.Status = "expire"
+	f.transactions[orderId] = transaction
+
+	return nil
+}
+
+func (f *FakeProvider) notification(transaction FakeTransaction) dto.PaymentNotificationInput {
+	statusCode := "200"
+	if transaction.Status == "pending" {
+		statusCode = "201"
+	} else if transaction.Status != "settlement" {
+		statusCode = "202"
+	}
+
+	grossAmount := fmt.Sprintf("%d.00", transaction.Amount)
+
+	return dto.PaymentNotificationInput{
+		TransactionStatus: transaction.Status,
+		OrderId:           transaction.OrderId,
+		PaymentType:       "fake",
+		FraudStatus:       "accept",
+		StatusCode:        statusCode,
+		GrossAmount:       grossAmount,
+		SignatureKey:      notificationSignature(transaction.OrderId, statusCode, grossAmount, f.serverKey),
+	}
+}
+
+func NewFakeProvider(baseURL string, serverKey string) *FakeProvider {
+	return &FakeProvider{
+		baseURL:      baseURL,
+		serverKey:    serverKey,
+		transactions: map[string]FakeTransaction{},
+		refunds:      map[string]int{},
+	}
+}
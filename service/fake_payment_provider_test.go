@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FakeProviderTestSuite struct {
+	suite.Suite
+	provider *FakeProvider
+	received []dto.PaymentNotificationInput
+}
+
+func (suite *FakeProviderTestSuite) SetupTest() {
+	suite.received = nil
+	suite.provider = NewFakeProvider("http://localhost:8080/api/v1/fake-gateway/", "server-key")
+	suite.provider.SetNotifier(func(payload dto.PaymentNotificationInput, raw []byte) error {
+		suite.received = append(suite.received, payload)
+		return nil
+	})
+}
+
+func TestFakeProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(FakeProviderTestSuite))
+}
+
+func (suite *FakeProviderTestSuite) TestCreateTransaction() {
+	url, err := suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+
+	suite.NoError(err)
+	suite.Equal("http://localhost:8080/api/v1/fake-gateway/Booking-1", url)
+
+	transaction, err := suite.provider.FindTransaction("Booking-1")
+	suite.NoError(err)
+	suite.Equal("pending", transaction.Status)
+	suite.Equal(60000, transaction.Amount)
+}
+
+func (suite *FakeProviderTestSuite) TestEmit_Settlement() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+
+	payload, err := suite.provider.Emit("Booking-1", "settlement")
+
+	suite.NoError(err)
+	suite.Equal([]dto.PaymentNotificationInput{payload}, suite.received)
+	suite.Equal("settlement", payload.TransactionStatus)
+	suite.Equal("60000.00", payload.GrossAmount)
+	suite.Equal(notificationSignature("Booking-1", "200", "60000.00", "server-key"), payload.SignatureKey)
+
+	transaction, _ := suite.provider.FindTransaction("Booking-1")
+	suite.Equal("settlement", transaction.Status)
+}
+
+func (suite *FakeProviderTestSuite) TestEmit_UnknownOrder() {
+	_, err := suite.provider.Emit("Booking-404", "expire")
+
+	suite.EqualError(err, "transaction not found")
+	suite.Empty(suite.received)
+}
+
+func (suite *FakeProviderTestSuite) TestEmit_InvalidStatus() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+
+	_, err := suite.provider.Emit("Booking-1", "refund")
+
+	suite.ErrorContains(err, "invalid status")
+	suite.Empty(suite.received)
+}
+
+func (suite *FakeProviderTestSuite) TestEmit_NotifierError() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	suite.provider.SetNotifier(func(payload dto.PaymentNotificationInput, raw []byte) error {
+		return errors.New("error")
+	})
+
+	_, err := suite.provider.Emit("Booking-1", "cancel")
+
+	suite.Error(err)
+}
+
+func (suite *FakeProviderTestSuite) TestRefund_PartialThenFull() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	suite.provider.UpdateStatus("Booking-1", "settlement")
+
+	err := suite.provider.Refund(model.Payment{OrderId: "Refund-1", RefundOf: "Booking-1", Price: 20000})
+	suite.NoError(err)
+
+	transaction, _ := suite.provider.FindTransaction("Booking-1")
+	suite.Equal("partial_refund", transaction.Status)
+	suite.Equal(20000, transaction.Refunded)
+
+	err = suite.provider.Refund(model.Payment{OrderId: "Refund-2", RefundOf: "Booking-1", Price: 40000})
+	suite.NoError(err)
+
+	transaction, _ = suite.provider.FindTransaction("Booking-1")
+	suite.Equal("refund", transaction.Status)
+	suite.Equal(60000, transaction.Refunded)
+}
+
+func (suite *FakeProviderTestSuite) TestRefund_RetryIsIdempotent() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	suite.provider.UpdateStatus("Booking-1", "settlement")
+
+	refund := model.Payment{OrderId: "Refund-1", RefundOf: "Booking-1", Price: 20000}
+	suite.NoError(suite.provider.Refund(refund))
+	suite.NoError(suite.provider.Refund(refund))
+
+	transaction, _ := suite.provider.FindTransaction("Booking-1")
+	suite.Equal(20000, transaction.Refunded)
+}
+
+func (suite *FakeProviderTestSuite) TestRefund_MoreThanPaid() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	suite.provider.UpdateStatus("Booking-1", "settlement")
+
+	err := suite.provider.Refund(model.Payment{OrderId: "Refund-1", RefundOf: "Booking-1", Price: 70000})
+
+	suite.EqualError(err, "invalid refund amount, 60000 of the transaction is refundable")
+}
+
+func (suite *FakeProviderTestSuite) TestRefund_NotSettled() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+
+	err := suite.provider.Refund(model.Payment{OrderId: "Refund-1", RefundOf: "Booking-1", Price: 10000})
+
+	suite.EqualError(err, "cannot refund transaction with status pending")
+}
+
+func (suite *FakeProviderTestSuite) TestRefund_UnknownOrder() {
+	err := suite.provider.Refund(model.Payment{OrderId: "Refund-1", RefundOf: "Booking-404", Price: 10000})
+
+	suite.ErrorIs(err, ErrTransactionNotFound)
+}
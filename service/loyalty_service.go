@@ -0,0 +1,149 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"time"
+)
+
+type LoyaltyService interface {
+	FindSetting() (model.LoyaltySetting, error)
+	UpdateSetting(payload dto.UpdateLoyaltySettingRequest) (model.LoyaltySetting, error)
+	FindPoints(customerId string, page int, size int) (model.PointBalance, dto.Paginate, error)
+	AdjustPoints(payload dto.AdjustPointsRequest) (model.PointEntry, error)
+	QuoteRedemption(customerId string, total int) (model.PointEntry, error)
+	FindRedemption(bookingId string) (model.PointEntry, error)
+}
+
+type loyaltyService struct {
+	loyaltyRepository repository.LoyaltyRepository
+}
+
+func (s *loyaltyService) FindSetting() (model.LoyaltySetting, error) {
+	return s.loyaltyRepository.FindSetting()
+}
+
+func (s *loyaltyService) UpdateSetting(payload dto.UpdateLoyaltySettingRequest) (model.LoyaltySetting, error) {
+	if payload.EarnPoints < 0 || payload.EarnAmount < 0 || payload.ExpiryDays < 0 {
+		return model.LoyaltySetting{}, errors.New("invalid loyalty setting, earnPoints, earnAmount and expiryDays cannot be negative")
+	}
+
+	if payload.RedeemPoints < 1 || payload.RedeemValue < 1 {
+		return model.LoyaltySetting{}, errors.New("invalid loyalty setting, redeemPoints and redeemValue must be greater than 0")
+	}
+
+	return s.loyaltyRepository.SaveSetting(model.LoyaltySetting{
+		EarnPoints:   payload.EarnPoints,
+		EarnAmount:   payload.EarnAmount,
+		RedeemPoints: payload.RedeemPoints,
+		RedeemValue:  payload.RedeemValue,
+		ExpiryDays:   payload.ExpiryDays,
+	})
+}
+
+// FindPoints expires the customer's overdue points first, so the balance and
+// history always show the expire entry.
+func (s *loyaltyService) FindPoints(customerId string, page int, size int) (model.PointBalance, dto.Paginate, error) {
+	_, err := s.loyaltyRepository.ExpirePoints(customerId, time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PointBalance{}, dto.Paginate{}, errors.New("customer not found")
+		}
+		return model.PointBalance{}, dto.Paginate{}, err
+	}
+
+	balance, err := s.loyaltyRepository.FindBalance(customerId)
+	if err != nil {
+		return model.PointBalance{}, dto.Paginate{}, err
+	}
+
+	entries, paginate, err := s.loyaltyRepository.FindEntries(customerId, page, size)
+	if err != nil {
+		return model.PointBalance{}, dto.Paginate{}, err
+	}
+
+	return model.PointBalance{CustomerId: customerId, Balance: balance, Entries: entries}, paginate, nil
+}
+
+func (s *loyaltyService) AdjustPoints(payload dto.AdjustPointsRequest) (model.PointEntry, error) {
+	if payload.CustomerId == "" || payload.Points == 0 {
+		return model.PointEntry{}, errors.New("invalid point adjustment, customerId and a non zero points are required")
+	}
+
+	if payload.Description == "" {
+		return model.PointEntry{}, errors.New("invalid point adjustment, description is required")
+	}
+
+	entry, err := s.loyaltyRepository.CreateEntry(model.PointEntry{
+		CustomerId:  payload.CustomerId,
+		Type:        "adjust",
+		Points:      payload.Points,
+		Description: payload.Description,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PointEntry{}, errors.New("customer not found")
+		}
+		if errors.Is(err, repository.ErrInsufficientPoints) {
+			return model.PointEntry{}, errors.New("invalid point adjustment, balance cannot go below 0")
+		}
+		return model.PointEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// QuoteRedemption returns the redeem entry to record with a booking of the
+// given total, using as many whole blocks of points as the balance allows.
+// The balance is checked again when the booking is saved.
+func (s *loyaltyService) QuoteRedemption(customerId string, total int) (model.PointEntry, error) {
+	setting, err := s.loyaltyRepository.FindSetting()
+	if err != nil {
+		return model.PointEntry{}, err
+	}
+
+	_, err = s.loyaltyRepository.ExpirePoints(customerId, time.Now())
+	if err != nil {
+		return model.PointEntry{}, err
+	}
+
+	balance, err := s.loyaltyRepository.FindBalance(customerId)
+	if err != nil {
+		return model.PointEntry{}, err
+	}
+
+	points, value := setting.Redemption(balance, total)
+	if points == 0 {
+		return model.PointEntry{}, fmt.Errorf("cannot book, not enough loyalty points, %d points needed and %d available", setting.RedeemPoints, balance)
+	}
+
+	return model.PointEntry{
+		CustomerId:  customerId,
+		Type:        "redeem",
+		Points:      -points,
+		Amount:      value,
+		Description: fmt.Sprintf("Redeemed %d points for Rp %d off", points, value),
+	}, nil
+}
+
+// FindRedemption returns the points redeemed on a booking, or an empty entry
+// when the booking was paid without points.
+func (s *loyaltyService) FindRedemption(bookingId string) (model.PointEntry, error) {
+	entry, err := s.loyaltyRepository.FindRedemptionByBookingId(bookingId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PointEntry{}, nil
+		}
+		return model.PointEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func NewLoyaltyService(loyaltyRepository repository.LoyaltyRepository) LoyaltyService {
+	return &loyaltyService{loyaltyRepository: loyaltyRepository}
+}
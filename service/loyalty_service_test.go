@@ -0,0 +1,139 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	repomock "team2/shuttleslot/mock/repo_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoyaltyServiceTestSuite struct {
+	suite.Suite
+	repoMock *repomock.LoyaltyRepositoryMock
+	lS       LoyaltyService
+}
+
+func (suite *LoyaltyServiceTestSuite) SetupTest() {
+	suite.repoMock = new(repomock.LoyaltyRepositoryMock)
+	suite.lS = NewLoyaltyService(suite.repoMock)
+}
+
+func TestLoyaltyServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(LoyaltyServiceTestSuite))
+}
+
+func (suite *LoyaltyServiceTestSuite) TestUpdateSetting_Success() {
+	request := dto.UpdateLoyaltySettingRequest{EarnPoints: 1, EarnAmount: 10000, RedeemPoints: 50, RedeemValue: 5000, ExpiryDays: 365}
+	setting := model.LoyaltySetting{EarnPoints: 1, EarnAmount: 10000, RedeemPoints: 50, RedeemValue: 5000, ExpiryDays: 365}
+	suite.repoMock.On("SaveSetting", setting).Return(setting, nil)
+
+	result, err := suite.lS.UpdateSetting(request)
+
+	suite.NoError(err)
+	suite.Equal(setting, result)
+}
+
+func (suite *LoyaltyServiceTestSuite) TestUpdateSetting_Invalid() {
+	_, err := suite.lS.UpdateSetting(dto.UpdateLoyaltySettingRequest{EarnPoints: 10, RedeemPoints: 0, RedeemValue: 10000})
+
+	suite.ErrorContains(err, "invalid loyalty setting")
+	suite.repoMock.AssertNotCalled(suite.T(), "SaveSetting", mock.Anything)
+}
+
+func (suite *LoyaltyServiceTestSuite) TestFindPoints_Success() {
+	entries := []model.PointEntry{{Id: "1", Type: "earn", Points: 10}}
+	paginate := dto.Paginate{Page: 1, Size: 10, TotalRows: 1, TotalPages: 1}
+	suite.repoMock.On("ExpirePoints", "customer_id", mock.Anything).Return(0, nil)
+	suite.repoMock.On("FindBalance", "customer_id").Return(10, nil)
+	suite.repoMock.On("FindEntries", "customer_id", 1, 10).Return(entries, paginate, nil)
+
+	balance, result, err := suite.lS.FindPoints("customer_id", 1, 10)
+
+	suite.NoError(err)
+	suite.Equal(10, balance.Balance)
+	suite.Equal(entries, balance.Entries)
+	suite.Equal(paginate, result)
+}
+
+func (suite *LoyaltyServiceTestSuite) TestFindPoints_CustomerNotFound() {
+	suite.repoMock.On("ExpirePoints", "unknown", mock.Anything).Return(0, sql.ErrNoRows)
+
+	_, _, err := suite.lS.FindPoints("unknown", 1, 10)
+
+	suite.EqualError(err, "customer not found")
+}
+
+func (suite *LoyaltyServiceTestSuite) TestAdjustPoints_Success() {
+	request := dto.AdjustPointsRequest{CustomerId: "customer_id", Points: 50, Description: "Tournament prize"}
+	entry := model.PointEntry{Id: "1", CustomerId: "customer_id", Type: "adjust", Points: 50, Description: "Tournament prize"}
+	suite.repoMock.On("CreateEntry", mock.MatchedBy(func(e model.PointEntry) bool {
+		return e.Type == "adjust" && e.Points == 50
+	})).Return(entry, nil)
+
+	result, err := suite.lS.AdjustPoints(request)
+
+	suite.NoError(err)
+	suite.Equal(entry, result)
+}
+
+func (suite *LoyaltyServiceTestSuite) TestAdjustPoints_BelowZero() {
+	request := dto.AdjustPointsRequest{CustomerId: "customer_id", Points: -500, Description: "Correction"}
+	suite.repoMock.On("CreateEntry", mock.Anything).Return(model.PointEntry{}, repository.ErrInsufficientPoints)
+
+	_, err := suite.lS.AdjustPoints(request)
+
+	suite.EqualError(err, "invalid point adjustment, balance cannot go below 0")
+}
+
+func (suite *LoyaltyServiceTestSuite) TestAdjustPoints_MissingDescription() {
+	_, err := suite.lS.AdjustPoints(dto.AdjustPointsRequest{CustomerId: "customer_id", Points: 10})
+
+	suite.ErrorContains(err, "invalid point adjustment")
+	suite.repoMock.AssertNotCalled(suite.T(), "CreateEntry", mock.Anything)
+}
+
+func (suite *LoyaltyServiceTestSuite) TestQuoteRedemption_Success() {
+	suite.repoMock.On("FindSetting").Return(model.DefaultLoyaltySetting, nil)
+	suite.repoMock.On("ExpirePoints", "customer_id", mock.Anything).Return(0, nil)
+	suite.repoMock.On("FindBalance", "customer_id").Return(250, nil)
+
+	entry, err := suite.lS.QuoteRedemption("customer_id", 120000)
+
+	suite.NoError(err)
+	suite.Equal(-200, entry.Points)
+	suite.Equal(20000, entry.Amount)
+	suite.Equal("redeem", entry.Type)
+}
+
+func (suite *LoyaltyServiceTestSuite) TestQuoteRedemption_NotEnoughPoints() {
+	suite.repoMock.On("FindSetting").Return(model.DefaultLoyaltySetting, nil)
+	suite.repoMock.On("ExpirePoints", "customer_id", mock.Anything).Return(0, nil)
+	suite.repoMock.On("FindBalance", "customer_id").Return(40, nil)
+
+	_, err := suite.lS.QuoteRedemption("customer_id", 120000)
+
+	suite.EqualError(err, "cannot book, not enough loyalty points, 100 points needed and 40 available")
+}
+
+func (suite *LoyaltyServiceTestSuite) TestFindRedemption_None() {
+	suite.repoMock.On("FindRedemptionByBookingId", "1").Return(model.PointEntry{}, sql.ErrNoRows)
+
+	entry, err := suite.lS.FindRedemption("1")
+
+	suite.NoError(err)
+	suite.Equal(model.PointEntry{}, entry)
+}
+
+func (suite *LoyaltyServiceTestSuite) TestFindRedemption_Error() {
+	suite.repoMock.On("FindRedemptionByBookingId", "1").Return(model.PointEntry{}, errors.New("error"))
+
+	_, err := suite.lS.FindRedemption("1")
+
+	suite.Error(err)
+}
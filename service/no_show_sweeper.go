@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"log"
+	"team2/shuttleslot/config"
+	"time"
+)
+
+// NoShowSweeper periodically marks booked bookings as no-shows once their start
+// time is further in the past than the grace period and nobody checked in.
+type NoShowSweeper struct {
+	bookingService BookingService
+	gracePeriod    time.Duration
+	interval       time.Duration
+	now            func() time.Time
+}
+
+func (s *NoShowSweeper) Sweep() (int, error) {
+	return s.bookingService.MarkNoShows(s.now().Add(-s.gracePeriod))
+}
+
+func (s *NoShowSweeper) Start(ctx context.Context) {
+	runEvery(ctx, s.interval, func() {
+		marked, err := s.Sweep()
+		if err != nil {
+			log.Println("failed to mark no-show bookings:", err)
+		}
+		if marked > 0 {
+			log.Printf("marked %d bookings as no-show\n", marked)
+		}
+	})
+}
+
+func NewNoShowSweeper(bookingService BookingService, noShowConfig config.NoShowConfig, now func() time.Time) *NoShowSweeper {
+	return &NoShowSweeper{
+		bookingService: bookingService,
+		gracePeriod:    noShowConfig.GracePeriod,
+		interval:       noShowConfig.SweepInterval,
+		now:            now,
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"team2/shuttleslot/config"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type NoShowSweeperTestSuite struct {
+	suite.Suite
+	bS  *servicemock.BookingServiceMock
+	now time.Time
+}
+
+func (suite *NoShowSweeperTestSuite) SetupTest() {
+	suite.bS = new(servicemock.BookingServiceMock)
+	suite.now = time.Date(2030, 10, 1, 10, 0, 0, 0, time.UTC)
+}
+
+func (suite *NoShowSweeperTestSuite) clock() time.Time {
+	return suite.now
+}
+
+func TestNoShowSweeperTestSuite(t *testing.T) {
+	suite.Run(t, new(NoShowSweeperTestSuite))
+}
+
+func (suite *NoShowSweeperTestSuite) TestSweep_UsesGracePeriod() {
+	sweeper := NewNoShowSweeper(suite.bS, config.NoShowConfig{GracePeriod: 15 * time.Minute}, suite.clock)
+
+	suite.bS.On("MarkNoShows", time.Date(2030, 10, 1, 9, 45, 0, 0, time.UTC)).Return(1, nil).Once()
+	marked, err := sweeper.Sweep()
+	suite.NoError(err)
+	suite.Equal(1, marked)
+
+	suite.bS.AssertExpectations(suite.T())
+}
+
+func (suite *NoShowSweeperTestSuite) TestSweep_Failed() {
+	sweeper := NewNoShowSweeper(suite.bS, config.NoShowConfig{GracePeriod: 15 * time.Minute}, suite.clock)
+
+	suite.bS.On("MarkNoShows", mock.Anything).Return(0, errors.New("error"))
+
+	_, err := sweeper.Sweep()
+	suite.Error(err)
+}
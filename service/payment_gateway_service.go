@@ -1,69 +1,89 @@
 package service
 
 import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
 	"team2/shuttleslot/config"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
 	"team2/shuttleslot/repository"
+)
 
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/snap"
+var (
+	ErrInvalidSignature  = errors.New("forbidden, invalid notification signature")
+	ErrAmountMismatch    = errors.New("forbidden, notification amount does not match the payment")
+	ErrStaleNotification = errors.New("stale payment notification")
 )
 
 type paymentGateService struct {
 	config      config.PayGateConfig
+	provider    PaymentProvider
 	bookingRepo repository.BookingRepository
+	audit       AuditService
 }
 
-var s snap.Client
-
 type PaymentGateService interface {
 	GetPaymentURL(payment model.Payment) (string, error)
 	PaymentProcess(payload dto.PaymentNotificationInput) (model.Payment, error)
+	Refund(refund model.Payment) error
+	Expire(orderId string) error
 }
 
 func (p *paymentGateService) GetPaymentURL(payment model.Payment) (string, error) {
-	s.New(p.config.ServerKey, midtrans.Sandbox)
-
-	snapReq := &snap.Request{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  payment.OrderId,
-			GrossAmt: int64(payment.Price),
-		},
-		CustomerDetail: &midtrans.CustomerDetails{
-			FName: payment.User.Name,
-			Email: "test@mail.com",
-			Phone: payment.User.PhoneNumber,
-		},
-		Items: &[]midtrans.ItemDetails{
-			{
-				Name:  payment.Court.Name,
-				Price: int64(payment.Court.Price),
-				Qty:   int32(payment.Qty),
-			},
-		},
-	}
+	return p.provider.CreateTransaction(payment)
+}
 
-	resp, err := s.CreateTransaction(snapReq)
+// notificationSignature is the signature_key Midtrans sends with every
+// notification: SHA-512 of order_id, status_code, gross_amount and the server
+// key, hex encoded.
+func notificationSignature(orderId, statusCode, grossAmount, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderId + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func amountMatches(grossAmount string, price int) bool {
+	amount, err := strconv.ParseFloat(grossAmount, 64)
 	if err != nil {
-		return "", err
+		return false
 	}
 
-	return resp.RedirectURL, nil
+	return int64(math.Round(amount)) == int64(price)
+}
+
+// reject records a refused notification in the audit log and returns reason.
+func (p *paymentGateService) reject(payload dto.PaymentNotificationInput, reason error) error {
+	p.audit.Record(model.AuditLog{Actor: "payment-gateway", Action: "payment.notification_rejected", EntityType: "payment", EntityId: payload.OrderId, Detail: reason.Error()}, nil, payload)
+
+	return reason
 }
 
 func (p *paymentGateService) PaymentProcess(payload dto.PaymentNotificationInput) (model.Payment, error) {
-	newPayload, err := p.bookingRepo.FindPaymentByOrderId(payload.OrderId)
+	signature := notificationSignature(payload.OrderId, payload.StatusCode, payload.GrossAmount, p.config.ServerKey)
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(payload.SignatureKey)) != 1 {
+		return model.Payment{}, p.reject(payload, ErrInvalidSignature)
+	}
+
+	payment, err := p.bookingRepo.FindPaymentByOrderId(payload.OrderId)
 	if err != nil {
 		return model.Payment{}, err
 	}
 
-	booking, err := p.bookingRepo.FindById(newPayload.BookingId)
+	// A series order is charged once for every occurrence it pays for.
+	total, err := p.bookingRepo.FindOrderTotal(payload.OrderId)
 	if err != nil {
 		return model.Payment{}, err
 	}
 
-	payment, err := p.bookingRepo.FindPaymentByOrderId(payload.OrderId)
+	if !amountMatches(payload.GrossAmount, total) {
+		return model.Payment{}, p.reject(payload, ErrAmountMismatch)
+	}
+
+	booking, err := p.bookingRepo.FindById(payment.BookingId)
 	if err != nil {
 		return model.Payment{}, err
 	}
@@ -72,6 +92,8 @@ func (p *paymentGateService) PaymentProcess(payload dto.PaymentNotificationInput
 		payment.User.Id = booking.Employee.Id
 	}
 
+	current := payment
+
 	if payload.PaymentType == "credit-card" && payload.TransactionStatus == "capture" && payload.FraudStatus == "accept" {
 		payment.Status = "paid"
 
@@ -85,14 +107,43 @@ func (p *paymentGateService) PaymentProcess(payload dto.PaymentNotificationInput
 		payment.Status = "pending"
 	}
 
+	// Notifications can be retried and arrive out of order, so one that would
+	// move a settled payment, e.g. a late pending after paid, is dropped.
+	if !current.CanTransitionTo(payment.Status) {
+		return model.Payment{}, fmt.Errorf("%w, payment %s is already %s", ErrStaleNotification, payload.OrderId, current.Status)
+	}
+
 	payment.PaymentMethod = payload.PaymentType
 
 	return payment, nil
 }
 
-func NewPayGateService(payGateConfig config.PayGateConfig, bookingRepository repository.BookingRepository) PaymentGateService {
+func (p *paymentGateService) Refund(refund model.Payment) error {
+	return p.provider.Refund(refund)
+}
+
+// Expire closes the gateway transaction of an unpaid order so a late payment
+// cannot settle it. An order the customer never opened a transaction for, or
+// one the gateway has already closed, has nothing left to close.
+func (p *paymentGateService) Expire(orderId string) error {
+	err := p.provider.Expire(orderId)
+	if err == nil || errors.Is(err, ErrTransactionNotFound) {
+		return nil
+	}
+
+	status, checkErr := p.provider.CheckTransaction(orderId)
+	if checkErr == nil && (status.TransactionStatus == "expire" || status.TransactionStatus == "cancel" || status.TransactionStatus == "deny") {
+		return nil
+	}
+
+	return err
+}
+
+func NewPayGateService(payGateConfig config.PayGateConfig, provider PaymentProvider, bookingRepository repository.BookingRepository, auditService AuditService) PaymentGateService {
 	return &paymentGateService{
 		config:      payGateConfig,
+		provider:    provider,
 		bookingRepo: bookingRepository,
+		audit:       auditService,
 	}
 }
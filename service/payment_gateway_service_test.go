@@ -3,13 +3,16 @@ package service
 import (
 	"errors"
 	"os"
+	"strings"
 	"team2/shuttleslot/config"
 	repomock "team2/shuttleslot/mock/repo_mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -23,6 +26,7 @@ type PaymentServiceTestSuite struct {
 	pS         PaymentGateService
 	config     *config.PayGateConfig
 	mockClient *repomock.SnapClient
+	audit      *servicemock.AuditServiceMock
 }
 
 func (suite *PaymentServiceTestSuite) SetupSuite() {
@@ -37,13 +41,24 @@ func (suite *PaymentServiceTestSuite) SetupTest() {
 
 	suite.mockClient = new(repomock.SnapClient)
 
-	suite.pS = NewPayGateService(*suite.config, suite.repoMock)
+	suite.audit = new(servicemock.AuditServiceMock)
+
+	suite.pS = NewPayGateService(*suite.config, NewMidtransProvider(*suite.config), suite.repoMock, suite.audit)
 }
 
 func TestPaymentServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(PaymentServiceTestSuite))
 }
 
+// sign fills in the fields Midtrans signs a notification with, for a payment
+// of the given gross amount.
+func (suite *PaymentServiceTestSuite) sign(payload dto.PaymentNotificationInput, grossAmount string) dto.PaymentNotificationInput {
+	payload.StatusCode = "200"
+	payload.GrossAmount = grossAmount
+	payload.SignatureKey = notificationSignature(payload.OrderId, payload.StatusCode, grossAmount, suite.config.ServerKey)
+	return payload
+}
+
 func (suite *PaymentServiceTestSuite) TestGetPaymentURL_Success() {
 	payment := model.Payment{
 		OrderId: "order123",
@@ -98,15 +113,17 @@ func (suite *PaymentServiceTestSuite) TestPaymentProcess_Succes() {
 		TransactionStatus: "capture",
 		FraudStatus:       "accept",
 	}
+	payload = suite.sign(payload, "0.00")
 
 	booking := model.Booking{
 		Employee: model.User{
 			Id: "employee123",
 		},
 	}
-	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{}, nil)
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{Status: "unpaid"}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(0, nil)
 	suite.repoMock.On("FindById", "").Return(booking, nil)
-	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{}, nil)
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{Status: "unpaid"}, nil)
 
 	payment, err := suite.pS.PaymentProcess(payload)
 
@@ -121,8 +138,10 @@ func (suite *PaymentServiceTestSuite) TestPaymentProcess_Cancel() {
 		OrderId:           "order123",
 		TransactionStatus: "cancel",
 	}
+	payload = suite.sign(payload, "0.00")
 
-	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{}, nil)
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{Status: "unpaid"}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(0, nil)
 	suite.repoMock.On("FindById", "").Return(model.Booking{}, nil)
 
 	payment, err := suite.pS.PaymentProcess(payload)
@@ -136,6 +155,7 @@ func (suite *PaymentServiceTestSuite) TestPaymentProcess_ErrorFindingPayment() {
 	payload := dto.PaymentNotificationInput{
 		OrderId: "order123",
 	}
+	payload = suite.sign(payload, "0.00")
 
 	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{}, errors.New("error"))
 
@@ -154,8 +174,10 @@ func (suite *PaymentServiceTestSuite) TestPaymentProcess_ErrorFindingId() {
 		TransactionStatus: "capture",
 		FraudStatus:       "accept",
 	}
+	payload = suite.sign(payload, "0.00")
 
-	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{}, nil)
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{Status: "unpaid"}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(0, nil)
 	suite.repoMock.On("FindById", "").Return(model.Booking{}, errors.New("error"))
 
 	_, err := suite.pS.PaymentProcess(payload)
@@ -169,6 +191,7 @@ func (suite *PaymentServiceTestSuite) TestPaymentProcess_TransactionStatusSettle
 		TransactionStatus: "settlement",
 		FraudStatus:       "accept",
 	}
+	payload = suite.sign(payload, "0.00")
 
 	booking := model.Booking{
 		Employee: model.User{
@@ -176,7 +199,8 @@ func (suite *PaymentServiceTestSuite) TestPaymentProcess_TransactionStatusSettle
 		},
 	}
 	suite.repoMock.On("FindById", "").Return(booking, nil)
-	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{}, nil)
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{Status: "unpaid"}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(0, nil)
 
 	payment, err := suite.pS.PaymentProcess(payload)
 
@@ -193,6 +217,7 @@ func (suite *PaymentServiceTestSuite) TestPaymentProcess_TransactionStatuspendin
 		TransactionStatus: "pending",
 		FraudStatus:       "accept",
 	}
+	payload = suite.sign(payload, "0.00")
 
 	booking := model.Booking{
 		Employee: model.User{
@@ -200,7 +225,8 @@ func (suite *PaymentServiceTestSuite) TestPaymentProcess_TransactionStatuspendin
 		},
 	}
 	suite.repoMock.On("FindById", "").Return(booking, nil)
-	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{}, nil)
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{Status: "unpaid"}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(0, nil)
 
 	payment, err := suite.pS.PaymentProcess(payload)
 
@@ -209,3 +235,145 @@ func (suite *PaymentServiceTestSuite) TestPaymentProcess_TransactionStatuspendin
 	assert.Equal(suite.T(), "credit-card", payment.PaymentMethod)
 	assert.Equal(suite.T(), "employee123", payment.User.Id)
 }
+
+func (suite *PaymentServiceTestSuite) TestPaymentProcess_InvalidSignature() {
+	payload := suite.sign(dto.PaymentNotificationInput{
+		OrderId:           "order123",
+		TransactionStatus: "settlement",
+	}, "60000.00")
+	payload.SignatureKey = "forged"
+
+	suite.audit.On("Record", model.AuditLog{Actor: "payment-gateway", Action: "payment.notification_rejected", EntityType: "payment", EntityId: "order123", Detail: ErrInvalidSignature.Error()}, nil, payload).Return()
+
+	_, err := suite.pS.PaymentProcess(payload)
+
+	assert.ErrorIs(suite.T(), err, ErrInvalidSignature)
+	suite.audit.AssertExpectations(suite.T())
+	suite.repoMock.AssertNotCalled(suite.T(), "FindPaymentByOrderId", mock.Anything)
+}
+
+func (suite *PaymentServiceTestSuite) TestPaymentProcess_AmountMismatch() {
+	payload := suite.sign(dto.PaymentNotificationInput{
+		OrderId:           "order123",
+		TransactionStatus: "settlement",
+	}, "1000.00")
+
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{OrderId: "order123", Price: 60000}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(60000, nil)
+	suite.audit.On("Record", mock.MatchedBy(func(a model.AuditLog) bool {
+		return a.Action == "payment.notification_rejected" && strings.Contains(a.Detail, "amount does not match")
+	}), nil, payload).Return()
+
+	_, err := suite.pS.PaymentProcess(payload)
+
+	assert.ErrorIs(suite.T(), err, ErrAmountMismatch)
+	suite.audit.AssertExpectations(suite.T())
+	suite.repoMock.AssertNotCalled(suite.T(), "FindById", mock.Anything)
+}
+
+func (suite *PaymentServiceTestSuite) TestPaymentProcess_AmountMatches() {
+	payload := suite.sign(dto.PaymentNotificationInput{
+		OrderId:           "order123",
+		TransactionStatus: "settlement",
+		PaymentType:       "gopay",
+	}, "60000.00")
+
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{OrderId: "order123", BookingId: "1", Price: 60000, Status: "unpaid"}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(60000, nil)
+	suite.repoMock.On("FindById", "1").Return(model.Booking{Id: "1"}, nil)
+
+	payment, err := suite.pS.PaymentProcess(payload)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "paid", payment.Status)
+}
+
+func (suite *PaymentServiceTestSuite) TestPaymentProcess_SeriesOrderTotal() {
+	payload := suite.sign(dto.PaymentNotificationInput{
+		OrderId:           "Series-1",
+		TransactionStatus: "settlement",
+		PaymentType:       "gopay",
+	}, "120000.00")
+
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{OrderId: "Series-1", BookingId: "1", Price: 30000, Status: "unpaid"}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(120000, nil)
+	suite.repoMock.On("FindById", "1").Return(model.Booking{Id: "1"}, nil)
+
+	payment, err := suite.pS.PaymentProcess(payload)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "paid", payment.Status)
+	suite.audit.AssertNotCalled(suite.T(), "Record", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *PaymentServiceTestSuite) TestPaymentProcess_LatePendingAfterPaid() {
+	payload := suite.sign(dto.PaymentNotificationInput{
+		OrderId:           "order123",
+		TransactionStatus: "pending",
+	}, "60000.00")
+
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{OrderId: "order123", BookingId: "1", Price: 60000, Status: "paid"}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(60000, nil)
+	suite.repoMock.On("FindById", "1").Return(model.Booking{Id: "1"}, nil)
+
+	_, err := suite.pS.PaymentProcess(payload)
+
+	assert.ErrorIs(suite.T(), err, ErrStaleNotification)
+}
+
+func (suite *PaymentServiceTestSuite) TestPaymentProcess_SettlementAfterCapture() {
+	payload := suite.sign(dto.PaymentNotificationInput{
+		OrderId:           "order123",
+		TransactionStatus: "settlement",
+	}, "60000.00")
+
+	suite.repoMock.On("FindPaymentByOrderId", payload.OrderId).Return(model.Payment{OrderId: "order123", BookingId: "1", Price: 60000, Status: "paid"}, nil)
+	suite.repoMock.On("FindOrderTotal", payload.OrderId).Return(60000, nil)
+	suite.repoMock.On("FindById", "1").Return(model.Booking{Id: "1"}, nil)
+
+	_, err := suite.pS.PaymentProcess(payload)
+
+	assert.ErrorIs(suite.T(), err, ErrStaleNotification)
+}
+
+func (suite *PaymentServiceTestSuite) TestRefund_DelegatesToProvider() {
+	provider := NewFakeProvider("http://localhost/api/v1/fake-gateway", suite.config.ServerKey)
+	provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	provider.UpdateStatus("Booking-1", "settlement")
+
+	pS := NewPayGateService(*suite.config, provider, suite.repoMock, suite.audit)
+
+	err := pS.Refund(model.Payment{OrderId: "Refund-1", RefundOf: "Booking-1", Price: 60000})
+
+	suite.NoError(err)
+	transaction, _ := provider.FindTransaction("Booking-1")
+	suite.Equal("refund", transaction.Status)
+}
+
+func (suite *PaymentServiceTestSuite) TestExpire_ClosesPendingTransaction() {
+	provider := NewFakeProvider("http://localhost/api/v1/fake-gateway", suite.config.ServerKey)
+	provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+
+	pS := NewPayGateService(*suite.config, provider, suite.repoMock, suite.audit)
+
+	suite.NoError(pS.Expire("Booking-1"))
+	transaction, _ := provider.FindTransaction("Booking-1")
+	suite.Equal("expire", transaction.Status)
+
+	suite.NoError(pS.Expire("Booking-1"))
+	suite.NoError(pS.Expire("Booking-2"))
+}
+
+func (suite *PaymentServiceTestSuite) TestExpire_AlreadyPaid() {
+	provider := NewFakeProvider("http://localhost/api/v1/fake-gateway", suite.config.ServerKey)
+	provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	provider.UpdateStatus("Booking-1", "settlement")
+
+	pS := NewPayGateService(*suite.config, provider, suite.repoMock, suite.audit)
+
+	err := pS.Expire("Booking-1")
+
+	suite.EqualError(err, "cannot expire transaction with status settlement")
+	transaction, _ := provider.FindTransaction("Booking-1")
+	suite.Equal("settlement", transaction.Status)
+}
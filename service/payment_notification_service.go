@@ -0,0 +1,119 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+)
+
+// PaymentNotificationService is the inbox in front of BookingService.UpdatePayment.
+// Every notification is stored before it is applied, redeliveries of one that
+// was already handled are acknowledged without running it again, and stored
+// notifications can be replayed by an admin.
+type PaymentNotificationService interface {
+	Receive(payload dto.PaymentNotificationInput, raw []byte) error
+	Replay(id string) (model.PaymentNotification, error)
+	FindNotifications(status string, page int, size int) ([]model.PaymentNotification, dto.Paginate, error)
+}
+
+type paymentNotificationService struct {
+	notificationRepository repository.PaymentNotificationRepository
+	bookingService         BookingService
+}
+
+func (s *paymentNotificationService) Receive(payload dto.PaymentNotificationInput, raw []byte) error {
+	notification, err := s.notificationRepository.Save(model.PaymentNotification{
+		OrderId:           payload.OrderId,
+		TransactionStatus: payload.TransactionStatus,
+		Payload:           string(raw),
+	})
+	if err != nil {
+		return err
+	}
+
+	claimed, err := s.notificationRepository.Claim(notification.Id)
+	if err != nil {
+		return err
+	}
+
+	if !claimed {
+		return nil
+	}
+
+	notification, err = s.process(notification, payload)
+	if err != nil {
+		return err
+	}
+
+	if notification.Status == "rejected" || notification.Status == "failed" {
+		return errors.New(notification.Error)
+	}
+
+	return nil
+}
+
+// process applies a claimed notification and records the outcome. The
+// returned error is only set when the outcome could not be recorded.
+func (s *paymentNotificationService) process(notification model.PaymentNotification, payload dto.PaymentNotificationInput) (model.PaymentNotification, error) {
+	notification.Status = "processed"
+	notification.Error = ""
+
+	if err := s.bookingService.UpdatePayment(payload); err != nil {
+		notification.Error = err.Error()
+
+		switch {
+		case errors.Is(err, ErrStaleNotification):
+			notification.Status = "ignored"
+		case errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrAmountMismatch):
+			notification.Status = "rejected"
+		default:
+			notification.Status = "failed"
+		}
+	}
+
+	if err := s.notificationRepository.Finish(notification.Id, notification.Status, notification.Error); err != nil {
+		return model.PaymentNotification{}, err
+	}
+
+	return notification, nil
+}
+
+func (s *paymentNotificationService) Replay(id string) (model.PaymentNotification, error) {
+	notification, err := s.notificationRepository.FindById(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PaymentNotification{}, errors.New("payment notification not found")
+		}
+		return model.PaymentNotification{}, err
+	}
+
+	var payload dto.PaymentNotificationInput
+	if err := json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+		return model.PaymentNotification{}, errors.New("cannot replay, stored payload is not valid json")
+	}
+
+	claimed, err := s.notificationRepository.Reclaim(id)
+	if err != nil {
+		return model.PaymentNotification{}, err
+	}
+
+	if !claimed {
+		return model.PaymentNotification{}, errors.New("cannot replay, notification is being processed")
+	}
+
+	return s.process(notification, payload)
+}
+
+func (s *paymentNotificationService) FindNotifications(status string, page int, size int) ([]model.PaymentNotification, dto.Paginate, error) {
+	return s.notificationRepository.FindAll(status, page, size)
+}
+
+func NewPaymentNotificationService(notificationRepository repository.PaymentNotificationRepository, bookingService BookingService) PaymentNotificationService {
+	return &paymentNotificationService{
+		notificationRepository: notificationRepository,
+		bookingService:         bookingService,
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	repomock "team2/shuttleslot/mock/repo_mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var settlementNotification = dto.PaymentNotificationInput{
+	OrderId:           "Booking-1",
+	TransactionStatus: "settlement",
+	PaymentType:       "gopay",
+}
+
+var settlementRaw = []byte(`{"order_id":"Booking-1","transaction_status":"settlement","payment_type":"gopay"}`)
+
+var storedNotification = model.PaymentNotification{
+	Id:                "1",
+	OrderId:           "Booking-1",
+	TransactionStatus: "settlement",
+	Payload:           string(settlementRaw),
+	Status:            "received",
+	Attempts:          1,
+}
+
+type PaymentNotificationServiceTestSuite struct {
+	suite.Suite
+	repoMock *repomock.PaymentNotificationRepositoryMock
+	bS       *servicemock.BookingServiceMock
+	nS       PaymentNotificationService
+}
+
+func (suite *PaymentNotificationServiceTestSuite) SetupTest() {
+	suite.repoMock = new(repomock.PaymentNotificationRepositoryMock)
+	suite.bS = new(servicemock.BookingServiceMock)
+	suite.nS = NewPaymentNotificationService(suite.repoMock, suite.bS)
+}
+
+func TestPaymentNotificationServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentNotificationServiceTestSuite))
+}
+
+func (suite *PaymentNotificationServiceTestSuite) TestReceive_Processed() {
+	suite.repoMock.On("Save", model.PaymentNotification{OrderId: "Booking-1", TransactionStatus: "settlement", Payload: string(settlementRaw)}).Return(storedNotification, nil)
+	suite.repoMock.On("Claim", "1").Return(true, nil)
+	suite.bS.On("UpdatePayment", settlementNotification).Return(nil)
+	suite.repoMock.On("Finish", "1", "processed", "").Return(nil)
+
+	err := suite.nS.Receive(settlementNotification, settlementRaw)
+
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *PaymentNotificationServiceTestSuite) TestReceive_Duplicate() {
+	suite.repoMock.On("Save", mock.Anything).Return(storedNotification, nil)
+	suite.repoMock.On("Claim", "1").Return(false, nil)
+
+	err := suite.nS.Receive(settlementNotification, settlementRaw)
+
+	suite.NoError(err)
+	suite.bS.AssertNotCalled(suite.T(), "UpdatePayment", mock.Anything)
+}
+
+func (suite *PaymentNotificationServiceTestSuite) TestReceive_Stale() {
+	stale := fmt.Errorf("%w, payment Booking-1 is already paid", ErrStaleNotification)
+	suite.repoMock.On("Save", mock.Anything).Return(storedNotification, nil)
+	suite.repoMock.On("Claim", "1").Return(true, nil)
+	suite.bS.On("UpdatePayment", settlementNotification).Return(stale)
+	suite.repoMock.On("Finish", "1", "ignored", stale.Error()).Return(nil)
+
+	err := suite.nS.Receive(settlementNotification, settlementRaw)
+
+	suite.NoError(err)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *PaymentNotificationServiceTestSuite) TestReceive_Rejected() {
+	suite.repoMock.On("Save", mock.Anything).Return(storedNotification, nil)
+	suite.repoMock.On("Claim", "1").Return(true, nil)
+	suite.bS.On("UpdatePayment", settlementNotification).Return(ErrInvalidSignature)
+	suite.repoMock.On("Finish", "1", "rejected", ErrInvalidSignature.Error()).Return(nil)
+
+	err := suite.nS.Receive(settlementNotification, settlementRaw)
+
+	suite.EqualError(err, ErrInvalidSignature.Error())
+}
+
+func (suite *PaymentNotificationServiceTestSuite) TestReceive_Failed() {
+	suite.repoMock.On("Save", mock.Anything).Return(storedNotification, nil)
+	suite.repoMock.On("Claim", "1").Return(true, nil)
+	suite.bS.On("UpdatePayment", settlementNotification).Return(errors.New("connection refused"))
+	suite.repoMock.On("Finish", "1", "failed", "connection refused").Return(nil)
+
+	err := suite.nS.Receive(settlementNotification, settlementRaw)
+
+	suite.EqualError(err, "connection refused")
+}
+
+func (suite *PaymentNotificationServiceTestSuite) TestReceive_SaveError() {
+	suite.repoMock.On("Save", mock.Anything).Return(model.PaymentNotification{}, errors.New("error"))
+
+	err := suite.nS.Receive(settlementNotification, settlementRaw)
+
+	suite.Error(err)
+	suite.bS.AssertNotCalled(suite.T(), "UpdatePayment", mock.Anything)
+}
+
+func (suite *PaymentNotificationServiceTestSuite) TestReplay_Success() {
+	failed := storedNotification
+	failed.Status = "failed"
+	failed.Error = "connection refused"
+
+	suite.repoMock.On("FindById", "1").Return(failed, nil)
+	suite.repoMock.On("Reclaim", "1").Return(true, nil)
+	suite.bS.On("UpdatePayment", settlementNotification).Return(nil)
+	suite.repoMock.On("Finish", "1", "processed", "").Return(nil)
+
+	notification, err := suite.nS.Replay("1")
+
+	suite.NoError(err)
+	suite.Equal("processed", notification.Status)
+	suite.Empty(notification.Error)
+}
+
+func (suite *PaymentNotificationServiceTestSuite) TestReplay_NotFound() {
+	suite.repoMock.On("FindById", "9").Return(model.PaymentNotification{}, sql.ErrNoRows)
+
+	_, err := suite.nS.Replay("9")
+
+	suite.EqualError(err, "payment notification not found")
+}
+
+func (suite *PaymentNotificationServiceTestSuite) TestReplay_InProgress() {
+	suite.repoMock.On("FindById", "1").Return(storedNotification, nil)
+	suite.repoMock.On("Reclaim", "1").Return(false, nil)
+
+	_, err := suite.nS.Replay("1")
+
+	suite.EqualError(err, "cannot replay, notification is being processed")
+	suite.bS.AssertNotCalled(suite.T(), "UpdatePayment", mock.Anything)
+}
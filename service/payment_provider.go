@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"team2/shuttleslot/config"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/midtrans/midtrans-go/snap"
+)
+
+// ErrTransactionNotFound is returned by CheckTransaction for an order the
+// gateway has no transaction for, e.g. when the customer never opened the
+// payment page.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// PaymentProvider is the gateway that collects online payments. The result of
+// a payment comes back as a notification handled by PaymentGateService, and
+// CheckTransaction returns the same notification on demand. Refund returns
+// money of a settled transaction, refund.RefundOf, keyed by refund.OrderId so a
+// retried refund is not paid out twice. Expire closes a transaction that is
+// still pending so it can no longer be paid.
+type PaymentProvider interface {
+	CreateTransaction(payment model.Payment) (string, error)
+	CheckTransaction(orderId string) (dto.PaymentNotificationInput, error)
+	Refund(refund model.Payment) error
+	Expire(orderId string) error
+}
+
+type midtransProvider struct {
+	client     snap.Client
+	coreClient coreapi.Client
+}
+
+func (p *midtransProvider) CreateTransaction(payment model.Payment) (string, error) {
+	snapReq := &snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  payment.OrderId,
+			GrossAmt: int64(payment.Price),
+		},
+		CustomerDetail: &midtrans.CustomerDetails{
+			FName: payment.User.Name,
+			Email: "test@mail.com",
+			Phone: payment.User.PhoneNumber,
+		},
+		Items: paymentItems(payment),
+	}
+
+	resp, err := p.client.CreateTransaction(snapReq)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.RedirectURL, nil
+}
+
+func (p *midtransProvider) CheckTransaction(orderId string) (dto.PaymentNotificationInput, error) {
+	resp, err := p.coreClient.CheckTransaction(orderId)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return dto.PaymentNotificationInput{}, ErrTransactionNotFound
+		}
+		return dto.PaymentNotificationInput{}, err
+	}
+
+	return dto.PaymentNotificationInput{
+		TransactionStatus: resp.TransactionStatus,
+		OrderId:           resp.OrderID,
+		PaymentType:       resp.PaymentType,
+		FraudStatus:       resp.FraudStatus,
+		StatusCode:        resp.StatusCode,
+		GrossAmount:       resp.GrossAmount,
+		SignatureKey:      resp.SignatureKey,
+	}, nil
+}
+
+func (p *midtransProvider) Refund(refund model.Payment) error {
+	refundReq := &coreapi.RefundReq{
+		RefundKey: refund.OrderId,
+		Amount:    int64(refund.Price),
+		Reason:    refund.Description,
+	}
+
+	_, err := p.coreClient.RefundTransaction(refund.RefundOf, refundReq)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return ErrTransactionNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (p *midtransProvider) Expire(orderId string) error {
+	_, err := p.coreClient.ExpireTransaction(orderId)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return ErrTransactionNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// paymentItems lists the priced items of a payment so the amounts shown by
+// Midtrans add up to the gross amount. Payments without a breakdown fall back
+// to a single court line.
+func paymentItems(payment model.Payment) *[]midtrans.ItemDetails {
+	if len(payment.Items) == 0 {
+		return &[]midtrans.ItemDetails{
+			{
+				Name:  payment.Court.Name,
+				Price: int64(payment.Court.Price),
+				Qty:   int32(payment.Qty),
+			},
+		}
+	}
+
+	items := make([]midtrans.ItemDetails, 0, len(payment.Items))
+	for _, item := range payment.Items {
+		items = append(items, midtrans.ItemDetails{
+			Name:  item.Name,
+			Price: int64(item.Price),
+			Qty:   1,
+		})
+	}
+
+	return &items
+}
+
+func NewMidtransProvider(payGateConfig config.PayGateConfig) PaymentProvider {
+	environment := midtrans.Sandbox
+	if payGateConfig.Environment == "production" {
+		environment = midtrans.Production
+	}
+
+	provider := &midtransProvider{}
+	provider.client.New(payGateConfig.ServerKey, environment)
+	provider.coreClient.New(payGateConfig.ServerKey, environment)
+
+	return provider
+}
+
+// NewPaymentProvider returns the provider selected by PAYMENT_PROVIDER.
+func NewPaymentProvider(payGateConfig config.PayGateConfig) (PaymentProvider, error) {
+	switch payGateConfig.Provider {
+	case "", "midtrans":
+		return NewMidtransProvider(payGateConfig), nil
+	case "fake":
+		return NewFakeProvider(payGateConfig.FakeURL, payGateConfig.ServerKey), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q, use 'midtrans' or 'fake'", payGateConfig.Provider)
+	}
+}
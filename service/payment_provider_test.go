@@ -0,0 +1,31 @@
+package service
+
+import (
+	"team2/shuttleslot/config"
+	"team2/shuttleslot/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPaymentProvider(t *testing.T) {
+	provider, err := NewPaymentProvider(config.PayGateConfig{Provider: "fake", FakeURL: "http://localhost/fake"})
+	assert.NoError(t, err)
+	assert.IsType(t, &FakeProvider{}, provider)
+
+	provider, err = NewPaymentProvider(config.PayGateConfig{Provider: "midtrans", ServerKey: "key", Environment: "production"})
+	assert.NoError(t, err)
+	assert.IsType(t, &midtransProvider{}, provider)
+
+	_, err = NewPaymentProvider(config.PayGateConfig{Provider: "paypal"})
+	assert.Error(t, err)
+}
+
+func TestPaymentItems_Breakdown(t *testing.T) {
+	items := paymentItems(model.Payment{
+		Items: []model.PriceItem{{Name: "Court A 08:00-09:00", Price: 30000}, {Name: "Promo HEMAT", Price: -5000}},
+	})
+
+	assert.Len(t, *items, 2)
+	assert.Equal(t, int64(-5000), (*items)[1].Price)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"team2/shuttleslot/config"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/repository"
+	"time"
+)
+
+// PaymentReconciler periodically asks the gateway for the status of payments
+// that are still unpaid after a while, so a notification that never reached
+// us does not leave the booking pending. What the gateway reports goes
+// through the notification inbox, exactly like a delivered notification.
+type PaymentReconciler struct {
+	bookingRepository   repository.BookingRepository
+	provider            PaymentProvider
+	notificationService PaymentNotificationService
+	staleAfter          time.Duration
+	interval            time.Duration
+	now                 func() time.Time
+
+	mu         sync.Mutex
+	lastReport model.ReconciliationReport
+}
+
+func (r *PaymentReconciler) Reconcile() (model.ReconciliationReport, error) {
+	now := r.now()
+
+	payments, err := r.bookingRepository.FindStalePayments(now.Add(-r.staleAfter))
+	if err != nil {
+		return model.ReconciliationReport{}, err
+	}
+
+	report := model.ReconciliationReport{
+		RunAt:         now,
+		Checked:       len(payments),
+		Discrepancies: []model.ReconciliationItem{},
+	}
+
+	for _, payment := range payments {
+		item := model.ReconciliationItem{
+			OrderId:     payment.OrderId,
+			BookingId:   payment.BookingId,
+			LocalStatus: payment.Status,
+		}
+
+		status, err := r.provider.CheckTransaction(payment.OrderId)
+		if err != nil {
+			// The customer has not opened the payment page yet, the booking
+			// sweeper expires it once the hold time is over.
+			if errors.Is(err, ErrTransactionNotFound) {
+				continue
+			}
+
+			item.Action = "failed"
+			item.Error = err.Error()
+			report.Discrepancies = append(report.Discrepancies, item)
+			continue
+		}
+
+		if status.TransactionStatus == "pending" {
+			continue
+		}
+
+		item.GatewayStatus = status.TransactionStatus
+		item.Action = "applied"
+
+		raw, _ := json.Marshal(status)
+		if err := r.notificationService.Receive(status, raw); err != nil {
+			item.Action = "failed"
+			item.Error = err.Error()
+		}
+
+		report.Discrepancies = append(report.Discrepancies, item)
+	}
+
+	r.mu.Lock()
+	r.lastReport = report
+	r.mu.Unlock()
+
+	return report, nil
+}
+
+// LastReport returns the report of the latest run, empty before the first one.
+func (r *PaymentReconciler) LastReport() model.ReconciliationReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lastReport
+}
+
+func (r *PaymentReconciler) Start(ctx context.Context) {
+	runEvery(ctx, r.interval, func() {
+		report, err := r.Reconcile()
+		if err != nil {
+			log.Println("failed to reconcile payments:", err)
+		}
+		if len(report.Discrepancies) > 0 {
+			log.Printf("reconciled %d payments, %d discrepancies\n", report.Checked, len(report.Discrepancies))
+		}
+	})
+}
+
+func NewPaymentReconciler(bookingRepository repository.BookingRepository, provider PaymentProvider, notificationService PaymentNotificationService, reconcileConfig config.ReconcileConfig, now func() time.Time) *PaymentReconciler {
+	return &PaymentReconciler{
+		bookingRepository:   bookingRepository,
+		provider:            provider,
+		notificationService: notificationService,
+		staleAfter:          reconcileConfig.StaleAfter,
+		interval:            reconcileConfig.Interval,
+		now:                 now,
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"team2/shuttleslot/config"
+	repomock "team2/shuttleslot/mock/repo_mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PaymentReconcilerTestSuite struct {
+	suite.Suite
+	repoMock   *repomock.BookingRepositoryMock
+	nS         *servicemock.PaymentNotificationServiceMock
+	provider   *FakeProvider
+	reconciler *PaymentReconciler
+	now        time.Time
+}
+
+func (suite *PaymentReconcilerTestSuite) SetupTest() {
+	suite.repoMock = new(repomock.BookingRepositoryMock)
+	suite.nS = new(servicemock.PaymentNotificationServiceMock)
+	suite.provider = NewFakeProvider("http://localhost/api/v1/fake-gateway", "server-key")
+	suite.now = time.Date(2030, 10, 1, 10, 0, 0, 0, time.UTC)
+	suite.reconciler = NewPaymentReconciler(suite.repoMock, suite.provider, suite.nS, config.ReconcileConfig{StaleAfter: 10 * time.Minute}, suite.clock)
+}
+
+func (suite *PaymentReconcilerTestSuite) clock() time.Time {
+	return suite.now
+}
+
+func TestPaymentReconcilerTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentReconcilerTestSuite))
+}
+
+func (suite *PaymentReconcilerTestSuite) TestReconcile_AppliesGatewayStatus() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-2", Price: 60000})
+	suite.provider.UpdateStatus("Booking-1", "settlement")
+
+	suite.repoMock.On("FindStalePayments", time.Date(2030, 10, 1, 9, 50, 0, 0, time.UTC)).Return([]model.Payment{
+		{OrderId: "Booking-1", BookingId: "1", Status: "unpaid"},
+		{OrderId: "Booking-2", BookingId: "2", Status: "unpaid"},
+		{OrderId: "Booking-3", BookingId: "3", Status: "unpaid"},
+	}, nil)
+	suite.nS.On("Receive", mock.MatchedBy(func(p dto.PaymentNotificationInput) bool {
+		return p.OrderId == "Booking-1" && p.TransactionStatus == "settlement" &&
+			p.SignatureKey == notificationSignature("Booking-1", "200", "60000.00", "server-key")
+	}), mock.Anything).Return(nil).Once()
+
+	report, err := suite.reconciler.Reconcile()
+
+	suite.NoError(err)
+	suite.Equal(3, report.Checked)
+	suite.Equal([]model.ReconciliationItem{
+		{OrderId: "Booking-1", BookingId: "1", LocalStatus: "unpaid", GatewayStatus: "settlement", Action: "applied"},
+	}, report.Discrepancies)
+	suite.Equal(report, suite.reconciler.LastReport())
+	suite.nS.AssertExpectations(suite.T())
+}
+
+func (suite *PaymentReconcilerTestSuite) TestReconcile_ApplyFailed() {
+	suite.provider.CreateTransaction(model.Payment{OrderId: "Booking-1", Price: 60000})
+	suite.provider.UpdateStatus("Booking-1", "expire")
+
+	suite.repoMock.On("FindStalePayments", mock.Anything).Return([]model.Payment{{OrderId: "Booking-1", BookingId: "1", Status: "unpaid"}}, nil)
+	suite.nS.On("Receive", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	report, err := suite.reconciler.Reconcile()
+
+	suite.NoError(err)
+	suite.Equal("failed", report.Discrepancies[0].Action)
+	suite.Equal("expire", report.Discrepancies[0].GatewayStatus)
+	suite.Equal("connection refused", report.Discrepancies[0].Error)
+}
+
+func (suite *PaymentReconcilerTestSuite) TestReconcile_FindFailed() {
+	suite.repoMock.On("FindStalePayments", mock.Anything).Return([]model.Payment{}, errors.New("error"))
+
+	_, err := suite.reconciler.Reconcile()
+
+	suite.Error(err)
+	suite.Empty(suite.reconciler.LastReport().Discrepancies)
+}
+
+func (suite *PaymentReconcilerTestSuite) TestStart_RunsUntilCancelled() {
+	reconciler := NewPaymentReconciler(suite.repoMock, suite.provider, suite.nS, config.ReconcileConfig{StaleAfter: 10 * time.Minute, Interval: time.Millisecond}, suite.clock)
+
+	reconciled := make(chan struct{}, 1)
+	suite.repoMock.On("FindStalePayments", mock.Anything).Return([]model.Payment{}, nil).Run(func(args mock.Arguments) {
+		select {
+		case reconciled <- struct{}{}:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reconciler.Start(ctx)
+
+	select {
+	case <-reconciled:
+	case <-time.After(time.Second):
+		suite.Fail("reconciler did not run")
+	}
+	cancel()
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"team2/shuttleslot/util"
+	"time"
+)
+
+type PricingService interface {
+	CreateRule(payload dto.CreatePricingRuleRequest) (model.PricingRule, error)
+	FindAllRules() ([]model.PricingRule, error)
+	DeleteRule(id string) error
+	PriceBooking(court model.Court, bookingDate, startTime time.Time, hour int) ([]model.PriceItem, error)
+}
+
+type pricingService struct {
+	pricingRuleRepository repository.PricingRuleRepository
+}
+
+func (s *pricingService) CreateRule(payload dto.CreatePricingRuleRequest) (model.PricingRule, error) {
+	if payload.Name == "" {
+		return model.PricingRule{}, errors.New("invalid pricing rule, name is required")
+	}
+
+	if payload.Price < 1 {
+		return model.PricingRule{}, errors.New("invalid pricing rule, price must be greater than 0")
+	}
+
+	if !util.IsValidTime(payload.StartTime) || !util.IsValidTime(payload.EndTime) {
+		return model.PricingRule{}, errors.New("invalid pricing rule, use 'hh:mm:ss' for startTime and endTime")
+	}
+
+	rule := model.PricingRule{
+		Name:      payload.Name,
+		CourtId:   payload.CourtId,
+		Weekdays:  payload.Weekdays,
+		StartTime: util.StringToTime(payload.StartTime),
+		EndTime:   util.StringToTime(payload.EndTime),
+		Price:     payload.Price,
+	}
+
+	if !rule.StartTime.Before(rule.EndTime) {
+		return model.PricingRule{}, errors.New("invalid pricing rule, startTime must be before endTime")
+	}
+
+	if payload.HolidayDate != "" {
+		if !util.IsValidDate(payload.HolidayDate) {
+			return model.PricingRule{}, errors.New("invalid pricing rule, use 'dd-mm-yyyy' for holidayDate")
+		}
+
+		if len(payload.Weekdays) > 0 {
+			return model.PricingRule{}, errors.New("invalid pricing rule, a holiday rule cannot have weekdays")
+		}
+
+		rule.HolidayDate = util.StringToDate(payload.HolidayDate)
+	}
+
+	for _, val := range payload.Weekdays {
+		if val < 0 || val > 6 {
+			return model.PricingRule{}, errors.New("invalid pricing rule, weekday must be between 0 (sunday) and 6 (saturday)")
+		}
+	}
+
+	return s.pricingRuleRepository.Create(rule)
+}
+
+func (s *pricingService) FindAllRules() ([]model.PricingRule, error) {
+	return s.pricingRuleRepository.FindAll()
+}
+
+func (s *pricingService) DeleteRule(id string) error {
+	err := s.pricingRuleRepository.Delete(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("pricing rule not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// PriceBooking prices every hour of a booking on its own. Holiday rules win
+// over weekday rules, court rules win over rules for all courts, and hours
+// without any rule use the court price.
+func (s *pricingService) PriceBooking(court model.Court, bookingDate, startTime time.Time, hour int) ([]model.PriceItem, error) {
+	rules, err := s.pricingRuleRepository.FindAll()
+	if err != nil {
+		return []model.PriceItem{}, err
+	}
+
+	var items []model.PriceItem
+
+	for i := 0; i < hour; i++ {
+		hourStart := startTime.Add(time.Hour * time.Duration(i))
+		hourEnd := hourStart.Add(time.Hour)
+
+		item := model.PriceItem{
+			Name:      fmt.Sprintf("%s %s-%s", court.Name, hourStart.Format("15:04"), hourEnd.Format("15:04")),
+			StartTime: hourStart,
+			EndTime:   hourEnd,
+			Price:     court.Price,
+		}
+
+		if rule, ok := matchPricingRule(rules, court.Id, bookingDate, hourStart); ok {
+			item.Name = fmt.Sprintf("%s (%s)", item.Name, rule.Name)
+			item.Price = rule.Price
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func matchPricingRule(rules []model.PricingRule, courtId string, date, hourStart time.Time) (model.PricingRule, bool) {
+	var best model.PricingRule
+	bestRank := 0
+
+	for _, val := range rules {
+		if !val.Matches(courtId, date, hourStart) {
+			continue
+		}
+
+		rank := 1
+		if val.CourtId != "" {
+			rank++
+		}
+		if val.IsHoliday() {
+			rank += 2
+		}
+
+		if rank > bestRank {
+			best = val
+			bestRank = rank
+		}
+	}
+
+	return best, bestRank > 0
+}
+
+// totalPrice sums the price of every item.
+func totalPrice(items []model.PriceItem) int {
+	total := 0
+
+	for _, val := range items {
+		total += val.Price
+	}
+
+	return total
+}
+
+// promoItem is the breakdown line of a redeemed promo, priced negative so the
+// items still add up to the discounted total.
+func promoItem(redemption model.PromoRedemption) model.PriceItem {
+	return model.PriceItem{
+		Name:  fmt.Sprintf("Promo %s", redemption.Code),
+		Price: -redemption.Discount,
+	}
+}
+
+// pointsItem is the breakdown line of redeemed loyalty points.
+func pointsItem(entry model.PointEntry) model.PriceItem {
+	return model.PriceItem{
+		Name:  fmt.Sprintf("Loyalty points (%d)", -entry.Points),
+		Price: -entry.Amount,
+	}
+}
+
+// depositItems scales every item down to the share of the total charged as
+// deposit, putting any rounding difference on the last item so the items add
+// up to exactly deposit.
+func depositItems(items []model.PriceItem, deposit int) []model.PriceItem {
+	total := totalPrice(items)
+	shares := make([]model.PriceItem, len(items))
+	sum := 0
+
+	for i, val := range items {
+		shares[i] = val
+		if total != 0 {
+			shares[i].Price = val.Price * deposit / total
+		}
+		sum += shares[i].Price
+	}
+
+	if len(shares) > 0 {
+		shares[len(shares)-1].Price += deposit - sum
+	}
+
+	return shares
+}
+
+func NewPricingService(pricingRuleRepository repository.PricingRuleRepository) PricingService {
+	return &pricingService{pricingRuleRepository: pricingRuleRepository}
+}
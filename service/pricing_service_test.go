@@ -0,0 +1,213 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	repomock "team2/shuttleslot/mock/repo_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var pricingCourt = model.Court{
+	Id:    "court_1",
+	Name:  "Court 1",
+	Price: 50000,
+}
+
+var weekdayEvening = model.PricingRule{
+	Id:        "rule_1",
+	Name:      "Evening",
+	Weekdays:  []int{1, 2, 3, 4, 5},
+	StartTime: util.StringToTime("18:00:00"),
+	EndTime:   util.StringToTime("22:00:00"),
+	Price:     70000,
+}
+
+var courtEvening = model.PricingRule{
+	Id:        "rule_2",
+	Name:      "Court 1 evening",
+	CourtId:   "court_1",
+	StartTime: util.StringToTime("18:00:00"),
+	EndTime:   util.StringToTime("22:00:00"),
+	Price:     75000,
+}
+
+var newYear = model.PricingRule{
+	Id:          "rule_3",
+	Name:        "New year",
+	HolidayDate: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	StartTime:   util.StringToTime("00:00:00"),
+	EndTime:     util.StringToTime("23:59:59"),
+	Price:       100000,
+}
+
+type PricingServiceTestSuite struct {
+	suite.Suite
+	repoMock *repomock.PricingRuleRepositoryMock
+	pS       PricingService
+}
+
+func (suite *PricingServiceTestSuite) SetupTest() {
+	suite.repoMock = new(repomock.PricingRuleRepositoryMock)
+	suite.pS = NewPricingService(suite.repoMock)
+}
+
+func TestPricingServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PricingServiceTestSuite))
+}
+
+func (suite *PricingServiceTestSuite) TestCreateRule_Success() {
+	request := dto.CreatePricingRuleRequest{
+		Name:      "Evening",
+		Weekdays:  []int{1, 2, 3, 4, 5},
+		StartTime: "18:00:00",
+		EndTime:   "22:00:00",
+		Price:     70000,
+	}
+	suite.repoMock.On("Create", mock.MatchedBy(func(r model.PricingRule) bool {
+		return r.Name == "Evening" && r.Price == 70000 && !r.IsHoliday() && r.StartTime.Equal(util.StringToTime("18:00:00"))
+	})).Return(weekdayEvening, nil)
+
+	rule, err := suite.pS.CreateRule(request)
+
+	suite.NoError(err)
+	suite.Equal(weekdayEvening, rule)
+}
+
+func (suite *PricingServiceTestSuite) TestCreateRule_Holiday() {
+	request := dto.CreatePricingRuleRequest{
+		Name:        "New year",
+		HolidayDate: "01-01-2030",
+		StartTime:   "00:00:00",
+		EndTime:     "23:59:59",
+		Price:       100000,
+	}
+	suite.repoMock.On("Create", mock.MatchedBy(func(r model.PricingRule) bool {
+		return r.HolidayDate.Equal(newYear.HolidayDate)
+	})).Return(newYear, nil)
+
+	_, err := suite.pS.CreateRule(request)
+
+	suite.NoError(err)
+}
+
+func (suite *PricingServiceTestSuite) TestCreateRule_Invalid() {
+	valid := dto.CreatePricingRuleRequest{Name: "Evening", StartTime: "18:00:00", EndTime: "22:00:00", Price: 70000}
+
+	noName := valid
+	noName.Name = ""
+	noPrice := valid
+	noPrice.Price = 0
+	badTime := valid
+	badTime.StartTime = "18.00"
+	reversed := valid
+	reversed.StartTime, reversed.EndTime = valid.EndTime, valid.StartTime
+	badWeekday := valid
+	badWeekday.Weekdays = []int{7}
+	badHoliday := valid
+	badHoliday.HolidayDate = "2030-01-01"
+	holidayWeekdays := valid
+	holidayWeekdays.HolidayDate = "01-01-2030"
+	holidayWeekdays.Weekdays = []int{1}
+
+	for _, request := range []dto.CreatePricingRuleRequest{noName, noPrice, badTime, reversed, badWeekday, badHoliday, holidayWeekdays} {
+		_, err := suite.pS.CreateRule(request)
+		suite.ErrorContains(err, "invalid pricing rule")
+	}
+
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *PricingServiceTestSuite) TestDeleteRule_NotFound() {
+	suite.repoMock.On("Delete", "rule_1").Return(sql.ErrNoRows)
+
+	err := suite.pS.DeleteRule("rule_1")
+
+	suite.EqualError(err, "pricing rule not found")
+}
+
+func (suite *PricingServiceTestSuite) TestPriceBooking_NoRules() {
+	suite.repoMock.On("FindAll").Return([]model.PricingRule{}, nil)
+
+	items, err := suite.pS.PriceBooking(pricingCourt, util.StringToDate("07-01-2030"), util.StringToTime("10:00:00"), 2)
+
+	suite.NoError(err)
+	suite.Len(items, 2)
+	suite.Equal("Court 1 10:00-11:00", items[0].Name)
+	suite.Equal(50000, items[0].Price)
+	suite.Equal(50000, items[1].Price)
+}
+
+func (suite *PricingServiceTestSuite) TestPriceBooking_SpansPeakBoundary() {
+	suite.repoMock.On("FindAll").Return([]model.PricingRule{weekdayEvening}, nil)
+
+	// 07-01-2030 is a monday
+	items, err := suite.pS.PriceBooking(pricingCourt, util.StringToDate("07-01-2030"), util.StringToTime("17:00:00"), 2)
+
+	suite.NoError(err)
+	suite.Equal(50000, items[0].Price)
+	suite.Equal(70000, items[1].Price)
+	suite.Equal("Court 1 18:00-19:00 (Evening)", items[1].Name)
+	suite.Equal(120000, totalPrice(items))
+}
+
+func (suite *PricingServiceTestSuite) TestPriceBooking_WeekdayRuleSkipsWeekend() {
+	suite.repoMock.On("FindAll").Return([]model.PricingRule{weekdayEvening}, nil)
+
+	// 05-01-2030 is a saturday
+	items, err := suite.pS.PriceBooking(pricingCourt, util.StringToDate("05-01-2030"), util.StringToTime("18:00:00"), 1)
+
+	suite.NoError(err)
+	suite.Equal(50000, items[0].Price)
+}
+
+func (suite *PricingServiceTestSuite) TestPriceBooking_CourtRuleWins() {
+	suite.repoMock.On("FindAll").Return([]model.PricingRule{weekdayEvening, courtEvening}, nil)
+
+	items, err := suite.pS.PriceBooking(pricingCourt, util.StringToDate("07-01-2030"), util.StringToTime("18:00:00"), 1)
+
+	suite.NoError(err)
+	suite.Equal(75000, items[0].Price)
+
+	other := pricingCourt
+	other.Id = "court_2"
+	items, err = suite.pS.PriceBooking(other, util.StringToDate("07-01-2030"), util.StringToTime("18:00:00"), 1)
+
+	suite.NoError(err)
+	suite.Equal(70000, items[0].Price)
+}
+
+func (suite *PricingServiceTestSuite) TestPriceBooking_HolidayWins() {
+	suite.repoMock.On("FindAll").Return([]model.PricingRule{weekdayEvening, courtEvening, newYear}, nil)
+
+	// 01-01-2030 is a tuesday
+	items, err := suite.pS.PriceBooking(pricingCourt, util.StringToDate("01-01-2030"), util.StringToTime("18:00:00"), 1)
+
+	suite.NoError(err)
+	suite.Equal(100000, items[0].Price)
+	suite.Equal("Court 1 18:00-19:00 (New year)", items[0].Name)
+}
+
+func (suite *PricingServiceTestSuite) TestPriceBooking_Failed() {
+	suite.repoMock.On("FindAll").Return([]model.PricingRule{}, errors.New("error"))
+
+	_, err := suite.pS.PriceBooking(pricingCourt, util.StringToDate("07-01-2030"), util.StringToTime("10:00:00"), 1)
+
+	suite.Error(err)
+}
+
+func (suite *PricingServiceTestSuite) TestDepositItems_AddsUpToDeposit() {
+	items := []model.PriceItem{{Price: 50001}, {Price: 70001}}
+
+	deposit := depositItems(items, totalPrice(items)/2)
+
+	suite.Equal(totalPrice(items)/2, totalPrice(deposit))
+	suite.Equal(25000, deposit[0].Price)
+	suite.Equal(50001, items[0].Price)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"team2/shuttleslot/util"
+	"time"
+)
+
+type PromoService interface {
+	CreatePromo(payload dto.CreatePromoCodeRequest) (model.PromoCode, error)
+	FindAllPromos() ([]model.PromoCode, error)
+	DeletePromo(id string) error
+	ApplyPromo(code, customerId, courtId string, total int) (model.PromoRedemption, error)
+	FindRedemption(bookingId string) (model.PromoRedemption, error)
+	FindPromoReport(startDate, endDate time.Time) ([]model.PromoReport, error)
+}
+
+type promoService struct {
+	promoCodeRepository repository.PromoCodeRepository
+}
+
+func (s *promoService) CreatePromo(payload dto.CreatePromoCodeRequest) (model.PromoCode, error) {
+	if payload.Code == "" {
+		return model.PromoCode{}, errors.New("invalid promo code, code is required")
+	}
+
+	if payload.DiscountType != "percentage" && payload.DiscountType != "fixed" {
+		return model.PromoCode{}, errors.New("invalid promo code, discountType must be percentage or fixed")
+	}
+
+	if payload.DiscountValue < 1 || (payload.DiscountType == "percentage" && payload.DiscountValue > 100) {
+		return model.PromoCode{}, errors.New("invalid promo code, discountValue must be between 1 and 100 for percentage and greater than 0 for fixed")
+	}
+
+	if payload.MaxDiscount < 0 || payload.UsageLimit < 0 || payload.PerCustomerLimit < 0 {
+		return model.PromoCode{}, errors.New("invalid promo code, maxDiscount and usage limits cannot be negative")
+	}
+
+	if !util.IsValidDate(payload.ValidFrom) || !util.IsValidDate(payload.ValidUntil) {
+		return model.PromoCode{}, errors.New("invalid promo code, use 'dd-mm-yyyy' for validFrom and validUntil")
+	}
+
+	promo := model.PromoCode{
+		Code:             strings.ToUpper(payload.Code),
+		DiscountType:     payload.DiscountType,
+		DiscountValue:    payload.DiscountValue,
+		MaxDiscount:      payload.MaxDiscount,
+		ValidFrom:        util.StringToDate(payload.ValidFrom),
+		ValidUntil:       util.StringToDate(payload.ValidUntil),
+		UsageLimit:       payload.UsageLimit,
+		PerCustomerLimit: payload.PerCustomerLimit,
+		CourtIds:         payload.CourtIds,
+	}
+
+	if promo.ValidUntil.Before(promo.ValidFrom) {
+		return model.PromoCode{}, errors.New("invalid promo code, validUntil cannot be before validFrom")
+	}
+
+	return s.promoCodeRepository.Create(promo)
+}
+
+func (s *promoService) FindAllPromos() ([]model.PromoCode, error) {
+	return s.promoCodeRepository.FindAll()
+}
+
+func (s *promoService) DeletePromo(id string) error {
+	err := s.promoCodeRepository.Delete(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("promo code not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ApplyPromo checks that the code can be used for the booking and returns the
+// redemption to record with it. The usage limits are checked again when the
+// booking is saved, so two bookings cannot both take the last use.
+func (s *promoService) ApplyPromo(code, customerId, courtId string, total int) (model.PromoRedemption, error) {
+	promo, err := s.promoCodeRepository.FindByCode(strings.ToUpper(code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PromoRedemption{}, errors.New("cannot book, promo code not found")
+		}
+		return model.PromoRedemption{}, err
+	}
+
+	if !promo.IsActiveOn(util.StringToDate(util.DateToString(time.Now()))) {
+		return model.PromoRedemption{}, fmt.Errorf("cannot book, promo code is only valid from %s to %s", util.DateToString(promo.ValidFrom), util.DateToString(promo.ValidUntil))
+	}
+
+	if !promo.AppliesTo(courtId) {
+		return model.PromoRedemption{}, errors.New("cannot book, promo code is not valid for this court")
+	}
+
+	used, usedByCustomer, err := s.promoCodeRepository.CountRedemptions(promo.Id, customerId)
+	if err != nil {
+		return model.PromoRedemption{}, err
+	}
+
+	if promo.UsageLimit > 0 && used >= promo.UsageLimit {
+		return model.PromoRedemption{}, repository.ErrPromoUsageLimit
+	}
+
+	if promo.PerCustomerLimit > 0 && usedByCustomer >= promo.PerCustomerLimit {
+		return model.PromoRedemption{}, repository.ErrPromoCustomerLimit
+	}
+
+	return model.PromoRedemption{
+		PromoCodeId: promo.Id,
+		Code:        promo.Code,
+		CustomerId:  customerId,
+		Discount:    promo.DiscountFor(total),
+	}, nil
+}
+
+// FindRedemption returns the promo redeemed on a booking, or an empty
+// redemption when the booking was made without one.
+func (s *promoService) FindRedemption(bookingId string) (model.PromoRedemption, error) {
+	redemption, err := s.promoCodeRepository.FindRedemptionByBookingId(bookingId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PromoRedemption{}, nil
+		}
+		return model.PromoRedemption{}, err
+	}
+
+	return redemption, nil
+}
+
+func (s *promoService) FindPromoReport(startDate, endDate time.Time) ([]model.PromoReport, error) {
+	if endDate.Before(startDate) {
+		return []model.PromoReport{}, errors.New("invalid date range, endDate cannot be before startDate")
+	}
+
+	return s.promoCodeRepository.FindReport(startDate, endDate.AddDate(0, 0, 1))
+}
+
+func NewPromoService(promoCodeRepository repository.PromoCodeRepository) PromoService {
+	return &promoService{promoCodeRepository: promoCodeRepository}
+}
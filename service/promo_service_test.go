@@ -0,0 +1,191 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	repomock "team2/shuttleslot/mock/repo_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var activePromo = model.PromoCode{
+	Id:               "promo_1",
+	Code:             "HEMAT",
+	DiscountType:     "percentage",
+	DiscountValue:    10,
+	MaxDiscount:      15000,
+	ValidFrom:        time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	ValidUntil:       time.Date(2099, 12, 31, 0, 0, 0, 0, time.UTC),
+	UsageLimit:       100,
+	PerCustomerLimit: 2,
+}
+
+var promoRequest = dto.CreatePromoCodeRequest{
+	Code:          "hemat",
+	DiscountType:  "fixed",
+	DiscountValue: 10000,
+	ValidFrom:     "01-01-2030",
+	ValidUntil:    "31-01-2030",
+}
+
+type PromoServiceTestSuite struct {
+	suite.Suite
+	repoMock *repomock.PromoCodeRepositoryMock
+	pS       PromoService
+}
+
+func (suite *PromoServiceTestSuite) SetupTest() {
+	suite.repoMock = new(repomock.PromoCodeRepositoryMock)
+	suite.pS = NewPromoService(suite.repoMock)
+}
+
+func TestPromoServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PromoServiceTestSuite))
+}
+
+func (suite *PromoServiceTestSuite) TestCreatePromo_Success() {
+	suite.repoMock.On("Create", mock.MatchedBy(func(p model.PromoCode) bool {
+		return p.Code == "HEMAT" && p.ValidUntil.Equal(time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC))
+	})).Return(activePromo, nil)
+
+	promo, err := suite.pS.CreatePromo(promoRequest)
+
+	suite.NoError(err)
+	suite.Equal(activePromo, promo)
+}
+
+func (suite *PromoServiceTestSuite) TestCreatePromo_Invalid() {
+	noCode := promoRequest
+	noCode.Code = ""
+	badType := promoRequest
+	badType.DiscountType = "free"
+	overPercent := promoRequest
+	overPercent.DiscountType = "percentage"
+	overPercent.DiscountValue = 150
+	negativeLimit := promoRequest
+	negativeLimit.UsageLimit = -1
+	badDate := promoRequest
+	badDate.ValidFrom = "2030-01-01"
+	reversed := promoRequest
+	reversed.ValidFrom, reversed.ValidUntil = promoRequest.ValidUntil, promoRequest.ValidFrom
+
+	for _, request := range []dto.CreatePromoCodeRequest{noCode, badType, overPercent, negativeLimit, badDate, reversed} {
+		_, err := suite.pS.CreatePromo(request)
+		suite.ErrorContains(err, "invalid promo code")
+	}
+
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *PromoServiceTestSuite) TestDeletePromo_NotFound() {
+	suite.repoMock.On("Delete", "promo_1").Return(sql.ErrNoRows)
+
+	err := suite.pS.DeletePromo("promo_1")
+
+	suite.EqualError(err, "promo code not found")
+}
+
+func (suite *PromoServiceTestSuite) TestApplyPromo_PercentageCapped() {
+	suite.repoMock.On("FindByCode", "HEMAT").Return(activePromo, nil)
+	suite.repoMock.On("CountRedemptions", "promo_1", "customer_1").Return(3, 1, nil)
+
+	redemption, err := suite.pS.ApplyPromo("hemat", "customer_1", "court_1", 200000)
+
+	suite.NoError(err)
+	suite.Equal(15000, redemption.Discount)
+	suite.Equal("promo_1", redemption.PromoCodeId)
+	suite.Equal("customer_1", redemption.CustomerId)
+}
+
+func (suite *PromoServiceTestSuite) TestApplyPromo_FixedNeverAboveTotal() {
+	fixed := activePromo
+	fixed.DiscountType = "fixed"
+	fixed.DiscountValue = 50000
+	suite.repoMock.On("FindByCode", "HEMAT").Return(fixed, nil)
+	suite.repoMock.On("CountRedemptions", "promo_1", "customer_1").Return(0, 0, nil)
+
+	redemption, err := suite.pS.ApplyPromo("HEMAT", "customer_1", "court_1", 40000)
+
+	suite.NoError(err)
+	suite.Equal(40000, redemption.Discount)
+}
+
+func (suite *PromoServiceTestSuite) TestApplyPromo_NotFound() {
+	suite.repoMock.On("FindByCode", "NOPE").Return(model.PromoCode{}, sql.ErrNoRows)
+
+	_, err := suite.pS.ApplyPromo("nope", "customer_1", "court_1", 40000)
+
+	suite.EqualError(err, "cannot book, promo code not found")
+}
+
+func (suite *PromoServiceTestSuite) TestApplyPromo_Expired() {
+	expired := activePromo
+	expired.ValidUntil = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.repoMock.On("FindByCode", "HEMAT").Return(expired, nil)
+
+	_, err := suite.pS.ApplyPromo("HEMAT", "customer_1", "court_1", 40000)
+
+	suite.EqualError(err, "cannot book, promo code is only valid from 01-01-2020 to 01-01-2021")
+}
+
+func (suite *PromoServiceTestSuite) TestApplyPromo_OtherCourt() {
+	restricted := activePromo
+	restricted.CourtIds = []string{"court_2"}
+	suite.repoMock.On("FindByCode", "HEMAT").Return(restricted, nil)
+
+	_, err := suite.pS.ApplyPromo("HEMAT", "customer_1", "court_1", 40000)
+
+	suite.EqualError(err, "cannot book, promo code is not valid for this court")
+}
+
+func (suite *PromoServiceTestSuite) TestApplyPromo_UsageLimits() {
+	suite.repoMock.On("FindByCode", "HEMAT").Return(activePromo, nil)
+	suite.repoMock.On("CountRedemptions", "promo_1", "customer_1").Return(100, 0, nil).Once()
+	suite.repoMock.On("CountRedemptions", "promo_1", "customer_1").Return(10, 2, nil).Once()
+
+	_, err := suite.pS.ApplyPromo("HEMAT", "customer_1", "court_1", 40000)
+	suite.ErrorIs(err, repository.ErrPromoUsageLimit)
+
+	_, err = suite.pS.ApplyPromo("HEMAT", "customer_1", "court_1", 40000)
+	suite.ErrorIs(err, repository.ErrPromoCustomerLimit)
+}
+
+func (suite *PromoServiceTestSuite) TestFindRedemption_None() {
+	suite.repoMock.On("FindRedemptionByBookingId", "1").Return(model.PromoRedemption{}, sql.ErrNoRows)
+
+	redemption, err := suite.pS.FindRedemption("1")
+
+	suite.NoError(err)
+	suite.Equal(0, redemption.Discount)
+}
+
+func (suite *PromoServiceTestSuite) TestFindRedemption_Failed() {
+	suite.repoMock.On("FindRedemptionByBookingId", "1").Return(model.PromoRedemption{}, errors.New("error"))
+
+	_, err := suite.pS.FindRedemption("1")
+
+	suite.Error(err)
+}
+
+func (suite *PromoServiceTestSuite) TestFindPromoReport_IncludesEndDate() {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	suite.repoMock.On("FindReport", start, end.AddDate(0, 0, 1)).Return([]model.PromoReport{{Code: "HEMAT"}}, nil)
+
+	reports, err := suite.pS.FindPromoReport(start, end)
+
+	suite.NoError(err)
+	suite.Len(reports, 1)
+}
+
+func (suite *PromoServiceTestSuite) TestFindPromoReport_InvalidRange() {
+	_, err := suite.pS.FindPromoReport(time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	suite.ErrorContains(err, "invalid date range")
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"team2/shuttleslot/config"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"time"
+)
+
+type RestrictionService interface {
+	ImposeRestriction(payload dto.ImposeRestrictionRequest) (model.Restriction, error)
+	LiftRestriction(id string, actor string) (model.Restriction, error)
+	FindRestrictions(customerId string) ([]model.Restriction, error)
+	FindActiveRestrictions(customerId string) ([]model.Restriction, error)
+	ApplyNoShowPenalties(customerId string) ([]model.Restriction, error)
+}
+
+type restrictionService struct {
+	restrictionRepository repository.RestrictionRepository
+	userServ              UserService
+	audit                 AuditService
+	penalty               config.NoShowPenaltyConfig
+}
+
+// penaltyRule restricts a customer for days once they reach count no-shows
+// within windowDays.
+type penaltyRule struct {
+	restriction model.RestrictionType
+	count       int
+	windowDays  int
+	days        int
+}
+
+func (s *restrictionService) penaltyRules() []penaltyRule {
+	return []penaltyRule{
+		{restriction: model.RestrictionBlockOnline, count: s.penalty.BlockCount, windowDays: s.penalty.BlockWindowDays, days: s.penalty.BlockDays},
+		{restriction: model.RestrictionFullPrepayment, count: s.penalty.PrepayCount, windowDays: s.penalty.PrepayWindowDays, days: s.penalty.PrepayDays},
+	}
+}
+
+func (s *restrictionService) ImposeRestriction(payload dto.ImposeRestrictionRequest) (model.Restriction, error) {
+	restrictionType := model.RestrictionType(payload.Type)
+	if !restrictionType.IsValid() {
+		return model.Restriction{}, errors.New("invalid restriction, type must be block_online or full_prepayment")
+	}
+
+	if payload.Days < 1 {
+		return model.Restriction{}, errors.New("invalid restriction, days must be greater than 0")
+	}
+
+	customer, err := s.userServ.FindUserById(payload.CustomerId)
+	if err != nil {
+		return model.Restriction{}, errors.New("customer not found")
+	}
+
+	if customer.Role != "customer" && customer.Role != "guest" {
+		return model.Restriction{}, errors.New("invalid restriction, only customers can be restricted")
+	}
+
+	reason := payload.Reason
+	if reason == "" {
+		reason = "imposed by admin"
+	}
+
+	now := time.Now()
+	restriction, err := s.restrictionRepository.Create(model.Restriction{
+		CustomerId: customer.Id,
+		Type:       restrictionType,
+		Reason:     reason,
+		StartsAt:   now,
+		EndsAt:     now.AddDate(0, 0, payload.Days),
+		ImposedBy:  payload.AdminId,
+	})
+	if err != nil {
+		return model.Restriction{}, err
+	}
+
+	s.audit.Record(model.AuditLog{Actor: payload.AdminId, Action: "restriction.create", EntityType: "restriction", EntityId: restriction.Id}, nil, restriction)
+
+	return restriction, nil
+}
+
+func (s *restrictionService) LiftRestriction(id string, actor string) (model.Restriction, error) {
+	restriction, err := s.restrictionRepository.Lift(id, actor, time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Restriction{}, errors.New("restriction not found or already lifted")
+		}
+		return model.Restriction{}, err
+	}
+
+	before := restriction
+	before.LiftedBy = ""
+	before.LiftedAt = time.Time{}
+
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "restriction.lift", EntityType: "restriction", EntityId: restriction.Id}, before, restriction)
+
+	return restriction, nil
+}
+
+func (s *restrictionService) FindRestrictions(customerId string) ([]model.Restriction, error) {
+	return s.restrictionRepository.FindByCustomer(customerId)
+}
+
+func (s *restrictionService) FindActiveRestrictions(customerId string) ([]model.Restriction, error) {
+	return s.restrictionRepository.FindActive(customerId, time.Now())
+}
+
+// ApplyNoShowPenalties imposes every configured penalty the customer's recent
+// no-shows have earned and that is not already in force.
+func (s *restrictionService) ApplyNoShowPenalties(customerId string) ([]model.Restriction, error) {
+	now := time.Now()
+
+	active, err := s.restrictionRepository.FindActive(customerId, now)
+	if err != nil {
+		return []model.Restriction{}, err
+	}
+
+	var imposed []model.Restriction
+
+	for _, rule := range s.penaltyRules() {
+		if rule.count < 1 || hasRestriction(active, rule.restriction) {
+			continue
+		}
+
+		noShows, err := s.restrictionRepository.CountNoShowsSince(customerId, now.AddDate(0, 0, -rule.windowDays))
+		if err != nil {
+			return imposed, err
+		}
+
+		if noShows < rule.count {
+			continue
+		}
+
+		restriction, err := s.restrictionRepository.Create(model.Restriction{
+			CustomerId: customerId,
+			Type:       rule.restriction,
+			Reason:     fmt.Sprintf("%d no-shows in %d days", noShows, rule.windowDays),
+			StartsAt:   now,
+			EndsAt:     now.AddDate(0, 0, rule.days),
+			ImposedBy:  "system",
+		})
+		if err != nil {
+			return imposed, err
+		}
+
+		s.audit.Record(model.AuditLog{Actor: "system", Action: "restriction.create", EntityType: "restriction", EntityId: restriction.Id}, nil, restriction)
+		imposed = append(imposed, restriction)
+	}
+
+	return imposed, nil
+}
+
+func hasRestriction(restrictions []model.Restriction, restrictionType model.RestrictionType) bool {
+	for _, val := range restrictions {
+		if val.Type == restrictionType {
+			return true
+		}
+	}
+	return false
+}
+
+func NewRestrictionService(restrictionRepository repository.RestrictionRepository, userService UserService, auditService AuditService, penalty config.NoShowPenaltyConfig) RestrictionService {
+	return &restrictionService{
+		restrictionRepository: restrictionRepository,
+		userServ:              userService,
+		audit:                 auditService,
+		penalty:               penalty,
+	}
+}
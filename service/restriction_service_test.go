@@ -0,0 +1,144 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"team2/shuttleslot/config"
+	repomock "team2/shuttleslot/mock/repo_mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var penaltyConfig = config.NoShowPenaltyConfig{
+	BlockCount:       3,
+	BlockWindowDays:  60,
+	BlockDays:        14,
+	PrepayCount:      2,
+	PrepayWindowDays: 60,
+	PrepayDays:       30,
+}
+
+type RestrictionServiceTestSuite struct {
+	suite.Suite
+	repoMock *repomock.RestrictionRepositoryMock
+	uS       *servicemock.UserServiceMock
+	audit    *servicemock.AuditServiceMock
+	rS       RestrictionService
+}
+
+func (suite *RestrictionServiceTestSuite) SetupTest() {
+	suite.repoMock = new(repomock.RestrictionRepositoryMock)
+	suite.uS = new(servicemock.UserServiceMock)
+	suite.audit = new(servicemock.AuditServiceMock)
+	suite.audit.On("Record", mock.Anything, mock.Anything, mock.Anything).Maybe()
+	suite.rS = NewRestrictionService(suite.repoMock, suite.uS, suite.audit, penaltyConfig)
+}
+
+func TestRestrictionServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(RestrictionServiceTestSuite))
+}
+
+func (suite *RestrictionServiceTestSuite) TestImposeRestriction_Success() {
+	suite.uS.On("FindUserById", "customer_1").Return(model.User{Id: "customer_1", Role: "customer"}, nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(r model.Restriction) bool {
+		return r.Type == model.RestrictionFullPrepayment && r.ImposedBy == "admin_1" && r.Reason == "imposed by admin" && r.EndsAt.Sub(r.StartsAt) == 7*24*time.Hour
+	})).Return(model.Restriction{Id: "restriction_1"}, nil)
+
+	restriction, err := suite.rS.ImposeRestriction(dto.ImposeRestrictionRequest{CustomerId: "customer_1", Type: "full_prepayment", Days: 7, AdminId: "admin_1"})
+
+	suite.NoError(err)
+	suite.Equal("restriction_1", restriction.Id)
+	suite.audit.AssertCalled(suite.T(), "Record", mock.MatchedBy(func(e model.AuditLog) bool {
+		return e.Action == "restriction.create" && e.Actor == "admin_1"
+	}), mock.Anything, mock.Anything)
+}
+
+func (suite *RestrictionServiceTestSuite) TestImposeRestriction_InvalidType() {
+	_, err := suite.rS.ImposeRestriction(dto.ImposeRestrictionRequest{CustomerId: "customer_1", Type: "ban", Days: 7})
+
+	suite.EqualError(err, "invalid restriction, type must be block_online or full_prepayment")
+}
+
+func (suite *RestrictionServiceTestSuite) TestImposeRestriction_InvalidDays() {
+	_, err := suite.rS.ImposeRestriction(dto.ImposeRestrictionRequest{CustomerId: "customer_1", Type: "block_online"})
+
+	suite.EqualError(err, "invalid restriction, days must be greater than 0")
+}
+
+func (suite *RestrictionServiceTestSuite) TestImposeRestriction_NotCustomer() {
+	suite.uS.On("FindUserById", "employee_1").Return(model.User{Id: "employee_1", Role: "employee"}, nil)
+
+	_, err := suite.rS.ImposeRestriction(dto.ImposeRestrictionRequest{CustomerId: "employee_1", Type: "block_online", Days: 7})
+
+	suite.EqualError(err, "invalid restriction, only customers can be restricted")
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *RestrictionServiceTestSuite) TestImposeRestriction_CustomerNotFound() {
+	suite.uS.On("FindUserById", "unknown").Return(model.User{}, errors.New("user not found"))
+
+	_, err := suite.rS.ImposeRestriction(dto.ImposeRestrictionRequest{CustomerId: "unknown", Type: "block_online", Days: 7})
+
+	suite.EqualError(err, "customer not found")
+}
+
+func (suite *RestrictionServiceTestSuite) TestLiftRestriction_Success() {
+	lifted := model.Restriction{Id: "restriction_1", LiftedBy: "admin_1", LiftedAt: time.Now()}
+	suite.repoMock.On("Lift", "restriction_1", "admin_1", mock.Anything).Return(lifted, nil)
+
+	restriction, err := suite.rS.LiftRestriction("restriction_1", "admin_1")
+
+	suite.NoError(err)
+	suite.Equal("admin_1", restriction.LiftedBy)
+}
+
+func (suite *RestrictionServiceTestSuite) TestLiftRestriction_NotFound() {
+	suite.repoMock.On("Lift", "restriction_1", "admin_1", mock.Anything).Return(model.Restriction{}, sql.ErrNoRows)
+
+	_, err := suite.rS.LiftRestriction("restriction_1", "admin_1")
+
+	suite.EqualError(err, "restriction not found or already lifted")
+}
+
+func (suite *RestrictionServiceTestSuite) TestApplyNoShowPenalties_ImposesEarnedRules() {
+	suite.repoMock.On("FindActive", "customer_1", mock.Anything).Return([]model.Restriction{}, nil)
+	suite.repoMock.On("CountNoShowsSince", "customer_1", mock.Anything).Return(2, nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(r model.Restriction) bool {
+		return r.Type == model.RestrictionFullPrepayment && r.ImposedBy == "system" && r.Reason == "2 no-shows in 60 days"
+	})).Return(model.Restriction{Id: "restriction_1", Type: model.RestrictionFullPrepayment}, nil)
+
+	imposed, err := suite.rS.ApplyNoShowPenalties("customer_1")
+
+	suite.NoError(err)
+	suite.Equal(1, len(imposed))
+	suite.repoMock.AssertNumberOfCalls(suite.T(), "Create", 1)
+}
+
+func (suite *RestrictionServiceTestSuite) TestApplyNoShowPenalties_SkipsRulesInForce() {
+	active := []model.Restriction{{Id: "restriction_1", Type: model.RestrictionFullPrepayment}}
+	suite.repoMock.On("FindActive", "customer_1", mock.Anything).Return(active, nil)
+	suite.repoMock.On("CountNoShowsSince", "customer_1", mock.Anything).Return(2, nil).Once()
+
+	imposed, err := suite.rS.ApplyNoShowPenalties("customer_1")
+
+	suite.NoError(err)
+	suite.Empty(imposed)
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *RestrictionServiceTestSuite) TestApplyNoShowPenalties_Disabled() {
+	suite.rS = NewRestrictionService(suite.repoMock, suite.uS, suite.audit, config.NoShowPenaltyConfig{})
+	suite.repoMock.On("FindActive", "customer_1", mock.Anything).Return([]model.Restriction{}, nil)
+
+	imposed, err := suite.rS.ApplyNoShowPenalties("customer_1")
+
+	suite.NoError(err)
+	suite.Empty(imposed)
+	suite.repoMock.AssertNotCalled(suite.T(), "CountNoShowsSince", mock.Anything, mock.Anything)
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
 	"team2/shuttleslot/repository"
@@ -9,21 +11,24 @@ import (
 )
 
 type UserService interface {
-	CreateAdmin(payload model.User) (model.User, error)
+	CreateAdmin(payload model.User, actor string) (model.User, error)
 	CreateCustomer(payload model.User) (model.User, error)
-	CreateEmployee(payload model.User) (model.User, error)
+	CreateEmployee(payload model.User, actor string) (model.User, error)
+	FindOrCreateGuest(payload model.User, actor string) (model.User, error)
 	FindUserByRole(role string, page int, size int) ([]model.User, dto.Paginate, error)
 	FindUserByUsername(username string) (model.User, error)
 	FindUserById(id string) (model.User, error)
-	UpdatedUser(id string, payload model.User) (model.User, error)
-	DeletedUser(id string) error
+	UpdatedUser(id string, payload model.User, actor string) (model.User, error)
+	DeletedUser(id string, actor string) error
 	Login(payload dto.LoginRequest) (dto.LoginResponse, error)
+	ClaimGuestBookings(customerId string, token string) (int, error)
 }
 
 type userService struct {
 	userRepository repository.UserRepository
 	auth           AuthService
 	util           util.UtilInterface
+	audit          AuditService
 }
 
 // Login implements UserService.
@@ -47,7 +52,7 @@ func (s *userService) Login(payload dto.LoginRequest) (dto.LoginResponse, error)
 }
 
 // CreateAdmin implements UserService.
-func (s *userService) CreateAdmin(payload model.User) (model.User, error) {
+func (s *userService) CreateAdmin(payload model.User, actor string) (model.User, error) {
 	passwordHash, err := s.util.EncryptPassword(payload.Password)
 	if err != nil {
 		return model.User{}, err
@@ -55,7 +60,14 @@ func (s *userService) CreateAdmin(payload model.User) (model.User, error) {
 	payload.Password = passwordHash
 	payload.Role = "admin"
 
-	return s.userRepository.CreateAdmin(payload)
+	user, err := s.userRepository.CreateAdmin(payload)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "user.create", EntityType: "user", EntityId: user.Id}, nil, withoutPassword(user))
+
+	return user, nil
 }
 
 // CreateCustomer implements UserService.
@@ -67,11 +79,42 @@ func (s *userService) CreateCustomer(payload model.User) (model.User, error) {
 	payload.Password = passwordHash
 	payload.Role = "customer"
 
-	return s.userRepository.CreateCustomer(payload)
+	user, err := s.userRepository.CreateCustomer(payload)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	// Customers register themselves, so the new account is its own actor.
+	s.audit.Record(model.AuditLog{Actor: user.Id, Action: "user.create", EntityType: "user", EntityId: user.Id}, nil, withoutPassword(user))
+
+	return user, nil
+}
+
+// ClaimGuestBookings implements UserService. The lookup token a guest received
+// for one of their bookings, together with a phone number or email the guest
+// shares with the customer, proves the guest is the customer, so everything the
+// guest booked moves onto the customer's account.
+func (s *userService) ClaimGuestBookings(customerId string, token string) (int, error) {
+	bookingId, err := s.auth.VerifyLookupToken(token)
+	if err != nil {
+		return 0, err
+	}
+
+	claimed, err := s.userRepository.ClaimGuest(bookingId, customerId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errors.New("cannot claim, booking is not a guest booking under your phone number or email, or was already claimed")
+		}
+		return 0, err
+	}
+
+	s.audit.Record(model.AuditLog{Actor: customerId, Action: "user.claim", EntityType: "user", EntityId: customerId, Detail: fmt.Sprintf("claimed %d guest bookings with booking %s", claimed, bookingId)}, nil, nil)
+
+	return claimed, nil
 }
 
 // CreateEmployee implements UserService.
-func (s *userService) CreateEmployee(payload model.User) (model.User, error) {
+func (s *userService) CreateEmployee(payload model.User, actor string) (model.User, error) {
 	passwordHash, err := s.util.EncryptPassword(payload.Password)
 	if err != nil {
 		return model.User{}, err
@@ -79,7 +122,39 @@ func (s *userService) CreateEmployee(payload model.User) (model.User, error) {
 	payload.Password = passwordHash
 	payload.Role = "employee"
 
-	return s.userRepository.CreateEmployee(payload)
+	user, err := s.userRepository.CreateEmployee(payload)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "user.create", EntityType: "user", EntityId: user.Id}, nil, withoutPassword(user))
+
+	return user, nil
+}
+
+// FindOrCreateGuest implements UserService. The customer is looked up by phone
+// number or email, and a guest without a password is created when there is none.
+func (s *userService) FindOrCreateGuest(payload model.User, actor string) (model.User, error) {
+	user, err := s.userRepository.FindUserByContact(payload.PhoneNumber, payload.Email)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, err
+	}
+
+	payload.Username = "guest_" + payload.PhoneNumber
+	payload.Password = ""
+	payload.Role = "guest"
+
+	user, err = s.userRepository.CreateCustomer(payload)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "user.create", EntityType: "user", EntityId: user.Id}, nil, withoutPassword(user))
+
+	return user, nil
 }
 
 // FindUserByRole implements UserService.
@@ -106,7 +181,7 @@ func (s *userService) FindUserById(id string) (model.User, error) {
 }
 
 // UpdateUser implements UserService.
-func (s *userService) UpdatedUser(id string, payload model.User) (model.User, error) {
+func (s *userService) UpdatedUser(id string, payload model.User, actor string) (model.User, error) {
 
 	user, err := s.userRepository.FindUserById(id)
 	if err != nil {
@@ -142,23 +217,44 @@ func (s *userService) UpdatedUser(id string, payload model.User) (model.User, er
 
 	payload.Password = passwordHash
 
-	return s.userRepository.UpdateUser(id, payload)
+	updated, err := s.userRepository.UpdateUser(id, payload)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "user.update", EntityType: "user", EntityId: id}, withoutPassword(user), withoutPassword(updated))
+
+	return updated, nil
 }
 
 // DeleteUser implements UserService.
-func (s *userService) DeletedUser(id string) error {
-	_, err := s.userRepository.FindUserById(id)
+func (s *userService) DeletedUser(id string, actor string) error {
+	user, err := s.userRepository.FindUserById(id)
 	if err != nil {
 		return errors.New("user not found")
 	}
 
-	return s.userRepository.DeleteUser(id)
+	err = s.userRepository.DeleteUser(id)
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(model.AuditLog{Actor: actor, Action: "user.delete", EntityType: "user", EntityId: id}, withoutPassword(user), nil)
+
+	return nil
+}
+
+// withoutPassword keeps password hashes out of the audit log.
+func withoutPassword(user model.User) model.User {
+	user.Password = ""
+	return user
 }
 
-func NewUserService(userRepository repository.UserRepository, authService AuthService, util util.UtilInterface) UserService {
+func NewUserService(userRepository repository.UserRepository, authService AuthService, util util.UtilInterface, auditService AuditService) UserService {
 	return &userService{
 		userRepository: userRepository,
 		auth:           authService,
 		util:           util,
+		audit:          auditService,
 	}
 }
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
 	authmock "team2/shuttleslot/mock/auth_mock"
 	repomock "team2/shuttleslot/mock/repo_mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
 	utilmock "team2/shuttleslot/mock/util_mock"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
@@ -38,13 +40,16 @@ type UserServiceTestSuite struct {
 	uS           UserService
 	aU           *authmock.AuthServiceMock
 	uM           *utilmock.MockUtil
+	auditMock    *servicemock.AuditServiceMock
 }
 
 func (suite *UserServiceTestSuite) SetupTest() {
 	suite.repoUserMock = new(repomock.UserRepositoryMock)
 	suite.uM = new(utilmock.MockUtil)
 	suite.aU = new(authmock.AuthServiceMock)
-	suite.uS = NewUserService(suite.repoUserMock, suite.aU, suite.uM)
+	suite.auditMock = new(servicemock.AuditServiceMock)
+	suite.auditMock.On("Record", mock.Anything, mock.Anything, mock.Anything).Maybe()
+	suite.uS = NewUserService(suite.repoUserMock, suite.aU, suite.uM, suite.auditMock)
 }
 
 func TestUserServiceTestSuite(t *testing.T) {
@@ -97,7 +102,7 @@ func (suite *UserServiceTestSuite) TestCreateAdmin_Success() {
 	suite.uM.On("EncryptPassword", mock.AnythingOfType("string")).Return(mockUser.Password, nil)
 	suite.repoUserMock.On("CreateAdmin", mock.Anything).Return(mockUser, nil)
 
-	createdUser, err := suite.uS.CreateAdmin(mockUser)
+	createdUser, err := suite.uS.CreateAdmin(mockUser, "admin_id")
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), mockUser.Password, createdUser.Password)
@@ -108,7 +113,7 @@ func (suite *UserServiceTestSuite) TestCreateAdmin_Fail() {
 	suite.uM.On("EncryptPassword", mock.AnythingOfType("string")).Return("", errors.New("err"))
 	suite.repoUserMock.On("CreateAdmin", mock.Anything).Return(mockUser, nil)
 
-	_, err := suite.uS.CreateAdmin(model.User{})
+	_, err := suite.uS.CreateAdmin(model.User{}, "admin_id")
 	assert.Error(suite.T(), err)
 }
 
@@ -138,7 +143,7 @@ func (suite *UserServiceTestSuite) TestCreateEmployee_Success() {
 	suite.uM.On("EncryptPassword", mock.AnythingOfType("string")).Return(mockUser.Password, nil)
 	suite.repoUserMock.On("CreateEmployee", mock.Anything).Return(mockUser, nil)
 
-	createdUser, err := suite.uS.CreateEmployee(mockUser)
+	createdUser, err := suite.uS.CreateEmployee(mockUser, "admin_id")
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), mockUser.Password, createdUser.Password)
@@ -149,11 +154,44 @@ func (suite *UserServiceTestSuite) TestCreateEmployee_Fail() {
 	suite.uM.On("EncryptPassword", mock.AnythingOfType("string")).Return("", errors.New("err"))
 	suite.repoUserMock.On("CreateEmployee", mock.Anything).Return(mockUser, nil)
 
-	_, err := suite.uS.CreateEmployee(mockUser)
+	_, err := suite.uS.CreateEmployee(mockUser, "admin_id")
 
 	assert.Error(suite.T(), err)
 }
 
+func (suite *UserServiceTestSuite) TestFindOrCreateGuest_ExistingCustomer() {
+	suite.repoUserMock.On("FindUserByContact", "123", "").Return(mockUser, nil)
+
+	user, err := suite.uS.FindOrCreateGuest(model.User{Name: "lala", PhoneNumber: "123"}, "employee_id")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), mockUser, user)
+	suite.repoUserMock.AssertNotCalled(suite.T(), "CreateCustomer", mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestFindOrCreateGuest_CreatesGuest() {
+	guest := model.User{Id: "2", Name: "walk in", PhoneNumber: "0812", Username: "guest_0812", Role: "guest"}
+	suite.repoUserMock.On("FindUserByContact", "0812", "").Return(model.User{}, sql.ErrNoRows)
+	suite.repoUserMock.On("CreateCustomer", model.User{Name: "walk in", PhoneNumber: "0812", Username: "guest_0812", Role: "guest"}).Return(guest, nil)
+
+	user, err := suite.uS.FindOrCreateGuest(model.User{Name: "walk in", PhoneNumber: "0812"}, "employee_id")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), guest, user)
+	suite.auditMock.AssertCalled(suite.T(), "Record", mock.MatchedBy(func(entry model.AuditLog) bool {
+		return entry.Actor == "employee_id" && entry.Action == "user.create" && entry.EntityId == "2"
+	}), nil, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestFindOrCreateGuest_LookupError() {
+	suite.repoUserMock.On("FindUserByContact", "0812", "").Return(model.User{}, errors.New("connection refused"))
+
+	_, err := suite.uS.FindOrCreateGuest(model.User{Name: "walk in", PhoneNumber: "0812"}, "employee_id")
+
+	assert.EqualError(suite.T(), err, "connection refused")
+	suite.repoUserMock.AssertNotCalled(suite.T(), "CreateCustomer", mock.Anything)
+}
+
 func (suite *UserServiceTestSuite) TestFindUserByRole_Success() {
 	page := 1
 	size := 10
@@ -231,7 +269,7 @@ func (suite *UserServiceTestSuite) TestUpdateUser_Success() {
 	suite.uM.On("EncryptPassword", mockUser.Password).Return(mockUser.Password, nil)
 	suite.repoUserMock.On("UpdateUser", "user_id", mockUser).Return(mockUser, nil)
 
-	returnedUser, err := suite.uS.UpdatedUser("user_id", mockUser)
+	returnedUser, err := suite.uS.UpdatedUser("user_id", mockUser, "admin_id")
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), returnedUser)
@@ -241,7 +279,7 @@ func (suite *UserServiceTestSuite) TestUpdateUser_Success() {
 func (suite *UserServiceTestSuite) TestUpdateUser_Fail() {
 	suite.repoUserMock.On("FindUserById", mockUser.Id).Return(model.User{}, errors.New("user not found"))
 
-	updatedUser, err := suite.uS.UpdatedUser(mockUser.Id, mockUser)
+	updatedUser, err := suite.uS.UpdatedUser(mockUser.Id, mockUser, "admin_id")
 
 	assert.Error(suite.T(), err)
 	assert.EqualError(suite.T(), err, "user not found")
@@ -256,7 +294,7 @@ func (suite *UserServiceTestSuite) TestUpdatedUser_Fail_EncryptPassword() {
 	suite.repoUserMock.On("FindUserById", id).Return(mockUser, nil)
 	suite.uM.On("EncryptPassword", payload.Password).Return("", errors.New("error in encrypting password"))
 
-	result, err := suite.uS.UpdatedUser(id, payload)
+	result, err := suite.uS.UpdatedUser(id, payload, "admin_id")
 
 	assert.Error(suite.T(), err)
 	assert.EqualError(suite.T(), err, "error in encrypting password")
@@ -267,15 +305,90 @@ func (suite *UserServiceTestSuite) TestDeleteUser_Success() {
 	suite.repoUserMock.On("FindUserById", mockUser.Id).Return(mockUser, nil)
 	suite.repoUserMock.On("DeleteUser", mockUser.Id).Return(nil)
 
-	err := suite.uS.DeletedUser(mockUser.Id)
+	err := suite.uS.DeletedUser(mockUser.Id, "admin_id")
+
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *UserServiceTestSuite) TestUpdateUser_AuditedWithoutPassword() {
+	suite.repoUserMock.On("FindUserById", "user_id").Return(mockUser, nil)
+	suite.uM.On("EncryptPassword", mockUser.Password).Return(mockUser.Password, nil)
+	suite.repoUserMock.On("UpdateUser", "user_id", mockUser).Return(mockUser, nil)
+
+	_, err := suite.uS.UpdatedUser("user_id", mockUser, "admin_id")
+
+	assert.NoError(suite.T(), err)
+	withoutHash := mockUser
+	withoutHash.Password = ""
+	suite.auditMock.AssertCalled(suite.T(), "Record", model.AuditLog{Actor: "admin_id", Action: "user.update", EntityType: "user", EntityId: "user_id"}, withoutHash, withoutHash)
+}
+
+func (suite *UserServiceTestSuite) TestCreateCustomer_AuditedAsSelf() {
+	suite.uM.On("EncryptPassword", mockUser.Password).Return("hashed", nil)
+	suite.repoUserMock.On("CreateCustomer", mock.Anything).Return(mockUser, nil)
+
+	_, err := suite.uS.CreateCustomer(mockUser)
+
+	assert.NoError(suite.T(), err)
+	suite.auditMock.AssertCalled(suite.T(), "Record", model.AuditLog{Actor: mockUser.Id, Action: "user.create", EntityType: "user", EntityId: mockUser.Id}, nil, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestCreateCustomer_DoesNotClaimGuests() {
+	suite.uM.On("EncryptPassword", mockUser.Password).Return("hashed", nil)
+	suite.repoUserMock.On("CreateCustomer", mock.Anything).Return(mockUser, nil)
+
+	_, err := suite.uS.CreateCustomer(mockUser)
 
 	assert.NoError(suite.T(), err)
+	suite.repoUserMock.AssertNotCalled(suite.T(), "ClaimGuest", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestClaimGuestBookings_Success() {
+	suite.aU.On("VerifyLookupToken", "lookup-token").Return("booking_1", nil)
+	suite.repoUserMock.On("ClaimGuest", "booking_1", mockUser.Id).Return(2, nil)
+
+	claimed, err := suite.uS.ClaimGuestBookings(mockUser.Id, "lookup-token")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, claimed)
+	suite.auditMock.AssertCalled(suite.T(), "Record", model.AuditLog{Actor: mockUser.Id, Action: "user.claim", EntityType: "user", EntityId: mockUser.Id, Detail: "claimed 2 guest bookings with booking booking_1"}, nil, nil)
+}
+
+func (suite *UserServiceTestSuite) TestClaimGuestBookings_InvalidToken() {
+	suite.aU.On("VerifyLookupToken", "forged").Return("", errors.New("forbidden, invalid or expired lookup token"))
+
+	_, err := suite.uS.ClaimGuestBookings(mockUser.Id, "forged")
+
+	assert.EqualError(suite.T(), err, "forbidden, invalid or expired lookup token")
+	suite.repoUserMock.AssertNotCalled(suite.T(), "ClaimGuest", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestClaimGuestBookings_DifferentContact() {
+	suite.aU.On("VerifyLookupToken", "other-token").Return("booking_2", nil)
+	suite.repoUserMock.On("ClaimGuest", "booking_2", mockUser.Id).Return(0, sql.ErrNoRows)
+
+	claimed, err := suite.uS.ClaimGuestBookings(mockUser.Id, "other-token")
+
+	assert.EqualError(suite.T(), err, "cannot claim, booking is not a guest booking under your phone number or email, or was already claimed")
+	assert.Equal(suite.T(), 0, claimed)
+	suite.auditMock.AssertNotCalled(suite.T(), "Record", mock.MatchedBy(func(entry model.AuditLog) bool {
+		return entry.Action == "user.claim"
+	}), mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestClaimGuestBookings_NotAGuestBooking() {
+	suite.aU.On("VerifyLookupToken", "lookup-token").Return("booking_1", nil)
+	suite.repoUserMock.On("ClaimGuest", "booking_1", mockUser.Id).Return(0, sql.ErrNoRows)
+
+	_, err := suite.uS.ClaimGuestBookings(mockUser.Id, "lookup-token")
+
+	assert.EqualError(suite.T(), err, "cannot claim, booking is not a guest booking under your phone number or email, or was already claimed")
 }
 
 func (suite *UserServiceTestSuite) TestDeleteUser_Fail() {
 	suite.repoUserMock.On("FindUserById", mockUser.Id).Return(model.User{}, errors.New("user not found"))
 
-	err := suite.uS.DeletedUser(mockUser.Id)
+	err := suite.uS.DeletedUser(mockUser.Id, "admin_id")
 
 	assert.Error(suite.T(), err)
 	assert.EqualError(suite.T(), err, "user not found")
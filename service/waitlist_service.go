@@ -0,0 +1,212 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"team2/shuttleslot/config"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"team2/shuttleslot/util"
+	"time"
+)
+
+type WaitlistService interface {
+	JoinWaitlist(payload dto.JoinWaitlistRequest) (model.WaitlistEntry, error)
+	FindWaitlist(customerId string) ([]model.WaitlistEntry, error)
+	LeaveWaitlist(customerId string, entryId string) error
+	OfferSlot(released model.Booking) (model.WaitlistEntry, error)
+	ClaimOffer(customerId string, entryId string) (model.WaitlistEntry, error)
+	FulfilOffer(entryId string, bookingId string) error
+	ExpireOffers(now time.Time) (int, error)
+}
+
+type waitlistService struct {
+	waitlistRepository repository.WaitlistRepository
+	courtServ          CourtService
+	restrictionServ    RestrictionService
+	offerTime          time.Duration
+}
+
+func (s *waitlistService) JoinWaitlist(payload dto.JoinWaitlistRequest) (model.WaitlistEntry, error) {
+	if payload.Hour < 1 {
+		return model.WaitlistEntry{}, errors.New("invalid waitlist, hour must be greater than 0")
+	}
+
+	entry := model.WaitlistEntry{
+		CustomerId:  payload.CustomerId,
+		CourtId:     payload.CourtId,
+		BookingDate: util.StringToDate(payload.BookingDate),
+		StartTime:   util.StringToTime(payload.StartTime),
+		EndTime:     util.StringToTime(payload.EndTime),
+		Hour:        payload.Hour,
+	}
+
+	if entry.StartTime.Add(time.Hour * time.Duration(entry.Hour)).After(entry.EndTime) {
+		return model.WaitlistEntry{}, errors.New("invalid waitlist, the window from startTime to endTime is shorter than the requested hours")
+	}
+
+	if entry.CourtId != "" {
+		_, err := s.courtServ.FindCourtById(entry.CourtId)
+		if err != nil {
+			return model.WaitlistEntry{}, errors.New("court not found")
+		}
+	}
+
+	return s.waitlistRepository.Create(entry)
+}
+
+func (s *waitlistService) FindWaitlist(customerId string) ([]model.WaitlistEntry, error) {
+	return s.waitlistRepository.FindByCustomer(customerId)
+}
+
+func (s *waitlistService) LeaveWaitlist(customerId string, entryId string) error {
+	entry, err := s.findOwnEntry(customerId, entryId)
+	if err != nil {
+		return err
+	}
+
+	err = s.waitlistRepository.Leave(entry.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("cannot leave the waitlist, entry is already %s", entry.Status)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *waitlistService) findOwnEntry(customerId string, entryId string) (model.WaitlistEntry, error) {
+	entry, err := s.waitlistRepository.FindById(entryId)
+	if err != nil {
+		return model.WaitlistEntry{}, errors.New("waitlist entry not found")
+	}
+
+	if entry.CustomerId != customerId {
+		return model.WaitlistEntry{}, errors.New("forbidden, this waitlist entry belongs to another customer")
+	}
+
+	return entry, nil
+}
+
+// OfferSlot offers a freed slot to the first waiting customer whose window it
+// fits, skipping whoever freed it and customers blocked from booking online.
+// Part of the slot that was booked, closed or offered again in the meantime is
+// not offered. It returns an empty entry when nobody is offered the slot.
+func (s *waitlistService) OfferSlot(released model.Booking) (model.WaitlistEntry, error) {
+	entries, err := s.waitlistRepository.FindWaiting(released.BookingDate, released.Court.Id)
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+
+	now := time.Now()
+
+	for _, entry := range entries {
+		if entry.CustomerId == released.Customer.Id {
+			continue
+		}
+
+		start, ok := entry.SlotIn(released.StartTime, released.EndTime)
+		if !ok || util.CombineDateTime(released.BookingDate, start).Before(util.CombineDateTime(now, now)) {
+			continue
+		}
+
+		restrictions, err := s.restrictionServ.FindActiveRestrictions(entry.CustomerId)
+		if err != nil {
+			return model.WaitlistEntry{}, err
+		}
+
+		if hasRestriction(restrictions, model.RestrictionBlockOnline) {
+			continue
+		}
+
+		entry.Status = model.WaitlistOffered
+		entry.OfferedCourtId = released.Court.Id
+		entry.OfferedStartTime = start
+		entry.OfferExpiresAt = now.Add(s.offerTime)
+
+		err = s.waitlistRepository.Offer(entry)
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, repository.ErrBookingConflict) || errors.Is(err, repository.ErrCourtClosed) {
+			continue
+		}
+		if err != nil {
+			return model.WaitlistEntry{}, err
+		}
+
+		return entry, nil
+	}
+
+	return model.WaitlistEntry{}, nil
+}
+
+// ClaimOffer returns the customer's entry when it holds an offer that can
+// still be taken up.
+func (s *waitlistService) ClaimOffer(customerId string, entryId string) (model.WaitlistEntry, error) {
+	entry, err := s.findOwnEntry(customerId, entryId)
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+
+	if entry.Status != model.WaitlistOffered {
+		return model.WaitlistEntry{}, fmt.Errorf("cannot accept, waitlist entry is %s and has no open offer", entry.Status)
+	}
+
+	if entry.OfferExpiresAt.Before(time.Now()) {
+		return model.WaitlistEntry{}, errors.New("cannot accept, the offer has expired")
+	}
+
+	return entry, nil
+}
+
+func (s *waitlistService) FulfilOffer(entryId string, bookingId string) error {
+	return s.waitlistRepository.Fulfil(entryId, bookingId)
+}
+
+// ExpireOffers closes every offer that was not taken up before now and passes
+// the slot on to the next customer in line, as long as it is still free.
+func (s *waitlistService) ExpireOffers(now time.Time) (int, error) {
+	entries, err := s.waitlistRepository.FindExpiredOffers(now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	var errs []error
+
+	for _, entry := range entries {
+		err = s.waitlistRepository.Expire(entry.Id)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		expired++
+
+		slot := model.Booking{
+			Court:       model.Court{Id: entry.OfferedCourtId},
+			Customer:    model.User{Id: entry.CustomerId},
+			BookingDate: entry.BookingDate,
+			StartTime:   entry.OfferedStartTime,
+			EndTime:     entry.OfferedStartTime.Add(time.Hour * time.Duration(entry.Hour)),
+		}
+
+		_, err = s.OfferSlot(slot)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return expired, errors.Join(errs...)
+}
+
+func NewWaitlistService(waitlistRepository repository.WaitlistRepository, courtService CourtService, restrictionService RestrictionService, waitlistConfig config.WaitlistConfig) WaitlistService {
+	return &waitlistService{
+		waitlistRepository: waitlistRepository,
+		courtServ:          courtService,
+		restrictionServ:    restrictionService,
+		offerTime:          waitlistConfig.OfferTime,
+	}
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"team2/shuttleslot/config"
+	repomock "team2/shuttleslot/mock/repo_mock"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"team2/shuttleslot/model"
+	"team2/shuttleslot/model/dto"
+	"team2/shuttleslot/repository"
+	"team2/shuttleslot/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var waitlistDate = util.StringToDate("01-10-2030")
+
+func waitingEntry(id string, customerId string, start string, end string, hour int) model.WaitlistEntry {
+	return model.WaitlistEntry{
+		Id:          id,
+		CustomerId:  customerId,
+		BookingDate: waitlistDate,
+		StartTime:   util.StringToTime(start),
+		EndTime:     util.StringToTime(end),
+		Hour:        hour,
+		Status:      model.WaitlistWaiting,
+	}
+}
+
+var releasedBooking = model.Booking{
+	Id:          "booking_1",
+	Customer:    model.User{Id: "customer_1"},
+	Court:       model.Court{Id: "court_1"},
+	BookingDate: waitlistDate,
+	StartTime:   util.StringToTime("10:00:00"),
+	EndTime:     util.StringToTime("12:00:00"),
+}
+
+type WaitlistServiceTestSuite struct {
+	suite.Suite
+	repoMock *repomock.WaitlistRepositoryMock
+	cS       *servicemock.CourtServiceMock
+	restrict *servicemock.RestrictionServiceMock
+	wS       WaitlistService
+}
+
+func (suite *WaitlistServiceTestSuite) SetupTest() {
+	suite.repoMock = new(repomock.WaitlistRepositoryMock)
+	suite.cS = new(servicemock.CourtServiceMock)
+	suite.restrict = new(servicemock.RestrictionServiceMock)
+	suite.wS = NewWaitlistService(suite.repoMock, suite.cS, suite.restrict, config.WaitlistConfig{OfferTime: 30 * time.Minute})
+}
+
+func TestWaitlistServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WaitlistServiceTestSuite))
+}
+
+func (suite *WaitlistServiceTestSuite) TestJoinWaitlist_Success() {
+	suite.cS.On("FindCourtById", "court_1").Return(model.Court{Id: "court_1"}, nil)
+	suite.repoMock.On("Create", mock.MatchedBy(func(e model.WaitlistEntry) bool {
+		return e.CustomerId == "customer_1" && e.CourtId == "court_1" && e.Hour == 2 && e.BookingDate.Equal(waitlistDate)
+	})).Return(model.WaitlistEntry{Id: "entry_1", Status: model.WaitlistWaiting}, nil)
+
+	entry, err := suite.wS.JoinWaitlist(dto.JoinWaitlistRequest{CustomerId: "customer_1", CourtId: "court_1", BookingDate: "01-10-2030", StartTime: "10:00:00", EndTime: "14:00:00", Hour: 2})
+
+	suite.NoError(err)
+	suite.Equal("entry_1", entry.Id)
+}
+
+func (suite *WaitlistServiceTestSuite) TestJoinWaitlist_WindowTooShort() {
+	_, err := suite.wS.JoinWaitlist(dto.JoinWaitlistRequest{CustomerId: "customer_1", BookingDate: "01-10-2030", StartTime: "10:00:00", EndTime: "11:00:00", Hour: 2})
+
+	suite.EqualError(err, "invalid waitlist, the window from startTime to endTime is shorter than the requested hours")
+	suite.repoMock.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *WaitlistServiceTestSuite) TestJoinWaitlist_InvalidHour() {
+	_, err := suite.wS.JoinWaitlist(dto.JoinWaitlistRequest{CustomerId: "customer_1", BookingDate: "01-10-2030", StartTime: "10:00:00", EndTime: "11:00:00"})
+
+	suite.EqualError(err, "invalid waitlist, hour must be greater than 0")
+}
+
+func (suite *WaitlistServiceTestSuite) TestJoinWaitlist_CourtNotFound() {
+	suite.cS.On("FindCourtById", "court_9").Return(model.Court{}, errors.New("court not found"))
+
+	_, err := suite.wS.JoinWaitlist(dto.JoinWaitlistRequest{CustomerId: "customer_1", CourtId: "court_9", BookingDate: "01-10-2030", StartTime: "10:00:00", EndTime: "14:00:00", Hour: 1})
+
+	suite.EqualError(err, "court not found")
+}
+
+func (suite *WaitlistServiceTestSuite) TestLeaveWaitlist_Forbidden() {
+	suite.repoMock.On("FindById", "entry_1").Return(waitingEntry("entry_1", "customer_2", "10:00:00", "12:00:00", 1), nil)
+
+	err := suite.wS.LeaveWaitlist("customer_1", "entry_1")
+
+	suite.EqualError(err, "forbidden, this waitlist entry belongs to another customer")
+	suite.repoMock.AssertNotCalled(suite.T(), "Leave", mock.Anything)
+}
+
+func (suite *WaitlistServiceTestSuite) TestLeaveWaitlist_AlreadyClosed() {
+	entry := waitingEntry("entry_1", "customer_1", "10:00:00", "12:00:00", 1)
+	entry.Status = model.WaitlistExpired
+	suite.repoMock.On("FindById", "entry_1").Return(entry, nil)
+	suite.repoMock.On("Leave", "entry_1").Return(sql.ErrNoRows)
+
+	err := suite.wS.LeaveWaitlist("customer_1", "entry_1")
+
+	suite.EqualError(err, "cannot leave the waitlist, entry is already expired")
+}
+
+func (suite *WaitlistServiceTestSuite) TestOfferSlot_SkipsOwnerBlockedAndNonFitting() {
+	suite.repoMock.On("FindWaiting", waitlistDate, "court_1").Return([]model.WaitlistEntry{
+		waitingEntry("entry_1", "customer_1", "10:00:00", "12:00:00", 1),
+		waitingEntry("entry_2", "customer_2", "10:00:00", "12:00:00", 3),
+		waitingEntry("entry_3", "customer_3", "09:00:00", "12:00:00", 1),
+		waitingEntry("entry_4", "customer_4", "11:00:00", "13:00:00", 1),
+	}, nil)
+	suite.restrict.On("FindActiveRestrictions", "customer_3").Return([]model.Restriction{{Type: model.RestrictionBlockOnline}}, nil)
+	suite.restrict.On("FindActiveRestrictions", "customer_4").Return([]model.Restriction{}, nil)
+	suite.repoMock.On("Offer", mock.MatchedBy(func(e model.WaitlistEntry) bool {
+		return e.Id == "entry_4" && e.OfferedCourtId == "court_1" && e.OfferedStartTime.Equal(util.StringToTime("11:00:00"))
+	})).Return(nil)
+
+	entry, err := suite.wS.OfferSlot(releasedBooking)
+
+	suite.NoError(err)
+	suite.Equal("entry_4", entry.Id)
+	suite.Equal(model.WaitlistOffered, entry.Status)
+	suite.WithinDuration(time.Now().Add(30*time.Minute), entry.OfferExpiresAt, time.Minute)
+	suite.restrict.AssertNotCalled(suite.T(), "FindActiveRestrictions", "customer_1")
+	suite.restrict.AssertNotCalled(suite.T(), "FindActiveRestrictions", "customer_2")
+}
+
+func (suite *WaitlistServiceTestSuite) TestOfferSlot_SlotTakenMeanwhile() {
+	suite.repoMock.On("FindWaiting", waitlistDate, "court_1").Return([]model.WaitlistEntry{
+		waitingEntry("entry_2", "customer_2", "10:00:00", "12:00:00", 1),
+	}, nil)
+	suite.restrict.On("FindActiveRestrictions", "customer_2").Return([]model.Restriction{}, nil)
+	suite.repoMock.On("Offer", mock.Anything).Return(repository.ErrBookingConflict)
+
+	entry, err := suite.wS.OfferSlot(releasedBooking)
+
+	suite.NoError(err)
+	suite.Equal("", entry.Id)
+}
+
+func (suite *WaitlistServiceTestSuite) TestOfferSlot_NobodyWaiting() {
+	suite.repoMock.On("FindWaiting", waitlistDate, "court_1").Return([]model.WaitlistEntry{}, nil)
+
+	entry, err := suite.wS.OfferSlot(releasedBooking)
+
+	suite.NoError(err)
+	suite.Equal("", entry.Id)
+}
+
+func (suite *WaitlistServiceTestSuite) TestOfferSlot_PastSlot() {
+	past := releasedBooking
+	past.BookingDate = util.StringToDate("01-10-2020")
+	suite.repoMock.On("FindWaiting", past.BookingDate, "court_1").Return([]model.WaitlistEntry{
+		waitingEntry("entry_2", "customer_2", "10:00:00", "12:00:00", 1),
+	}, nil)
+
+	entry, err := suite.wS.OfferSlot(past)
+
+	suite.NoError(err)
+	suite.Equal("", entry.Id)
+	suite.repoMock.AssertNotCalled(suite.T(), "Offer", mock.Anything)
+}
+
+func (suite *WaitlistServiceTestSuite) TestClaimOffer_Success() {
+	entry := waitingEntry("entry_1", "customer_1", "10:00:00", "12:00:00", 1)
+	entry.Status = model.WaitlistOffered
+	entry.OfferExpiresAt = time.Now().Add(10 * time.Minute)
+	suite.repoMock.On("FindById", "entry_1").Return(entry, nil)
+
+	claimed, err := suite.wS.ClaimOffer("customer_1", "entry_1")
+
+	suite.NoError(err)
+	suite.Equal("entry_1", claimed.Id)
+}
+
+func (suite *WaitlistServiceTestSuite) TestClaimOffer_Expired() {
+	entry := waitingEntry("entry_1", "customer_1", "10:00:00", "12:00:00", 1)
+	entry.Status = model.WaitlistOffered
+	entry.OfferExpiresAt = time.Now().Add(-time.Minute)
+	suite.repoMock.On("FindById", "entry_1").Return(entry, nil)
+
+	_, err := suite.wS.ClaimOffer("customer_1", "entry_1")
+
+	suite.EqualError(err, "cannot accept, the offer has expired")
+}
+
+func (suite *WaitlistServiceTestSuite) TestClaimOffer_NoOffer() {
+	suite.repoMock.On("FindById", "entry_1").Return(waitingEntry("entry_1", "customer_1", "10:00:00", "12:00:00", 1), nil)
+
+	_, err := suite.wS.ClaimOffer("customer_1", "entry_1")
+
+	suite.EqualError(err, "cannot accept, waitlist entry is waiting and has no open offer")
+}
+
+func (suite *WaitlistServiceTestSuite) TestClaimOffer_NotFound() {
+	suite.repoMock.On("FindById", "entry_9").Return(model.WaitlistEntry{}, sql.ErrNoRows)
+
+	_, err := suite.wS.ClaimOffer("customer_1", "entry_9")
+
+	suite.EqualError(err, "waitlist entry not found")
+}
+
+func (suite *WaitlistServiceTestSuite) TestExpireOffers_PassesSlotOn() {
+	now := time.Now()
+	offered := waitingEntry("entry_1", "customer_1", "10:00:00", "12:00:00", 2)
+	offered.Status = model.WaitlistOffered
+	offered.OfferedCourtId = "court_1"
+	offered.OfferedStartTime = util.StringToTime("10:00:00")
+
+	suite.repoMock.On("FindExpiredOffers", now).Return([]model.WaitlistEntry{offered}, nil)
+	suite.repoMock.On("Expire", "entry_1").Return(nil)
+	suite.repoMock.On("FindWaiting", waitlistDate, "court_1").Return([]model.WaitlistEntry{
+		offered,
+		waitingEntry("entry_2", "customer_2", "10:00:00", "12:00:00", 2),
+	}, nil)
+	suite.restrict.On("FindActiveRestrictions", "customer_2").Return([]model.Restriction{}, nil)
+	suite.repoMock.On("Offer", mock.MatchedBy(func(e model.WaitlistEntry) bool {
+		return e.Id == "entry_2"
+	})).Return(nil)
+
+	expired, err := suite.wS.ExpireOffers(now)
+
+	suite.NoError(err)
+	suite.Equal(1, expired)
+	suite.repoMock.AssertExpectations(suite.T())
+}
+
+func (suite *WaitlistServiceTestSuite) TestExpireOffers_SkipsAlreadyClosed() {
+	now := time.Now()
+	offered := waitingEntry("entry_1", "customer_1", "10:00:00", "12:00:00", 2)
+	offered.Status = model.WaitlistOffered
+
+	suite.repoMock.On("FindExpiredOffers", now).Return([]model.WaitlistEntry{offered}, nil)
+	suite.repoMock.On("Expire", "entry_1").Return(sql.ErrNoRows)
+
+	expired, err := suite.wS.ExpireOffers(now)
+
+	suite.NoError(err)
+	suite.Equal(0, expired)
+	suite.repoMock.AssertNotCalled(suite.T(), "FindWaiting", mock.Anything, mock.Anything)
+}
+
+func (suite *WaitlistServiceTestSuite) TestExpireOffers_SlotRebooked() {
+	now := time.Now()
+	offered := waitingEntry("entry_1", "customer_1", "10:00:00", "12:00:00", 2)
+	offered.Status = model.WaitlistOffered
+	offered.OfferedCourtId = "court_1"
+	offered.OfferedStartTime = util.StringToTime("10:00:00")
+
+	suite.repoMock.On("FindExpiredOffers", now).Return([]model.WaitlistEntry{offered}, nil)
+	suite.repoMock.On("Expire", "entry_1").Return(nil)
+	suite.repoMock.On("FindWaiting", waitlistDate, "court_1").Return([]model.WaitlistEntry{
+		waitingEntry("entry_2", "customer_2", "10:00:00", "12:00:00", 2),
+	}, nil)
+	suite.restrict.On("FindActiveRestrictions", "customer_2").Return([]model.Restriction{}, nil)
+	suite.repoMock.On("Offer", mock.Anything).Return(repository.ErrBookingConflict)
+
+	expired, err := suite.wS.ExpireOffers(now)
+
+	suite.NoError(err)
+	suite.Equal(1, expired)
+}
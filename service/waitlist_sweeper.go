@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"log"
+	"team2/shuttleslot/config"
+	"time"
+)
+
+// WaitlistSweeper periodically closes waitlist offers nobody took up in time,
+// so the slot moves on to the next customer in line.
+type WaitlistSweeper struct {
+	waitlistService WaitlistService
+	interval        time.Duration
+	now             func() time.Time
+}
+
+func (s *WaitlistSweeper) Sweep() (int, error) {
+	return s.waitlistService.ExpireOffers(s.now())
+}
+
+func (s *WaitlistSweeper) Start(ctx context.Context) {
+	runEvery(ctx, s.interval, func() {
+		expired, err := s.Sweep()
+		if err != nil {
+			log.Println("failed to expire waitlist offers:", err)
+		}
+		if expired > 0 {
+			log.Printf("expired %d waitlist offers\n", expired)
+		}
+	})
+}
+
+func NewWaitlistSweeper(waitlistService WaitlistService, waitlistConfig config.WaitlistConfig, now func() time.Time) *WaitlistSweeper {
+	return &WaitlistSweeper{
+		waitlistService: waitlistService,
+		interval:        waitlistConfig.SweepInterval,
+		now:             now,
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"team2/shuttleslot/config"
+	servicemock "team2/shuttleslot/mock/service_mock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type WaitlistSweeperTestSuite struct {
+	suite.Suite
+	wS  *servicemock.WaitlistServiceMock
+	now time.Time
+}
+
+func (suite *WaitlistSweeperTestSuite) SetupTest() {
+	suite.wS = new(servicemock.WaitlistServiceMock)
+	suite.now = time.Date(2030, 10, 1, 10, 0, 0, 0, time.UTC)
+}
+
+func (suite *WaitlistSweeperTestSuite) clock() time.Time {
+	return suite.now
+}
+
+func TestWaitlistSweeperTestSuite(t *testing.T) {
+	suite.Run(t, new(WaitlistSweeperTestSuite))
+}
+
+func (suite *WaitlistSweeperTestSuite) TestSweep_UsesClock() {
+	sweeper := NewWaitlistSweeper(suite.wS, config.WaitlistConfig{SweepInterval: time.Minute}, suite.clock)
+
+	suite.wS.On("ExpireOffers", suite.now).Return(2, nil).Once()
+	expired, err := sweeper.Sweep()
+	suite.NoError(err)
+	suite.Equal(2, expired)
+
+	suite.wS.AssertExpectations(suite.T())
+}
+
+func (suite *WaitlistSweeperTestSuite) TestSweep_Failed() {
+	sweeper := NewWaitlistSweeper(suite.wS, config.WaitlistConfig{SweepInterval: time.Minute}, suite.clock)
+
+	suite.wS.On("ExpireOffers", mock.Anything).Return(0, errors.New("error"))
+
+	_, err := sweeper.Sweep()
+	suite.Error(err)
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"encoding/json"
 	"net/http"
 	"team2/shuttleslot/model"
 	"team2/shuttleslot/model/dto"
@@ -51,6 +52,16 @@ func SendErrorResponse(c *gin.Context, message string, code int) {
 	})
 }
 
+func SendErrorDataResponse(c *gin.Context, message string, data any, code int) {
+	c.JSON(code, dto.SingleResponse{
+		Status: dto.Status{
+			Code:    code,
+			Message: message,
+		},
+		Data: data,
+	})
+}
+
 func SendPaymentResponse(c *gin.Context, data dto.PaymentResponse, code int) {
 	c.JSON(code, dto.PaymentResponse{
 		OrderId:           data.OrderId,
@@ -85,14 +96,39 @@ func (*GetUserByRoleResponse) FromModel(payload model.User) *GetUserByRoleRespon
 }
 
 type CreateBookingResponse struct {
-	BookingId    string          `json:"bookingId"`
-	BookingDate  string          `json:"bookingDate"`
-	CustomerName string          `json:"customerName"`
-	CourtName    string          `json:"courtName"`
-	StartTime    string          `json:"startTime"`
-	EndTime      string          `json:"endTime"`
-	TotalPayment int             `json:"totalPayment"`
-	Payment      PaymentResponse `json:"payment"`
+	BookingId      string              `json:"bookingId"`
+	BookingDate    string              `json:"bookingDate"`
+	CustomerName   string              `json:"customerName"`
+	CourtName      string              `json:"courtName"`
+	StartTime      string              `json:"startTime"`
+	EndTime        string              `json:"endTime"`
+	TotalPayment   int                 `json:"totalPayment"`
+	Discount       int                 `json:"discount"`
+	Status         string              `json:"status"`
+	PriceBreakdown []PriceItemResponse `json:"priceBreakdown"`
+	Payment        PaymentResponse     `json:"payment"`
+}
+
+type PriceItemResponse struct {
+	Name      string `json:"name"`
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Price     int    `json:"price"`
+}
+
+func priceBreakdownResponse(items []model.PriceItem) []PriceItemResponse {
+	var response []PriceItemResponse
+
+	for _, val := range items {
+		response = append(response, PriceItemResponse{
+			Name:      val.Name,
+			StartTime: TimeToString(val.StartTime),
+			EndTime:   TimeToString(val.EndTime),
+			Price:     val.Price,
+		})
+	}
+
+	return response
 }
 
 type PaymentResponse struct {
@@ -104,13 +140,16 @@ type PaymentResponse struct {
 
 func (*CreateBookingResponse) FromModel(payload model.Booking) *CreateBookingResponse {
 	return &CreateBookingResponse{
-		BookingId:    payload.Id,
-		BookingDate:  DateToString(payload.BookingDate),
-		CustomerName: payload.Customer.Name,
-		CourtName:    payload.Court.Name,
-		StartTime:    TimeToString(payload.StartTime),
-		EndTime:      TimeToString(payload.EndTime),
-		TotalPayment: payload.Total_Payment,
+		BookingId:      payload.Id,
+		BookingDate:    DateToString(payload.BookingDate),
+		CustomerName:   payload.Customer.Name,
+		CourtName:      payload.Court.Name,
+		StartTime:      TimeToString(payload.StartTime),
+		EndTime:        TimeToString(payload.EndTime),
+		TotalPayment:   payload.Total_Payment,
+		Discount:       payload.Promo.Discount + payload.PointRedeemed.Amount,
+		Status:         string(payload.Status),
+		PriceBreakdown: priceBreakdownResponse(payload.PriceBreakdown),
 		Payment: PaymentResponse{
 			OrderId:     payload.PaymentDetails[0].OrderId,
 			Description: payload.PaymentDetails[0].Description,
@@ -120,6 +159,21 @@ func (*CreateBookingResponse) FromModel(payload model.Booking) *CreateBookingRes
 	}
 }
 
+// GuestBookingResponse adds the token a guest needs to view or cancel the
+// booking later without an account.
+type GuestBookingResponse struct {
+	*CreateBookingResponse
+	LookupToken string `json:"lookupToken"`
+}
+
+func (*GuestBookingResponse) FromModel(payload model.Booking, token string) *GuestBookingResponse {
+	var response CreateBookingResponse
+	return &GuestBookingResponse{
+		CreateBookingResponse: response.FromModel(payload),
+		LookupToken:           token,
+	}
+}
+
 type CreateRepaymentResponse struct {
 	BookingId     string `json:"bookingId"`
 	OrderId       string `json:"orderId"`
@@ -140,6 +194,248 @@ func (*CreateRepaymentResponse) FromModel(payload model.Payment) *CreateRepaymen
 	}
 }
 
+type RefundResponse struct {
+	BookingId     string `json:"bookingId"`
+	OrderId       string `json:"orderId"`
+	RefundOf      string `json:"refundOf"`
+	Description   string `json:"description"`
+	Price         int    `json:"price"`
+	PaymentMethod string `json:"paymentMethod"`
+}
+
+func (*RefundResponse) FromModel(payload model.Payment) *RefundResponse {
+	return &RefundResponse{
+		BookingId:     payload.BookingId,
+		OrderId:       payload.OrderId,
+		RefundOf:      payload.RefundOf,
+		Description:   payload.Description,
+		Price:         payload.Price,
+		PaymentMethod: payload.PaymentMethod,
+	}
+}
+
+type StatusHistoryResponse struct {
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	ChangedBy  string    `json:"changedBy"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func (*StatusHistoryResponse) FromModel(payload model.BookingStatusHistory) *StatusHistoryResponse {
+	return &StatusHistoryResponse{
+		FromStatus: string(payload.FromStatus),
+		ToStatus:   string(payload.ToStatus),
+		ChangedBy:  payload.ChangedBy,
+		Reason:     payload.Reason,
+		CreatedAt:  payload.CreatedAt,
+	}
+}
+
+type AuditLogResponse struct {
+	Id         string          `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityId   string          `json:"entityId"`
+	Detail     string          `json:"detail,omitempty"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// FromModel returns the stored before and after values as JSON objects
+// rather than strings, and null when there is no value.
+func (*AuditLogResponse) FromModel(payload model.AuditLog) *AuditLogResponse {
+	response := &AuditLogResponse{
+		Id:         payload.Id,
+		Actor:      payload.Actor,
+		Action:     payload.Action,
+		EntityType: payload.EntityType,
+		EntityId:   payload.EntityId,
+		Detail:     payload.Detail,
+		CreatedAt:  payload.CreatedAt,
+	}
+
+	if payload.Before != "" {
+		response.Before = json.RawMessage(payload.Before)
+	}
+	if payload.After != "" {
+		response.After = json.RawMessage(payload.After)
+	}
+
+	return response
+}
+
+type CreateBookingSeriesResponse struct {
+	SeriesId     string          `json:"seriesId"`
+	CustomerName string          `json:"customerName"`
+	CourtName    string          `json:"courtName"`
+	Frequency    string          `json:"frequency"`
+	StartTime    string          `json:"startTime"`
+	EndTime      string          `json:"endTime"`
+	BookingDates []string        `json:"bookingDates"`
+	TotalPayment int             `json:"totalPayment"`
+	Payment      PaymentResponse `json:"payment"`
+}
+
+func (*CreateBookingSeriesResponse) FromModel(payload model.BookingSeries) *CreateBookingSeriesResponse {
+	response := &CreateBookingSeriesResponse{
+		SeriesId:     payload.Id,
+		CustomerName: payload.Customer.Name,
+		CourtName:    payload.Court.Name,
+		Frequency:    payload.Frequency,
+		StartTime:    TimeToString(payload.StartTime),
+		EndTime:      TimeToString(payload.EndTime),
+		BookingDates: []string{},
+	}
+
+	for _, val := range payload.Bookings {
+		response.BookingDates = append(response.BookingDates, DateToString(val.BookingDate))
+		response.TotalPayment += val.Total_Payment
+
+		for _, p := range val.PaymentDetails {
+			response.Payment.OrderId = p.OrderId
+			response.Payment.Description = p.Description
+			response.Payment.PaymentUrl = p.PaymentURL
+			response.Payment.Price += p.Price
+		}
+	}
+
+	return response
+}
+
+type CancelBookingResponse struct {
+	BookingId    string            `json:"bookingId"`
+	Status       string            `json:"status"`
+	RefundAmount int               `json:"refundAmount"`
+	Refunds      []PaymentResponse `json:"refunds"`
+}
+
+func (*CancelBookingResponse) FromModel(payload model.Booking) *CancelBookingResponse {
+	response := &CancelBookingResponse{
+		BookingId: payload.Id,
+		Status:    string(payload.Status),
+		Refunds:   []PaymentResponse{},
+	}
+
+	for _, val := range payload.PaymentDetails {
+		response.RefundAmount += val.Price
+		response.Refunds = append(response.Refunds, PaymentResponse{
+			OrderId:     val.OrderId,
+			Description: val.Description,
+			Price:       val.Price,
+		})
+	}
+
+	return response
+}
+
+type RescheduleBookingResponse struct {
+	BookingId        string            `json:"bookingId"`
+	BookingDate      string            `json:"bookingDate"`
+	CourtName        string            `json:"courtName"`
+	StartTime        string            `json:"startTime"`
+	EndTime          string            `json:"endTime"`
+	TotalPayment     int               `json:"totalPayment"`
+	PaidAmount       int               `json:"paidAmount"`
+	RemainingPayment int               `json:"remainingPayment"`
+	Payments         []PaymentResponse `json:"payments"`
+}
+
+func (*RescheduleBookingResponse) FromModel(payload model.Booking) *RescheduleBookingResponse {
+	response := &RescheduleBookingResponse{
+		BookingId:    payload.Id,
+		BookingDate:  DateToString(payload.BookingDate),
+		CourtName:    payload.Court.Name,
+		StartTime:    TimeToString(payload.StartTime),
+		EndTime:      TimeToString(payload.EndTime),
+		TotalPayment: payload.Total_Payment,
+		Payments:     []PaymentResponse{},
+	}
+
+	for _, val := range payload.PaymentDetails {
+		if val.Status == "paid" {
+			response.PaidAmount += val.Price
+		}
+		if val.Status == "refund" {
+			response.PaidAmount -= val.Price
+		}
+		response.Payments = append(response.Payments, PaymentResponse{
+			OrderId:     val.OrderId,
+			Description: val.Description,
+			Price:       val.Price,
+			PaymentUrl:  val.PaymentURL,
+		})
+	}
+	response.RemainingPayment = response.TotalPayment - response.PaidAmount
+
+	return response
+}
+
+type CustomerBookingResponse struct {
+	BookingId        string                    `json:"bookingId"`
+	BookingDate      string                    `json:"bookingDate"`
+	CourtName        string                    `json:"courtName"`
+	StartTime        string                    `json:"startTime"`
+	EndTime          string                    `json:"endTime"`
+	Status           string                    `json:"status"`
+	TotalPayment     int                       `json:"totalPayment"`
+	PaidAmount       int                       `json:"paidAmount"`
+	RemainingPayment int                       `json:"remainingPayment"`
+	Payments         []CustomerPaymentResponse `json:"payments"`
+}
+
+type CustomerPaymentResponse struct {
+	OrderId       string `json:"orderId"`
+	Description   string `json:"description"`
+	PaymentMethod string `json:"paymentMethod"`
+	Price         int    `json:"price"`
+	Status        string `json:"status"`
+	PaymentUrl    string `json:"paymentUrl"`
+}
+
+// FromModel only shows a remaining payment while the booking is still due to
+// be played; nothing is owed on a cancelled, missed or refunded booking.
+func (*CustomerBookingResponse) FromModel(payload model.Booking) *CustomerBookingResponse {
+	response := &CustomerBookingResponse{
+		BookingId:    payload.Id,
+		BookingDate:  DateToString(payload.BookingDate),
+		CourtName:    payload.Court.Name,
+		StartTime:    TimeToString(payload.StartTime),
+		EndTime:      TimeToString(payload.EndTime),
+		Status:       string(payload.Status),
+		TotalPayment: payload.Total_Payment,
+		Payments:     []CustomerPaymentResponse{},
+	}
+
+	for _, val := range payload.PaymentDetails {
+		if val.Status == "paid" {
+			response.PaidAmount += val.Price
+		}
+		if val.Status == "refund" {
+			response.PaidAmount -= val.Price
+		}
+		response.Payments = append(response.Payments, CustomerPaymentResponse{
+			OrderId:       val.OrderId,
+			Description:   val.Description,
+			PaymentMethod: val.PaymentMethod,
+			Price:         val.Price,
+			Status:        val.Status,
+			PaymentUrl:    val.PaymentURL,
+		})
+	}
+
+	switch payload.Status {
+	case model.BookingPending, model.BookingBooked, model.BookingCheckedIn:
+		if response.TotalPayment > response.PaidAmount {
+			response.RemainingPayment = response.TotalPayment - response.PaidAmount
+		}
+	}
+
+	return response
+}
+
 type GetBookingsResponse struct {
 	Id          string      `json:"id"`
 	Customer    UserBooking `json:"customer"`
@@ -189,7 +485,7 @@ func (*GetBookingsResponse) FromModel(payload model.Booking) *GetBookingsRespons
 		BookingDate: DateToString(payload.BookingDate),
 		StartTime:   TimeToString(payload.StartTime),
 		EndTime:     TimeToString(payload.EndTime),
-		Status:      payload.Status,
+		Status:      string(payload.Status),
 		CreatedAt:   payload.CreatedAt,
 		UpdatedAt:   payload.UpdatedAt,
 	}
@@ -209,7 +505,107 @@ func (*CheckBookingResponse) FromModel(payload model.Booking) *CheckBookingRespo
 		BookingDate: DateToString(payload.BookingDate),
 		StartTime:   TimeToString(payload.StartTime),
 		EndTime:     TimeToString(payload.EndTime),
-		Status:      payload.Status,
+		Status:      string(payload.Status),
+	}
+}
+
+type CourtClosureResponse struct {
+	Id        string `json:"id"`
+	CourtId   string `json:"courtId"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Reason    string `json:"reason"`
+}
+
+func (*CourtClosureResponse) FromModel(payload model.CourtClosure) *CourtClosureResponse {
+	return &CourtClosureResponse{
+		Id:        payload.Id,
+		CourtId:   payload.CourtId,
+		StartDate: DateToString(payload.StartDate),
+		EndDate:   DateToString(payload.EndDate),
+		StartTime: TimeToString(payload.StartTime),
+		EndTime:   TimeToString(payload.EndTime),
+		Reason:    payload.Reason,
+	}
+}
+
+type ClosureConflictResponse struct {
+	BookingId   string `json:"bookingId"`
+	CustomerId  string `json:"customerId"`
+	BookingDate string `json:"bookingDate"`
+	StartTime   string `json:"startTime"`
+	EndTime     string `json:"endTime"`
+	Status      string `json:"status"`
+}
+
+type CreateCourtClosureResponse struct {
+	Closure             *CourtClosureResponse     `json:"closure"`
+	ConflictingBookings []ClosureConflictResponse `json:"conflictingBookings"`
+}
+
+func (*CreateCourtClosureResponse) FromModel(closure model.CourtClosure, conflicts []model.Booking) *CreateCourtClosureResponse {
+	var closureResponse CourtClosureResponse
+	conflictResponses := []ClosureConflictResponse{}
+
+	for _, val := range conflicts {
+		conflictResponses = append(conflictResponses, ClosureConflictResponse{
+			BookingId:   val.Id,
+			CustomerId:  val.Customer.Id,
+			BookingDate: DateToString(val.BookingDate),
+			StartTime:   TimeToString(val.StartTime),
+			EndTime:     TimeToString(val.EndTime),
+			Status:      string(val.Status),
+		})
+	}
+
+	return &CreateCourtClosureResponse{
+		Closure:             closureResponse.FromModel(closure),
+		ConflictingBookings: conflictResponses,
+	}
+}
+
+type CourtAvailabilityResponse struct {
+	CourtId   string                    `json:"courtId"`
+	CourtName string                    `json:"courtName"`
+	Days      []DayAvailabilityResponse `json:"days"`
+}
+
+type DayAvailabilityResponse struct {
+	Date  string         `json:"date"`
+	Slots []SlotResponse `json:"slots"`
+}
+
+type SlotResponse struct {
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Status    string `json:"status"`
+}
+
+func (*CourtAvailabilityResponse) FromModel(payload model.CourtAvailability) *CourtAvailabilityResponse {
+	var days []DayAvailabilityResponse
+
+	for _, day := range payload.Days {
+		var slots []SlotResponse
+		for _, slot := range day.Slots {
+			slots = append(slots, SlotResponse{
+				StartTime: TimeToString(slot.StartTime),
+				EndTime:   TimeToString(slot.EndTime),
+				Status:    slot.Status,
+			})
+		}
+
+		days = append(days, DayAvailabilityResponse{
+			Date:  DateToString(day.Date),
+			Slots: slots,
+		})
+	}
+
+	return &CourtAvailabilityResponse{
+		CourtId:   payload.Court.Id,
+		CourtName: payload.Court.Name,
+		Days:      days,
 	}
 }
 
@@ -233,7 +629,7 @@ func (*GetEndingResponse) FromModel(payload model.Booking) *GetEndingResponse {
 		StartTime:    TimeToString(payload.StartTime),
 		EndTime:      TimeToString(payload.EndTime),
 		TotalPayment: payload.Total_Payment,
-		Status:       payload.Status,
+		Status:       string(payload.Status),
 	}
 }
 
@@ -256,3 +652,209 @@ func (*GetPaymentReportResponse) FromModel(payload model.Payment) *GetPaymentRep
 		Price:         payload.Price,
 	}
 }
+
+type PricingRuleResponse struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	CourtId     string `json:"courtId"`
+	Weekdays    []int  `json:"weekdays"`
+	HolidayDate string `json:"holidayDate"`
+	StartTime   string `json:"startTime"`
+	EndTime     string `json:"endTime"`
+	Price       int    `json:"price"`
+}
+
+func (*PricingRuleResponse) FromModel(payload model.PricingRule) *PricingRuleResponse {
+	response := &PricingRuleResponse{
+		Id:        payload.Id,
+		Name:      payload.Name,
+		CourtId:   payload.CourtId,
+		Weekdays:  payload.Weekdays,
+		StartTime: TimeToString(payload.StartTime),
+		EndTime:   TimeToString(payload.EndTime),
+		Price:     payload.Price,
+	}
+
+	if payload.IsHoliday() {
+		response.HolidayDate = DateToString(payload.HolidayDate)
+	}
+
+	return response
+}
+
+type PromoCodeResponse struct {
+	Id               string   `json:"id"`
+	Code             string   `json:"code"`
+	DiscountType     string   `json:"discountType"`
+	DiscountValue    int      `json:"discountValue"`
+	MaxDiscount      int      `json:"maxDiscount"`
+	ValidFrom        string   `json:"validFrom"`
+	ValidUntil       string   `json:"validUntil"`
+	UsageLimit       int      `json:"usageLimit"`
+	PerCustomerLimit int      `json:"perCustomerLimit"`
+	CourtIds         []string `json:"courtIds"`
+}
+
+func (*PromoCodeResponse) FromModel(payload model.PromoCode) *PromoCodeResponse {
+	return &PromoCodeResponse{
+		Id:               payload.Id,
+		Code:             payload.Code,
+		DiscountType:     payload.DiscountType,
+		DiscountValue:    payload.DiscountValue,
+		MaxDiscount:      payload.MaxDiscount,
+		ValidFrom:        DateToString(payload.ValidFrom),
+		ValidUntil:       DateToString(payload.ValidUntil),
+		UsageLimit:       payload.UsageLimit,
+		PerCustomerLimit: payload.PerCustomerLimit,
+		CourtIds:         payload.CourtIds,
+	}
+}
+
+type LoyaltySettingResponse struct {
+	EarnPoints   int       `json:"earnPoints"`
+	EarnAmount   int       `json:"earnAmount"`
+	RedeemPoints int       `json:"redeemPoints"`
+	RedeemValue  int       `json:"redeemValue"`
+	ExpiryDays   int       `json:"expiryDays"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (*LoyaltySettingResponse) FromModel(payload model.LoyaltySetting) *LoyaltySettingResponse {
+	return &LoyaltySettingResponse{
+		EarnPoints:   payload.EarnPoints,
+		EarnAmount:   payload.EarnAmount,
+		RedeemPoints: payload.RedeemPoints,
+		RedeemValue:  payload.RedeemValue,
+		ExpiryDays:   payload.ExpiryDays,
+		UpdatedAt:    payload.UpdatedAt,
+	}
+}
+
+type PointEntryResponse struct {
+	Id          string    `json:"id"`
+	BookingId   string    `json:"bookingId"`
+	Type        string    `json:"type"`
+	Points      int       `json:"points"`
+	Amount      int       `json:"amount"`
+	Description string    `json:"description"`
+	ExpiresAt   string    `json:"expiresAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (*PointEntryResponse) FromModel(payload model.PointEntry) *PointEntryResponse {
+	response := &PointEntryResponse{
+		Id:          payload.Id,
+		BookingId:   payload.BookingId,
+		Type:        payload.Type,
+		Points:      payload.Points,
+		Amount:      payload.Amount,
+		Description: payload.Description,
+		CreatedAt:   payload.CreatedAt,
+	}
+
+	if !payload.ExpiresAt.IsZero() {
+		response.ExpiresAt = DateToString(payload.ExpiresAt)
+	}
+
+	return response
+}
+
+type PointBalanceResponse struct {
+	CustomerId string               `json:"customerId"`
+	Balance    int                  `json:"balance"`
+	Entries    []PointEntryResponse `json:"entries"`
+	Paginate   dto.Paginate         `json:"paginate"`
+}
+
+func (*PointBalanceResponse) FromModel(payload model.PointBalance, paginate dto.Paginate) *PointBalanceResponse {
+	response := &PointBalanceResponse{
+		CustomerId: payload.CustomerId,
+		Balance:    payload.Balance,
+		Entries:    []PointEntryResponse{},
+		Paginate:   paginate,
+	}
+
+	var entryTemplate PointEntryResponse
+	for _, entry := range payload.Entries {
+		response.Entries = append(response.Entries, *entryTemplate.FromModel(entry))
+	}
+
+	return response
+}
+
+type ClaimGuestResponse struct {
+	ClaimedBookings int `json:"claimedBookings"`
+}
+
+type NoShowCountResponse struct {
+	CustomerId  string `json:"customerId"`
+	NoShowCount int    `json:"noShowCount"`
+}
+
+type RestrictionResponse struct {
+	Id         string     `json:"id"`
+	CustomerId string     `json:"customerId"`
+	Type       string     `json:"type"`
+	Reason     string     `json:"reason"`
+	StartsAt   time.Time  `json:"startsAt"`
+	EndsAt     time.Time  `json:"endsAt"`
+	ImposedBy  string     `json:"imposedBy"`
+	LiftedBy   string     `json:"liftedBy,omitempty"`
+	LiftedAt   *time.Time `json:"liftedAt,omitempty"`
+	Active     bool       `json:"active"`
+}
+
+func (*RestrictionResponse) FromModel(payload model.Restriction) *RestrictionResponse {
+	response := &RestrictionResponse{
+		Id:         payload.Id,
+		CustomerId: payload.CustomerId,
+		Type:       string(payload.Type),
+		Reason:     payload.Reason,
+		StartsAt:   payload.StartsAt,
+		EndsAt:     payload.EndsAt,
+		ImposedBy:  payload.ImposedBy,
+		LiftedBy:   payload.LiftedBy,
+		Active:     payload.IsActiveAt(time.Now()),
+	}
+
+	if !payload.LiftedAt.IsZero() {
+		response.LiftedAt = &payload.LiftedAt
+	}
+
+	return response
+}
+
+type WaitlistEntryResponse struct {
+	Id               string     `json:"id"`
+	CourtId          string     `json:"courtId"`
+	BookingDate      string     `json:"bookingDate"`
+	StartTime        string     `json:"startTime"`
+	EndTime          string     `json:"endTime"`
+	Hour             int        `json:"hour"`
+	Status           string     `json:"status"`
+	OfferedCourtId   string     `json:"offeredCourtId,omitempty"`
+	OfferedStartTime string     `json:"offeredStartTime,omitempty"`
+	OfferExpiresAt   *time.Time `json:"offerExpiresAt,omitempty"`
+	BookingId        string     `json:"bookingId,omitempty"`
+}
+
+func (*WaitlistEntryResponse) FromModel(payload model.WaitlistEntry) *WaitlistEntryResponse {
+	response := &WaitlistEntryResponse{
+		Id:             payload.Id,
+		CourtId:        payload.CourtId,
+		BookingDate:    DateToString(payload.BookingDate),
+		StartTime:      TimeToString(payload.StartTime),
+		EndTime:        TimeToString(payload.EndTime),
+		Hour:           payload.Hour,
+		Status:         string(payload.Status),
+		OfferedCourtId: payload.OfferedCourtId,
+		BookingId:      payload.BookingId,
+	}
+
+	if payload.OfferedCourtId != "" {
+		response.OfferedStartTime = TimeToString(payload.OfferedStartTime)
+		response.OfferExpiresAt = &payload.OfferExpiresAt
+	}
+
+	return response
+}
@@ -22,6 +22,10 @@ func StringToDate(dateString string) time.Time {
 	return formatedDate
 }
 
+func CombineDateTime(date, clock time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, time.UTC)
+}
+
 func InTimeSpanStart(start, end, checkStart time.Time) bool {
 	if checkStart.After(start) || checkStart.Equal(start) {
 		if checkStart.Equal(end) || checkStart.After(end) {